@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// recentChangeModels are the kinds of content listed as recent changes
+var recentChangeModels = []string{"card", "dataset", "metric", "dashboard"}
+
+// activityEvent is an entry of /api/activity, the instance's activity feed
+type activityEvent struct {
+	Topic     string `json:"topic"` // e.g. "card-update" or "dashboard-create"
+	Model     string `json:"model"`
+	ModelID   int    `json:"model_id"`
+	Timestamp string `json:"timestamp"`
+	Exists    *bool  `json:"model_exists"`
+	User      struct {
+		CommonName string `json:"common_name"`
+	} `json:"user"`
+	Details struct {
+		Name string `json:"name"`
+	} `json:"details"`
+}
+
+// GetRecentChanges lists the questions and dashboards edited lately, newest
+// first, with who edited them and when in LastEditor and LastEditedAt. The
+// activity feed is used where Metabase still has it; newer versions dropped
+// it, so the search is asked for the content instead and sorted by its last
+// edit.
+func (c *MetabaseClient) GetRecentChanges(limit int) ([]SearchResult, error) {
+	changes, err := c.getActivityChanges()
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		changes, err = c.searchChanges()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// getActivityChanges keeps the newest creation or edit of each item of the
+// activity feed, which lists events newest first
+func (c *MetabaseClient) getActivityChanges() ([]SearchResult, error) {
+	req, err := c.newRequest("GET", "/api/activity", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Action: "get activity", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var events []activityEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	var changes []SearchResult
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if !strings.HasSuffix(event.Topic, "-create") && !strings.HasSuffix(event.Topic, "-update") {
+			continue
+		}
+		// Deleted content can't be opened any more
+		if event.Exists != nil && !*event.Exists {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", event.Model, event.ModelID)
+		if seen[key] || !slices.Contains(recentChangeModels, event.Model) {
+			continue
+		}
+		seen[key] = true
+		changes = append(changes, SearchResult{
+			ID:           event.ModelID,
+			Name:         event.Details.Name,
+			Model:        event.Model,
+			LastEditedAt: event.Timestamp,
+			LastEditor:   event.User.CommonName,
+		})
+	}
+	return changes, nil
+}
+
+// searchChanges lists the content the search finds, last edited first.
+// Content never edited since it was created sorts by its update time.
+func (c *MetabaseClient) searchChanges() ([]SearchResult, error) {
+	results, err := c.Search("", SearchFilter{Models: recentChangeModels})
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if results[i].LastEditedAt == "" {
+			results[i].LastEditedAt = results[i].UpdatedAt
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return editTime(results[i]).After(editTime(results[j]))
+	})
+	return results, nil
+}
+
+// editTime parses when a result was last edited; results with a time that
+// can't be read sort last
+func editTime(result SearchResult) time.Time {
+	edited, err := time.Parse(time.RFC3339Nano, result.LastEditedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return edited
+}
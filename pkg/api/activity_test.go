@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMetabaseClient_GetRecentChanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		activity     string // Answer of /api/activity, empty for a 404
+		search       string
+		expectError  string
+		expected     []string // Names in order
+		expectEditor string   // Editor of the first change
+	}{
+		{
+			name: "activity feed",
+			activity: `[
+				{"topic": "card-update", "model": "card", "model_id": 21, "timestamp": "2024-03-02T10:00:00Z", "user": {"common_name": "Ann Lee"}, "details": {"name": "Orders by month"}},
+				{"topic": "user-joined", "model": "user", "model_id": 3, "timestamp": "2024-03-01T12:00:00Z"},
+				{"topic": "dashboard-create", "model": "dashboard", "model_id": 7, "timestamp": "2024-03-01T09:00:00Z", "user": {"common_name": "Bo Ito"}, "details": {"name": "Revenue"}},
+				{"topic": "card-create", "model": "card", "model_id": 21, "timestamp": "2024-02-01T09:00:00Z", "details": {"name": "Orders by month"}},
+				{"topic": "card-update", "model": "card", "model_id": 22, "timestamp": "2024-01-01T09:00:00Z", "model_exists": false, "details": {"name": "Deleted"}}
+			]`,
+			expected:     []string{"Orders by month", "Revenue"},
+			expectEditor: "Ann Lee",
+		},
+		{
+			name: "search without the activity feed",
+			search: `{"data": [
+				{"id": 1, "name": "Old", "model": "card", "updated_at": "2023-05-01T00:00:00Z"},
+				{"id": 2, "name": "Edited", "model": "dashboard", "last_edited_at": "2024-03-02T10:00:00.123Z", "last_editor_common_name": "Ann Lee"},
+				{"id": 3, "name": "Created", "model": "dataset", "updated_at": "2024-01-01T00:00:00+02:00"}
+			]}`,
+			expected:     []string{"Edited", "Created", "Old"},
+			expectEditor: "Ann Lee",
+		},
+		{
+			name:        "search error",
+			search:      "",
+			expectError: "failed to search: 500 - boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/api/activity" && tt.activity != "":
+					w.Write([]byte(tt.activity))
+				case r.URL.Path == "/api/activity":
+					http.NotFound(w, r)
+				case r.URL.Path == "/api/search" && tt.search != "":
+					if models := r.URL.Query()["models"]; !reflect.DeepEqual(models, recentChangeModels) {
+						t.Errorf("search models = %v, want %v", models, recentChangeModels)
+					}
+					w.Write([]byte(tt.search))
+				default:
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte("boom"))
+				}
+			}))
+			defer server.Close()
+
+			changes, err := NewMetabaseClient(server.URL, "test-token", "dev").GetRecentChanges(10)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("GetRecentChanges() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetRecentChanges() unexpected error = %v", err)
+			}
+			var names []string
+			for _, change := range changes {
+				names = append(names, change.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Fatalf("GetRecentChanges() = %q, want %q", names, tt.expected)
+			}
+			if changes[0].LastEditor != tt.expectEditor || changes[0].LastEditedAt == "" {
+				t.Errorf("first change edited by %q at %q, want %q", changes[0].LastEditor, changes[0].LastEditedAt, tt.expectEditor)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_GetRecentChanges_Limit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"topic": "card-update", "model": "card", "model_id": 1, "details": {"name": "One"}},
+			{"topic": "card-update", "model": "card", "model_id": 2, "details": {"name": "Two"}},
+			{"topic": "card-update", "model": "card", "model_id": 3, "details": {"name": "Three"}}
+		]`))
+	}))
+	defer server.Close()
+
+	changes, err := NewMetabaseClient(server.URL, "test-token", "dev").GetRecentChanges(2)
+	if err != nil || len(changes) != 2 {
+		t.Errorf("GetRecentChanges(2) = %d changes, %v, want 2", len(changes), err)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRevalidatingTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator string // Response header carrying the validator
+		value     string
+		condition string // Request header expected to echo it
+	}{
+		{name: "etag", validator: "ETag", value: `"v1"`, condition: "If-None-Match"},
+		{name: "last modified", validator: "Last-Modified", value: "Tue, 13 Oct 2026 08:00:00 GMT", condition: "If-Modified-Since"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"data": [{"id": 1, "name": "Sample"}]}`
+			var conditions []string
+			notModified := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conditions = append(conditions, r.Header.Get(tt.condition))
+				if r.Header.Get(tt.condition) == tt.value {
+					notModified++
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set(tt.validator, tt.value)
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			for i := 0; i < 2; i++ {
+				databases, err := client.GetDatabases()
+				if err != nil {
+					t.Fatalf("GetDatabases() #%d unexpected error = %v", i+1, err)
+				}
+				if len(databases) != 1 || databases[0].Name != "Sample" {
+					t.Errorf("GetDatabases() #%d = %+v, want the Sample database", i+1, databases)
+				}
+			}
+			if conditions[0] != "" || conditions[1] != tt.value {
+				t.Errorf("%s sent = %q, want none and then %q", tt.condition, conditions, tt.value)
+			}
+			if notModified != 1 {
+				t.Errorf("server answered %d requests with 304, want 1", notModified)
+			}
+
+			// A changed resource replaces what was remembered
+			tt.value = "changed"
+			body = `{"data": [{"id": 2, "name": "Warehouse"}]}`
+			databases, err := client.GetDatabases()
+			if err != nil {
+				t.Fatalf("GetDatabases() unexpected error = %v", err)
+			}
+			if len(databases) != 1 || databases[0].Name != "Warehouse" {
+				t.Errorf("GetDatabases() after a change = %+v, want the Warehouse database", databases)
+			}
+		})
+	}
+}
+
+func TestRevalidatingTransport_WithoutValidators(t *testing.T) {
+	var conditional bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			conditional = true
+		}
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "test-token", "dev")
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetDatabases(); err != nil {
+			t.Fatalf("GetDatabases() unexpected error = %v", err)
+		}
+	}
+	if conditional {
+		t.Error("GetDatabases() sent a conditional request for a response without validators")
+	}
+}
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,29 +15,95 @@ type MetabaseClient struct {
 	BaseURL    string
 	APIToken   string
 	HTTPClient *http.Client
+	ReadOnly   bool              // Refuse every request that could modify Metabase
+	UserAgent  string            // Sent with every request so mbx shows up in access logs
+	BearerAuth bool              // Send the token as Authorization: Bearer, for auth gateways, instead of X-API-Key
+	Headers    map[string]string // Sent with every request; the auth header and User-Agent win over them
+	Cache      *DiskCache        // Saves lists for the next session; nil keeps nothing on disk
 }
 
-func NewMetabaseClient(baseURL, apiToken string) *MetabaseClient {
-	return &MetabaseClient{
-		BaseURL:    baseURL,
-		APIToken:   apiToken,
-		HTTPClient: &http.Client{},
+// ErrReadOnly is returned for write requests made in read-only mode
+var ErrReadOnly = errors.New("not allowed in read-only mode")
+
+// NewMetabaseClient returns a client identifying itself as the given mbx
+// version
+func NewMetabaseClient(baseURL, apiToken, version string) *MetabaseClient {
+	c := &MetabaseClient{
+		BaseURL:   baseURL,
+		APIToken:  apiToken,
+		UserAgent: DefaultUserAgent(version),
 	}
+	c.HTTPClient = &http.Client{Transport: newRevalidatingTransport(NewTransport(nil))}
+	return c
 }
 
-func (c *MetabaseClient) TestConnection() error {
+// UseProxy sends the client's requests through proxy instead of the one
+// from the environment
+func (c *MetabaseClient) UseProxy(proxy *url.URL) {
+	c.HTTPClient.Transport = newRevalidatingTransport(NewTransport(proxy))
+}
+
+// WithToken returns a copy of the client that sends another API token,
+// sharing its connections
+func (c *MetabaseClient) WithToken(apiToken string) *MetabaseClient {
+	copied := *c
+	copied.APIToken = apiToken
+	return &copied
+}
+
+// DefaultUserAgent is the User-Agent sent by the given mbx version
+func DefaultUserAgent(version string) string {
+	if version == "" {
+		return "mbx"
+	}
+	return "mbx/" + version
+}
+
+// newRequest builds an authenticated request for an API path. Anything but
+// GET is refused when the client is read-only.
+func (c *MetabaseClient) newRequest(method, path string, body io.Reader) (*http.Request, error) {
+	if c.ReadOnly && method != http.MethodGet {
+		return nil, ErrReadOnly
+	}
+	return c.buildRequest(method, path, body)
+}
+
+// buildRequest is newRequest without the read-only check, for the POST
+// endpoints that only read data
+func (c *MetabaseClient) buildRequest(method, path string, body io.Reader) (*http.Request, error) {
 	baseURL, err := url.Parse(c.BaseURL)
 	if err != nil {
-		return fmt.Errorf("invalid base URL: %v", err)
+		return nil, fmt.Errorf("invalid base URL: %v", err)
 	}
 
-	apiURL, err := baseURL.Parse("/api/user/current")
+	apiURL, err := baseURL.Parse(path)
 	if err != nil {
-		return fmt.Errorf("failed to construct API URL: %v", err)
+		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+	}
+
+	req, err := http.NewRequest(method, apiURL.String(), body)
+	if err != nil {
+		return nil, err
 	}
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+	if c.BearerAuth {
+		req.Header.Set("Authorization", "Bearer "+c.APIToken)
+	} else {
+		req.Header.Set("X-API-Key", c.APIToken)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	return req, nil
+}
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
+func (c *MetabaseClient) TestConnection() error {
+	req, err := c.newRequest("GET", "/api/user/current", nil)
+	if err != nil {
+		return err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -44,24 +113,41 @@ func (c *MetabaseClient) TestConnection() error {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API token authentication failed with status: %d - %s", resp.StatusCode, string(body))
+		return fmt.Errorf("API token authentication failed: %w", &StatusError{Action: "get current user", StatusCode: resp.StatusCode, Body: string(body)})
 	}
 	return nil
 }
 
-func (c *MetabaseClient) GetDatabases() ([]Database, error) {
-	baseURL, err := url.Parse(c.BaseURL)
+// GetCurrentUser returns the user the API token belongs to
+func (c *MetabaseClient) GetCurrentUser() (*UserInfo, error) {
+	req, err := c.newRequest("GET", "/api/user/current", nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
+		return nil, err
 	}
 
-	apiURL, err := baseURL.Parse("/api/database")
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Action: "get current user", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
+	var user UserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+	return &user, nil
+}
+
+func (c *MetabaseClient) GetDatabases() ([]Database, error) {
+	req, err := c.newRequest("GET", "/api/database", nil)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -71,27 +157,27 @@ func (c *MetabaseClient) GetDatabases() ([]Database, error) {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get databases: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get databases", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var result map[string][]Database
 	json.NewDecoder(resp.Body).Decode(&result)
+	c.Cache.store("databases", result["data"])
 	return result["data"], nil
 }
 
 func (c *MetabaseClient) GetTables(databaseID int) ([]Table, error) {
-	baseURL, err := url.Parse(c.BaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
-	}
+	return c.GetTablesContext(context.Background(), databaseID)
+}
 
-	apiURL, err := baseURL.Parse(fmt.Sprintf("/api/database/%d/metadata", databaseID))
+// GetTablesContext is GetTables with a context, so a prefetch that is no
+// longer wanted can be cancelled
+func (c *MetabaseClient) GetTablesContext(ctx context.Context, databaseID int) ([]Table, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/database/%d/metadata", databaseID), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+		return nil, err
 	}
-
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
+	req = req.WithContext(ctx)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -101,7 +187,7 @@ func (c *MetabaseClient) GetTables(databaseID int) ([]Table, error) {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get tables: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get tables", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	body, _ := io.ReadAll(resp.Body)
@@ -116,19 +202,36 @@ func (c *MetabaseClient) GetTables(databaseID int) ([]Table, error) {
 	return metadata.Tables, nil
 }
 
-func (c *MetabaseClient) GetTableFields(tableID int) ([]Field, error) {
-	baseURL, err := url.Parse(c.BaseURL)
+func (c *MetabaseClient) GetTable(tableID int) (*Table, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/table/%d", tableID), nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
+		return nil, err
 	}
 
-	apiURL, err := baseURL.Parse(fmt.Sprintf("/api/table/%d/query_metadata", tableID))
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Action: "get table", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
+	var table Table
+	if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return &table, nil
+}
+
+func (c *MetabaseClient) GetTableFields(tableID int) ([]Field, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/table/%d/query_metadata", tableID), nil)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -138,7 +241,7 @@ func (c *MetabaseClient) GetTableFields(tableID int) ([]Field, error) {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get table fields: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get table fields", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	body, _ := io.ReadAll(resp.Body)
@@ -153,19 +256,57 @@ func (c *MetabaseClient) GetTableFields(tableID int) ([]Field, error) {
 	return queryMeta.Fields, nil
 }
 
-func (c *MetabaseClient) GetCollections() ([]Collection, error) {
-	baseURL, err := url.Parse(c.BaseURL)
+// GetCollections returns root-level collections. Archived collections are
+// skipped unless includeArchived is set. Metabase lists them on their own
+// with archived=true, so they take a second request.
+func (c *MetabaseClient) GetCollections(includeArchived bool) ([]Collection, error) {
+	allCollections, err := c.fetchCollections("/api/collection")
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
+		return nil, err
+	}
+	if includeArchived {
+		archived, err := c.fetchCollections("/api/collection?archived=true")
+		if err != nil {
+			return nil, err
+		}
+		// Versions that ignore archived=true list every collection again
+		listed := make(map[string]bool)
+		for _, collection := range allCollections {
+			listed[fmt.Sprint(collection.ID)] = true
+		}
+		for _, collection := range archived {
+			if !listed[fmt.Sprint(collection.ID)] {
+				allCollections = append(allCollections, collection)
+			}
+		}
 	}
 
-	apiURL, err := baseURL.Parse("/api/collection")
-	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+	// Filter for meaningful root-level collections
+	// Include: root collection (id="root") and all collections at "/" (personal and non-personal)
+	var rootCollections []Collection
+	for _, collection := range allCollections {
+		if collection.Archived && !includeArchived {
+			continue
+		}
+		// Include the root collection itself
+		if collection.ID == "root" {
+			rootCollections = append(rootCollections, collection)
+		} else if collection.Location == "/" {
+			// Include all collections at root level (both personal and organizational)
+			rootCollections = append(rootCollections, collection)
+		}
 	}
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
+	c.Cache.store(collectionsCacheKey(includeArchived), rootCollections)
+	return rootCollections, nil
+}
+
+// fetchCollections decodes a list of collections from path
+func (c *MetabaseClient) fetchCollections(path string) ([]Collection, error) {
+	req, err := c.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -175,43 +316,79 @@ func (c *MetabaseClient) GetCollections() ([]Collection, error) {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get collections: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get collections", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	var allCollections []Collection
-	if err := json.NewDecoder(resp.Body).Decode(&allCollections); err != nil {
+	var collections []Collection
+	if err := json.NewDecoder(resp.Body).Decode(&collections); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %v", err)
 	}
+	return collections, nil
+}
 
-	// Filter for meaningful root-level collections
-	// Include: root collection (id="root") and all collections at "/" (personal and non-personal)
-	var rootCollections []Collection
-	for _, collection := range allCollections {
-		// Include the root collection itself
-		if collection.ID == "root" {
-			rootCollections = append(rootCollections, collection)
-		} else if collection.Location == "/" {
-			// Include all collections at root level (both personal and organizational)
-			rootCollections = append(rootCollections, collection)
-		}
+// GetCollectionTree returns the top-level collections with their
+// sub-collections nested in Children. Archived collections are skipped unless
+// includeArchived is set.
+func (c *MetabaseClient) GetCollectionTree(includeArchived bool) ([]Collection, error) {
+	path := "/api/collection/tree"
+	if !includeArchived {
+		path += "?exclude-archived=true"
+	}
+	req, err := c.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return rootCollections, nil
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Action: "get collection tree", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var tree []Collection
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return tree, nil
 }
 
-func (c *MetabaseClient) GetCollectionItems(collectionID interface{}) ([]CollectionItem, error) {
-	baseURL, err := url.Parse(c.BaseURL)
+// GetCollection returns a single collection, including its ancestors
+func (c *MetabaseClient) GetCollection(collectionID interface{}) (*Collection, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/collection/%v", collectionID), nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
+		return nil, err
 	}
 
-	apiURL, err := baseURL.Parse(fmt.Sprintf("/api/collection/%v/items", collectionID))
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Action: "get collection", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var collection Collection
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return &collection, nil
+}
+
+func (c *MetabaseClient) GetCollectionItems(collectionID interface{}) ([]CollectionItem, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/collection/%v/items", collectionID), nil)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -221,7 +398,7 @@ func (c *MetabaseClient) GetCollectionItems(collectionID interface{}) ([]Collect
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get collection items: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get collection items", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var result struct {
@@ -259,19 +436,44 @@ func (c *MetabaseClient) GetCollectionItems(collectionID interface{}) ([]Collect
 	return sortedItems, nil
 }
 
-func (c *MetabaseClient) GetCardDetail(cardID int) (*CardDetail, error) {
-	baseURL, err := url.Parse(c.BaseURL)
+// CountCollectionItems counts the items in a collection without listing
+// them. Metabase reports the total of a paged listing, so a single item is
+// asked for; older versions that don't page send everything instead.
+func (c *MetabaseClient) CountCollectionItems(collectionID interface{}) (int, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/collection/%v/items?limit=1&offset=0", collectionID), nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
+		return 0, err
 	}
 
-	apiURL, err := baseURL.Parse(fmt.Sprintf("/api/card/%d", cardID))
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, &StatusError{Action: "count collection items", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
+	var result struct {
+		Data  []json.RawMessage `json:"data"`
+		Total *int              `json:"total"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %v", err)
+	}
+	if result.Total != nil {
+		return *result.Total, nil
+	}
+	return len(result.Data), nil
+}
+
+func (c *MetabaseClient) GetCardDetail(cardID int) (*CardDetail, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/card/%d", cardID), nil)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -281,7 +483,7 @@ func (c *MetabaseClient) GetCardDetail(cardID int) (*CardDetail, error) {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get card detail: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get card detail", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var card CardDetail
@@ -293,19 +495,11 @@ func (c *MetabaseClient) GetCardDetail(cardID int) (*CardDetail, error) {
 }
 
 func (c *MetabaseClient) GetDashboardDetail(dashboardID int) (*DashboardDetail, error) {
-	baseURL, err := url.Parse(c.BaseURL)
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/dashboard/%d", dashboardID), nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
-	}
-
-	apiURL, err := baseURL.Parse(fmt.Sprintf("/api/dashboard/%d", dashboardID))
-	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+		return nil, err
 	}
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
-
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -314,7 +508,7 @@ func (c *MetabaseClient) GetDashboardDetail(dashboardID int) (*DashboardDetail,
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get dashboard detail: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get dashboard detail", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var dashboard DashboardDetail
@@ -326,19 +520,11 @@ func (c *MetabaseClient) GetDashboardDetail(dashboardID int) (*DashboardDetail,
 }
 
 func (c *MetabaseClient) GetMetricDetail(metricID int) (*MetricDetail, error) {
-	baseURL, err := url.Parse(c.BaseURL)
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/card/%d", metricID), nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
-	}
-
-	apiURL, err := baseURL.Parse(fmt.Sprintf("/api/card/%d", metricID))
-	if err != nil {
-		return nil, fmt.Errorf("failed to construct API URL: %v", err)
+		return nil, err
 	}
 
-	req, _ := http.NewRequest("GET", apiURL.String(), nil)
-	req.Header.Set("X-API-Key", c.APIToken)
-
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -347,7 +533,7 @@ func (c *MetabaseClient) GetMetricDetail(metricID int) (*MetricDetail, error) {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get metric detail: %d - %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Action: "get metric detail", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var metric MetricDetail
@@ -357,3 +543,218 @@ func (c *MetabaseClient) GetMetricDetail(metricID int) (*MetricDetail, error) {
 
 	return &metric, nil
 }
+
+// GetTableRelated returns the saved questions built on a table, preceded by
+// the dashboards those questions are saved in
+func (c *MetabaseClient) GetTableRelated(tableID int) ([]CollectionItem, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/card?f=table&model_id=%d", tableID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Action: "get table related", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var cards []struct {
+		ID           int    `json:"id"`
+		Name         string `json:"name"`
+		Description  string `json:"description"`
+		Type         string `json:"type"` // "question", "model" or "metric"
+		CollectionID *int   `json:"collection_id"`
+		DatabaseID   *int   `json:"database_id"`
+		Archived     bool   `json:"archived"`
+		Dashboard    *struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"dashboard"` // Set for questions saved in a dashboard
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&cards); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	var dashboards []CollectionItem
+	var questions []CollectionItem
+	seen := make(map[int]bool)
+	for _, card := range cards {
+		if card.Dashboard != nil && !seen[card.Dashboard.ID] {
+			seen[card.Dashboard.ID] = true
+			dashboards = append(dashboards, CollectionItem{ID: card.Dashboard.ID, Name: card.Dashboard.Name, Model: "dashboard"})
+		}
+
+		item := CollectionItem{
+			ID:          card.ID,
+			Name:        card.Name,
+			Description: card.Description,
+			Model:       "card",
+			DatabaseID:  card.DatabaseID,
+			Archived:    card.Archived,
+		}
+		if card.Type == "model" {
+			item.Model = "dataset"
+		} else if card.Type == "metric" {
+			item.Model = "metric"
+		}
+		if card.CollectionID != nil {
+			item.CollectionID = *card.CollectionID
+		}
+		questions = append(questions, item)
+	}
+
+	return append(dashboards, questions...), nil
+}
+
+// SyncDatabaseSchema asks Metabase to re-scan a database's schema
+func (c *MetabaseClient) SyncDatabaseSchema(databaseID int) error {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/database/%d/sync_schema", databaseID), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{Action: "sync database schema", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// SetArchived moves an item to the trash, or back out of it when archived is
+// false. The model is the one collection items and search results have, such
+// as "card" or "collection".
+func (c *MetabaseClient) SetArchived(model string, id int, archived bool) error {
+	action := "archive " + model
+	if !archived {
+		action = "unarchive " + model
+	}
+	return c.updateItem(action, model, id, map[string]interface{}{"archived": archived})
+}
+
+// MoveItem saves an item in another collection, or in the root collection
+// when targetCollectionID is 0. A collection is moved under the target.
+func (c *MetabaseClient) MoveItem(model string, id, targetCollectionID int) error {
+	var target interface{}
+	if targetCollectionID != 0 {
+		target = targetCollectionID
+	}
+	field := "collection_id"
+	if model == "collection" {
+		field = "parent_id"
+	}
+	return c.updateItem("move "+model, model, id, map[string]interface{}{field: target})
+}
+
+// updateItem changes the given fields of an item, leaving the others as
+// they are
+func (c *MetabaseClient) updateItem(action, model string, id int, fields map[string]interface{}) error {
+	path, err := itemAPIPath(model, id)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest("PUT", path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{Action: action, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// itemAPIPath is the API endpoint of an item. Models and metrics are cards
+// to the API.
+func itemAPIPath(model string, id int) (string, error) {
+	switch model {
+	case "card", "dataset", "metric":
+		return fmt.Sprintf("/api/card/%d", id), nil
+	case "dashboard", "collection":
+		return fmt.Sprintf("/api/%s/%d", model, id), nil
+	}
+	return "", fmt.Errorf("can't change a %s", model)
+}
+
+// RunCard runs a saved question with the given parameter values and returns
+// its result columns and rows
+func (c *MetabaseClient) RunCard(cardID int, params ...ParameterValue) ([]string, [][]interface{}, error) {
+	return c.RunCardContext(context.Background(), cardID, params...)
+}
+
+// RunCardContext is RunCard with a context that can cancel a long query.
+// Running a query only reads data, so it is allowed in read-only mode.
+func (c *MetabaseClient) RunCardContext(ctx context.Context, cardID int, params ...ParameterValue) ([]string, [][]interface{}, error) {
+	var body io.Reader
+	if len(params) > 0 {
+		payload, err := json.Marshal(map[string][]ParameterValue{"parameters": params})
+		if err != nil {
+			return nil, nil, err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := c.buildRequest("POST", fmt.Sprintf("/api/card/%d/query", cardID), body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// Metabase streams query results and answers 202 Accepted
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, nil, &StatusError{Action: "run card", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result QueryResult
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber() // Keep large integers and IDs exact
+	if err := decoder.Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	// Failed queries still stream a 202, with the reason in the body
+	if result.Status == "failed" {
+		return nil, nil, fmt.Errorf("query failed: %s", result.Error)
+	}
+
+	columns := make([]string, len(result.Data.Cols))
+	for i, col := range result.Data.Cols {
+		columns[i] = col.DisplayName
+		if columns[i] == "" {
+			columns[i] = col.Name
+		}
+	}
+	return columns, result.Data.Rows, nil
+}
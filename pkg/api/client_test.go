@@ -1,13 +1,17 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
 func TestNewMetabaseClient(t *testing.T) {
-	client := NewMetabaseClient("https://example.com", "test-token")
+	client := NewMetabaseClient("https://example.com", "test-token", "dev")
 
 	if client.BaseURL != "https://example.com" {
 		t.Errorf("NewMetabaseClient() BaseURL = %s, want https://example.com", client.BaseURL)
@@ -20,6 +24,103 @@ func TestNewMetabaseClient(t *testing.T) {
 	}
 }
 
+func TestMetabaseClient_UserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		override  string
+		userAgent string
+	}{
+		{name: "version", version: "v1.4.0", userAgent: "mbx/v1.4.0"},
+		{name: "no version", userAgent: "mbx"},
+		{name: "override", version: "v1.4.0", override: "acme-analytics/2.0", userAgent: "acme-analytics/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+				w.Write([]byte(`{"id": 1}`))
+			}))
+			defer server.Close()
+
+			pool := NewClientPool(tt.version)
+			pool.UserAgent = tt.override
+			if err := pool.Get("work", server.URL, "test-token").TestConnection(); err != nil {
+				t.Fatalf("TestConnection() unexpected error = %v", err)
+			}
+			if got != tt.userAgent {
+				t.Errorf("User-Agent = %q, want %q", got, tt.userAgent)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_AuthHeader(t *testing.T) {
+	tests := []struct {
+		name          string
+		bearer        bool
+		apiKey        string
+		authorization string
+	}{
+		{name: "x-api-key", apiKey: "test-token"},
+		{name: "bearer", bearer: true, authorization: "Bearer test-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var apiKey, authorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				apiKey = r.Header.Get("X-API-Key")
+				authorization = r.Header.Get("Authorization")
+				w.Write([]byte(`{"id": 1}`))
+			}))
+			defer server.Close()
+
+			pool := NewClientPool("dev")
+			pool.Bearer = tt.bearer
+			if err := pool.Get("work", server.URL, "test-token").TestConnection(); err != nil {
+				t.Fatalf("TestConnection() unexpected error = %v", err)
+			}
+			if apiKey != tt.apiKey || authorization != tt.authorization {
+				t.Errorf("X-API-Key = %q, Authorization = %q, want %q and %q", apiKey, authorization, tt.apiKey, tt.authorization)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_Headers(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	pool := NewClientPool("dev")
+	pool.Headers = map[string]string{"X-Tenant-ID": "acme", "X-API-Key": "gateway-key", "Authorization": "Basic Zm9v"}
+	if err := pool.Get("work", server.URL, "test-token").TestConnection(); err != nil {
+		t.Fatalf("TestConnection() unexpected error = %v", err)
+	}
+	if got.Get("X-Tenant-ID") != "acme" {
+		t.Errorf("X-Tenant-ID = %q, want acme", got.Get("X-Tenant-ID"))
+	}
+	if got.Get("X-API-Key") != "test-token" {
+		t.Errorf("X-API-Key = %q, want the token rather than the configured header", got.Get("X-API-Key"))
+	}
+
+	pool = NewClientPool("dev")
+	pool.Bearer = true
+	pool.Headers = map[string]string{"Authorization": "Basic Zm9v"}
+	if err := pool.Get("work", server.URL, "test-token").TestConnection(); err != nil {
+		t.Fatalf("TestConnection() unexpected error = %v", err)
+	}
+	if got.Get("Authorization") != "Bearer test-token" {
+		t.Errorf("Authorization = %q, want the bearer token rather than the configured header", got.Get("Authorization"))
+	}
+}
+
 func TestMetabaseClient_TestConnection(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -67,7 +168,7 @@ func TestMetabaseClient_TestConnection(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewMetabaseClient(server.URL, "test-token")
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
 			err := client.TestConnection()
 
 			if tt.expectedError {
@@ -85,6 +186,24 @@ func TestMetabaseClient_TestConnection(t *testing.T) {
 	}
 }
 
+func TestMetabaseClient_GetCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/user/current" {
+			t.Errorf("Expected path /api/user/current, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"id": 7, "email": "ada@example.com", "first_name": "Ada", "last_name": "Lovelace"}`))
+	}))
+	defer server.Close()
+
+	user, err := NewMetabaseClient(server.URL, "test-token", "dev").GetCurrentUser()
+	if err != nil {
+		t.Fatalf("GetCurrentUser() unexpected error = %v", err)
+	}
+	if user.ID != 7 || user.Email != "ada@example.com" {
+		t.Errorf("GetCurrentUser() = %+v, want user 7", user)
+	}
+}
+
 func TestMetabaseClient_GetDatabases(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -139,7 +258,7 @@ func TestMetabaseClient_GetDatabases(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewMetabaseClient(server.URL, "test-token")
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
 			databases, err := client.GetDatabases()
 
 			if tt.expectedError {
@@ -205,7 +324,7 @@ func TestMetabaseClient_GetTables(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewMetabaseClient(server.URL, "test-token")
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
 			tables, err := client.GetTables(tt.databaseID)
 
 			if tt.expectedError {
@@ -271,7 +390,7 @@ func TestMetabaseClient_GetTableFields(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewMetabaseClient(server.URL, "test-token")
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
 			fields, err := client.GetTableFields(tt.tableID)
 
 			if tt.expectedError {
@@ -290,8 +409,391 @@ func TestMetabaseClient_GetTableFields(t *testing.T) {
 	}
 }
 
+func TestMetabaseClient_GetTable(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		responseBody  string
+		expectError   bool
+		expectedTable *Table
+	}{
+		{
+			name:          "successful response",
+			statusCode:    200,
+			responseBody:  `{"id": 100, "db_id": 5, "name": "orders", "display_name": "Orders", "schema": "public"}`,
+			expectError:   false,
+			expectedTable: &Table{ID: 100, DBID: 5, Name: "orders", DisplayName: "Orders", Schema: "public"},
+		},
+		{
+			name:         "not found",
+			statusCode:   404,
+			responseBody: `Not found.`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/table/100" {
+					t.Errorf("Expected path /api/table/100, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			table, err := client.GetTable(100)
+
+			if tt.expectError {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.statusCode {
+					t.Errorf("GetTable() error = %v, want StatusError with status %d", err, tt.statusCode)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetTable() unexpected error = %v", err)
+			}
+			if table.ID != tt.expectedTable.ID || table.DBID != tt.expectedTable.DBID || table.Schema != tt.expectedTable.Schema {
+				t.Errorf("GetTable() = %+v, want %+v", table, tt.expectedTable)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_GetCollections(t *testing.T) {
+	responseBody := `[
+		{"id": "root", "name": "Our analytics"},
+		{"id": 1, "name": "Finance", "location": "/"},
+		{"id": 3, "name": "Nested", "location": "/1/"}
+	]`
+	archivedBody := `[
+		{"id": 2, "name": "Old Reports", "location": "/", "archived": true},
+		{"id": 4, "name": "Old Nested", "location": "/2/", "archived": true}
+	]`
+
+	tests := []struct {
+		name            string
+		includeArchived bool
+		expectedQueries []string
+		expectedNames   []string
+	}{
+		{
+			name:            "archived excluded by default",
+			includeArchived: false,
+			expectedQueries: []string{""},
+			expectedNames:   []string{"Our analytics", "Finance"},
+		},
+		{
+			name:            "archived included on request",
+			includeArchived: true,
+			expectedQueries: []string{"", "archived=true"},
+			expectedNames:   []string{"Our analytics", "Finance", "Old Reports"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/collection" {
+					t.Errorf("Expected path /api/collection, got %s", r.URL.Path)
+				}
+				queries = append(queries, r.URL.RawQuery)
+
+				w.WriteHeader(200)
+				if r.URL.Query().Get("archived") == "true" {
+					w.Write([]byte(archivedBody))
+					return
+				}
+				w.Write([]byte(responseBody))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			collections, err := client.GetCollections(tt.includeArchived)
+			if err != nil {
+				t.Fatalf("GetCollections() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(queries, tt.expectedQueries) {
+				t.Errorf("GetCollections() queries = %q, want %q", queries, tt.expectedQueries)
+			}
+			if len(collections) != len(tt.expectedNames) {
+				t.Fatalf("GetCollections() returned %d collections, want %d", len(collections), len(tt.expectedNames))
+			}
+			for i, name := range tt.expectedNames {
+				if collections[i].Name != name {
+					t.Errorf("GetCollections()[%d].Name = %s, want %s", i, collections[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_GetCollections_ArchivedIgnored(t *testing.T) {
+	// Versions without the archived parameter list everything both times
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "name": "Finance", "location": "/"}, {"id": 2, "name": "Old Reports", "location": "/", "archived": true}]`))
+	}))
+	defer server.Close()
+
+	collections, err := NewMetabaseClient(server.URL, "test-token", "dev").GetCollections(true)
+	if err != nil || len(collections) != 2 {
+		t.Errorf("GetCollections(true) = %+v, %v, want each collection once", collections, err)
+	}
+}
+
+func TestMetabaseClient_GetCollectionTree(t *testing.T) {
+	responseBody := `[
+		{"id": 1, "name": "Finance", "location": "/", "children": [
+			{"id": 3, "name": "Quarterly", "location": "/1/", "children": [
+				{"id": 4, "name": "Q1", "location": "/1/3/", "children": []}
+			]}
+		]},
+		{"id": 2, "name": "Marketing", "location": "/", "children": []}
+	]`
+
+	tests := []struct {
+		name            string
+		includeArchived bool
+		expectedQuery   string
+	}{
+		{name: "archived excluded by default", expectedQuery: "exclude-archived=true"},
+		{name: "archived included on request", includeArchived: true, expectedQuery: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/collection/tree" {
+					t.Errorf("Expected path /api/collection/tree, got %s", r.URL.Path)
+				}
+				if r.URL.RawQuery != tt.expectedQuery {
+					t.Errorf("query = %q, want %q", r.URL.RawQuery, tt.expectedQuery)
+				}
+				w.Write([]byte(responseBody))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			tree, err := client.GetCollectionTree(tt.includeArchived)
+			if err != nil {
+				t.Fatalf("GetCollectionTree() unexpected error = %v", err)
+			}
+
+			if len(tree) != 2 || tree[0].Name != "Finance" || tree[1].Name != "Marketing" {
+				t.Fatalf("GetCollectionTree() = %+v, want Finance and Marketing at the top", tree)
+			}
+			if len(tree[0].Children) != 1 || tree[0].Children[0].Name != "Quarterly" {
+				t.Fatalf("GetCollectionTree()[0].Children = %+v, want Quarterly", tree[0].Children)
+			}
+			if grandchildren := tree[0].Children[0].Children; len(grandchildren) != 1 || grandchildren[0].Name != "Q1" {
+				t.Errorf("GetCollectionTree() Quarterly children = %+v, want Q1", grandchildren)
+			}
+			if len(tree[1].Children) != 0 {
+				t.Errorf("GetCollectionTree()[1].Children = %+v, want none", tree[1].Children)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_CountCollectionItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected int
+	}{
+		{name: "paged total", response: `{"data": [{"id": 1, "model": "card"}], "total": 42, "limit": 1, "offset": 0}`, expected: 42},
+		{name: "empty", response: `{"data": [], "total": 0}`, expected: 0},
+		{name: "no paging", response: `{"data": [{"id": 1, "model": "card"}, {"id": 2, "model": "dashboard"}]}`, expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/collection/5/items" || r.URL.Query().Get("limit") != "1" {
+					t.Errorf("request = %s, want one item of collection 5", r.URL)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			count, err := client.CountCollectionItems(5)
+			if err != nil {
+				t.Fatalf("CountCollectionItems() unexpected error = %v", err)
+			}
+			if count != tt.expected {
+				t.Errorf("CountCollectionItems() = %d, want %d", count, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_ReadOnly(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.WriteHeader(200)
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "test-token", "dev")
+	client.ReadOnly = true
+
+	err := client.SyncDatabaseSchema(1)
+	if !errors.Is(err, ErrReadOnly) || !containsString(err.Error(), "read-only mode") {
+		t.Errorf("SyncDatabaseSchema() error = %v, want read-only mode error", err)
+	}
+
+	if _, err := client.GetDatabases(); err != nil {
+		t.Errorf("GetDatabases() unexpected error in read-only mode = %v", err)
+	}
+
+	client.ReadOnly = false
+	if err := client.SyncDatabaseSchema(1); err != nil {
+		t.Errorf("SyncDatabaseSchema() unexpected error = %v", err)
+	}
+
+	if len(methods) != 2 || methods[0] != "GET" || methods[1] != "POST" {
+		t.Errorf("server saw %v, want [GET POST]", methods)
+	}
+}
+
+func TestMetabaseClient_SetArchived(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		archived bool
+		wantPath string
+		wantBody string
+	}{
+		{"archive question", "card", true, "/api/card/7", `{"archived":true}`},
+		{"archive model", "dataset", true, "/api/card/7", `{"archived":true}`},
+		{"unarchive dashboard", "dashboard", false, "/api/dashboard/7", `{"archived":false}`},
+		{"archive collection", "collection", true, "/api/collection/7", `{"archived":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				method, path, body = r.Method, r.URL.Path, string(data)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			if err := client.SetArchived(tt.model, 7, tt.archived); err != nil {
+				t.Fatalf("SetArchived() error = %v", err)
+			}
+			if method != "PUT" || path != tt.wantPath || body != tt.wantBody {
+				t.Errorf("server saw %s %s %s, want PUT %s %s", method, path, body, tt.wantPath, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_SetArchived_Errors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(403)
+		w.Write([]byte("You don't have permissions to do that."))
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "test-token", "dev")
+	client.ReadOnly = true
+	if err := client.SetArchived("card", 7, true); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SetArchived() in read-only mode error = %v, want ErrReadOnly", err)
+	}
+	if requests != 0 {
+		t.Errorf("read-only client made %d requests, want none", requests)
+	}
+
+	client.ReadOnly = false
+	if err := client.SetArchived("table", 7, true); err == nil {
+		t.Error("SetArchived() of a table succeeded, want an error")
+	}
+	err := client.SetArchived("dashboard", 7, false)
+	if err == nil || !containsString(err.Error(), "unarchive dashboard: 403") {
+		t.Errorf("SetArchived() error = %v, want the status of the unarchive", err)
+	}
+}
+
+func TestMetabaseClient_MoveItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		target   int
+		wantPath string
+		wantBody string
+	}{
+		{"question", "card", 12, "/api/card/7", `{"collection_id":12}`},
+		{"dashboard to root", "dashboard", 0, "/api/dashboard/7", `{"collection_id":null}`},
+		{"collection", "collection", 12, "/api/collection/7", `{"parent_id":12}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				method, path, body = r.Method, r.URL.Path, string(data)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			if err := client.MoveItem(tt.model, 7, tt.target); err != nil {
+				t.Fatalf("MoveItem() error = %v", err)
+			}
+			if method != "PUT" || path != tt.wantPath || body != tt.wantBody {
+				t.Errorf("server saw %s %s %s, want PUT %s %s", method, path, body, tt.wantPath, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_MoveItem_Errors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(403)
+		w.Write([]byte("You don't have permissions to do that."))
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "test-token", "dev")
+	client.ReadOnly = true
+	if err := client.MoveItem("card", 7, 12); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("MoveItem() in read-only mode error = %v, want ErrReadOnly", err)
+	}
+	if requests != 0 {
+		t.Errorf("read-only client made %d requests, want none", requests)
+	}
+
+	client.ReadOnly = false
+	if err := client.MoveItem("table", 7, 12); err == nil || requests != 0 {
+		t.Errorf("MoveItem() of a table error = %v after %d requests, want an error before any request", err, requests)
+	}
+	var statusErr *StatusError
+	err := client.MoveItem("card", 7, 12)
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 403 || statusErr.Action != "move card" {
+		t.Errorf("MoveItem() error = %v, want the 403 of the move", err)
+	}
+}
+
 func TestMetabaseClient_InvalidBaseURL(t *testing.T) {
-	client := NewMetabaseClient("not-a-valid-url", "test-token")
+	client := NewMetabaseClient("not-a-valid-url", "test-token", "dev")
 
 	err := client.TestConnection()
 	if err == nil {
@@ -329,3 +831,183 @@ func containsStringHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestMetabaseClient_RunCard(t *testing.T) {
+	tests := []struct {
+		name            string
+		statusCode      int
+		responseBody    string
+		readOnly        bool
+		expectError     string
+		expectedColumns []string
+		expectedRows    [][]interface{}
+	}{
+		{
+			name:       "completed query",
+			statusCode: 202,
+			responseBody: `{"status": "completed", "row_count": 2, "data": {
+				"cols": [{"name": "CATEGORY", "display_name": "Category"}, {"name": "count"}],
+				"rows": [["Gadget", 1234567890123], ["Widget", null]]}}`,
+			expectedColumns: []string{"Category", "count"},
+			expectedRows: [][]interface{}{
+				{"Gadget", json.Number("1234567890123")},
+				{"Widget", nil},
+			},
+		},
+		{
+			name:            "allowed in read-only mode",
+			statusCode:      200,
+			readOnly:        true,
+			responseBody:    `{"status": "completed", "data": {"cols": [{"name": "id"}], "rows": [[1]]}}`,
+			expectedColumns: []string{"id"},
+			expectedRows:    [][]interface{}{{json.Number("1")}},
+		},
+		{
+			name:         "failed query",
+			statusCode:   202,
+			responseBody: `{"status": "failed", "error": "Table \"ORDERS\" not found"}`,
+			expectError:  `query failed: Table "ORDERS" not found`,
+		},
+		{
+			name:         "not found",
+			statusCode:   404,
+			responseBody: `Not found.`,
+			expectError:  "failed to run card: 404 - Not found.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.URL.Path != "/api/card/42/query" {
+					t.Errorf("Expected POST /api/card/42/query, got %s %s", r.Method, r.URL.Path)
+				}
+
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			client.ReadOnly = tt.readOnly
+			columns, rows, err := client.RunCard(42)
+
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("RunCard() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("RunCard() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(columns, tt.expectedColumns) {
+				t.Errorf("RunCard() columns = %v, want %v", columns, tt.expectedColumns)
+			}
+			if !reflect.DeepEqual(rows, tt.expectedRows) {
+				t.Errorf("RunCard() rows = %v, want %v", rows, tt.expectedRows)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_RunCard_Parameters(t *testing.T) {
+	var gotBody, gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotContentType = r.Header.Get("Content-Type")
+		w.WriteHeader(202)
+		w.Write([]byte(`{"status": "completed", "data": {"cols": [], "rows": []}}`))
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "test-token", "dev")
+	_, _, err := client.RunCard(42,
+		ParameterValue{ID: "p1", Type: "category", Target: json.RawMessage(`["variable",["template-tag","category"]]`), Value: "Gadget"},
+		ParameterValue{ID: "p2", Type: "number/=", Value: []interface{}{json.Number("5")}},
+	)
+	if err != nil {
+		t.Fatalf("RunCard() unexpected error = %v", err)
+	}
+
+	expected := `{"parameters":[` +
+		`{"id":"p1","type":"category","target":["variable",["template-tag","category"]],"value":"Gadget"},` +
+		`{"id":"p2","type":"number/=","value":[5]}]}`
+	if gotBody != expected {
+		t.Errorf("RunCard() body = %s, want %s", gotBody, expected)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("RunCard() Content-Type = %q, want application/json", gotContentType)
+	}
+}
+
+func TestMetabaseClient_GetTableRelated(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		responseBody string
+		expectError  string
+		expected     []CollectionItem
+	}{
+		{
+			name:       "questions and their dashboards",
+			statusCode: 200,
+			responseBody: `[
+				{"id": 1, "name": "Orders by month", "type": "question", "collection_id": 5, "dashboard": {"id": 9, "name": "Revenue"}},
+				{"id": 2, "name": "Orders model", "type": "model", "collection_id": null},
+				{"id": 3, "name": "Order count", "type": "metric", "dashboard": {"id": 9, "name": "Revenue"}}
+			]`,
+			expected: []CollectionItem{
+				{ID: 9, Name: "Revenue", Model: "dashboard"},
+				{ID: 1, Name: "Orders by month", Model: "card", CollectionID: 5},
+				{ID: 2, Name: "Orders model", Model: "dataset"},
+				{ID: 3, Name: "Order count", Model: "metric"},
+			},
+		},
+		{
+			name:         "no questions",
+			statusCode:   200,
+			responseBody: `[]`,
+			expected:     nil,
+		},
+		{
+			name:         "not found",
+			statusCode:   404,
+			responseBody: `Not found.`,
+			expectError:  "failed to get table related: 404 - Not found.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/card" || r.URL.Query().Get("f") != "table" || r.URL.Query().Get("model_id") != "7" {
+					t.Errorf("Expected /api/card?f=table&model_id=7, got %s", r.URL)
+				}
+
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			items, err := client.GetTableRelated(7)
+
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("GetTableRelated() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetTableRelated() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(items, tt.expected) {
+				t.Errorf("GetTableRelated() = %+v, want %+v", items, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DiskCache keeps the last lists loaded from an instance in files, so the
+// next session can show them before the server has answered. Saving is best
+// effort: a cache that can't be written is the same as no cache.
+type DiskCache struct {
+	dir     string        // Directory of the profile's entries
+	baseURL string        // Entries saved for another instance are ignored
+	ttl     time.Duration // Entries older than this are ignored
+}
+
+// diskEntry is the file saved for one list
+type diskEntry struct {
+	BaseURL string          `json:"base_url"`
+	SavedAt time.Time       `json:"saved_at"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// cacheNow is the clock entries are saved and aged with; replaced in tests
+var cacheNow = time.Now
+
+// NewDiskCache returns the cache of a profile in a directory under root.
+// Connections without a profile share the "default" directory, where the
+// base URL keeps instances apart.
+func NewDiskCache(root, profile, baseURL string, ttl time.Duration) *DiskCache {
+	if profile == "" {
+		profile = "default"
+	}
+	return &DiskCache{dir: filepath.Join(root, url.PathEscape(profile)), baseURL: baseURL, ttl: ttl}
+}
+
+// load decodes the entry saved under key into v, returning when it was
+// saved. Missing, expired and unreadable entries are all misses.
+func (c *DiskCache) load(key string, v interface{}) (time.Time, bool) {
+	if c == nil {
+		return time.Time{}, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.BaseURL != c.baseURL {
+		return time.Time{}, false
+	}
+	if c.ttl > 0 && cacheNow().Sub(entry.SavedAt) > c.ttl {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(entry.Data, v); err != nil {
+		return time.Time{}, false
+	}
+	return entry.SavedAt, true
+}
+
+// store saves v under key. The file is written next to its final name and
+// renamed, so a session reading it never sees half an entry.
+func (c *DiskCache) store(key string, v interface{}) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	entry, err := json.Marshal(diskEntry{BaseURL: c.baseURL, SavedAt: cacheNow(), Data: data})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(entry)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), c.path(key)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// CachedDatabases returns the databases saved by an earlier session and
+// when they were saved
+func (c *MetabaseClient) CachedDatabases() ([]Database, time.Time, bool) {
+	var databases []Database
+	savedAt, ok := c.Cache.load("databases", &databases)
+	return databases, savedAt, ok
+}
+
+// CachedCollections returns the root collections saved by an earlier
+// session, as GetCollections returned them, and when they were saved
+func (c *MetabaseClient) CachedCollections(includeArchived bool) ([]Collection, time.Time, bool) {
+	var collections []Collection
+	savedAt, ok := c.Cache.load(collectionsCacheKey(includeArchived), &collections)
+	return collections, savedAt, ok
+}
+
+func collectionsCacheKey(includeArchived bool) string {
+	if includeArchived {
+		return "collections-archived"
+	}
+	return "collections"
+}
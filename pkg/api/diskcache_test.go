@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	original := cacheNow
+	defer func() { cacheNow = original }()
+	cacheNow = func() time.Time { return now }
+
+	root := t.TempDir()
+	databases := []Database{{ID: 1, Name: "Sample Database", Engine: "h2"}}
+	work := NewDiskCache(root, "work", "https://work.metabase.com", time.Hour)
+	work.store("databases", databases)
+
+	var loaded []Database
+	savedAt, ok := work.load("databases", &loaded)
+	if !ok || !savedAt.Equal(now) || !reflect.DeepEqual(loaded, databases) {
+		t.Fatalf("load() = %v saved %v, %v, want the stored databases", loaded, savedAt, ok)
+	}
+	if _, err := os.Stat(filepath.Join(root, "work", "databases.json")); err != nil {
+		t.Errorf("entry not saved under the profile: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		cache *DiskCache
+		age   time.Duration
+		key   string
+	}{
+		{name: "expired", cache: work, age: time.Hour + time.Minute, key: "databases"},
+		{name: "other profile", cache: NewDiskCache(root, "home", "https://work.metabase.com", time.Hour), key: "databases"},
+		{name: "profile pointed at another instance", cache: NewDiskCache(root, "work", "https://new.metabase.com", time.Hour), key: "databases"},
+		{name: "other list", cache: work, key: "collections"},
+		{name: "no cache", cache: nil, key: "databases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheNow = func() time.Time { return now.Add(tt.age) }
+			var loaded []Database
+			if _, ok := tt.cache.load(tt.key, &loaded); ok {
+				t.Errorf("load() = %v, want a miss", loaded)
+			}
+		})
+	}
+
+	// A corrupt entry is a miss rather than an error
+	if err := os.WriteFile(filepath.Join(root, "work", "databases.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cacheNow = func() time.Time { return now }
+	if _, ok := work.load("databases", &loaded); ok {
+		t.Error("load() of a corrupt entry succeeded")
+	}
+}
+
+func TestClient_CachedLists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/database":
+			_, _ = w.Write([]byte(`{"data": [{"id": 1, "name": "Sample Database"}]}`))
+		case "/api/collection":
+			_, _ = w.Write([]byte(`[{"id": "root", "name": "Our analytics"}, {"id": 5, "name": "Analytics", "location": "/"}, {"id": 6, "name": "Nested", "location": "/5/"}]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "token", "dev")
+	if _, _, ok := client.CachedDatabases(); ok {
+		t.Error("CachedDatabases() hit without a cache")
+	}
+	client.Cache = NewDiskCache(t.TempDir(), "", server.URL, time.Hour)
+	if _, _, ok := client.CachedDatabases(); ok {
+		t.Error("CachedDatabases() hit before anything was loaded")
+	}
+
+	fresh, err := client.GetDatabases()
+	if err != nil {
+		t.Fatalf("GetDatabases() unexpected error = %v", err)
+	}
+	if cached, _, ok := client.CachedDatabases(); !ok || !reflect.DeepEqual(cached, fresh) {
+		t.Errorf("CachedDatabases() = %v, %v, want %v", cached, ok, fresh)
+	}
+
+	collections, err := client.GetCollections(false)
+	if err != nil {
+		t.Fatalf("GetCollections() unexpected error = %v", err)
+	}
+	if cached, _, ok := client.CachedCollections(false); !ok || !reflect.DeepEqual(cached, collections) || len(cached) != 2 {
+		t.Errorf("CachedCollections() = %v, %v, want the two root collections", cached, ok)
+	}
+	if _, _, ok := client.CachedCollections(true); ok {
+		t.Error("CachedCollections(true) hit with only the unarchived list saved")
+	}
+}
@@ -0,0 +1,14 @@
+package api
+
+import "fmt"
+
+// StatusError is returned when the Metabase API answers with a non-200 status
+type StatusError struct {
+	Action     string // What the client was trying to do, e.g. "get databases"
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to %s: %d - %s", e.Action, e.StatusCode, e.Body)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportFormats are the formats Metabase can export question results in
+var ExportFormats = []string{"csv", "json", "xlsx"}
+
+// exportPath returns the export endpoint of a card for a format
+func exportPath(cardID int, format string) (string, error) {
+	supported := false
+	for _, f := range ExportFormats {
+		supported = supported || f == format
+	}
+	if !supported {
+		return "", fmt.Errorf("unsupported export format %q (use %s)", format, strings.Join(ExportFormats, ", "))
+	}
+	return fmt.Sprintf("/api/card/%d/query/%s", cardID, format), nil
+}
+
+// ExportCard runs a saved question through Metabase's export endpoint and
+// writes the file it returns to w. Like RunCard, it only reads data.
+func (c *MetabaseClient) ExportCard(cardID int, format string, w io.Writer, params ...ParameterValue) error {
+	path, err := exportPath(cardID, format)
+	if err != nil {
+		return err
+	}
+
+	// The export endpoints take their parameters as a form field
+	var body io.Reader
+	if len(params) > 0 {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		body = strings.NewReader(url.Values{"parameters": {string(encoded)}}.Encode())
+	}
+
+	req, err := c.buildRequest("POST", path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
+		data, _ := io.ReadAll(resp.Body)
+		return &StatusError{Action: "export card", StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	// A failed query comes back as a JSON error instead of the requested file
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if contentType == "application/json" && format != "json" {
+		var result struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &result) == nil && result.Error != "" {
+			return fmt.Errorf("query failed: %s", result.Error)
+		}
+		return fmt.Errorf("unexpected %s response to %s export", contentType, format)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// ExportCardToFile exports a saved question into a file. The results are
+// written next to it first and only replace it once the export succeeded, so
+// a failed export leaves an existing file as it was.
+func (c *MetabaseClient) ExportCardToFile(cardID int, format, filename string, params ...ParameterValue) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	err = c.ExportCard(cardID, format, tmp, params...)
+	if err == nil {
+		// Temporary files are private, exports are not
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
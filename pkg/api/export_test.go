@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportPath(t *testing.T) {
+	tests := []struct {
+		format      string
+		expected    string
+		expectError bool
+	}{
+		{format: "csv", expected: "/api/card/7/query/csv"},
+		{format: "json", expected: "/api/card/7/query/json"},
+		{format: "xlsx", expected: "/api/card/7/query/xlsx"},
+		{format: "pdf", expectError: true},
+		{format: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			path, err := exportPath(7, tt.format)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("exportPath() = %q, want error", path)
+				}
+				return
+			}
+			if err != nil || path != tt.expected {
+				t.Errorf("exportPath() = %q, %v, want %q", path, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_ExportCard(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		contentType  string
+		responseBody string
+		expectError  string
+	}{
+		{
+			name:         "csv",
+			format:       "csv",
+			contentType:  "text/csv",
+			responseBody: "Month,Orders\n2025-01,1520\n",
+		},
+		{
+			name:         "json",
+			format:       "json",
+			contentType:  "application/json; charset=utf-8",
+			responseBody: `[{"Month":"2025-01","Orders":1520}]`,
+		},
+		{
+			name:         "xlsx",
+			format:       "xlsx",
+			contentType:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			responseBody: "PK\x03\x04binary",
+		},
+		{
+			name:         "query error instead of csv",
+			format:       "csv",
+			contentType:  "application/json",
+			responseBody: `{"status": "failed", "error": "Column not found"}`,
+			expectError:  "query failed: Column not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.URL.Path != "/api/card/7/query/"+tt.format {
+					t.Errorf("Expected POST /api/card/7/query/%s, got %s %s", tt.format, r.Method, r.URL.Path)
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(200)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewMetabaseClient(server.URL, "test-token", "dev")
+			client.ReadOnly = true // Exports only read data
+			var out bytes.Buffer
+			err := client.ExportCard(7, tt.format, &out)
+
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("ExportCard() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportCard() unexpected error = %v", err)
+			}
+			if out.String() != tt.responseBody {
+				t.Errorf("ExportCard() wrote %q, want %q", out.String(), tt.responseBody)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_ExportCardToFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/card/404/query/csv" {
+			w.WriteHeader(404)
+			w.Write([]byte("Not found."))
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("parameters") != `[{"id":"p1","type":"category","value":"Gadget"}]` {
+			t.Errorf("Expected parameters form field, got %q", r.PostForm.Get("parameters"))
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("Month,Orders\n"))
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "test-token", "dev")
+	dir := t.TempDir()
+
+	filename := filepath.Join(dir, "orders.csv")
+	err := client.ExportCardToFile(7, "csv", filename, ParameterValue{ID: "p1", Type: "category", Value: "Gadget"})
+	if err != nil {
+		t.Fatalf("ExportCardToFile() unexpected error = %v", err)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != "Month,Orders\n" {
+		t.Errorf("ExportCardToFile() wrote %q, want the CSV body", data)
+	}
+
+	failed := filepath.Join(dir, "missing.csv")
+	if err := client.ExportCardToFile(404, "csv", failed); err == nil {
+		t.Error("ExportCardToFile() error = nil, want the 404")
+	}
+	if _, err := os.Stat(failed); !os.IsNotExist(err) {
+		t.Errorf("ExportCardToFile() left %s behind after failing", failed)
+	}
+
+	// A failed export over an earlier one keeps the earlier one
+	if err := client.ExportCardToFile(404, "csv", filename); err == nil {
+		t.Error("ExportCardToFile() error = nil, want the 404")
+	}
+	if data, _ := os.ReadFile(filename); string(data) != "Month,Orders\n" {
+		t.Errorf("ExportCardToFile() left %q after failing, want the earlier export", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("ExportCardToFile() left %d files in %s, want only the earlier export", len(entries), dir)
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CardParameter is a filter a saved question asks for before it runs
+type CardParameter struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"` // e.g. "category", "number/=", "date/single", "string/="
+	Name     string          `json:"name"`
+	Slug     string          `json:"slug"`
+	Target   json.RawMessage `json:"target"`
+	Default  interface{}     `json:"default"`
+	Required bool            `json:"required"`
+}
+
+// ParameterValue is a filled-in parameter as sent with a card query
+type ParameterValue struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Target json.RawMessage `json:"target,omitempty"`
+	Value  interface{}     `json:"value"`
+}
+
+// Kind groups the parameter types into the inputs the TUI offers:
+// "number", "date" or "text"
+func (p CardParameter) Kind() string {
+	switch {
+	case strings.HasPrefix(p.Type, "number"):
+		return "number"
+	case strings.HasPrefix(p.Type, "date"):
+		return "date"
+	}
+	return "text"
+}
+
+// DefaultInput returns the parameter's default as text for an input field
+func (p CardParameter) DefaultInput() string {
+	switch value := p.Default.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		// Multi-value defaults; the form edits a single value
+		if len(value) == 0 {
+			return ""
+		}
+		return fmt.Sprint(value[0])
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Value converts user input into the value to send for this parameter. It
+// returns ok=false for an empty optional parameter, which is then left out.
+func (p CardParameter) Value(input string) (value ParameterValue, ok bool, err error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		if p.Required {
+			return ParameterValue{}, false, fmt.Errorf("%s is required", p.Name)
+		}
+		return ParameterValue{}, false, nil
+	}
+
+	var v interface{} = input
+	switch p.Kind() {
+	case "number":
+		if _, err := strconv.ParseFloat(input, 64); err != nil {
+			return ParameterValue{}, false, fmt.Errorf("%s must be a number", p.Name)
+		}
+		v = json.Number(input)
+	case "date":
+		// Ranges and relative dates ("past30days") are passed through as typed
+		if p.Type == "date/single" {
+			if _, err := time.Parse("2006-01-02", input); err != nil {
+				return ParameterValue{}, false, fmt.Errorf("%s must be a date like 2025-01-31", p.Name)
+			}
+		}
+	}
+
+	// Operator parameters such as "number/=" and "string/=" take a list
+	if strings.Contains(p.Type, "/") && p.Kind() != "date" {
+		v = []interface{}{v}
+	}
+
+	return ParameterValue{ID: p.ID, Type: p.Type, Target: p.Target, Value: v}, true, nil
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCardParameter_Value(t *testing.T) {
+	target := json.RawMessage(`["variable",["template-tag","category"]]`)
+
+	tests := []struct {
+		name        string
+		param       CardParameter
+		input       string
+		expectOK    bool
+		expectError string
+		expected    string // Value as JSON
+	}{
+		{
+			name:     "category text",
+			param:    CardParameter{ID: "p1", Type: "category", Name: "Category", Target: target},
+			input:    " Gadget ",
+			expectOK: true,
+			expected: `{"id":"p1","type":"category","target":["variable",["template-tag","category"]],"value":"Gadget"}`,
+		},
+		{
+			name:     "string operator takes a list",
+			param:    CardParameter{ID: "p2", Type: "string/=", Name: "Vendor"},
+			input:    "Acme",
+			expectOK: true,
+			expected: `{"id":"p2","type":"string/=","value":["Acme"]}`,
+		},
+		{
+			name:     "number keeps its literal",
+			param:    CardParameter{ID: "p3", Type: "number/=", Name: "Quantity"},
+			input:    "42.50",
+			expectOK: true,
+			expected: `{"id":"p3","type":"number/=","value":[42.50]}`,
+		},
+		{
+			name:        "number rejects text",
+			param:       CardParameter{ID: "p3", Type: "number/=", Name: "Quantity"},
+			input:       "many",
+			expectError: "Quantity must be a number",
+		},
+		{
+			name:     "single date",
+			param:    CardParameter{ID: "p4", Type: "date/single", Name: "Since"},
+			input:    "2025-01-31",
+			expectOK: true,
+			expected: `{"id":"p4","type":"date/single","value":"2025-01-31"}`,
+		},
+		{
+			name:        "single date rejects other formats",
+			param:       CardParameter{ID: "p4", Type: "date/single", Name: "Since"},
+			input:       "31/01/2025",
+			expectError: "Since must be a date like 2025-01-31",
+		},
+		{
+			name:     "relative date passes through",
+			param:    CardParameter{ID: "p5", Type: "date/all-options", Name: "Created"},
+			input:    "past30days",
+			expectOK: true,
+			expected: `{"id":"p5","type":"date/all-options","value":"past30days"}`,
+		},
+		{
+			name:        "required and empty",
+			param:       CardParameter{ID: "p1", Type: "category", Name: "Category", Required: true},
+			input:       "  ",
+			expectError: "Category is required",
+		},
+		{
+			name:     "optional and empty is left out",
+			param:    CardParameter{ID: "p1", Type: "category", Name: "Category"},
+			input:    "",
+			expectOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok, err := tt.param.Value(tt.input)
+
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("Value() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Value() unexpected error = %v", err)
+			}
+			if ok != tt.expectOK {
+				t.Fatalf("Value() ok = %v, want %v", ok, tt.expectOK)
+			}
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(value)
+			if err != nil {
+				t.Fatalf("json.Marshal() unexpected error = %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Value() = %s, want %s", data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCardParameter_DefaultInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      interface{}
+		expected string
+	}{
+		{name: "none", def: nil, expected: ""},
+		{name: "text", def: "Gadget", expected: "Gadget"},
+		{name: "list", def: []interface{}{"Widget", "Gizmo"}, expected: "Widget"},
+		{name: "number", def: float64(10), expected: "10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (CardParameter{Default: tt.def}).DefaultInput(); got != tt.expected {
+				t.Errorf("DefaultInput() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
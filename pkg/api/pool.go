@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// activePoolClients is how many recently used clients keep their idle
+// connections open; older ones are asked to close theirs
+const activePoolClients = 2
+
+// NewTransport returns the transport settings shared by every client and by
+// the release check. Requests go through proxy when it is set, else through
+// the one in the HTTPS_PROXY and HTTP_PROXY environment variables.
+func NewTransport(proxy *url.URL) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 10
+	transport.MaxIdleConnsPerHost = 4
+	transport.IdleConnTimeout = 90 * time.Second
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return transport
+}
+
+// sharedTransport is the transport all clients of a pool send through. Each
+// host gets its own NewTransport underneath, so the pool can close the idle
+// connections to one Metabase instance and keep those to the others, while
+// profiles on the same instance share theirs.
+type sharedTransport struct {
+	proxy *url.URL
+
+	mu    sync.Mutex
+	hosts map[string]*http.Transport
+}
+
+func newSharedTransport(proxy *url.URL) *sharedTransport {
+	return &sharedTransport{proxy: proxy, hosts: make(map[string]*http.Transport)}
+}
+
+func (t *sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.host(hostKey(req.URL)).RoundTrip(req)
+}
+
+// host returns the transport for a host, creating it on first use
+func (t *sharedTransport) host(key string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	transport, ok := t.hosts[key]
+	if !ok {
+		transport = NewTransport(t.proxy)
+		t.hosts[key] = transport
+	}
+	return transport
+}
+
+// closeIdleExcept closes the idle connections to every host but the kept ones
+func (t *sharedTransport) closeIdleExcept(kept map[string]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, transport := range t.hosts {
+		if !kept[key] {
+			transport.CloseIdleConnections()
+		}
+	}
+}
+
+// CloseIdleConnections closes the idle connections to every host, for
+// http.Client.CloseIdleConnections
+func (t *sharedTransport) CloseIdleConnections() {
+	t.closeIdleExcept(nil)
+}
+
+// hostKey identifies the instance a URL is on
+func hostKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+// baseHostKey is hostKey for a client's base URL
+func baseHostKey(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return hostKey(u)
+}
+
+// ClientPool hands out one MetabaseClient per profile so switching back and
+// forth between profiles reuses connections instead of leaking new ones
+type ClientPool struct {
+	ReadOnly  bool              // Applied to every client the pool creates
+	UserAgent string            // Replaces the default User-Agent when set
+	Bearer    bool              // Send tokens as Authorization: Bearer instead of X-API-Key
+	Headers   map[string]string // Sent with every request of every client
+	Proxy     *url.URL          // Replaces the proxy from the environment when set
+	// Directory the clients save lists in for the next session, empty for
+	// none, and how long the saved lists are used for
+	CacheDir string
+	CacheTTL time.Duration
+
+	version string
+
+	mu        sync.Mutex
+	clients   map[string]*pooledClient
+	transport *sharedTransport // Created with the first client, once Proxy is set
+}
+
+type pooledClient struct {
+	client   *MetabaseClient
+	lastUsed time.Time
+}
+
+// NewClientPool returns a pool of clients for the given mbx version
+func NewClientPool(version string) *ClientPool {
+	return &ClientPool{clients: make(map[string]*pooledClient), version: version}
+}
+
+// Get returns the client for a profile, creating it on first use or when
+// the profile's URL or token has changed since. Every client sends through
+// the pool's transport, so a new client reuses the connections of the one
+// it replaces.
+func (p *ClientPool) Get(profile, baseURL, apiToken string) *MetabaseClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.transport == nil {
+		p.transport = newSharedTransport(p.Proxy)
+	}
+	entry, ok := p.clients[profile]
+	if !ok || entry.client.BaseURL != baseURL || entry.client.APIToken != apiToken {
+		entry = &pooledClient{client: NewMetabaseClient(baseURL, apiToken, p.version)}
+		entry.client.HTTPClient.Transport = newRevalidatingTransport(p.transport)
+		entry.client.ReadOnly = p.ReadOnly
+		entry.client.BearerAuth = p.Bearer
+		entry.client.Headers = p.Headers
+		if p.UserAgent != "" {
+			entry.client.UserAgent = p.UserAgent
+		}
+		if p.CacheDir != "" {
+			entry.client.Cache = NewDiskCache(p.CacheDir, profile, baseURL, p.CacheTTL)
+		}
+		p.clients[profile] = entry
+	}
+	entry.lastUsed = time.Now()
+
+	p.closeStale()
+	return entry.client
+}
+
+// closeStale closes the idle connections to the instances of all but the
+// most recently used clients
+func (p *ClientPool) closeStale() {
+	entries := make([]*pooledClient, 0, len(p.clients))
+	for _, entry := range p.clients {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.After(entries[j].lastUsed)
+	})
+
+	kept := make(map[string]bool)
+	for _, entry := range entries[:min(activePoolClients, len(entries))] {
+		kept[baseHostKey(entry.client.BaseURL)] = true
+	}
+	p.transport.closeIdleExcept(kept)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"testing"
+)
+
+func TestClientPool_Get(t *testing.T) {
+	pool := NewClientPool("dev")
+
+	work := pool.Get("work", "https://work.metabase.com", "work-token")
+	home := pool.Get("home", "https://home.metabase.com", "home-token")
+
+	if work == home {
+		t.Fatal("Get() returned the same client for different profiles")
+	}
+	if again := pool.Get("work", "https://work.metabase.com", "work-token"); again != work {
+		t.Error("Get() created a new client for a repeated profile, want the pooled one")
+	}
+
+	rotated := pool.Get("work", "https://work.metabase.com", "new-token")
+	if rotated == work {
+		t.Error("Get() reused the client after the token changed")
+	}
+	if rotated.APIToken != "new-token" {
+		t.Errorf("Get() token = %s, want new-token", rotated.APIToken)
+	}
+}
+
+func TestClientPool_SharedTransport(t *testing.T) {
+	var servers []*httptest.Server
+	for range 3 {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		}))
+		defer server.Close()
+		servers = append(servers, server)
+	}
+
+	// reused reports whether a request of the client went over a connection
+	// opened before
+	reused := func(client *MetabaseClient) bool {
+		t.Helper()
+		var got bool
+		trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { got = info.Reused }}
+		req, _ := http.NewRequest(http.MethodGet, client.BaseURL+"/api/user/current", nil)
+		resp, err := client.HTTPClient.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+		if err != nil {
+			t.Fatalf("request to %s failed: %v", client.BaseURL, err)
+		}
+		resp.Body.Close()
+		return got
+	}
+
+	pool := NewClientPool("dev")
+	work := pool.Get("work", servers[0].URL, "token")
+	reused(work)
+	analyst := pool.Get("analyst", servers[0].URL, "other-token")
+	if !reused(analyst) {
+		t.Error("a profile on the same instance opened a new connection, want the shared one")
+	}
+
+	home := pool.Get("home", servers[1].URL, "token")
+	reused(home)
+	pool.Get("staging", servers[2].URL, "token")
+	if reused(work) {
+		t.Error("work's connection stayed open after two other profiles were used since")
+	}
+	if !reused(home) {
+		t.Error("home's connection was closed while it was recently used")
+	}
+}
+
+func TestClientPool_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte(`{"data": [{"id": 1, "name": "Sample Database"}]}`))
+	}))
+	defer proxy.Close()
+
+	pool := NewClientPool("dev")
+	pool.Proxy, _ = url.Parse(proxy.URL)
+	client := pool.Get("work", "http://metabase.invalid", "token")
+
+	databases, err := client.GetDatabases()
+	if err != nil {
+		t.Fatalf("GetDatabases() unexpected error = %v", err)
+	}
+	if len(databases) != 1 || proxied != "http://metabase.invalid/api/database" {
+		t.Errorf("GetDatabases() = %v through %q, want the database through the proxy", databases, proxied)
+	}
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SearchResult is an entry of Metabase's instance-wide search
+type SearchResult struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Model           string `json:"model"` // "card", "dataset", "metric", "dashboard", "collection", "table", "database", ...
+	DatabaseID      int    `json:"database_id"`
+	TableSchema     string `json:"table_schema"`
+	Archived        bool   `json:"archived"`
+	ModeratedStatus string `json:"moderated_status"`
+	CreatorID       int    `json:"creator_id"`
+	UpdatedAt       string `json:"updated_at"`
+	LastEditedAt    string `json:"last_edited_at"`
+	LastEditor      string `json:"last_editor_common_name"`
+	Collection      struct {
+		ID                 interface{} `json:"id"`
+		Name               string      `json:"name"`
+		EffectiveAncestors []struct {
+			ID interface{} `json:"id"`
+		} `json:"effective_ancestors"`
+	} `json:"collection"`
+	// Profile the result came from when several instances are searched
+	Profile string `json:"-"`
+}
+
+// ErrCreatorUnknown is returned for searches by creator on versions whose
+// results don't say who created them, so the filter can't be checked
+var ErrCreatorUnknown = errors.New("this Metabase version doesn't say who created its search results, so they can't be filtered by creator")
+
+// SearchFilter narrows a search down
+type SearchFilter struct {
+	// Only results inside this collection or below it; nil searches everywhere
+	CollectionID interface{}
+	// Only content created by this user; 0 for anyone's
+	CreatedBy int
+	// Only these kinds of results, e.g. "card" or "dashboard"; empty for all
+	Models []string
+}
+
+// params encodes the search query and filter for /api/search. An empty query
+// lists everything the filter matches.
+func (f SearchFilter) params(query string) url.Values {
+	params := url.Values{}
+	if query != "" {
+		params.Set("q", query)
+	}
+	if f.CollectionID != nil {
+		params.Set("collection", fmt.Sprint(f.CollectionID))
+	}
+	if f.CreatedBy != 0 {
+		params.Set("created_by", strconv.Itoa(f.CreatedBy))
+	}
+	for _, model := range f.Models {
+		params.Add("models", model)
+	}
+	return params
+}
+
+// matches checks a result against the filter, for versions that ignore
+// some of the search parameters
+func (f SearchFilter) matches(result SearchResult) bool {
+	if f.CollectionID != nil && !result.InCollection(f.CollectionID) {
+		return false
+	}
+	if f.CreatedBy != 0 && result.CreatorID != f.CreatedBy {
+		return false
+	}
+	if len(f.Models) > 0 && !slices.Contains(f.Models, result.Model) {
+		return false
+	}
+	return true
+}
+
+// InCollection reports whether the result is inside the collection or one of
+// its sub-collections. Results without ancestry information, as returned by
+// older versions, are assumed to be.
+func (r SearchResult) InCollection(collectionID interface{}) bool {
+	if r.Collection.EffectiveAncestors == nil || fmt.Sprint(r.Collection.ID) == fmt.Sprint(collectionID) {
+		return true
+	}
+	for _, ancestor := range r.Collection.EffectiveAncestors {
+		if fmt.Sprint(ancestor.ID) == fmt.Sprint(collectionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// URL is the Metabase page of the result on the instance at baseURL
+func (r SearchResult) URL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	switch r.Model {
+	case "database":
+		return fmt.Sprintf("%s/browse/databases/%d", baseURL, r.ID)
+	case "table":
+		return fmt.Sprintf("%s/reference/databases/%d/tables/%d", baseURL, r.DatabaseID, r.ID)
+	}
+	if path := itemPath(r.Model, r.ID); path != "" {
+		return baseURL + path
+	}
+	return fmt.Sprintf("%s/question/%d", baseURL, r.ID)
+}
+
+// Path is the API path Search requests for the query and filter
+func (f SearchFilter) Path(query string) string {
+	return "/api/search?" + f.params(query).Encode()
+}
+
+// SearchUnavailable reports whether a search failed because the instance
+// has no search endpoint (404) or doesn't let the token use it (403), as on
+// old or locked-down instances
+func SearchUnavailable(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Action != "search" {
+		return false
+	}
+	return statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusForbidden
+}
+
+// Search runs Metabase's search, returning results in Metabase's relevance
+// order
+func (c *MetabaseClient) Search(query string, filter SearchFilter) ([]SearchResult, error) {
+	req, err := c.newRequest("GET", filter.Path(query), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Action: "search", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Metabase before 0.39 returned a bare list instead of {"data": [...]}
+	var results []SearchResult
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		err = json.Unmarshal(body, &results)
+	} else {
+		var page struct {
+			Data []SearchResult `json:"data"`
+		}
+		err = json.Unmarshal(body, &page)
+		results = page.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	if filter.CreatedBy != 0 && len(results) > 0 && !slices.ContainsFunc(results, func(result SearchResult) bool { return result.CreatorID != 0 }) {
+		return nil, ErrCreatorUnknown
+	}
+
+	// Versions that don't know a filter parameter return everything
+	var filtered []SearchResult
+	for _, result := range results {
+		if filter.matches(result) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered, nil
+}
+
+// ProfileClient is a client labelled with the profile it connects as
+type ProfileClient struct {
+	Profile string
+	Client  *MetabaseClient
+}
+
+// SearchProfiles searches every client at once and tags each result with its
+// profile. Results are interleaved by rank, so the best match of each
+// instance comes before the second best of any. Instances that fail are
+// reported in the error alongside the results of the others.
+func SearchProfiles(clients []ProfileClient, query string, filter SearchFilter) ([]SearchResult, error) {
+	perClient := make([][]SearchResult, len(clients))
+	errs := make([]error, len(clients))
+
+	var wg sync.WaitGroup
+	for i, pc := range clients {
+		wg.Add(1)
+		go func(i int, pc ProfileClient) {
+			defer wg.Done()
+			results, err := pc.Client.Search(query, filter)
+			if err != nil {
+				if len(clients) > 1 {
+					err = fmt.Errorf("%s: %w", pc.Profile, err)
+				}
+				errs[i] = err
+				return
+			}
+			for j := range results {
+				results[j].Profile = pc.Profile
+			}
+			perClient[i] = results
+		}(i, pc)
+	}
+	wg.Wait()
+
+	var merged []SearchResult
+	for rank := 0; ; rank++ {
+		added := false
+		for _, results := range perClient {
+			if rank < len(results) {
+				merged = append(merged, results[rank])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return merged, errors.Join(errs...)
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// searchServer answers /api/search with body, checking the query on the way
+func searchServer(t *testing.T, statusCode int, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/search" || r.URL.Query().Get("q") != "orders" {
+			t.Errorf("Expected /api/search?q=orders, got %s", r.URL)
+		}
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMetabaseClient_Search(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		responseBody string
+		expectError  string
+		expected     []string // Result names in order
+	}{
+		{
+			name:       "paged response",
+			statusCode: 200,
+			responseBody: `{"total": 2, "data": [
+				{"id": 10, "name": "Orders", "model": "table", "database_id": 1, "table_schema": "PUBLIC"},
+				{"id": 21, "name": "Orders by month", "model": "card", "collection": {"id": 5, "name": "Analytics"}}
+			]}`,
+			expected: []string{"Orders", "Orders by month"},
+		},
+		{
+			name:         "bare list from older versions",
+			statusCode:   200,
+			responseBody: `[{"id": 21, "name": "Orders by month", "model": "card"}]`,
+			expected:     []string{"Orders by month"},
+		},
+		{
+			name:         "server error",
+			statusCode:   500,
+			responseBody: `boom`,
+			expectError:  "failed to search: 500 - boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := searchServer(t, tt.statusCode, tt.responseBody)
+			results, err := NewMetabaseClient(server.URL, "test-token", "dev").Search("orders", SearchFilter{})
+
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("Search() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search() unexpected error = %v", err)
+			}
+			if len(results) != len(tt.expected) {
+				t.Fatalf("Search() returned %d results, want %d", len(results), len(tt.expected))
+			}
+			for i, name := range tt.expected {
+				if results[i].Name != name {
+					t.Errorf("Search()[%d] = %q, want %q", i, results[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_Search_Collection(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"data": [
+			{"id": 21, "name": "Orders by month", "model": "card", "collection": {"id": 5, "name": "Finance", "effective_ancestors": [{"id": "root"}]}},
+			{"id": 22, "name": "Orders by region", "model": "card", "collection": {"id": 9, "name": "Sales", "effective_ancestors": [{"id": "root"}, {"id": 5}]}},
+			{"id": 23, "name": "Orders (marketing)", "model": "card", "collection": {"id": 6, "name": "Marketing", "effective_ancestors": [{"id": "root"}]}},
+			{"id": 24, "name": "Orders (old server)", "model": "card", "collection": {"id": 6, "name": "Marketing"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	results, err := NewMetabaseClient(server.URL, "test-token", "dev").Search("orders", SearchFilter{CollectionID: 5})
+	if err != nil {
+		t.Fatalf("Search() unexpected error = %v", err)
+	}
+	if query.Get("q") != "orders" || query.Get("collection") != "5" {
+		t.Errorf("Search() sent %v, want q=orders and collection=5", query)
+	}
+
+	// Results outside the collection are dropped when their ancestry says so
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	expected := []string{"Orders by month", "Orders by region", "Orders (old server)"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Search() = %v, want %v", names, expected)
+	}
+}
+
+func TestSearchFilter_Params(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		filter   SearchFilter
+		expected string
+	}{
+		{name: "query only", query: "orders", expected: "q=orders"},
+		{name: "collection", query: "orders", filter: SearchFilter{CollectionID: 5}, expected: "collection=5&q=orders"},
+		{
+			name:     "created by, without a query",
+			filter:   SearchFilter{CreatedBy: 7, Models: []string{"card", "dashboard"}},
+			expected: "created_by=7&models=card&models=dashboard",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.params(tt.query).Encode(); got != tt.expected {
+				t.Errorf("params() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMetabaseClient_Search_CreatedBy(t *testing.T) {
+	// The server ignores created_by and models, as versions without them do
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [
+			{"id": 21, "name": "Orders by month", "model": "card", "creator_id": 7},
+			{"id": 22, "name": "Someone else's", "model": "card", "creator_id": 8},
+			{"id": 20, "name": "Revenue", "model": "dashboard", "creator_id": 7},
+			{"id": 5, "name": "Analytics", "model": "collection"},
+			{"id": 10, "name": "ORDERS", "model": "table"}
+		]}`))
+	}))
+	defer server.Close()
+
+	results, err := NewMetabaseClient(server.URL, "test-token", "dev").Search("", SearchFilter{CreatedBy: 7, Models: []string{"card", "dashboard"}})
+	if err != nil {
+		t.Fatalf("Search() unexpected error = %v", err)
+	}
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	if expected := []string{"Orders by month", "Revenue"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Search() = %v, want %v", names, expected)
+	}
+}
+
+func TestMetabaseClient_Search_CreatorUnknown(t *testing.T) {
+	// Results without creator_id can't be told apart by creator
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [{"id": 21, "name": "Orders by month", "model": "card"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewMetabaseClient(server.URL, "test-token", "dev")
+	if _, err := client.Search("", SearchFilter{CreatedBy: 7}); !errors.Is(err, ErrCreatorUnknown) {
+		t.Errorf("Search() error = %v, want ErrCreatorUnknown", err)
+	}
+	if results, err := client.Search("orders", SearchFilter{}); err != nil || len(results) != 1 {
+		t.Errorf("Search() without a creator = %v, %v, want the result", results, err)
+	}
+}
+
+func TestSearchUnavailable(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		expected   bool
+	}{
+		{http.StatusNotFound, true},
+		{http.StatusForbidden, true},
+		{http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		server := searchServer(t, tt.statusCode, `nope`)
+		_, err := SearchProfiles([]ProfileClient{{Profile: "work", Client: NewMetabaseClient(server.URL, "token", "dev")}}, "orders", SearchFilter{})
+		if got := SearchUnavailable(err); got != tt.expected {
+			t.Errorf("SearchUnavailable() after a %d = %v, want %v", tt.statusCode, got, tt.expected)
+		}
+	}
+	if SearchUnavailable(&StatusError{Action: "get databases", StatusCode: http.StatusNotFound}) {
+		t.Error("SearchUnavailable() = true for another endpoint")
+	}
+}
+
+func TestSearchProfiles(t *testing.T) {
+	work := searchServer(t, 200, `{"data": [
+		{"id": 1, "name": "Orders", "model": "table"},
+		{"id": 2, "name": "Orders by month", "model": "card"},
+		{"id": 3, "name": "Order items", "model": "table"}
+	]}`)
+	dev := searchServer(t, 200, `{"data": [{"id": 7, "name": "Orders (dev)", "model": "table"}]}`)
+	broken := searchServer(t, 500, `boom`)
+
+	t.Run("interleaved by rank", func(t *testing.T) {
+		results, err := SearchProfiles([]ProfileClient{
+			{Profile: "work", Client: NewMetabaseClient(work.URL, "work-token", "dev")},
+			{Profile: "dev", Client: NewMetabaseClient(dev.URL, "dev-token", "dev")},
+		}, "orders", SearchFilter{})
+		if err != nil {
+			t.Fatalf("SearchProfiles() unexpected error = %v", err)
+		}
+
+		expected := []struct{ profile, name string }{
+			{"work", "Orders"},
+			{"dev", "Orders (dev)"},
+			{"work", "Orders by month"},
+			{"work", "Order items"},
+		}
+		if len(results) != len(expected) {
+			t.Fatalf("SearchProfiles() returned %d results, want %d", len(results), len(expected))
+		}
+		for i, want := range expected {
+			if results[i].Profile != want.profile || results[i].Name != want.name {
+				t.Errorf("SearchProfiles()[%d] = %s/%s, want %s/%s", i, results[i].Profile, results[i].Name, want.profile, want.name)
+			}
+		}
+	})
+
+	t.Run("failed profile is reported", func(t *testing.T) {
+		results, err := SearchProfiles([]ProfileClient{
+			{Profile: "broken", Client: NewMetabaseClient(broken.URL, "token", "dev")},
+			{Profile: "dev", Client: NewMetabaseClient(dev.URL, "dev-token", "dev")},
+		}, "orders", SearchFilter{})
+		if err == nil || err.Error() != "broken: failed to search: 500 - boom" {
+			t.Errorf("SearchProfiles() error = %v, want the broken profile named", err)
+		}
+		if len(results) != 1 || results[0].Profile != "dev" {
+			t.Errorf("SearchProfiles() = %+v, want the dev result kept", results)
+		}
+	})
+}
+
+func TestSearchResult_URL(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "card", want: "http://metabase.local/question/12"},
+		{model: "dataset", want: "http://metabase.local/model/12"},
+		{model: "metric", want: "http://metabase.local/metric/12"},
+		{model: "dashboard", want: "http://metabase.local/dashboard/12"},
+		{model: "collection", want: "http://metabase.local/collection/12"},
+		{model: "database", want: "http://metabase.local/browse/databases/12"},
+		{model: "table", want: "http://metabase.local/reference/databases/3/tables/12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			result := SearchResult{ID: 12, Model: tt.model, DatabaseID: 3}
+			if got := result.URL("http://metabase.local"); got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
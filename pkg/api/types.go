@@ -1,10 +1,17 @@
 package api
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type DetailInfo interface {
 	GetCreator() *UserInfo
 	GetLastEditInfo() *LastEditInfo
 	GetCreatedAt() string
 	GetUpdatedAt() string
+	IsVerified() bool
 }
 
 type UserInfo struct {
@@ -22,10 +29,30 @@ type LastEditInfo struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// ModerationReview is an Enterprise content review. Only the most recent
+// review of an item is in effect.
+type ModerationReview struct {
+	Status     string `json:"status"` // "verified", or null when a verification was removed
+	MostRecent bool   `json:"most_recent"`
+}
+
+// verified reports whether the reviews leave an item marked as verified
+func verified(reviews []ModerationReview) bool {
+	for _, review := range reviews {
+		if review.MostRecent {
+			return review.Status == "verified"
+		}
+	}
+	return false
+}
+
 type Database struct {
 	ID     int    `json:"id"`
 	Name   string `json:"name"`
 	Engine string `json:"engine"`
+	// Connection settings such as host and port. Only admins receive them,
+	// with secrets masked.
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
 type Schema struct {
@@ -35,6 +62,7 @@ type Schema struct {
 
 type Table struct {
 	ID          int     `json:"id"`
+	DBID        int     `json:"db_id"`
 	Name        string  `json:"name"`
 	DisplayName string  `json:"display_name"`
 	Schema      string  `json:"schema"`
@@ -43,19 +71,55 @@ type Table struct {
 }
 
 type Field struct {
-	ID             int    `json:"id"`
-	Name           string `json:"name"`
-	DisplayName    string `json:"display_name"`
-	Description    string `json:"description"`
-	BaseType       string `json:"base_type"`
-	EffectiveType  string `json:"effective_type"`
-	SemanticType   string `json:"semantic_type"`
-	DatabaseType   string `json:"database_type"`
-	TableID        int    `json:"table_id"`
-	Position       int    `json:"position"`
-	Active         bool   `json:"active"`
-	PreviewDisplay bool   `json:"preview_display"`
-	Visibility     string `json:"visibility_type"`
+	ID             int          `json:"id"`
+	Name           string       `json:"name"`
+	DisplayName    string       `json:"display_name"`
+	Description    string       `json:"description"`
+	BaseType       string       `json:"base_type"`
+	EffectiveType  string       `json:"effective_type"`
+	SemanticType   string       `json:"semantic_type"`
+	DatabaseType   string       `json:"database_type"`
+	TableID        int          `json:"table_id"`
+	Position       int          `json:"position"`
+	Active         bool         `json:"active"`
+	PreviewDisplay bool         `json:"preview_display"`
+	Visibility     string       `json:"visibility_type"`
+	Fingerprint    *Fingerprint `json:"fingerprint"` // Nil until Metabase has analyzed the field
+}
+
+// Fingerprint holds the statistics Metabase collects when it analyzes a field
+type Fingerprint struct {
+	Global GlobalFingerprint `json:"global"`
+	Type   TypeFingerprint   `json:"type"`
+}
+
+type GlobalFingerprint struct {
+	DistinctCount *int     `json:"distinct-count"`
+	NilPercent    *float64 `json:"nil%"` // Fraction between 0 and 1
+}
+
+// TypeFingerprint holds the type-specific statistics; only the entry matching
+// the field's type is present
+type TypeFingerprint struct {
+	Number   *NumberFingerprint   `json:"type/Number"`
+	Text     *TextFingerprint     `json:"type/Text"`
+	DateTime *DateTimeFingerprint `json:"type/DateTime"`
+}
+
+type NumberFingerprint struct {
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+	Avg *float64 `json:"avg"`
+	SD  *float64 `json:"sd"`
+}
+
+type TextFingerprint struct {
+	AverageLength *float64 `json:"average-length"`
+}
+
+type DateTimeFingerprint struct {
+	Earliest string `json:"earliest"`
+	Latest   string `json:"latest"`
 }
 
 type Collection struct {
@@ -67,6 +131,11 @@ type Collection struct {
 	Archived    bool        `json:"archived"`
 	Location    string      `json:"location"`
 	IsPersonal  bool        `json:"is_personal"`
+	CreatedAt   string      `json:"created_at"` // Not set by older Metabase versions
+	// Only populated by GetCollection; lists parents from the top down
+	EffectiveAncestors []Collection `json:"effective_ancestors,omitempty"`
+	// Only populated by GetCollectionTree
+	Children []Collection `json:"children,omitempty"`
 }
 
 type CollectionItem struct {
@@ -77,60 +146,181 @@ type CollectionItem struct {
 	CollectionID int    `json:"collection_id"`
 	DatabaseID   *int   `json:"database_id"` // Nullable for non-database items
 	Archived     bool   `json:"archived"`
+	// "verified" on Enterprise instances once the item passes review
+	ModeratedStatus string `json:"moderated_status"`
+}
+
+// URL is the Metabase page of the item on the instance at baseURL. Items
+// without a page of their own, such as snippets or models mbx doesn't know,
+// link to the collection they are listed in.
+func (i CollectionItem) URL(baseURL string, collectionID interface{}) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if path := itemPath(i.Model, i.ID); path != "" {
+		return baseURL + path
+	}
+	if i.Model == "timeline" {
+		return fmt.Sprintf("%s/collection/%v/timelines/%d", baseURL, collectionID, i.ID)
+	}
+	return fmt.Sprintf("%s/collection/%v", baseURL, collectionID)
+}
+
+// itemPath is the path of the page of a collection item of the given model,
+// or empty when the model has no page of its own
+func itemPath(model string, id int) string {
+	switch model {
+	case "card":
+		return fmt.Sprintf("/question/%d", id)
+	case "dataset":
+		return fmt.Sprintf("/model/%d", id)
+	case "metric":
+		return fmt.Sprintf("/metric/%d", id)
+	case "dashboard":
+		return fmt.Sprintf("/dashboard/%d", id)
+	case "collection":
+		return fmt.Sprintf("/collection/%d", id)
+	case "pulse":
+		return fmt.Sprintf("/pulse/%d", id)
+	}
+	return ""
 }
 
 type CardDetail struct {
-	ID               int           `json:"id"`
-	Name             string        `json:"name"`
-	Description      string        `json:"description"`
-	CollectionID     int           `json:"collection_id"`
-	DatabaseID       *int          `json:"database_id"`
-	Archived         bool          `json:"archived"`
-	CreatorID        int           `json:"creator_id"`
-	CreatedAt        string        `json:"created_at"`
-	UpdatedAt        string        `json:"updated_at"`
-	LastEditInfo     *LastEditInfo `json:"last-edit-info"`
-	Creator          *UserInfo     `json:"creator"`
-}
-
-func (c *CardDetail) GetCreator() *UserInfo        { return c.Creator }
+	ID                int                `json:"id"`
+	Name              string             `json:"name"`
+	Description       string             `json:"description"`
+	CollectionID      int                `json:"collection_id"`
+	DatabaseID        *int               `json:"database_id"`
+	Archived          bool               `json:"archived"`
+	CreatorID         int                `json:"creator_id"`
+	CreatedAt         string             `json:"created_at"`
+	UpdatedAt         string             `json:"updated_at"`
+	LastEditInfo      *LastEditInfo      `json:"last-edit-info"`
+	Creator           *UserInfo          `json:"creator"`
+	Parameters        []CardParameter    `json:"parameters"`
+	ModerationReviews []ModerationReview `json:"moderation_reviews"`
+	Type              string             `json:"type"` // "question", "model" or "metric"
+	DatasetQuery      DatasetQuery       `json:"dataset_query"`
+	// Columns the card returns; for models, the curated columns built on it
+	ResultMetadata []QueryColumn `json:"result_metadata"`
+}
+
+// DatasetQuery is the query a card runs, either built with the query builder
+// or written in SQL
+type DatasetQuery struct {
+	Type   string `json:"type"` // "query" or "native"
+	Native struct {
+		Query string `json:"query"`
+	} `json:"native"`
+	Query struct {
+		// A table ID, or "card__<id>" for a query built on another card
+		SourceTable interface{} `json:"source-table"`
+	} `json:"query"`
+}
+
+func (c *CardDetail) GetCreator() *UserInfo          { return c.Creator }
 func (c *CardDetail) GetLastEditInfo() *LastEditInfo { return c.LastEditInfo }
-func (c *CardDetail) GetCreatedAt() string         { return c.CreatedAt }
-func (c *CardDetail) GetUpdatedAt() string         { return c.UpdatedAt }
+func (c *CardDetail) GetCreatedAt() string           { return c.CreatedAt }
+func (c *CardDetail) GetUpdatedAt() string           { return c.UpdatedAt }
+func (c *CardDetail) IsVerified() bool               { return verified(c.ModerationReviews) }
 
 type DashboardDetail struct {
-	ID               int           `json:"id"`
-	Name             string        `json:"name"`
-	Description      string        `json:"description"`
-	CollectionID     int           `json:"collection_id"`
-	Archived         bool          `json:"archived"`
-	CreatorID        int           `json:"creator_id"`
-	CreatedAt        string        `json:"created_at"`
-	UpdatedAt        string        `json:"updated_at"`
-	LastEditInfo     *LastEditInfo `json:"last-edit-info"`
-	Creator          *UserInfo     `json:"creator"`
-}
-
-func (d *DashboardDetail) GetCreator() *UserInfo        { return d.Creator }
+	ID                int                `json:"id"`
+	Name              string             `json:"name"`
+	Description       string             `json:"description"`
+	CollectionID      int                `json:"collection_id"`
+	Archived          bool               `json:"archived"`
+	CreatorID         int                `json:"creator_id"`
+	CreatedAt         string             `json:"created_at"`
+	UpdatedAt         string             `json:"updated_at"`
+	LastEditInfo      *LastEditInfo      `json:"last-edit-info"`
+	Creator           *UserInfo          `json:"creator"`
+	Dashcards         []DashboardCard    `json:"dashcards"`
+	OrderedCards      []DashboardCard    `json:"ordered_cards"` // Name of dashcards before Metabase 0.47
+	ModerationReviews []ModerationReview `json:"moderation_reviews"`
+}
+
+func (d *DashboardDetail) GetCreator() *UserInfo          { return d.Creator }
 func (d *DashboardDetail) GetLastEditInfo() *LastEditInfo { return d.LastEditInfo }
-func (d *DashboardDetail) GetCreatedAt() string         { return d.CreatedAt }
-func (d *DashboardDetail) GetUpdatedAt() string         { return d.UpdatedAt }
+func (d *DashboardDetail) GetCreatedAt() string           { return d.CreatedAt }
+func (d *DashboardDetail) GetUpdatedAt() string           { return d.UpdatedAt }
+func (d *DashboardDetail) IsVerified() bool               { return verified(d.ModerationReviews) }
 
 type MetricDetail struct {
-	ID               int           `json:"id"`
-	Name             string        `json:"name"`
-	Description      string        `json:"description"`
-	CollectionID     int           `json:"collection_id"`
-	DatabaseID       *int          `json:"database_id"`
-	Archived         bool          `json:"archived"`
-	CreatorID        int           `json:"creator_id"`
-	CreatedAt        string        `json:"created_at"`
-	UpdatedAt        string        `json:"updated_at"`
-	LastEditInfo     *LastEditInfo `json:"last-edit-info"`
-	Creator          *UserInfo     `json:"creator"`
-}
-
-func (m *MetricDetail) GetCreator() *UserInfo        { return m.Creator }
+	ID                int                `json:"id"`
+	Name              string             `json:"name"`
+	Description       string             `json:"description"`
+	CollectionID      int                `json:"collection_id"`
+	DatabaseID        *int               `json:"database_id"`
+	Archived          bool               `json:"archived"`
+	CreatorID         int                `json:"creator_id"`
+	CreatedAt         string             `json:"created_at"`
+	UpdatedAt         string             `json:"updated_at"`
+	LastEditInfo      *LastEditInfo      `json:"last-edit-info"`
+	Creator           *UserInfo          `json:"creator"`
+	ModerationReviews []ModerationReview `json:"moderation_reviews"`
+}
+
+func (m *MetricDetail) GetCreator() *UserInfo          { return m.Creator }
 func (m *MetricDetail) GetLastEditInfo() *LastEditInfo { return m.LastEditInfo }
-func (m *MetricDetail) GetCreatedAt() string         { return m.CreatedAt }
-func (m *MetricDetail) GetUpdatedAt() string         { return m.UpdatedAt }
+func (m *MetricDetail) GetCreatedAt() string           { return m.CreatedAt }
+func (m *MetricDetail) GetUpdatedAt() string           { return m.UpdatedAt }
+func (m *MetricDetail) IsVerified() bool               { return verified(m.ModerationReviews) }
+
+// DashboardCard places a card on a dashboard. Text and heading cards have no
+// CardID.
+type DashboardCard struct {
+	ID     int                `json:"id"`
+	CardID *int               `json:"card_id"`
+	Card   *DashboardCardInfo `json:"card"`
+	Row    int                `json:"row"`
+	Col    int                `json:"col"`
+}
+
+// DashboardCardInfo is the summary of a card embedded in a dashboard
+type DashboardCardInfo struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Display     string `json:"display"`
+	Type        string `json:"type"` // "question", "model" or "metric"
+}
+
+// Cards returns the question cards on the dashboard in layout order, top to
+// bottom and left to right, leaving out text and heading cards
+func (d *DashboardDetail) Cards() []DashboardCard {
+	dashcards := d.Dashcards
+	if len(dashcards) == 0 {
+		dashcards = d.OrderedCards
+	}
+
+	var cards []DashboardCard
+	for _, dashcard := range dashcards {
+		if dashcard.CardID != nil && dashcard.Card != nil {
+			cards = append(cards, dashcard)
+		}
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		if cards[i].Row != cards[j].Row {
+			return cards[i].Row < cards[j].Row
+		}
+		return cards[i].Col < cards[j].Col
+	})
+	return cards
+}
+
+// QueryResult is the response of running a saved question
+type QueryResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error"`
+	RowCount int    `json:"row_count"`
+	Data     struct {
+		Cols []QueryColumn   `json:"cols"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"data"`
+}
+
+type QueryColumn struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	BaseType    string `json:"base_type"`
+}
@@ -142,3 +142,208 @@ func TestSchema(t *testing.T) {
 		t.Errorf("Schema.TableCount = %d, want 5", schema.TableCount)
 	}
 }
+
+func TestField_UnmarshalFingerprint(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		check func(t *testing.T, fp *Fingerprint)
+	}{
+		{
+			name: "number field",
+			input: `{"id": 1, "name": "total", "fingerprint": {
+				"global": {"distinct-count": 4021, "nil%": 0.25},
+				"type": {"type/Number": {"min": 1.5, "max": 99, "avg": 42.25, "sd": 3.1}}
+			}}`,
+			check: func(t *testing.T, fp *Fingerprint) {
+				if fp.Global.DistinctCount == nil || *fp.Global.DistinctCount != 4021 {
+					t.Errorf("DistinctCount = %v, want 4021", fp.Global.DistinctCount)
+				}
+				if fp.Global.NilPercent == nil || *fp.Global.NilPercent != 0.25 {
+					t.Errorf("NilPercent = %v, want 0.25", fp.Global.NilPercent)
+				}
+				number := fp.Type.Number
+				if number == nil || *number.Min != 1.5 || *number.Max != 99 || *number.Avg != 42.25 {
+					t.Errorf("Number = %+v, want min 1.5, max 99, avg 42.25", number)
+				}
+				if fp.Type.Text != nil {
+					t.Errorf("Text = %+v, want nil", fp.Type.Text)
+				}
+			},
+		},
+		{
+			name: "text field",
+			input: `{"id": 2, "name": "email", "fingerprint": {
+				"global": {"distinct-count": 12},
+				"type": {"type/Text": {"percent-email": 1.0, "average-length": 17.4}}
+			}}`,
+			check: func(t *testing.T, fp *Fingerprint) {
+				if fp.Type.Text == nil || fp.Type.Text.AverageLength == nil || *fp.Type.Text.AverageLength != 17.4 {
+					t.Errorf("Text = %+v, want average length 17.4", fp.Type.Text)
+				}
+				if fp.Global.NilPercent != nil {
+					t.Errorf("NilPercent = %v, want nil when absent", *fp.Global.NilPercent)
+				}
+			},
+		},
+		{
+			name: "datetime field",
+			input: `{"id": 3, "name": "created_at", "fingerprint": {
+				"global": {"distinct-count": 9, "nil%": 0},
+				"type": {"type/DateTime": {"earliest": "2023-01-01T00:00:00Z", "latest": "2024-06-30T12:00:00Z"}}
+			}}`,
+			check: func(t *testing.T, fp *Fingerprint) {
+				if fp.Type.DateTime == nil || fp.Type.DateTime.Earliest != "2023-01-01T00:00:00Z" {
+					t.Errorf("DateTime = %+v, want earliest 2023-01-01T00:00:00Z", fp.Type.DateTime)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var field Field
+			if err := json.Unmarshal([]byte(tt.input), &field); err != nil {
+				t.Fatalf("json.Unmarshal() unexpected error = %v", err)
+			}
+			if field.Fingerprint == nil {
+				t.Fatal("Fingerprint = nil, want parsed fingerprint")
+			}
+			tt.check(t, field.Fingerprint)
+		})
+	}
+
+	t.Run("no fingerprint", func(t *testing.T) {
+		var field Field
+		if err := json.Unmarshal([]byte(`{"id": 4, "name": "notes", "fingerprint": null}`), &field); err != nil {
+			t.Fatalf("json.Unmarshal() unexpected error = %v", err)
+		}
+		if field.Fingerprint != nil {
+			t.Errorf("Fingerprint = %+v, want nil", field.Fingerprint)
+		}
+	})
+}
+
+func TestDashboardDetail_UnmarshalCards(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string // Card names in layout order
+		ids      []int
+	}{
+		{
+			name: "dashcards in layout order without text cards",
+			input: `{"id": 3, "name": "Revenue", "dashcards": [
+				{"id": 11, "card_id": 42, "row": 4, "col": 0, "card": {"id": 42, "name": "Orders by month", "display": "line"}},
+				{"id": 12, "card_id": null, "row": 0, "col": 0, "card": {"display": "text"}},
+				{"id": 13, "card_id": 7, "row": 0, "col": 6, "card": {"id": 7, "name": "Total revenue", "display": "scalar"}},
+				{"id": 14, "card_id": 8, "row": 0, "col": 12, "card": {"id": 8, "name": "Customers", "type": "model"}}
+			]}`,
+			expected: []string{"Total revenue", "Customers", "Orders by month"},
+			ids:      []int{7, 8, 42},
+		},
+		{
+			name: "ordered_cards from older versions",
+			input: `{"id": 3, "name": "Revenue", "ordered_cards": [
+				{"id": 11, "card_id": 42, "card": {"id": 42, "name": "Orders by month"}}
+			]}`,
+			expected: []string{"Orders by month"},
+			ids:      []int{42},
+		},
+		{
+			name:  "no cards",
+			input: `{"id": 3, "name": "Empty", "dashcards": []}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dashboard DashboardDetail
+			if err := json.Unmarshal([]byte(tt.input), &dashboard); err != nil {
+				t.Fatalf("json.Unmarshal() unexpected error = %v", err)
+			}
+
+			cards := dashboard.Cards()
+			if len(cards) != len(tt.expected) {
+				t.Fatalf("Cards() returned %d cards, want %d", len(cards), len(tt.expected))
+			}
+			for i, card := range cards {
+				if card.Card.Name != tt.expected[i] || *card.CardID != tt.ids[i] {
+					t.Errorf("Cards()[%d] = %q (%d), want %q (%d)", i, card.Card.Name, *card.CardID, tt.expected[i], tt.ids[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalVerified(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "verified",
+			input:    `{"id": 1, "moderated_status": "verified", "moderation_reviews": [{"status": "verified", "most_recent": true}]}`,
+			expected: true,
+		},
+		{
+			name:     "verification removed",
+			input:    `{"id": 1, "moderated_status": null, "moderation_reviews": [{"status": null, "most_recent": true}, {"status": "verified", "most_recent": false}]}`,
+			expected: false,
+		},
+		{
+			name:     "open source instance",
+			input:    `{"id": 1}`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var item CollectionItem
+			if err := json.Unmarshal([]byte(tt.input), &item); err != nil {
+				t.Fatalf("json.Unmarshal() unexpected error = %v", err)
+			}
+			if got := item.ModeratedStatus == "verified"; got != tt.expected {
+				t.Errorf("CollectionItem.ModeratedStatus = %q, want verified %v", item.ModeratedStatus, tt.expected)
+			}
+
+			details := []DetailInfo{&CardDetail{}, &DashboardDetail{}, &MetricDetail{}}
+			for _, detail := range details {
+				if err := json.Unmarshal([]byte(tt.input), detail); err != nil {
+					t.Fatalf("json.Unmarshal() unexpected error = %v", err)
+				}
+				if got := detail.IsVerified(); got != tt.expected {
+					t.Errorf("%T.IsVerified() = %v, want %v", detail, got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionItem_URL(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "card", want: "http://metabase.local/question/12"},
+		{model: "dataset", want: "http://metabase.local/model/12"},
+		{model: "metric", want: "http://metabase.local/metric/12"},
+		{model: "dashboard", want: "http://metabase.local/dashboard/12"},
+		{model: "collection", want: "http://metabase.local/collection/12"},
+		{model: "pulse", want: "http://metabase.local/pulse/12"},
+		{model: "timeline", want: "http://metabase.local/collection/5/timelines/12"},
+		{model: "snippet", want: "http://metabase.local/collection/5"},
+		{model: "something-new", want: "http://metabase.local/collection/5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			item := CollectionItem{ID: 12, Model: tt.model}
+			if got := item.URL("http://metabase.local/", 5); got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
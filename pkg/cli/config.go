@@ -1,11 +1,20 @@
 package cli
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/amureki/metabase-explorer/pkg/api"
 	"github.com/amureki/metabase-explorer/pkg/config"
+	"github.com/charmbracelet/x/term"
 )
 
 func handleConfigCommand(args []string) {
@@ -22,6 +31,8 @@ COMMANDS:
     set --profile <name> <key> <value>  Set configuration value in specific profile
     delete <profile>        Delete a profile
     switch <profile>        Set default profile
+    edit                    Open the config file in $EDITOR and check it
+    sample                  Print an example config file documenting every key
 
 EXAMPLES:
     mbx config list
@@ -29,6 +40,8 @@ EXAMPLES:
     mbx config set --profile work token "abc123"
     mbx config get work
     mbx config switch work
+    EDITOR=nano mbx config edit
+    mbx config sample > config.sample.yaml
 `)
 		return
 	}
@@ -69,84 +82,143 @@ EXAMPLES:
 			os.Exit(1)
 		}
 		handleConfigSwitch(args[1])
+	case "edit":
+		handleConfigEdit()
+	case "sample":
+		fmt.Println(config.Sample())
 	default:
 		fmt.Fprintf(os.Stderr, "Error: Unknown config command '%s'\n", cmd)
 		os.Exit(1)
 	}
 }
 
-func handleConfigInit() {
+// connectionVerifier checks a URL and token and returns the user they sign
+// in as; replaced in tests
+type connectionVerifier func(metabaseURL, apiToken string) (*api.UserInfo, error)
+
+func verifyMetabaseConnection(metabaseURL, apiToken string) (*api.UserInfo, error) {
+	client := newClient(metabaseURL, apiToken)
+	if err := client.TestConnection(); err != nil {
+		return nil, err
+	}
+	return client.GetCurrentUser()
+}
+
+// secretInput reads secrets without echoing them when the input is a
+// terminal; replaced in tests
+type secretInput interface {
+	IsTerminal() bool
+	ReadPassword() ([]byte, error)
+}
+
+type stdinTerminal struct{}
+
+func (stdinTerminal) IsTerminal() bool { return term.IsTerminal(os.Stdin.Fd()) }
+
+func (stdinTerminal) ReadPassword() ([]byte, error) { return term.ReadPassword(os.Stdin.Fd()) }
+
+func handleConfigInit(skipTest bool) {
+	if err := runConfigInit(os.Stdin, os.Stdout, stdinTerminal{}, skipTest, verifyMetabaseConnection); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runConfigInit is the interactive setup wizard. Unless skipTest is set, the
+// URL and token are checked with verify before anything is saved.
+func runConfigInit(in io.Reader, out io.Writer, secrets secretInput, skipTest bool, verify connectionVerifier) error {
+	lines := bufio.NewReader(in)
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("loading config: %w", err)
 	}
 
-	fmt.Println("Metabase Explorer Configuration Setup")
-	fmt.Println("====================================")
+	fmt.Fprintln(out, "Metabase Explorer Configuration Setup")
+	fmt.Fprintln(out, "====================================")
 
 	// Show existing configuration if any
 	if len(cfg.Profiles) > 0 {
-		fmt.Println("\nExisting configuration:")
+		fmt.Fprintln(out, "\nExisting configuration:")
 		for name := range cfg.Profiles {
 			marker := "  "
 			if name == cfg.DefaultProfile {
 				marker = "* "
 			}
-			fmt.Printf("%s%s\n", marker, name)
+			fmt.Fprintf(out, "%s%s\n", marker, name)
 		}
-		fmt.Println()
+		fmt.Fprintln(out)
 	}
 
-	var url, token, profileName string
+	var defaultURL, defaultToken string
 
-	fmt.Print("Profile name [default]: ")
-	fmt.Scanln(&profileName)
+	profileName, err := prompt(lines, out, "Profile name [default]: ")
+	if err != nil {
+		return err
+	}
 	if profileName == "" {
 		profileName = "default"
 	}
 
 	// Check if profile already exists
 	if existingProfile, exists := cfg.Profiles[profileName]; exists {
-		fmt.Printf("\nProfile '%s' already exists:\n", profileName)
-		fmt.Printf("  URL: %s\n", existingProfile.URL)
-		if len(existingProfile.Token) > 8 {
-			fmt.Printf("  Token: %s...%s\n", existingProfile.Token[:4], existingProfile.Token[len(existingProfile.Token)-4:])
-		} else {
-			fmt.Printf("  Token: %s\n", existingProfile.Token)
-		}
+		fmt.Fprintf(out, "\nProfile '%s' already exists:\n", profileName)
+		fmt.Fprintf(out, "  URL: %s\n", existingProfile.URL)
+		fmt.Fprintf(out, "  Token: %s\n", maskToken(existingProfile.Token))
 
-		var overwrite string
-		fmt.Print("\nOverwrite existing profile? [y/N]: ")
-		fmt.Scanln(&overwrite)
-		if strings.ToLower(overwrite) != "y" && strings.ToLower(overwrite) != "yes" {
-			fmt.Println("Configuration unchanged.")
-			return
+		overwrite, err := prompt(lines, out, "\nOverwrite existing profile? [y/N]: ")
+		if err != nil {
+			return err
+		}
+		overwrite = strings.ToLower(strings.TrimSpace(overwrite))
+		if overwrite != "y" && overwrite != "yes" {
+			fmt.Fprintln(out, "Configuration unchanged.")
+			return nil
 		}
 
 		// Pre-fill with existing values
-		fmt.Printf("\nMetabase URL [%s]: ", existingProfile.URL)
-		fmt.Scanln(&url)
-		if url == "" {
-			url = existingProfile.URL
+		defaultURL, defaultToken = existingProfile.URL, existingProfile.Token
+	}
+
+	var url, token string
+	for {
+		url, token, err = promptConnection(lines, out, secrets, defaultURL, defaultToken)
+		if err != nil {
+			return err
+		}
+		if url == "" || token == "" {
+			return fmt.Errorf("URL and token are required")
 		}
 
-		fmt.Printf("API Token [keep existing]: ")
-		fmt.Scanln(&token)
-		if token == "" {
-			token = existingProfile.Token
+		var note string
+		url, note, err = config.NormalizeURL(url)
+		if err != nil {
+			return err
+		}
+		if note != "" {
+			fmt.Fprintln(out, note)
 		}
-	} else {
-		fmt.Print("\nMetabase URL: ")
-		fmt.Scanln(&url)
 
-		fmt.Print("API Token: ")
-		fmt.Scanln(&token)
-	}
+		if skipTest {
+			break
+		}
+		fmt.Fprintf(out, "\nTesting connection to %s...\n", url)
+		user, err := verify(url, token)
+		if err == nil {
+			fmt.Fprintf(out, "✓ Connected as %s\n", userDisplayName(user))
+			break
+		}
+		fmt.Fprintf(out, "✗ Connection failed: %v\n", err)
 
-	if url == "" || token == "" {
-		fmt.Fprintf(os.Stderr, "Error: URL and token are required\n")
-		os.Exit(1)
+		answer, err := prompt(lines, out, "\nRe-enter URL and token? [Y/n]: ")
+		if err != nil {
+			return err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "" && answer != "y" && answer != "yes" {
+			return fmt.Errorf("configuration not saved; run 'mbx init --skip-test' to save it without a connection test")
+		}
+		// Offer what was just typed as the defaults for the next attempt
+		defaultURL, defaultToken = url, token
 	}
 
 	cfg.Profiles[profileName] = config.Profile{URL: url, Token: token}
@@ -154,16 +226,90 @@ func handleConfigInit() {
 		cfg.DefaultProfile = profileName
 	}
 
-	err = config.SaveConfig(cfg)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
-		os.Exit(1)
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("saving config: %w", err)
 	}
 
-	fmt.Printf("\n✓ Configuration saved for profile '%s'\n", profileName)
+	fmt.Fprintf(out, "\n✓ Configuration saved for profile '%s'\n", profileName)
 	if cfg.DefaultProfile == profileName {
-		fmt.Println("✓ Set as default profile")
+		fmt.Fprintln(out, "✓ Set as default profile")
 	}
+	return nil
+}
+
+// promptConnection asks for the URL and token, keeping the defaults when the
+// answer is left empty. The token is not echoed when typed into a terminal.
+func promptConnection(lines *bufio.Reader, out io.Writer, secrets secretInput, defaultURL, defaultToken string) (url, token string, err error) {
+	question := "\nMetabase URL: "
+	if defaultURL != "" {
+		question = fmt.Sprintf("\nMetabase URL [%s]: ", defaultURL)
+	}
+	url, err = prompt(lines, out, question)
+	if err != nil {
+		return "", "", err
+	}
+	if url == "" {
+		url = defaultURL
+	}
+
+	if defaultToken != "" {
+		fmt.Fprint(out, "API Token [keep existing]: ")
+	} else {
+		fmt.Fprint(out, "API Token: ")
+	}
+	token, err = readSecret(lines, out, secrets)
+	if err != nil {
+		return "", "", fmt.Errorf("reading token: %w", err)
+	}
+	if token == "" {
+		token = defaultToken
+	}
+	return url, token, nil
+}
+
+// readSecret reads a whole line, without echo when the input is a terminal
+func readSecret(lines *bufio.Reader, out io.Writer, secrets secretInput) (string, error) {
+	if !secrets.IsTerminal() {
+		return readLine(lines)
+	}
+	secret, err := secrets.ReadPassword()
+	// The newline the user typed was not echoed either
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// prompt asks question and returns the answer
+func prompt(lines *bufio.Reader, out io.Writer, question string) (string, error) {
+	fmt.Fprint(out, question)
+	answer, err := readLine(lines)
+	if err != nil {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+	return answer, nil
+}
+
+// readLine reads a whole line, spaces included, dropping only the line ending.
+// It returns io.EOF once the input has run out, so an answer that never came
+// isn't taken for an empty one.
+func readLine(lines *bufio.Reader) (string, error) {
+	line, err := lines.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// userDisplayName is the user's full name, or their email when Metabase has
+// no name on record
+func userDisplayName(user *api.UserInfo) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Email
 }
 
 func handleConfigList() {
@@ -215,11 +361,15 @@ func handleConfigShow(profileName string) {
 		fmt.Println("(default)")
 	}
 	fmt.Printf("URL: %s\n", profile.URL)
-	if len(profile.Token) > 8 {
-		fmt.Printf("Token: %s...%s\n", profile.Token[:4], profile.Token[len(profile.Token)-4:])
-	} else {
-		fmt.Printf("Token: %s\n", profile.Token)
+	fmt.Printf("Token: %s\n", maskToken(profile.Token))
+}
+
+// maskToken keeps only the ends of a token, enough to tell tokens apart
+func maskToken(token string) string {
+	if len(token) > 8 {
+		return token[:4] + "..." + token[len(token)-4:]
 	}
+	return strings.Repeat("*", len(token))
 }
 
 func handleConfigSet(profileName, key, value string) {
@@ -240,7 +390,15 @@ func handleConfigSet(profileName, key, value string) {
 	profile := cfg.Profiles[profileName]
 	switch strings.ToLower(key) {
 	case "url":
-		profile.URL = value
+		normalized, note, err := config.NormalizeURL(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if note != "" {
+			fmt.Println(note)
+		}
+		profile.URL = normalized
 	case "token":
 		profile.Token = value
 	default:
@@ -320,3 +478,90 @@ func handleConfigSwitch(profileName string) {
 
 	fmt.Printf("✓ Switched to profile '%s'\n", profileName)
 }
+
+// editFile opens a file in the user's editor and waits for it to exit;
+// replaced in tests
+var editFile = func(path string) error {
+	editor := strings.Fields(preferredEditor())
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// preferredEditor is $VISUAL or $EDITOR, which may include arguments such as
+// "code --wait", or the platform's default editor
+func preferredEditor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+func handleConfigEdit() {
+	if err := runConfigEdit(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runConfigEdit opens the config file in the editor, creating it first if
+// needed, and checks that it still loads once the editor exits
+func runConfigEdit(out io.Writer) error {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+			return err
+		}
+		// Tokens end up in here, so only the user may read it
+		if err := os.WriteFile(configPath, nil, 0600); err != nil {
+			return err
+		}
+	}
+
+	if err := editFile(configPath); err != nil {
+		return fmt.Errorf("editor failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		data, _ := os.ReadFile(configPath)
+		message := fmt.Sprintf("%s is not valid: %v", configPath, err)
+		if line := offendingLine(data, err); line != "" {
+			message += "\n" + line
+		}
+		return fmt.Errorf("%s\nRun 'mbx config edit' again to fix it", message)
+	}
+	fmt.Fprintf(out, "✓ %s is valid\n", configPath)
+	if warning := cfg.Warning(); warning != "" {
+		fmt.Fprintf(out, "⚠ %s\n", warning)
+	}
+	return nil
+}
+
+// yamlLinePattern finds the line number in a YAML parse error
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// offendingLine quotes the line of data a YAML parse error points at, or
+// returns "" when the error doesn't name one
+func offendingLine(data []byte, err error) string {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return ""
+	}
+	number, _ := strconv.Atoi(match[1])
+	lines := strings.Split(string(data), "\n")
+	if number < 1 || number > len(lines) {
+		return ""
+	}
+	return fmt.Sprintf("%5d | %s", number, lines[number-1])
+}
@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+func TestRunConfigInit(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		skipTest    bool
+		failURLs    []string // URLs the stubbed connection test rejects
+		expectError bool
+		expectTests int
+		expectSaved *config.Profile
+		wantOutput  []string
+	}{
+		{
+			name:        "connection ok",
+			input:       "\nmetabase.example.com\ntoken-1234\n",
+			expectTests: 1,
+			expectSaved: &config.Profile{URL: "https://metabase.example.com", Token: "token-1234"},
+			wantOutput:  []string{"✓ Connected as Ada Lovelace", "✓ Configuration saved for profile 'default'"},
+		},
+		{
+			name:        "failure, then re-entered",
+			input:       "\nhttps://wrong.example.com\ntoken-1234\ny\nhttps://metabase.example.com\n\n",
+			failURLs:    []string{"https://wrong.example.com"},
+			expectTests: 2,
+			expectSaved: &config.Profile{URL: "https://metabase.example.com", Token: "token-1234"},
+			wantOutput:  []string{"✗ Connection failed: API token authentication failed", "Metabase URL [https://wrong.example.com]: ", "✓ Connected as"},
+		},
+		{
+			name:        "failure, declined",
+			input:       "\nhttps://wrong.example.com\ntoken-1234\nn\n",
+			failURLs:    []string{"https://wrong.example.com"},
+			expectError: true,
+			expectTests: 1,
+		},
+		{
+			name:        "failure, input ends",
+			input:       "\nhttps://wrong.example.com\ntoken-1234\n",
+			failURLs:    []string{"https://wrong.example.com"},
+			expectError: true,
+			expectTests: 1,
+		},
+		{
+			name:        "spaces kept",
+			input:       "my work\n metabase.example.com/ \ntoken with spaces \n",
+			skipTest:    true,
+			expectSaved: &config.Profile{URL: "https://metabase.example.com", Token: "token with spaces "},
+			wantOutput:  []string{"saved for profile 'my work'"},
+		},
+		{
+			name:        "skip test",
+			input:       "work\nhttps://offline.example.com\ntoken-1234\n",
+			skipTest:    true,
+			expectSaved: &config.Profile{URL: "https://offline.example.com", Token: "token-1234"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.SetGlobalConfigFile(filepath.Join(t.TempDir(), "config.yaml"))
+			defer config.SetGlobalConfigFile("")
+
+			tests := 0
+			verify := func(metabaseURL, apiToken string) (*api.UserInfo, error) {
+				tests++
+				for _, failURL := range tt.failURLs {
+					if metabaseURL == failURL {
+						return nil, errors.New("API token authentication failed with status: 401")
+					}
+				}
+				return &api.UserInfo{FirstName: "Ada", LastName: "Lovelace"}, nil
+			}
+
+			var out bytes.Buffer
+			err := runConfigInit(strings.NewReader(tt.input), &out, fakeTerminal{}, tt.skipTest, verify)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("runConfigInit() error = %v, expectError %v (output %q)", err, tt.expectError, out.String())
+			}
+			if tests != tt.expectTests {
+				t.Errorf("runConfigInit() tested the connection %d times, want %d", tests, tt.expectTests)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("runConfigInit() output = %q, want it to contain %q", out.String(), want)
+				}
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error = %v", err)
+			}
+			if tt.expectSaved == nil {
+				if len(cfg.Profiles) != 0 {
+					t.Errorf("runConfigInit() saved %v, want nothing saved", cfg.Profiles)
+				}
+				return
+			}
+			var saved config.Profile
+			for _, profile := range cfg.Profiles {
+				saved = profile
+			}
+			if saved != *tt.expectSaved {
+				t.Errorf("runConfigInit() saved %+v, want %+v", saved, *tt.expectSaved)
+			}
+		})
+	}
+}
+
+// fakeTerminal stands in for stdin; when password is set it acts as a
+// terminal and returns it from ReadPassword
+type fakeTerminal struct {
+	password string
+	err      error
+}
+
+func (f fakeTerminal) IsTerminal() bool { return f.password != "" || f.err != nil }
+
+func (f fakeTerminal) ReadPassword() ([]byte, error) { return []byte(f.password), f.err }
+
+func TestReadSecret(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		terminal    fakeTerminal
+		expected    string
+		expectError bool
+		expectNext  string // Line left for the next prompt
+	}{
+		{name: "terminal", input: "next\n", terminal: fakeTerminal{password: "mb_a1b2 c3$d4!"}, expected: "mb_a1b2 c3$d4!", expectNext: "next"},
+		{name: "terminal error", terminal: fakeTerminal{err: errors.New("inappropriate ioctl for device")}, expectError: true},
+		{name: "piped", input: "mb_a1b2 c3$d4!\nnext\n", expected: "mb_a1b2 c3$d4!", expectNext: "next"},
+		{name: "piped with CRLF", input: "mb_token\r\nnext\n", expected: "mb_token", expectNext: "next"},
+		{name: "piped without newline", input: "mb_token", expected: "mb_token"},
+		{name: "empty line", input: "\n", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := bufio.NewReader(strings.NewReader(tt.input))
+			var out bytes.Buffer
+			got, err := readSecret(lines, &out, tt.terminal)
+			if tt.expectError {
+				if err == nil {
+					t.Error("readSecret() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readSecret() unexpected error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("readSecret() = %q, want %q", got, tt.expected)
+			}
+			if next, _ := readLine(lines); next != tt.expectNext {
+				t.Errorf("readSecret() left %q for the next prompt, want %q", next, tt.expectNext)
+			}
+			if strings.Contains(out.String(), tt.expected) && tt.expected != "" {
+				t.Errorf("readSecret() echoed the secret: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "https://metabase.example.com \n", expected: []string{"https://metabase.example.com "}},
+		{input: "my work profile\nnext\n", expected: []string{"my work profile", "next"}},
+		{input: "  padded  \r\n", expected: []string{"  padded  "}},
+		{input: "\nno trailing newline", expected: []string{"", "no trailing newline"}},
+		{input: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lines := bufio.NewReader(strings.NewReader(tt.input))
+			for _, expected := range tt.expected {
+				got, err := readLine(lines)
+				if err != nil {
+					t.Fatalf("readLine() unexpected error = %v", err)
+				}
+				if got != expected {
+					t.Errorf("readLine() = %q, want %q", got, expected)
+				}
+			}
+			if _, err := readLine(lines); err != io.EOF {
+				t.Errorf("readLine() at the end error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestRunConfigEdit(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    string // Empty leaves the config file missing
+		edited      string
+		expectError []string
+	}{
+		{
+			name:   "created and filled in",
+			edited: "default_profile: work\nprofiles:\n  work:\n    url: https://metabase.example.com\n",
+		},
+		{
+			name:     "left as it was",
+			existing: "read_only: true\n",
+			edited:   "read_only: true\n",
+		},
+		{
+			name:        "broken",
+			existing:    "read_only: true\n",
+			edited:      "read_only: true\nprofiles:\n  work:\n  url https://metabase.example.com\n    token: abc\n",
+			expectError: []string{"is not valid: yaml: line 4", "    4 |   url https://metabase.example.com"},
+		},
+	}
+
+	original := editFile
+	defer func() { editFile = original }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "mbx", "config.yaml")
+			config.SetGlobalConfigFile(configPath)
+			defer config.SetGlobalConfigFile("")
+			if tt.existing != "" {
+				if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(configPath, []byte(tt.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var edited string
+			editFile = func(path string) error {
+				edited = path
+				if _, err := os.Stat(path); err != nil {
+					t.Errorf("editor opened a missing file: %v", err)
+				}
+				return os.WriteFile(path, []byte(tt.edited), 0600)
+			}
+
+			var out bytes.Buffer
+			err := runConfigEdit(&out)
+			if edited != configPath {
+				t.Errorf("editor opened %q, want %q", edited, configPath)
+			}
+			if len(tt.expectError) > 0 {
+				if err == nil {
+					t.Fatal("runConfigEdit() = nil, want a parse error")
+				}
+				for _, want := range tt.expectError {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("runConfigEdit() error = %q, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runConfigEdit() unexpected error = %v", err)
+			}
+			if !strings.Contains(out.String(), "is valid") {
+				t.Errorf("output = %q, want the file reported valid", out.String())
+			}
+		})
+	}
+}
+
+func TestPreferredEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	if got := preferredEditor(); got != "code --wait" {
+		t.Errorf("preferredEditor() = %q, want $EDITOR", got)
+	}
+	t.Setenv("VISUAL", "nano")
+	if got := preferredEditor(); got != "nano" {
+		t.Errorf("preferredEditor() = %q, want $VISUAL first", got)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := preferredEditor(); got == "" {
+		t.Error("preferredEditor() = \"\", want a default")
+	}
+}
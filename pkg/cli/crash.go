@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// crashLogName is the file a panic is written to, in the config directory
+const crashLogName = "crash.log"
+
+// commandPanic carries a panic out of a command's goroutine, so it can be
+// raised again where runGuarded recovers it
+type commandPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// crashGuard wraps the TUI model so panics in the commands it starts reach
+// the event loop instead of ending the program with the terminal in raw
+// mode. Panics in Update and View already happen on the event loop.
+type crashGuard struct {
+	model tea.Model
+}
+
+func (g crashGuard) Init() tea.Cmd {
+	return guardCmd(g.model.Init())
+}
+
+func (g crashGuard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if p, ok := msg.(commandPanic); ok {
+		panic(p)
+	}
+	model, cmd := g.model.Update(msg)
+	return crashGuard{model: model}, guardCmd(cmd)
+}
+
+func (g crashGuard) View() string {
+	return g.model.View()
+}
+
+// cmdType is the element type of batches and of sequences, whose message
+// type Bubble Tea doesn't export
+var cmdType = reflect.TypeOf(tea.Cmd(nil))
+
+// guardCmd turns a panic in cmd into a commandPanic message. The commands of
+// a batch or a sequence run separately, so each is guarded too.
+func guardCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = commandPanic{value: r, stack: debug.Stack()}
+			}
+		}()
+		msg = cmd()
+		if cmds := reflect.ValueOf(msg); cmds.Kind() == reflect.Slice && cmds.Type().Elem() == cmdType {
+			for i := 0; i < cmds.Len(); i++ {
+				guarded := guardCmd(cmds.Index(i).Interface().(tea.Cmd))
+				cmds.Index(i).Set(reflect.ValueOf(guarded))
+			}
+		}
+		return msg
+	}
+}
+
+// runGuarded calls run and, if it panics, restores the terminal, writes the
+// panic and its stack to the crash log in logDir and tells the user where to
+// find it. Nothing is sent anywhere; the log is for attaching to an issue.
+func runGuarded(logDir string, stderr io.Writer, restore func(), run func() error) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		stack := debug.Stack()
+		if p, ok := r.(commandPanic); ok {
+			r, stack = p.value, p.stack
+		}
+		restore()
+		err = fmt.Errorf("mbx crashed: %v", r)
+
+		path, writeErr := writeCrashLog(logDir, r, stack)
+		if writeErr != nil {
+			fmt.Fprintf(stderr, "%v\n\n%s\nCould not write the crash log: %v\n", err, stack, writeErr)
+			return
+		}
+		fmt.Fprintf(stderr, "%v\nThe details were written to %s\nPlease attach it to an issue at https://github.com/amureki/metabase-explorer/issues\n", err, path)
+	}()
+	return run()
+}
+
+// runProgram runs the TUI and returns the exit code once the terminal has
+// been handed back, so no error ends mbx in the alternate screen or raw mode
+func runProgram(p *tea.Program, logDir string, stderr io.Writer) int {
+	err := runGuarded(logDir, stderr, func() { _ = p.ReleaseTerminal() }, func() error {
+		_, err := p.Run()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error running program: %v\n", err)
+		}
+		return err
+	})
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// writeCrashLog replaces the crash log with the given panic and returns its
+// path
+func writeCrashLog(logDir string, value interface{}, stack []byte) (string, error) {
+	if logDir == "" {
+		return "", fmt.Errorf("no config directory")
+	}
+	if err := os.MkdirAll(logDir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(logDir, crashLogName)
+	report := fmt.Sprintf("mbx version: %s\nPlatform:    %s/%s (%s)\nTime:        %s\n\npanic: %v\n\n%s",
+		version, runtime.GOOS, runtime.GOARCH, runtime.Version(), time.Now().Format(time.RFC3339), value, stack)
+	if err := os.WriteFile(path, []byte(report), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRunGuarded(t *testing.T) {
+	t.Run("panic", func(t *testing.T) {
+		logDir := filepath.Join(t.TempDir(), "mbx")
+		var stderr bytes.Buffer
+		restored := false
+
+		err := runGuarded(logDir, &stderr, func() { restored = true }, func() error {
+			var table *struct{ Name string }
+			_ = table.Name // A nil pointer, as in a renderer
+			return nil
+		})
+
+		if err == nil {
+			t.Fatal("runGuarded() = nil, want an error for the panic")
+		}
+		if !restored {
+			t.Error("terminal not restored")
+		}
+		path := filepath.Join(logDir, crashLogName)
+		log, readErr := os.ReadFile(path)
+		if readErr != nil {
+			t.Fatalf("crash log not written: %v", readErr)
+		}
+		for _, want := range []string{"panic: runtime error: invalid memory address", "crash_test.go", "mbx version:"} {
+			if !strings.Contains(string(log), want) {
+				t.Errorf("crash log missing %q:\n%s", want, log)
+			}
+		}
+		if !strings.Contains(stderr.String(), path) {
+			t.Errorf("stderr = %q, want the log path", stderr.String())
+		}
+	})
+
+	t.Run("command panic", func(t *testing.T) {
+		logDir := t.TempDir()
+		cmd := guardCmd(tea.Batch(
+			func() tea.Msg { return nil },
+			func() tea.Msg { panic("lost in a goroutine") },
+		))
+		batch := cmd().(tea.BatchMsg)
+		msg := batch[1]()
+
+		err := runGuarded(logDir, &bytes.Buffer{}, func() {}, func() error {
+			crashGuard{}.Update(msg)
+			return nil
+		})
+
+		if err == nil || !strings.Contains(err.Error(), "lost in a goroutine") {
+			t.Fatalf("runGuarded() = %v, want the command's panic", err)
+		}
+		log, _ := os.ReadFile(filepath.Join(logDir, crashLogName))
+		// The stack is the command's, not the one it was raised again from
+		if !strings.Contains(string(log), "guardCmd") {
+			t.Errorf("crash log has the wrong stack:\n%s", log)
+		}
+	})
+
+	t.Run("command panic in a sequence", func(t *testing.T) {
+		cmd := guardCmd(tea.Sequence(
+			func() tea.Msg { return nil },
+			func() tea.Msg { panic("lost in a sequence") },
+		))
+		// Sequences are run one command at a time by Bubble Tea
+		sequence := reflect.ValueOf(cmd())
+		msg := sequence.Index(1).Interface().(tea.Cmd)()
+
+		if p, ok := msg.(commandPanic); !ok || p.value != "lost in a sequence" {
+			t.Errorf("sequence command = %#v, want its panic as a commandPanic", msg)
+		}
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		logDir := t.TempDir()
+		runErr := errors.New("program failed")
+		err := runGuarded(logDir, &bytes.Buffer{}, func() { t.Error("terminal restored without a panic") }, func() error {
+			return runErr
+		})
+		if err != runErr {
+			t.Errorf("runGuarded() = %v, want %v", err, runErr)
+		}
+		if _, statErr := os.Stat(filepath.Join(logDir, crashLogName)); !os.IsNotExist(statErr) {
+			t.Errorf("crash log written without a panic: %v", statErr)
+		}
+	})
+}
+
+// failingModel quits as soon as it starts, or panics at the given point
+type failingModel struct {
+	failIn string // "update", "command" or "" to quit cleanly
+}
+
+type startedMsg struct{}
+
+func (m failingModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		if m.failIn == "command" {
+			panic("command failed")
+		}
+		return startedMsg{}
+	}
+}
+
+func (m failingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(startedMsg); ok {
+		if m.failIn == "update" {
+			panic("update failed")
+		}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m failingModel) View() string {
+	return "running"
+}
+
+func TestRunProgram(t *testing.T) {
+	const exitAltScreen = "\x1b[?1049l"
+
+	tests := []struct {
+		name     string
+		failIn   string
+		wantCode int
+	}{
+		{name: "quit", wantCode: 0},
+		{name: "panic in update", failIn: "update", wantCode: 1},
+		{name: "panic in a command", failIn: "command", wantCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output, stderr bytes.Buffer
+			p := newProgram(failingModel{failIn: tt.failIn}, true, tea.WithInput(nil), tea.WithOutput(&output))
+
+			done := make(chan int)
+			go func() { done <- runProgram(p, t.TempDir(), &stderr) }()
+			select {
+			case code := <-done:
+				if code != tt.wantCode {
+					t.Errorf("runProgram() = %d, want %d; stderr:\n%s", code, tt.wantCode, stderr.String())
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("runProgram() did not return")
+			}
+			if !strings.Contains(output.String(), exitAltScreen) {
+				t.Errorf("output %q never left the alternate screen", output.String())
+			}
+		})
+	}
+}
+
+func TestNewProgram_AltScreen(t *testing.T) {
+	const enterAltScreen = "\x1b[?1049h"
+
+	for _, altScreen := range []bool{true, false} {
+		var output bytes.Buffer
+		p := newProgram(failingModel{}, altScreen, tea.WithInput(nil), tea.WithOutput(&output))
+		if code := runProgram(p, t.TempDir(), io.Discard); code != 0 {
+			t.Fatalf("runProgram() = %d, want 0", code)
+		}
+		if entered := strings.Contains(output.String(), enterAltScreen); entered != altScreen {
+			t.Errorf("altScreen = %v, but the alternate screen was entered = %v", altScreen, entered)
+		}
+		if !altScreen && !strings.Contains(output.String(), "running") {
+			t.Errorf("output %q, want the last view left in the main screen", output.String())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+// diagnostics is what "mbx doctor" reports for bug reports
+type diagnostics struct {
+	version      string
+	platform     string
+	configPath   string
+	configStatus string
+	profile      string
+	url          string
+	token        string // Masked
+	connection   string
+	connected    bool
+}
+
+// connectionTester checks that a URL and token reach Metabase; replaced in tests
+type connectionTester func(metabaseURL, apiToken string) error
+
+func testMetabaseConnection(metabaseURL, apiToken string) error {
+	return newClient(metabaseURL, apiToken).TestConnection()
+}
+
+// collectDiagnostics gathers the environment, the state of the config file
+// and the result of a connection test with the resolved settings
+func collectDiagnostics(flagURL, flagToken, flagProfile string, test connectionTester) diagnostics {
+	d := diagnostics{
+		version:  version,
+		platform: fmt.Sprintf("%s/%s (%s)", runtime.GOOS, runtime.GOARCH, runtime.Version()),
+	}
+
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		d.configStatus = fmt.Sprintf("unknown: %v", err)
+	} else {
+		d.configPath = configPath
+		d.configStatus = configFileStatus(configPath)
+	}
+
+	if flagURL == "" || flagToken == "" {
+		d.profile = flagProfile
+		if cfg, err := config.LoadConfig(); err == nil && d.profile == "" {
+			d.profile = cfg.DefaultProfile
+		}
+	}
+
+	metabaseURL, apiToken, err := config.ResolveConfiguration(flagURL, flagToken, flagProfile)
+	if err != nil {
+		d.connection = fmt.Sprintf("not tested, %v", err)
+		return d
+	}
+	d.url = metabaseURL
+	d.token = maskToken(apiToken)
+
+	start := time.Now()
+	err = test(metabaseURL, apiToken)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		d.connection = fmt.Sprintf("failed after %s: %v", elapsed, err)
+	} else {
+		d.connection = fmt.Sprintf("ok in %s", elapsed)
+		d.connected = true
+	}
+	return d
+}
+
+// configFileStatus reports whether the config file exists, can be read and
+// parses
+func configFileStatus(path string) string {
+	if _, err := os.ReadFile(path); err != nil {
+		if os.IsNotExist(err) {
+			return "not found"
+		}
+		return fmt.Sprintf("not readable: %v", err)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Sprintf("not valid: %v", err)
+	}
+	if len(cfg.UnknownKeys) > 0 {
+		return "ok, ignoring unknown keys: " + strings.Join(cfg.UnknownKeys, ", ")
+	}
+	return "ok"
+}
+
+func (d diagnostics) write(w io.Writer) {
+	orNone := func(value string) string {
+		if value == "" {
+			return "(none)"
+		}
+		return value
+	}
+	fmt.Fprintf(w, "mbx version: %s\n", d.version)
+	fmt.Fprintf(w, "Platform:    %s\n", d.platform)
+	fmt.Fprintf(w, "Config file: %s (%s)\n", orNone(d.configPath), d.configStatus)
+	fmt.Fprintf(w, "Profile:     %s\n", orNone(d.profile))
+	fmt.Fprintf(w, "URL:         %s\n", orNone(d.url))
+	fmt.Fprintf(w, "Token:       %s\n", orNone(d.token))
+	fmt.Fprintf(w, "Connection:  %s\n", d.connection)
+}
+
+func handleDoctorCommand(metabaseURL, apiToken, profile string) {
+	d := collectDiagnostics(metabaseURL, apiToken, profile, testMetabaseConnection)
+	d.write(os.Stdout)
+	if !d.connected {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+func TestCollectDiagnostics(t *testing.T) {
+	const token = "mb_secret_token_1234"
+	validConfig := "default_profile: work\nprofiles:\n  work:\n    url: https://metabase.example.com\n    token: " + token + "\n"
+
+	tests := []struct {
+		name           string
+		configContent  string // Empty leaves the config file missing
+		flagURL        string
+		flagToken      string
+		connectErr     error
+		wantConnected  bool
+		wantConfig     string
+		wantProfile    string
+		wantConnection string
+	}{
+		{
+			name:           "profile from config",
+			configContent:  validConfig,
+			wantConnected:  true,
+			wantConfig:     "ok",
+			wantProfile:    "work",
+			wantConnection: "ok in ",
+		},
+		{
+			name:           "connection fails",
+			configContent:  validConfig,
+			connectErr:     errors.New("API token authentication failed with status: 401"),
+			wantConfig:     "ok",
+			wantProfile:    "work",
+			wantConnection: "failed after ",
+		},
+		{
+			name:           "flags without a config",
+			flagURL:        "https://metabase.example.com",
+			flagToken:      token,
+			wantConnected:  true,
+			wantConfig:     "not found",
+			wantConnection: "ok in ",
+		},
+		{
+			name:           "unknown key",
+			configContent:  validConfig + "promptless: true\n",
+			wantConnected:  true,
+			wantConfig:     "ok, ignoring unknown keys: promptless (line 6)",
+			wantProfile:    "work",
+			wantConnection: "ok in ",
+		},
+		{
+			name:           "invalid config",
+			configContent:  "profiles: [",
+			wantConfig:     "not valid: ",
+			wantConnection: "not tested, ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			config.SetGlobalConfigFile(configPath)
+			defer config.SetGlobalConfigFile("")
+			if tt.configContent != "" {
+				if err := os.WriteFile(configPath, []byte(tt.configContent), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var tested string
+			d := collectDiagnostics(tt.flagURL, tt.flagToken, "", func(metabaseURL, apiToken string) error {
+				tested = metabaseURL + " " + apiToken
+				return tt.connectErr
+			})
+
+			if d.connected != tt.wantConnected {
+				t.Errorf("connected = %v, want %v", d.connected, tt.wantConnected)
+			}
+			if !strings.HasPrefix(d.configStatus, tt.wantConfig) {
+				t.Errorf("configStatus = %q, want prefix %q", d.configStatus, tt.wantConfig)
+			}
+			if d.profile != tt.wantProfile {
+				t.Errorf("profile = %q, want %q", d.profile, tt.wantProfile)
+			}
+			if !strings.HasPrefix(d.connection, tt.wantConnection) {
+				t.Errorf("connection = %q, want prefix %q", d.connection, tt.wantConnection)
+			}
+			if tt.wantConnection != "not tested, " && tested != "https://metabase.example.com "+token {
+				t.Errorf("connection tested with %q, want the resolved URL and token", tested)
+			}
+
+			var out bytes.Buffer
+			d.write(&out)
+			if strings.Contains(out.String(), token) {
+				t.Errorf("write() printed the token:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestMaskToken(t *testing.T) {
+	tests := []struct {
+		token    string
+		expected string
+	}{
+		{"mb_secret_token_1234", "mb_s...1234"},
+		{"short", "*****"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := maskToken(tt.token); got != tt.expected {
+			t.Errorf("maskToken(%q) = %q, want %q", tt.token, got, tt.expected)
+		}
+	}
+}
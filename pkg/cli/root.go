@@ -2,8 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
+	"github.com/amureki/metabase-explorer/pkg/api"
 	"github.com/amureki/metabase-explorer/pkg/config"
 	"github.com/amureki/metabase-explorer/pkg/tui"
 	"github.com/amureki/metabase-explorer/pkg/util"
@@ -12,6 +17,12 @@ import (
 
 var version = "dev"
 
+// headerFlags are the headers given with --header, sent by every client
+var headerFlags map[string]string
+
+// setupWizard runs the interactive setup; replaced in tests
+var setupWizard = func() { handleConfigInit(false) }
+
 func printHelp() {
 	fmt.Printf(`mbx - Metabase Explorer %s
 
@@ -19,22 +30,42 @@ A Terminal User Interface for exploring Metabase database metadata.
 
 USAGE:
     mbx [OPTIONS]
-    mbx init
+    mbx init [--skip-test]
     mbx config <command> [arguments]
-    mbx update
+    mbx run <card-id> [--format csv|json|xlsx] [-o <file>]
+    mbx search <query> [--model <type>] [--format table|json|csv] [--limit <n>]
+    mbx schema --database <id> [--format json|markdown]
+    mbx update [--dry-run]
+    mbx doctor
 
 OPTIONS:
     -h, --help                Show this help message
     -v, --version             Show version information
     -u, --url <url>           Metabase URL (overrides config)
     -t, --token <token>       API token (overrides config)
-    -p, --profile <name>      Configuration profile to use
+    -p, --profile <name>      Configuration profile to use; a comma list
+                              (work,dev) also searches the other profiles
     -c, --config <path>       Custom config file location
+        --read-only           Refuse any action that would modify Metabase
+        --offline             Skip the update check and background requests
+        --no-altscreen        Keep the last view in the terminal scrollback
+                              after quitting
+        --header <name=value> Send a header with every request, such as
+                              X-Tenant-ID=acme; repeat for more
 
 COMMANDS:
     init                               Interactive setup wizard
+                                       (--skip-test saves without connecting)
     config <subcommand>                Configuration management
+    run <card-id>                      Run a saved question and export its results
+    search <query>                     Search questions, dashboards, collections
+                                       and tables, e.g. to pipe into fzf
+    schema --database <id>             Print the schemas, tables and fields of
+                                       a database for documentation
     update                             Update to the latest version
+                                       (--dry-run only reports it)
+    doctor                             Print version, config and connection
+                                       details for bug reports
 
 CONFIGURATION:
     mbx init                           # Interactive setup wizard
@@ -53,7 +84,7 @@ For more information, visit: https://github.com/amureki/metabase-explorer
 
 func Execute(args []string, ver string) {
 	version = ver
-	var showVersion, showHelp bool
+	var showVersion, showHelp, readOnly, offline, noAltScreen bool
 	var metabaseURL, apiToken, profile, configFile string
 	var parsedArgs []string
 
@@ -79,13 +110,33 @@ func Execute(args []string, ver string) {
 				profile = args[i+1]
 				i++
 			}
+		case "--read-only":
+			readOnly = true
+		case "--offline":
+			offline = true
+		case "--no-altscreen":
+			noAltScreen = true
+		case "--header":
+			if i+1 < len(args) {
+				name, value, err := config.ParseHeader(args[i+1])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				if headerFlags == nil {
+					headerFlags = make(map[string]string)
+				}
+				headerFlags[name] = value
+				i++
+			}
 		case "-c", "--config":
 			if i+1 < len(args) {
 				configFile = args[i+1]
 				i++
 			}
 		default:
-			if args[i][0] == '-' {
+			// Flags after a command belong to it, e.g. "mbx run 42 -o out.csv"
+			if strings.HasPrefix(args[i], "-") && len(parsedArgs) == 0 {
 				fmt.Fprintf(os.Stderr, "Error: Unknown flag '%s'\n", args[i])
 				fmt.Fprintf(os.Stderr, "Run 'mbx --help' for usage information.\n")
 				os.Exit(1)
@@ -98,16 +149,39 @@ func Execute(args []string, ver string) {
 		config.SetGlobalConfigFile(configFile)
 	}
 
+	// "--profile work,dev" browses work and adds dev to global search
+	profile, extraProfiles, err := splitProfiles(profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(extraProfiles) > 0 && len(parsedArgs) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: Several profiles can only be given to the interactive browser\n")
+		os.Exit(1)
+	}
+
 	if len(parsedArgs) > 0 {
 		switch parsedArgs[0] {
 		case "init":
-			handleConfigInit()
+			handleInitCommand(parsedArgs[1:])
 			return
 		case "config":
 			handleConfigCommand(parsedArgs[1:])
 			return
+		case "run":
+			handleRunCommand(parsedArgs[1:], metabaseURL, apiToken, profile)
+			return
+		case "search":
+			handleSearchCommand(parsedArgs[1:], metabaseURL, apiToken, profile)
+			return
+		case "schema":
+			handleSchemaCommand(parsedArgs[1:], metabaseURL, apiToken, profile)
+			return
 		case "update":
-			util.HandleUpdateCommand(version)
+			handleUpdateCommand(parsedArgs[1:])
+			return
+		case "doctor":
+			handleDoctorCommand(metabaseURL, apiToken, profile)
 			return
 		default:
 			fmt.Fprintf(os.Stderr, "Error: Unknown command '%s'\n", parsedArgs[0])
@@ -126,9 +200,181 @@ func Execute(args []string, ver string) {
 		return
 	}
 
-	p := tea.NewProgram(tui.InitialModel(metabaseURL, apiToken, profile, version), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+	if !firstRunSetup(metabaseURL, apiToken, os.Stdin, os.Stdout) {
+		return
+	}
+
+	opts := tui.Options{
+		URL:      metabaseURL,
+		Token:    apiToken,
+		Profile:  profile,
+		Version:  version,
+		ReadOnly: readOnly,
+		Offline:  offline,
+		Headers:  headerFlags,
+
+		ExtraProfiles: extraProfiles,
+	}
+	model, err := tui.InitialModel(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, `Error: %v
+
+Configuration sources (in priority order):
+1. CLI flags: --url and --token
+2. Config file: ~/.config/mbx/config.yaml (or --config <path>)
+
+To get started, run: mbx init
+
+See https://www.metabase.com/docs/latest/people-and-groups/api-keys for API token setup.
+Run 'mbx --help' for more information.
+`, err)
 		os.Exit(1)
 	}
+
+	if cfg, err := config.LoadConfig(); err == nil && cfg.NoAltScreen {
+		noAltScreen = true
+	}
+	p := newProgram(model, !noAltScreen)
+	logDir, _ := config.GetConfigDir()
+	if code := runProgram(p, logDir, os.Stderr); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// newProgram runs the model in the alternate screen, or without it to leave
+// the last view in the terminal's scrollback. Panics are caught by
+// runGuarded rather than Bubble Tea, to keep a log.
+func newProgram(model tea.Model, altScreen bool, opts ...tea.ProgramOption) *tea.Program {
+	opts = append(opts, tea.WithoutCatchPanics())
+	if altScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	return tea.NewProgram(crashGuard{model: model}, opts...)
+}
+
+func handleUpdateCommand(args []string) {
+	dryRun := false
+	for _, arg := range args {
+		if arg != "--dry-run" {
+			fmt.Fprintf(os.Stderr, "Error: Unknown argument '%s' for 'update'\n", arg)
+			os.Exit(1)
+		}
+		dryRun = true
+	}
+	releaseURL := ""
+	if cfg, err := config.LoadConfig(); err == nil {
+		releaseURL = cfg.ReleaseURL
+	}
+	client := &http.Client{Transport: api.NewTransport(configuredProxy())}
+	util.HandleUpdateCommand(client, util.ReleaseURL(releaseURL), version, dryRun)
+}
+
+// newClient returns a client for a command, with the User-Agent, auth
+// header, extra headers and proxy from the config file and flags when they
+// are set
+func newClient(metabaseURL, apiToken string) *api.MetabaseClient {
+	client := api.NewMetabaseClient(metabaseURL, apiToken, version)
+	client.Headers = headerFlags
+	if cfg, err := config.LoadConfig(); err == nil {
+		if cfg.UserAgent != "" {
+			client.UserAgent = cfg.UserAgent
+		}
+		if client.BearerAuth, err = cfg.BearerAuth(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if client.Headers, err = cfg.RequestHeaders(headerFlags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if proxy := configuredProxy(); proxy != nil {
+		client.UseProxy(proxy)
+	}
+	return client
+}
+
+// configuredProxy returns the proxy set in the config file, or nil to use
+// the proxy environment variables. An invalid proxy ends the command rather
+// than connecting without it.
+func configuredProxy() *url.URL {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil
+	}
+	proxy, err := cfg.ProxyURL()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return proxy
+}
+
+func handleInitCommand(args []string) {
+	skipTest := false
+	for _, arg := range args {
+		if arg != "--skip-test" {
+			fmt.Fprintf(os.Stderr, "Error: Unknown argument '%s' for 'init'\n", arg)
+			os.Exit(1)
+		}
+		skipTest = true
+	}
+	handleConfigInit(skipTest)
+}
+
+// splitProfiles splits a comma-separated --profile value into the profile to
+// browse and the extra profiles to search alongside it. An empty value
+// leaves the profile to the config; one naming no profile is an error.
+func splitProfiles(value string) (string, []string, error) {
+	if value == "" {
+		return "", nil, nil
+	}
+	var profiles []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			profiles = append(profiles, name)
+		}
+	}
+	switch len(profiles) {
+	case 0:
+		return "", nil, fmt.Errorf("--profile '%s' names no profile", value)
+	case 1:
+		return profiles[0], nil, nil
+	}
+	return profiles[0], profiles[1:], nil
+}
+
+// firstRunSetup offers to run the setup wizard when there is nothing to
+// connect with yet. It returns false if the user declined.
+func firstRunSetup(flagURL, flagToken string, in io.Reader, out io.Writer) bool {
+	if !needsSetup(flagURL, flagToken) {
+		return true
+	}
+
+	fmt.Fprint(out, "No configuration found. Run setup now? [Y/n]: ")
+	var answer string
+	fmt.Fscanln(in, &answer)
+	answer = strings.ToLower(answer)
+	if answer != "" && answer != "y" && answer != "yes" {
+		fmt.Fprintln(out, "Run 'mbx init' when you are ready to connect.")
+		return false
+	}
+
+	setupWizard()
+	fmt.Fprintln(out)
+	return true
+}
+
+// needsSetup reports whether this looks like a first run: the flags don't
+// supply a connection and no profiles are configured
+func needsSetup(flagURL, flagToken string) bool {
+	if flagURL != "" && flagToken != "" {
+		return false
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		// A broken config is not a first run; let the usual error explain it
+		return false
+	}
+	return len(cfg.Profiles) == 0
 }
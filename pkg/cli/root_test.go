@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+func TestFirstRunSetup(t *testing.T) {
+	tests := []struct {
+		name         string
+		profiles     map[string]config.Profile
+		flagURL      string
+		flagToken    string
+		input        string
+		expectPrompt bool
+		expectWizard bool
+		expectResult bool
+	}{
+		{
+			name:         "no config, accept default",
+			input:        "\n",
+			expectPrompt: true,
+			expectWizard: true,
+			expectResult: true,
+		},
+		{
+			name:         "no config, accept explicitly",
+			input:        "Y\n",
+			expectPrompt: true,
+			expectWizard: true,
+			expectResult: true,
+		},
+		{
+			name:         "no config, decline",
+			input:        "n\n",
+			expectPrompt: true,
+			expectWizard: false,
+			expectResult: false,
+		},
+		{
+			name:         "flags supply the connection",
+			flagURL:      "https://metabase.example.com",
+			flagToken:    "token",
+			expectResult: true,
+		},
+		{
+			name:         "profile configured",
+			profiles:     map[string]config.Profile{"default": {URL: "https://metabase.example.com", Token: "token"}},
+			expectResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.SetGlobalConfigFile(filepath.Join(t.TempDir(), "config.yaml"))
+			defer config.SetGlobalConfigFile("")
+			if tt.profiles != nil {
+				if err := config.SaveConfig(&config.Config{DefaultProfile: "default", Profiles: tt.profiles}); err != nil {
+					t.Fatalf("SaveConfig() unexpected error = %v", err)
+				}
+			}
+
+			original := setupWizard
+			defer func() { setupWizard = original }()
+			wizardRan := false
+			setupWizard = func() { wizardRan = true }
+
+			var out bytes.Buffer
+			result := firstRunSetup(tt.flagURL, tt.flagToken, strings.NewReader(tt.input), &out)
+
+			if result != tt.expectResult {
+				t.Errorf("firstRunSetup() = %v, want %v", result, tt.expectResult)
+			}
+			if wizardRan != tt.expectWizard {
+				t.Errorf("firstRunSetup() ran wizard = %v, want %v", wizardRan, tt.expectWizard)
+			}
+			if prompted := strings.Contains(out.String(), "No configuration found"); prompted != tt.expectPrompt {
+				t.Errorf("firstRunSetup() prompted = %v, want %v (output %q)", prompted, tt.expectPrompt, out.String())
+			}
+		})
+	}
+}
+
+func TestSplitProfiles(t *testing.T) {
+	tests := []struct {
+		value         string
+		expected      string
+		expectedExtra []string
+		expectError   bool
+	}{
+		{value: "", expected: ""},
+		{value: "work", expected: "work"},
+		{value: "work,", expected: "work"},
+		{value: " work", expected: "work"},
+		{value: "work,dev", expected: "work", expectedExtra: []string{"dev"}},
+		{value: " work , dev,,staging ", expected: "work", expectedExtra: []string{"dev", "staging"}},
+		{value: " , ", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			profile, extra, err := splitProfiles(tt.value)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("splitProfiles(%q) error = %v, expectError %v", tt.value, err, tt.expectError)
+			}
+			if profile != tt.expected || !reflect.DeepEqual(extra, tt.expectedExtra) {
+				t.Errorf("splitProfiles(%q) = %q, %v, want %q, %v", tt.value, profile, extra, tt.expected, tt.expectedExtra)
+			}
+		})
+	}
+}
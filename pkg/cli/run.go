@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+// runOptions are the arguments of "mbx run"
+type runOptions struct {
+	cardID int
+	format string
+	output string // File to write; empty writes to stdout
+}
+
+func parseRunArgs(args []string) (runOptions, error) {
+	opts := runOptions{format: "csv"}
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-f", "--format":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			opts.format = args[i+1]
+			i++
+		case "-o", "--output":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			opts.output = args[i+1]
+			i++
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return opts, fmt.Errorf("unknown flag '%s'", args[i])
+			}
+			positional = append(positional, args[i])
+		}
+	}
+
+	if len(positional) != 1 {
+		return opts, fmt.Errorf("'run' requires a card ID")
+	}
+	id, err := strconv.Atoi(positional[0])
+	if err != nil || id <= 0 {
+		return opts, fmt.Errorf("invalid card ID '%s'", positional[0])
+	}
+	opts.cardID = id
+
+	if opts.format == "xlsx" && opts.output == "" {
+		return opts, fmt.Errorf("xlsx export needs an output file, pass -o <file>")
+	}
+	return opts, nil
+}
+
+func handleRunCommand(args []string, metabaseURL, apiToken, profile string) {
+	opts, err := parseRunArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\nUsage: mbx run <card-id> [--format csv|json|xlsx] [-o <file>]\n", err)
+		os.Exit(1)
+	}
+
+	metabaseURL, apiToken, err = config.ResolveConfiguration(metabaseURL, apiToken, profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\nRun 'mbx init' to set up a connection.\n", err)
+		os.Exit(1)
+	}
+	client := newClient(metabaseURL, apiToken)
+
+	if opts.output == "" {
+		err = client.ExportCard(opts.cardID, opts.format, os.Stdout)
+	} else {
+		err = client.ExportCardToFile(opts.cardID, opts.format, opts.output)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.output != "" {
+		fmt.Fprintf(os.Stderr, "✓ Exported card %d to %s\n", opts.cardID, opts.output)
+	}
+}
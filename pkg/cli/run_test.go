@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestParseRunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    runOptions
+		expectError string
+	}{
+		{
+			name:     "defaults to csv on stdout",
+			args:     []string{"42"},
+			expected: runOptions{cardID: 42, format: "csv"},
+		},
+		{
+			name:     "format and output",
+			args:     []string{"--format", "json", "42", "-o", "orders.json"},
+			expected: runOptions{cardID: 42, format: "json", output: "orders.json"},
+		},
+		{
+			name:        "xlsx needs a file",
+			args:        []string{"42", "-f", "xlsx"},
+			expectError: "xlsx export needs an output file, pass -o <file>",
+		},
+		{
+			name:        "missing card",
+			args:        []string{"-o", "out.csv"},
+			expectError: "'run' requires a card ID",
+		},
+		{
+			name:        "empty argument",
+			args:        []string{""},
+			expectError: "invalid card ID ''",
+		},
+		{
+			name:        "invalid card",
+			args:        []string{"orders"},
+			expectError: "invalid card ID 'orders'",
+		},
+		{
+			name:        "flag without value",
+			args:        []string{"42", "-o"},
+			expectError: "-o requires a value",
+		},
+		{
+			name:        "unknown flag",
+			args:        []string{"42", "--pretty"},
+			expectError: "unknown flag '--pretty'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseRunArgs(tt.args)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("parseRunArgs() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRunArgs() unexpected error = %v", err)
+			}
+			if opts != tt.expected {
+				t.Errorf("parseRunArgs() = %+v, want %+v", opts, tt.expected)
+			}
+		})
+	}
+}
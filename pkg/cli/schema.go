@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+// schemaOptions are the arguments of "mbx schema"
+type schemaOptions struct {
+	databaseID int
+	format     string
+}
+
+func parseSchemaArgs(args []string) (schemaOptions, error) {
+	opts := schemaOptions{format: "json"}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-d", "--database":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			id, err := strconv.Atoi(args[i+1])
+			if err != nil || id <= 0 {
+				return opts, fmt.Errorf("invalid database ID '%s'", args[i+1])
+			}
+			opts.databaseID = id
+			i++
+		case "-f", "--format":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			opts.format = args[i+1]
+			i++
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return opts, fmt.Errorf("unknown flag '%s'", args[i])
+			}
+			return opts, fmt.Errorf("unexpected argument '%s'", args[i])
+		}
+	}
+
+	if opts.databaseID == 0 {
+		return opts, fmt.Errorf("'schema' requires a database, pass --database <id>")
+	}
+	switch opts.format {
+	case "json", "markdown":
+	default:
+		return opts, fmt.Errorf("unknown format '%s', use json or markdown", opts.format)
+	}
+	return opts, nil
+}
+
+// Structure of a database as printed by "mbx schema"
+type (
+	databaseDump struct {
+		ID      int          `json:"id"`
+		Name    string       `json:"name"`
+		Engine  string       `json:"engine"`
+		Schemas []schemaDump `json:"schemas"`
+	}
+	schemaDump struct {
+		Name   string      `json:"name"`
+		Tables []tableDump `json:"tables"`
+	}
+	tableDump struct {
+		ID          int         `json:"id"`
+		Name        string      `json:"name"`
+		DisplayName string      `json:"display_name"`
+		Description string      `json:"description,omitempty"`
+		Fields      []fieldDump `json:"fields"`
+	}
+	fieldDump struct {
+		ID           int    `json:"id"`
+		Name         string `json:"name"`
+		DisplayName  string `json:"display_name"`
+		BaseType     string `json:"base_type"`
+		DatabaseType string `json:"database_type,omitempty"`
+		SemanticType string `json:"semantic_type,omitempty"`
+		Description  string `json:"description,omitempty"`
+	}
+)
+
+// dumpDatabase nests the tables of a database under their schemas, both
+// sorted by name, with the fields in column order
+func dumpDatabase(db api.Database, tables []api.Table) databaseDump {
+	dump := databaseDump{ID: db.ID, Name: db.Name, Engine: db.Engine, Schemas: []schemaDump{}}
+
+	sorted := make([]api.Table, len(tables))
+	copy(sorted, tables)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Schema != sorted[j].Schema {
+			return sorted[i].Schema < sorted[j].Schema
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, table := range sorted {
+		if len(dump.Schemas) == 0 || dump.Schemas[len(dump.Schemas)-1].Name != table.Schema {
+			dump.Schemas = append(dump.Schemas, schemaDump{Name: table.Schema})
+		}
+		fields := make([]api.Field, len(table.Fields))
+		copy(fields, table.Fields)
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].Position < fields[j].Position })
+
+		tableOut := tableDump{
+			ID:          table.ID,
+			Name:        table.Name,
+			DisplayName: table.DisplayName,
+			Description: table.Description,
+			Fields:      []fieldDump{},
+		}
+		for _, field := range fields {
+			tableOut.Fields = append(tableOut.Fields, fieldDump{
+				ID:           field.ID,
+				Name:         field.Name,
+				DisplayName:  field.DisplayName,
+				BaseType:     field.BaseType,
+				DatabaseType: field.DatabaseType,
+				SemanticType: field.SemanticType,
+				Description:  field.Description,
+			})
+		}
+		schema := &dump.Schemas[len(dump.Schemas)-1]
+		schema.Tables = append(schema.Tables, tableOut)
+	}
+	return dump
+}
+
+// writeSchema prints the structure of the database in the given format
+func writeSchema(w io.Writer, dump databaseDump, format string) error {
+	if format == "markdown" {
+		return writeSchemaMarkdown(w, dump)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(dump)
+}
+
+// writeSchemaMarkdown prints the database as a data dictionary: a section
+// per table with its description and a table of its fields
+func writeSchemaMarkdown(w io.Writer, dump databaseDump) error {
+	var out strings.Builder
+	fmt.Fprintf(&out, "# %s\n", dump.Name)
+	if dump.Engine != "" {
+		fmt.Fprintf(&out, "\nData dictionary of the %s database.\n", dump.Engine)
+	}
+	for _, schema := range dump.Schemas {
+		for _, table := range schema.Tables {
+			fmt.Fprintf(&out, "\n## %s\n\n", markdownHeading(schema.Name, table))
+			if table.Description != "" {
+				out.WriteString(table.Description)
+				out.WriteString("\n\n")
+			}
+			if len(table.Fields) == 0 {
+				out.WriteString("No fields.\n")
+				continue
+			}
+			out.WriteString("| Field | Type | Semantic type | Description |\n")
+			out.WriteString("| --- | --- | --- | --- |\n")
+			for _, field := range table.Fields {
+				fmt.Fprintf(&out, "| %s | %s | %s | %s |\n",
+					markdownCell(field.Name),
+					markdownCell(strings.TrimPrefix(field.BaseType, "type/")),
+					markdownCell(strings.TrimPrefix(field.SemanticType, "type/")),
+					markdownCell(field.Description))
+			}
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// markdownHeading names a table by its display name, followed by the
+// qualified name to use in SQL when they differ
+func markdownHeading(schema string, table tableDump) string {
+	qualified := table.Name
+	if schema != "" {
+		qualified = schema + "." + table.Name
+	}
+	if table.DisplayName == "" || table.DisplayName == table.Name {
+		return "`" + qualified + "`"
+	}
+	return table.DisplayName + " (`" + qualified + "`)"
+}
+
+// markdownCell escapes text for a cell of a Markdown table, where a pipe
+// would start a new column and a line break would end the row
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "|", "\\|")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(strings.TrimSpace(text), "\n", "<br>")
+}
+
+func handleSchemaCommand(args []string, metabaseURL, apiToken, profile string) {
+	opts, err := parseSchemaArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\nUsage: mbx schema --database <id> [--format json|markdown]\n", err)
+		os.Exit(1)
+	}
+
+	metabaseURL, apiToken, err = config.ResolveConfiguration(metabaseURL, apiToken, profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\nRun 'mbx init' to set up a connection.\n", err)
+		os.Exit(1)
+	}
+	client := newClient(metabaseURL, apiToken)
+
+	databases, err := client.GetDatabases()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var db *api.Database
+	for i := range databases {
+		if databases[i].ID == opts.databaseID {
+			db = &databases[i]
+		}
+	}
+	if db == nil {
+		fmt.Fprintf(os.Stderr, "Error: database %d not found\n", opts.databaseID)
+		os.Exit(1)
+	}
+
+	tables, err := client.GetTables(db.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := writeSchema(os.Stdout, dumpDatabase(*db, tables), opts.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
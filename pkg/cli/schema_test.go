@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files in testdata")
+
+func TestParseSchemaArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    schemaOptions
+		expectError string
+	}{
+		{
+			name:     "defaults to JSON",
+			args:     []string{"--database", "1"},
+			expected: schemaOptions{databaseID: 1, format: "json"},
+		},
+		{
+			name:     "markdown",
+			args:     []string{"-d", "2", "-f", "markdown"},
+			expected: schemaOptions{databaseID: 2, format: "markdown"},
+		},
+		{
+			name:        "missing database",
+			args:        []string{"--format", "json"},
+			expectError: "'schema' requires a database, pass --database <id>",
+		},
+		{
+			name:        "invalid database",
+			args:        []string{"--database", "sample"},
+			expectError: "invalid database ID 'sample'",
+		},
+		{
+			name:        "empty argument",
+			args:        []string{"--database", "1", ""},
+			expectError: "unexpected argument ''",
+		},
+		{
+			name:        "unknown format",
+			args:        []string{"--database", "1", "-f", "yaml"},
+			expectError: "unknown format 'yaml', use json or markdown",
+		},
+		{
+			name:        "positional argument",
+			args:        []string{"1"},
+			expectError: "unexpected argument '1'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseSchemaArgs(tt.args)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("parseSchemaArgs() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSchemaArgs() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(opts, tt.expected) {
+				t.Errorf("parseSchemaArgs() = %+v, want %+v", opts, tt.expected)
+			}
+		})
+	}
+}
+
+// fixtureSchemaDatabase is a small database with two schemas, listed out of
+// order as the API may return them
+var (
+	fixtureSchemaDatabase = api.Database{ID: 1, Name: "Shop", Engine: "postgres"}
+	fixtureSchemaTables   = []api.Table{
+		{ID: 11, Name: "orders", DisplayName: "Orders", Schema: "public", Description: "One row per order", Fields: []api.Field{
+			{ID: 111, Name: "total", DisplayName: "Total", BaseType: "type/Float", DatabaseType: "float8", Position: 1},
+			{ID: 110, Name: "id", DisplayName: "ID", BaseType: "type/Integer", DatabaseType: "int4", SemanticType: "type/PK", Position: 0},
+		}},
+		{ID: 20, Name: "events", DisplayName: "Events", Schema: "analytics", Fields: []api.Field{
+			{ID: 200, Name: "email", DisplayName: "Email", BaseType: "type/Text", SemanticType: "type/Email", Description: "Who did it"},
+		}},
+	}
+	// Descriptions as typed in the data model, with pipes and line breaks
+	fixtureDictionaryTables = append(fixtureSchemaTables,
+		api.Table{ID: 12, Name: "payments", Schema: "public", Description: "Card | cash payments", Fields: []api.Field{
+			{ID: 121, Name: "status", BaseType: "type/Text", SemanticType: "type/Category", Description: "paid | refunded\nor void", Position: 1},
+			{ID: 120, Name: "order_id", BaseType: "type/Integer", SemanticType: "type/FK", Position: 0},
+		}},
+		api.Table{ID: 13, Name: "empty", Schema: "public"},
+	)
+)
+
+func TestWriteSchema(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "json",
+			expected: `{
+  "id": 1,
+  "name": "Shop",
+  "engine": "postgres",
+  "schemas": [
+    {
+      "name": "analytics",
+      "tables": [
+        {
+          "id": 20,
+          "name": "events",
+          "display_name": "Events",
+          "fields": [
+            {
+              "id": 200,
+              "name": "email",
+              "display_name": "Email",
+              "base_type": "type/Text",
+              "semantic_type": "type/Email",
+              "description": "Who did it"
+            }
+          ]
+        }
+      ]
+    },
+    {
+      "name": "public",
+      "tables": [
+        {
+          "id": 11,
+          "name": "orders",
+          "display_name": "Orders",
+          "description": "One row per order",
+          "fields": [
+            {
+              "id": 110,
+              "name": "id",
+              "display_name": "ID",
+              "base_type": "type/Integer",
+              "database_type": "int4",
+              "semantic_type": "type/PK"
+            },
+            {
+              "id": 111,
+              "name": "total",
+              "display_name": "Total",
+              "base_type": "type/Float",
+              "database_type": "float8"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeSchema(&out, dumpDatabase(fixtureSchemaDatabase, fixtureSchemaTables), tt.format); err != nil {
+				t.Fatalf("writeSchema() error = %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("writeSchema(%s) =\n%s\nwant\n%s", tt.format, out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteSchemaMarkdown(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeSchema(&out, dumpDatabase(fixtureSchemaDatabase, fixtureDictionaryTables), "markdown"); err != nil {
+		t.Fatalf("writeSchema() error = %v", err)
+	}
+
+	path := filepath.Join("testdata", "schema.md.golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatalf("MkdirAll() unexpected error = %v", err)
+		}
+		if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+			t.Fatalf("WriteFile() unexpected error = %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v (run with -update to create it)", err)
+	}
+	if out.String() != string(want) {
+		t.Errorf("writeSchema(markdown) does not match %s (run with -update to accept)\n--- got ---\n%s\n--- want ---\n%s", path, out.String(), want)
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "plain", expected: "plain"},
+		{text: "a | b", expected: `a \| b`},
+		{text: `C:\temp`, expected: `C:\\temp`},
+		{text: "first line\r\nsecond line\n", expected: "first line<br>second line"},
+	}
+	for _, tt := range tests {
+		if got := markdownCell(tt.text); got != tt.expected {
+			t.Errorf("markdownCell(%q) = %q, want %q", tt.text, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+// searchOptions are the arguments of "mbx search"
+type searchOptions struct {
+	query  string
+	models []string // Metabase models to keep, e.g. "card"; empty for all
+	format string
+	limit  int // 0 for every result
+}
+
+func parseSearchArgs(args []string) (searchOptions, error) {
+	opts := searchOptions{format: "table"}
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-m", "--model":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			// Repeated or comma separated, as in --model card,dashboard
+			for _, model := range strings.Split(args[i+1], ",") {
+				if model = strings.TrimSpace(model); model != "" {
+					opts.models = append(opts.models, model)
+				}
+			}
+			i++
+		case "-f", "--format":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			opts.format = args[i+1]
+			i++
+		case "-n", "--limit":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", args[i])
+			}
+			limit, err := strconv.Atoi(args[i+1])
+			if err != nil || limit <= 0 {
+				return opts, fmt.Errorf("invalid limit '%s'", args[i+1])
+			}
+			opts.limit = limit
+			i++
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return opts, fmt.Errorf("unknown flag '%s'", args[i])
+			}
+			positional = append(positional, args[i])
+		}
+	}
+
+	if len(positional) != 1 || positional[0] == "" {
+		return opts, fmt.Errorf("'search' requires one query, quote it if it has spaces")
+	}
+	opts.query = positional[0]
+
+	switch opts.format {
+	case "table", "json", "csv":
+	default:
+		return opts, fmt.Errorf("unknown format '%s', use table, json or csv", opts.format)
+	}
+	return opts, nil
+}
+
+// searchRow is a search result as printed by "mbx search"
+type searchRow struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Collection string `json:"collection"`
+	URL        string `json:"url"`
+}
+
+// writeSearchResults prints the results in the given format, with links to
+// the instance at baseURL
+func writeSearchResults(w io.Writer, results []api.SearchResult, baseURL, format string) error {
+	rows := make([]searchRow, len(results))
+	for i, result := range results {
+		rows[i] = searchRow{
+			Name:       result.Name,
+			Type:       result.Model,
+			Collection: result.Collection.Name,
+			URL:        result.URL(baseURL),
+		}
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case "csv":
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{"name", "type", "collection", "url"})
+		for _, row := range rows {
+			_ = writer.Write([]string{row.Name, row.Type, row.Collection, row.URL})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "NAME\tTYPE\tCOLLECTION\tURL")
+		for _, row := range rows {
+			// Tabs and newlines in names would break the columns
+			name := strings.Join(strings.Fields(row.Name), " ")
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", name, row.Type, row.Collection, row.URL)
+		}
+		return writer.Flush()
+	}
+}
+
+func handleSearchCommand(args []string, metabaseURL, apiToken, profile string) {
+	opts, err := parseSearchArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\nUsage: mbx search <query> [--model card,dashboard] [--format table|json|csv] [--limit <n>]\n", err)
+		os.Exit(1)
+	}
+
+	metabaseURL, apiToken, err = config.ResolveConfiguration(metabaseURL, apiToken, profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\nRun 'mbx init' to set up a connection.\n", err)
+		os.Exit(1)
+	}
+	client := newClient(metabaseURL, apiToken)
+
+	results, err := client.Search(opts.query, api.SearchFilter{Models: opts.models})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.limit > 0 && len(results) > opts.limit {
+		results = results[:opts.limit]
+	}
+	if err := writeSearchResults(os.Stdout, results, client.BaseURL, opts.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+)
+
+func TestParseSearchArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    searchOptions
+		expectError string
+	}{
+		{
+			name:     "defaults to a table of every result",
+			args:     []string{"orders"},
+			expected: searchOptions{query: "orders", format: "table"},
+		},
+		{
+			name:     "models, format and limit",
+			args:     []string{"--model", "card,dashboard", "orders", "-m", "table", "-f", "json", "--limit", "5"},
+			expected: searchOptions{query: "orders", models: []string{"card", "dashboard", "table"}, format: "json", limit: 5},
+		},
+		{
+			name:        "missing query",
+			args:        []string{"--format", "csv"},
+			expectError: "'search' requires one query, quote it if it has spaces",
+		},
+		{
+			name:        "empty query",
+			args:        []string{""},
+			expectError: "'search' requires one query, quote it if it has spaces",
+		},
+		{
+			name:        "unquoted words",
+			args:        []string{"monthly", "orders"},
+			expectError: "'search' requires one query, quote it if it has spaces",
+		},
+		{
+			name:        "unknown format",
+			args:        []string{"orders", "-f", "xml"},
+			expectError: "unknown format 'xml', use table, json or csv",
+		},
+		{
+			name:        "invalid limit",
+			args:        []string{"orders", "-n", "0"},
+			expectError: "invalid limit '0'",
+		},
+		{
+			name:        "flag without value",
+			args:        []string{"orders", "--model"},
+			expectError: "--model requires a value",
+		},
+		{
+			name:        "unknown flag",
+			args:        []string{"orders", "--archived"},
+			expectError: "unknown flag '--archived'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseSearchArgs(tt.args)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("parseSearchArgs() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSearchArgs() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(opts, tt.expected) {
+				t.Errorf("parseSearchArgs() = %+v, want %+v", opts, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteSearchResults(t *testing.T) {
+	results := []api.SearchResult{
+		{ID: 1, Name: "Orders by month", Model: "card"},
+		{ID: 2, Name: "Sales, weekly", Model: "dashboard"},
+	}
+	results[0].Collection.Name = "Analytics"
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "table",
+			expected: "NAME             TYPE       COLLECTION  URL\n" +
+				"Orders by month  card       Analytics   https://metabase.example.com/question/1\n" +
+				"Sales, weekly    dashboard              https://metabase.example.com/dashboard/2\n",
+		},
+		{
+			format: "csv",
+			expected: "name,type,collection,url\n" +
+				"Orders by month,card,Analytics,https://metabase.example.com/question/1\n" +
+				"\"Sales, weekly\",dashboard,,https://metabase.example.com/dashboard/2\n",
+		},
+		{
+			format: "json",
+			expected: `[
+  {
+    "name": "Orders by month",
+    "type": "card",
+    "collection": "Analytics",
+    "url": "https://metabase.example.com/question/1"
+  },
+  {
+    "name": "Sales, weekly",
+    "type": "dashboard",
+    "collection": "",
+    "url": "https://metabase.example.com/dashboard/2"
+  }
+]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeSearchResults(&out, results, "https://metabase.example.com/", tt.format); err != nil {
+				t.Fatalf("writeSearchResults() error = %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("writeSearchResults(%s) =\n%s\nwant\n%s", tt.format, out.String(), tt.expected)
+			}
+		})
+	}
+}
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,11 +19,47 @@ import (
 type Profile struct {
 	URL   string `yaml:"url"`
 	Token string `yaml:"token"`
+	// Opened at launch instead of the main menu; the database when both
+	// are set
+	DefaultDatabase   int `yaml:"default_database,omitempty"`
+	DefaultCollection int `yaml:"default_collection,omitempty"`
 }
 
 type Config struct {
-	DefaultProfile string             `yaml:"default_profile"`
-	Profiles       map[string]Profile `yaml:"profiles"`
+	DefaultProfile     string             `yaml:"default_profile"`
+	Profiles           map[string]Profile `yaml:"profiles"`
+	ShowTechnicalNames bool               `yaml:"show_technical_names,omitempty"`
+	ReadOnly           bool               `yaml:"read_only,omitempty"`
+	StickySearch       bool               `yaml:"sticky_search,omitempty"`
+	AutoSkipSingle     bool               `yaml:"auto_skip_single,omitempty"`
+	RelativeTime       bool               `yaml:"relative_time,omitempty"`
+	UserAgent          string             `yaml:"user_agent,omitempty"`        // Replaces the default mbx/<version>
+	AuthHeader         string             `yaml:"auth_header,omitempty"`       // How the token is sent: x-api-key (default) or bearer
+	Headers            map[string]string  `yaml:"headers,omitempty"`           // Sent with every request, for API gateways
+	MaxTablesPerDB     int                `yaml:"max_tables_per_db,omitempty"` // Flat table list cap, -1 for none
+	Prefetch           bool               `yaml:"prefetch,omitempty"`          // Load a database's tables while its entry is hovered
+	Proxy              string             `yaml:"proxy,omitempty"`             // Proxy for Metabase and GitHub requests, instead of HTTPS_PROXY
+	ReleaseURL         string             `yaml:"release_url,omitempty"`       // Latest release feed of a fork or mirror; MBX_RELEASE_URL wins over it
+	DiskCache          bool               `yaml:"disk_cache,omitempty"`        // Save the databases and collections lists for the next session
+	CacheTTL           string             `yaml:"cache_ttl,omitempty"`         // How long saved lists are shown, such as 12h; a day by default
+	NoAltScreen        bool               `yaml:"no_altscreen,omitempty"`      // Draw in the main screen, leaving the last view in the scrollback
+	TimeFormat         string             `yaml:"time_format,omitempty"`       // Go layout, or iso, us, eu or relative
+	Timezone           string             `yaml:"timezone,omitempty"`          // Zone dates are shown in, such as Europe/Berlin; the system's by default
+	CollectionSort     string             `yaml:"collection_sort,omitempty"`   // api, name or created; the API order by default
+	PersonalLast       bool               `yaml:"personal_collections_last,omitempty"`
+	HidePersonal       bool               `yaml:"hide_personal_collections,omitempty"` // Toggled with p in the collections list
+	CollectionCounts   bool               `yaml:"collection_counts,omitempty"`         // Show how many items each collection holds, at a request per collection
+	// Update notices are hidden until this time, and never shown for
+	// SkipVersion; both are set from the interface
+	UpdateSnoozedUntil time.Time `yaml:"update_snoozed_until,omitempty"`
+	SkipVersion        string    `yaml:"skip_version,omitempty"`
+	// Keys for the up, down, back, forward, search, web, quit, refresh and
+	// home actions, replacing the defaults of each action listed
+	Keymap map[string][]string `yaml:"keymap,omitempty"`
+
+	// UnknownKeys are keys of the file mbx doesn't know, such as typos or
+	// options of a newer version, with their line. They are ignored.
+	UnknownKeys []string `yaml:"-"`
 }
 
 var globalConfigFile string
@@ -36,6 +80,19 @@ func GetConfigDir() (string, error) {
 	return filepath.Join(configDir, "mbx"), nil
 }
 
+// GetCacheDir returns the directory saved responses are kept in
+func GetCacheDir() (string, error) {
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		cacheDir = filepath.Join(homeDir, ".cache")
+	}
+	return filepath.Join(cacheDir, "mbx"), nil
+}
+
 func GetConfigPath() (string, error) {
 	// 1. CLI flag has highest priority
 	if globalConfigFile != "" {
@@ -77,10 +134,179 @@ func LoadConfig() (*Config, error) {
 	if config.Profiles == nil {
 		config.Profiles = make(map[string]Profile)
 	}
+	config.UnknownKeys = unknownKeys(data)
 
 	return &config, nil
 }
 
+// unknownFieldPattern reads the key and line out of a strict decoding error
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (.+) not found in type`)
+
+// unknownKeys decodes the file again, strictly this time, to list the keys
+// the lenient decoding skipped over
+func unknownKeys(data []byte) []string {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var typeErr *yaml.TypeError
+	if err := decoder.Decode(&Config{}); !errors.As(err, &typeErr) {
+		return nil
+	}
+	var keys []string
+	for _, message := range typeErr.Errors {
+		if match := unknownFieldPattern.FindStringSubmatch(message); match != nil {
+			keys = append(keys, fmt.Sprintf("%s (line %s)", match[2], match[1]))
+		}
+	}
+	return keys
+}
+
+// Warning explains the keys of the file that are ignored, or is empty when
+// every key is known
+func (c *Config) Warning() string {
+	if len(c.UnknownKeys) == 0 {
+		return ""
+	}
+	return "Ignoring unknown keys in the config file: " + strings.Join(c.UnknownKeys, ", ")
+}
+
+// ProxyURL parses the proxy setting, returning nil when none is set so the
+// proxy environment variables apply
+func (c *Config) ProxyURL() (*url.URL, error) {
+	if c.Proxy == "" {
+		return nil, nil
+	}
+	proxy, err := url.Parse(c.Proxy)
+	if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+		return nil, fmt.Errorf("invalid proxy '%s', use a URL such as http://proxy.example.com:3128", c.Proxy)
+	}
+	return proxy, nil
+}
+
+// BearerAuth reports whether auth_header asks for the token to be sent as
+// Authorization: Bearer rather than X-API-Key
+func (c *Config) BearerAuth() (bool, error) {
+	switch strings.ToLower(c.AuthHeader) {
+	case "", "x-api-key":
+		return false, nil
+	case "bearer":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid auth_header '%s', use x-api-key or bearer", c.AuthHeader)
+}
+
+// RequestHeaders returns the headers setting with the ones from --header
+// flags on top, checked so a typo fails at startup rather than on the
+// first request
+func (c *Config) RequestHeaders(flags map[string]string) (map[string]string, error) {
+	headers := make(map[string]string, len(c.Headers)+len(flags))
+	for name, value := range c.Headers {
+		if err := validateHeader(name, value); err != nil {
+			return nil, fmt.Errorf("invalid header in config: %v", err)
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	for name, value := range flags {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	return headers, nil
+}
+
+// ParseHeader reads a --header flag of the form name=value
+func ParseHeader(flag string) (string, string, error) {
+	name, value, ok := strings.Cut(flag, "=")
+	if !ok {
+		return "", "", fmt.Errorf("invalid header '%s', use name=value such as X-Tenant-ID=acme", flag)
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if err := validateHeader(name, value); err != nil {
+		return "", "", err
+	}
+	return name, value, nil
+}
+
+// validateHeader checks that a header name is an HTTP token and that its
+// value holds no line breaks or control characters
+func validateHeader(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("header name is empty")
+	}
+	for _, r := range name {
+		if r > unicode.MaxASCII || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) || r == 0x7f {
+			return fmt.Errorf("header name '%s' has an invalid character %q", name, r)
+		}
+	}
+	for _, r := range value {
+		if (r < ' ' && r != '\t') || r == 0x7f {
+			return fmt.Errorf("value of header '%s' has an invalid character %q", name, r)
+		}
+	}
+	return nil
+}
+
+// DefaultCacheTTL is how long saved lists are shown when cache_ttl isn't set
+const DefaultCacheTTL = 24 * time.Hour
+
+// CacheTTLDuration parses the cache_ttl setting
+func (c *Config) CacheTTLDuration() (time.Duration, error) {
+	if c.CacheTTL == "" {
+		return DefaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(c.CacheTTL)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid cache_ttl '%s', use a duration such as 12h", c.CacheTTL)
+	}
+	return ttl, nil
+}
+
+// DefaultTimeFormat is the layout dates are shown with when time_format
+// isn't set
+const DefaultTimeFormat = "Jan 2, 2006 at 3:04 PM"
+
+// timeFormatPresets are the names time_format takes besides Go layouts
+var timeFormatPresets = map[string]string{
+	"iso": "2006-01-02 15:04",
+	"us":  "01/02/2006 3:04 PM",
+	"eu":  "02/01/2006 15:04",
+}
+
+// TimeLayout parses the time_format setting into the Go layout dates are
+// shown with, and whether they start as relative times such as "3 days
+// ago". A layout without any element of the reference time is rejected, as
+// it would print the same text for every date.
+func (c *Config) TimeLayout() (layout string, relative bool, err error) {
+	switch c.TimeFormat {
+	case "":
+		return DefaultTimeFormat, false, nil
+	case "relative":
+		return DefaultTimeFormat, true, nil
+	}
+	if layout, ok := timeFormatPresets[c.TimeFormat]; ok {
+		return layout, false, nil
+	}
+	someDate := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	otherDate := time.Date(2017, time.August, 19, 9, 38, 27, 0, time.UTC)
+	if someDate.Format(c.TimeFormat) == otherDate.Format(c.TimeFormat) {
+		return "", false, fmt.Errorf("invalid time_format '%s', use iso, us, eu, relative or a Go layout such as 2006-01-02 15:04", c.TimeFormat)
+	}
+	return c.TimeFormat, false, nil
+}
+
+// Location loads the timezone setting, the zone of the system when it isn't
+// set
+func (c *Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s', use a name such as Europe/Berlin or UTC", c.Timezone)
+	}
+	return location, nil
+}
+
 func SaveConfig(config *Config) error {
 	configPath, err := GetConfigPath()
 	if err != nil {
@@ -101,6 +327,166 @@ func SaveConfig(config *Config) error {
 	return os.WriteFile(configPath, data, 0644)
 }
 
+// KeyChange is a key of the config file to set or remove
+type KeyChange struct {
+	Path  []string    // Keys from the top of the file, such as profiles, work, token
+	Value interface{} // Encoded as YAML; nil removes the key
+}
+
+// UpdateKeys changes keys of the config file in place. Unlike SaveConfig it
+// leaves the rest of the file alone, its comments and the keys mbx doesn't
+// know included.
+func UpdateKeys(changes ...KeyChange) error {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	// An empty file, or one with only comments, has no mapping yet
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping of keys", configPath)
+	}
+	for _, change := range changes {
+		if err := setKey(doc.Content[0], change.Path, change.Value); err != nil {
+			return err
+		}
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, out.Bytes(), 0644)
+}
+
+// setKey sets the key at path under mapping, adding the mappings on the way
+// that are missing, or removes it when value is nil. A replaced value keeps
+// the comments of the old one.
+func setKey(mapping *yaml.Node, path []string, value interface{}) error {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != path[0] {
+			continue
+		}
+		node := mapping.Content[i+1]
+		if len(path) > 1 {
+			if node.Kind != yaml.MappingNode {
+				if value == nil {
+					return nil
+				}
+				*node = yaml.Node{Kind: yaml.MappingNode, HeadComment: node.HeadComment, LineComment: node.LineComment}
+			}
+			return setKey(node, path[1:], value)
+		}
+		if value == nil {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return nil
+		}
+		var encoded yaml.Node
+		if err := encoded.Encode(value); err != nil {
+			return err
+		}
+		encoded.HeadComment, encoded.LineComment, encoded.FootComment = node.HeadComment, node.LineComment, node.FootComment
+		*node = encoded
+		return nil
+	}
+
+	if value == nil {
+		return nil
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if len(path) == 1 {
+		if err := node.Encode(value); err != nil {
+			return err
+		}
+	} else if err := setKey(node, path[1:], value); err != nil {
+		return err
+	}
+	mapping.Content = append(mapping.Content, key, node)
+	return nil
+}
+
+// appRoutes are the first path segments of Metabase pages, as opposed to the
+// path an instance itself may be served under
+var appRoutes = map[string]bool{
+	"question": true, "dashboard": true, "collection": true, "model": true,
+	"metric": true, "browse": true, "reference": true, "admin": true,
+	"auth": true, "search": true, "account": true, "archive": true,
+	"trash": true,
+}
+
+// NormalizeURL reduces a pasted Metabase address to the base URL of the
+// instance. A link to a question, dashboard or other page is cut back to the
+// instance, and a missing scheme is taken to be https; the returned note
+// explains such changes so they can be shown to the user.
+func NormalizeURL(raw string) (string, string, error) {
+	raw = strings.TrimSpace(raw)
+	var notes []string
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+		notes = append(notes, "assuming https")
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid URL: %v", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", "", fmt.Errorf("URL must start with http:// or https://")
+	}
+	if parsed.Host == "" {
+		return "", "", fmt.Errorf("URL has no host")
+	}
+
+	// Keep the path up to the first Metabase page, e.g. /metabase when the
+	// instance is served under a sub-path
+	var base []string
+	droppedPage := false
+	for _, segment := range strings.Split(strings.Trim(parsed.Path, "/"), "/") {
+		if appRoutes[segment] {
+			notes = append(notes, "dropped the page path "+parsed.Path)
+			droppedPage = true
+			break
+		}
+		if segment != "" {
+			base = append(base, segment)
+		}
+	}
+	if !droppedPage && (parsed.RawQuery != "" || parsed.Fragment != "") {
+		notes = append(notes, "dropped the query string")
+	}
+
+	normalized := parsed.Scheme + "://" + parsed.Host
+	if len(base) > 0 {
+		normalized += "/" + strings.Join(base, "/")
+	}
+
+	note := ""
+	if len(notes) > 0 {
+		note = fmt.Sprintf("Using %s (%s)", normalized, strings.Join(notes, ", "))
+	}
+	return normalized, note, nil
+}
+
 func ResolveConfiguration(flagURL, flagToken, flagProfile string) (string, string, error) {
 	var metabaseURL, apiToken string
 
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestProfile(t *testing.T) {
@@ -119,6 +121,178 @@ func TestLoadConfig_NonexistentFile(t *testing.T) {
 	}
 }
 
+func TestConfig_BearerAuth(t *testing.T) {
+	for header, want := range map[string]bool{"": false, "x-api-key": false, "X-API-Key": false, "bearer": true} {
+		if got, err := (&Config{AuthHeader: header}).BearerAuth(); err != nil || got != want {
+			t.Errorf("BearerAuth() with %q = %v, %v, want %v", header, got, err, want)
+		}
+	}
+	if _, err := (&Config{AuthHeader: "basic"}).BearerAuth(); err == nil {
+		t.Error("BearerAuth() with basic, want an error")
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		flag        string
+		name, value string
+		expectError bool
+	}{
+		{flag: "X-Tenant-ID=acme", name: "X-Tenant-ID", value: "acme"},
+		{flag: " X-CDN-Auth = a=b ", name: "X-CDN-Auth", value: "a=b"},
+		{flag: "X-Empty=", name: "X-Empty"},
+		{flag: "X-Tenant-ID", expectError: true},
+		{flag: "=acme", expectError: true},
+		{flag: "X Tenant=acme", expectError: true},
+		{flag: "X-Tenant:ID=acme", expectError: true},
+		{flag: "X-Tenant-ID=acme\r\nX-Admin: 1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			name, value, err := ParseHeader(tt.flag)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseHeader() = %q, %q, want an error", name, value)
+				}
+				return
+			}
+			if err != nil || name != tt.name || value != tt.value {
+				t.Errorf("ParseHeader() = %q, %q, %v, want %q, %q", name, value, err, tt.name, tt.value)
+			}
+		})
+	}
+}
+
+func TestConfig_RequestHeaders(t *testing.T) {
+	cfg := &Config{Headers: map[string]string{"x-tenant-id": "acme", "X-CDN-Auth": "secret"}}
+	headers, err := cfg.RequestHeaders(map[string]string{"X-Tenant-ID": "globex"})
+	if err != nil {
+		t.Fatalf("RequestHeaders() unexpected error = %v", err)
+	}
+	want := map[string]string{"X-Tenant-Id": "globex", "X-Cdn-Auth": "secret"}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("RequestHeaders() = %v, want %v with the flag winning", headers, want)
+	}
+
+	if headers, err := (&Config{}).RequestHeaders(nil); err != nil || headers != nil {
+		t.Errorf("RequestHeaders() without headers = %v, %v, want none", headers, err)
+	}
+	if _, err := (&Config{Headers: map[string]string{"X-Bad": "a\nb"}}).RequestHeaders(nil); err == nil {
+		t.Error("RequestHeaders() with a line break in a value, want an error")
+	}
+}
+
+func TestConfig_ProxyURL(t *testing.T) {
+	tests := []struct {
+		proxy       string
+		expected    string
+		expectError bool
+	}{
+		{proxy: "", expected: ""},
+		{proxy: "http://proxy.example.com:3128", expected: "http://proxy.example.com:3128"},
+		{proxy: "socks5://localhost:1080", expected: "socks5://localhost:1080"},
+		{proxy: "proxy.example.com:3128", expectError: true},
+		{proxy: "http://", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.proxy, func(t *testing.T) {
+			proxy, err := (&Config{Proxy: tt.proxy}).ProxyURL()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ProxyURL() = %v, want an error", proxy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProxyURL() unexpected error = %v", err)
+			}
+			got := ""
+			if proxy != nil {
+				got = proxy.String()
+			}
+			if got != tt.expected {
+				t.Errorf("ProxyURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_CacheTTLDuration(t *testing.T) {
+	tests := []struct {
+		ttl         string
+		expected    time.Duration
+		expectError bool
+	}{
+		{ttl: "", expected: DefaultCacheTTL},
+		{ttl: "12h", expected: 12 * time.Hour},
+		{ttl: "90m", expected: 90 * time.Minute},
+		{ttl: "a day", expectError: true},
+		{ttl: "-1h", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ttl, func(t *testing.T) {
+			ttl, err := (&Config{CacheTTL: tt.ttl}).CacheTTLDuration()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("CacheTTLDuration() = %v, want an error", ttl)
+				}
+				return
+			}
+			if err != nil || ttl != tt.expected {
+				t.Errorf("CacheTTLDuration() = %v, %v, want %v", ttl, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_TimeLayout(t *testing.T) {
+	tests := []struct {
+		format      string
+		expected    string
+		relative    bool
+		expectError bool
+	}{
+		{format: "", expected: "Jan 2, 2006 at 3:04 PM"},
+		{format: "iso", expected: "2006-01-02 15:04"},
+		{format: "us", expected: "01/02/2006 3:04 PM"},
+		{format: "eu", expected: "02/01/2006 15:04"},
+		{format: "relative", expected: "Jan 2, 2006 at 3:04 PM", relative: true},
+		{format: "Monday 2 January 2006", expected: "Monday 2 January 2006"},
+		{format: "YYYY-MM-DD", expectError: true},
+		{format: "ISO", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			layout, relative, err := (&Config{TimeFormat: tt.format}).TimeLayout()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("TimeLayout() = %q, want an error", layout)
+				}
+				return
+			}
+			if err != nil || layout != tt.expected || relative != tt.relative {
+				t.Errorf("TimeLayout() = %q, %v, %v, want %q, %v", layout, relative, err, tt.expected, tt.relative)
+			}
+		})
+	}
+}
+
+func TestConfig_Location(t *testing.T) {
+	if location, err := (&Config{}).Location(); err != nil || location != time.Local {
+		t.Errorf("Location() = %v, %v, want the system's zone", location, err)
+	}
+	if location, err := (&Config{Timezone: "America/New_York"}).Location(); err != nil || location.String() != "America/New_York" {
+		t.Errorf("Location() = %v, %v, want America/New_York", location, err)
+	}
+	if location, err := (&Config{Timezone: "Mars/Olympus"}).Location(); err == nil {
+		t.Errorf("Location() = %v, want an error", location)
+	}
+}
+
 func TestSaveAndLoadConfig(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "mbx-config-test")
 	if err != nil {
@@ -177,6 +351,78 @@ func TestSaveAndLoadConfig(t *testing.T) {
 	}
 }
 
+func TestUpdateKeys(t *testing.T) {
+	original := `# Work first
+default_profile: work
+profiles:
+  work:
+    url: https://work.metabase.com
+    token: old-token # rotated monthly
+future_option: true
+skip_version: v1.1.0
+`
+	tests := []struct {
+		name     string
+		file     string
+		changes  []KeyChange
+		expected string
+	}{
+		{
+			name:    "changed keys only",
+			file:    original,
+			changes: []KeyChange{{Path: []string{"profiles", "work", "token"}, Value: "new-token"}, {Path: []string{"skip_version"}, Value: "v1.2.0"}},
+			expected: `# Work first
+default_profile: work
+profiles:
+  work:
+    url: https://work.metabase.com
+    token: new-token # rotated monthly
+future_option: true
+skip_version: v1.2.0
+`,
+		},
+		{
+			name:    "added and removed",
+			file:    original,
+			changes: []KeyChange{{Path: []string{"skip_version"}}, {Path: []string{"update_snoozed_until"}, Value: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}},
+			expected: `# Work first
+default_profile: work
+profiles:
+  work:
+    url: https://work.metabase.com
+    token: old-token # rotated monthly
+future_option: true
+update_snoozed_until: 2026-01-02T00:00:00Z
+`,
+		},
+		{
+			name:     "no file yet",
+			changes:  []KeyChange{{Path: []string{"profiles", "work", "token"}, Value: "new-token"}},
+			expected: "profiles:\n  work:\n    token: new-token\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			SetGlobalConfigFile(configPath)
+			defer SetGlobalConfigFile("")
+			if tt.file != "" {
+				if err := os.WriteFile(configPath, []byte(tt.file), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := UpdateKeys(tt.changes...); err != nil {
+				t.Fatalf("UpdateKeys() error = %v", err)
+			}
+			if data, _ := os.ReadFile(configPath); string(data) != tt.expected {
+				t.Errorf("UpdateKeys() wrote:\n%s\nwant:\n%s", data, tt.expected)
+			}
+		})
+	}
+}
+
 func TestResolveConfiguration(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -280,3 +526,80 @@ func TestResolveConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    string
+		expectNote  bool
+		expectError bool
+	}{
+		{name: "base URL", raw: "https://acme.metabaseapp.com", expected: "https://acme.metabaseapp.com"},
+		{name: "trailing slash", raw: "https://acme.metabaseapp.com/ ", expected: "https://acme.metabaseapp.com"},
+		{name: "local instance", raw: "http://localhost:3000", expected: "http://localhost:3000"},
+		{name: "question link", raw: "https://acme.metabaseapp.com/question/42-orders-by-month", expected: "https://acme.metabaseapp.com", expectNote: true},
+		{name: "dashboard link with tab", raw: "https://acme.metabaseapp.com/dashboard/7-revenue?tab=3-overview", expected: "https://acme.metabaseapp.com", expectNote: true},
+		{name: "collection link", raw: "https://acme.metabaseapp.com/collection/root", expected: "https://acme.metabaseapp.com", expectNote: true},
+		{name: "served under a sub-path", raw: "https://tools.example.com/metabase/question/1", expected: "https://tools.example.com/metabase", expectNote: true},
+		{name: "sub-path base", raw: "https://tools.example.com/metabase/", expected: "https://tools.example.com/metabase"},
+		{name: "no scheme", raw: "acme.metabaseapp.com", expected: "https://acme.metabaseapp.com", expectNote: true},
+		{name: "query string", raw: "https://acme.metabaseapp.com/?utm_source=email", expected: "https://acme.metabaseapp.com", expectNote: true},
+		{name: "other scheme", raw: "ftp://acme.metabaseapp.com", expectError: true},
+		{name: "no host", raw: "https://", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, note, err := NormalizeURL(tt.raw)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("NormalizeURL(%q) = %q, want an error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeURL(%q) unexpected error = %v", tt.raw, err)
+			}
+			if got != tt.expected {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.raw, got, tt.expected)
+			}
+			if (note != "") != tt.expectNote {
+				t.Errorf("NormalizeURL(%q) note = %q, want a note: %v", tt.raw, note, tt.expectNote)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_UnknownKeys(t *testing.T) {
+	originalGlobal := globalConfigFile
+	defer func() { globalConfigFile = originalGlobal }()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "default_profile: work\nprofiles:\n  work:\n    url: https://metabase.example.com\n    tokenn: abc\nprefetch: true\ntheme: dark\n"
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	SetGlobalConfigFile(configPath)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want unknown keys to be ignored", err)
+	}
+	if !cfg.Prefetch || cfg.Profiles["work"].URL != "https://metabase.example.com" {
+		t.Errorf("LoadConfig() = %+v, want the known keys read", cfg)
+	}
+	if want := []string{"tokenn (line 5)", "theme (line 7)"}; !reflect.DeepEqual(cfg.UnknownKeys, want) {
+		t.Errorf("UnknownKeys = %v, want %v", cfg.UnknownKeys, want)
+	}
+	if want := "Ignoring unknown keys in the config file: tokenn (line 5), theme (line 7)"; cfg.Warning() != want {
+		t.Errorf("Warning() = %q, want %q", cfg.Warning(), want)
+	}
+
+	if err := os.WriteFile(configPath, []byte("default_profile: work\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := LoadConfig(); err != nil || cfg.UnknownKeys != nil || cfg.Warning() != "" {
+		t.Errorf("LoadConfig() of known keys: unknown = %v, warning = %q, err = %v", cfg.UnknownKeys, cfg.Warning(), err)
+	}
+}
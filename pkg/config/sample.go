@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// sampleKey documents a key of the config file, with YAML that sets it
+type sampleKey struct {
+	doc      string
+	example  string
+	required bool // Set in the sample rather than commented out
+}
+
+// profileSample documents the keys of a profile
+var profileSample = map[string]sampleKey{
+	"url":                {doc: "Address of the Metabase instance", example: "url: https://metabase.example.com", required: true},
+	"token":              {doc: "API key, created under Admin settings > Authentication > API keys", example: "token: mb_your-api-key", required: true},
+	"default_database":   {doc: "Open this database at launch instead of the main menu", example: "default_database: 1"},
+	"default_collection": {doc: "Open this collection at launch, unless a default database is set", example: "default_collection: 5"},
+}
+
+// configSample documents the keys of the config file besides the profiles.
+// Every key of Config needs an entry, which the tests check.
+var configSample = map[string]sampleKey{
+	"show_technical_names":      {doc: "Show table and field names as stored in the database", example: "show_technical_names: true"},
+	"read_only":                 {doc: "Never modify anything in Metabase, as with --read-only", example: "read_only: true"},
+	"sticky_search":             {doc: "Restore a list's search when going back to it", example: "sticky_search: true"},
+	"auto_skip_single":          {doc: "Open the only table or collection item instead of listing it", example: "auto_skip_single: true"},
+	"relative_time":             {doc: `Show dates as relative times such as "3 days ago"`, example: "relative_time: true"},
+	"user_agent":                {doc: "Replaces the default User-Agent, mbx/<version>", example: "user_agent: mbx-acme"},
+	"auth_header":               {doc: "How the token is sent: x-api-key (default) or bearer", example: "auth_header: bearer"},
+	"headers":                   {doc: "Sent with every request, for API gateways; --header adds more", example: "headers:\n  X-Tenant-ID: acme"},
+	"max_tables_per_db":         {doc: "Tables listed at most in the flat table list, -1 for no limit", example: "max_tables_per_db: 500"},
+	"prefetch":                  {doc: "Load a database's tables while its entry is hovered", example: "prefetch: true"},
+	"proxy":                     {doc: "Proxy for Metabase and GitHub requests, instead of HTTPS_PROXY", example: "proxy: http://proxy.example.com:3128"},
+	"release_url":               {doc: "Latest release feed of a fork or mirror; MBX_RELEASE_URL wins over it", example: "release_url: https://api.github.com/repos/acme/metabase-explorer/releases/latest"},
+	"disk_cache":                {doc: "Save the databases and collections lists for the next session", example: "disk_cache: true"},
+	"cache_ttl":                 {doc: "How long saved lists are shown; a day by default", example: "cache_ttl: 12h"},
+	"no_altscreen":              {doc: "Draw in the main screen, leaving the last view in the scrollback", example: "no_altscreen: true"},
+	"time_format":               {doc: "Go layout, or iso, us, eu or relative", example: "time_format: iso"},
+	"timezone":                  {doc: "Zone dates are shown in; the system's by default", example: "timezone: Europe/Berlin"},
+	"collection_sort":           {doc: "Order of the collections list: api (default), name or created", example: "collection_sort: name"},
+	"personal_collections_last": {doc: "List personal collections after the others", example: "personal_collections_last: true"},
+	"hide_personal_collections": {doc: "Start with personal collections hidden, toggled with p", example: "hide_personal_collections: true"},
+	"collection_counts":         {doc: "Show how many items each collection holds, at a request per collection", example: "collection_counts: true"},
+	"update_snoozed_until":      {doc: "Update notices are hidden until then; set from the interface", example: "update_snoozed_until: 2025-01-31T00:00:00Z"},
+	"skip_version":              {doc: "Release whose update notice is never shown; set from the interface", example: "skip_version: v1.2.0"},
+	"keymap":                    {doc: "Keys of the up, down, back, forward, search, web, quit, refresh and home actions,\nreplacing the defaults of each action listed", example: "keymap:\n  up: [up, k]\n  down: [down, j]"},
+}
+
+// Sample is an example config file documenting every key, with a profile
+// to fill in and every option commented out
+func Sample() string {
+	return sample(false)
+}
+
+// sample writes the example config, with the options set rather than
+// commented out when active, so tests can check them
+func sample(active bool) string {
+	var out strings.Builder
+	out.WriteString("# mbx configuration, read from ~/.config/mbx/config.yaml or --config <path>\n\n")
+
+	for _, key := range yamlKeys(reflect.TypeOf(Config{})) {
+		switch key {
+		case "default_profile":
+			out.WriteString("# Profile used when --profile isn't given\n")
+			out.WriteString("default_profile: default\n\n")
+		case "profiles":
+			out.WriteString("# Metabase instances, by name\n")
+			out.WriteString("profiles:\n  default:\n")
+			for _, profileKey := range yamlKeys(reflect.TypeOf(Profile{})) {
+				documented := profileSample[profileKey]
+				writeSampleKey(&out, documented, "    ", active || documented.required)
+			}
+			out.WriteString("\n")
+		default:
+			documented, ok := configSample[key]
+			if !ok {
+				documented = sampleKey{example: key + ":"}
+			}
+			writeSampleKey(&out, documented, "", active)
+			out.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func writeSampleKey(out *strings.Builder, key sampleKey, indent string, active bool) {
+	for _, line := range strings.Split(key.doc, "\n") {
+		if line != "" {
+			fmt.Fprintf(out, "%s# %s\n", indent, line)
+		}
+	}
+	for _, line := range strings.Split(key.example, "\n") {
+		if active {
+			fmt.Fprintf(out, "%s%s\n", indent, line)
+		} else {
+			fmt.Fprintf(out, "%s# %s\n", indent, line)
+		}
+	}
+}
+
+// yamlKeys lists the YAML keys of a struct's fields in declaration order
+func yamlKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
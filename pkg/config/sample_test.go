@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSample_DocumentsEveryKey(t *testing.T) {
+	for _, key := range yamlKeys(reflect.TypeOf(Config{})) {
+		if _, ok := configSample[key]; !ok && key != "default_profile" && key != "profiles" {
+			t.Errorf("%s is missing from the sample config", key)
+		}
+	}
+	for _, key := range yamlKeys(reflect.TypeOf(Profile{})) {
+		if _, ok := profileSample[key]; !ok {
+			t.Errorf("profile key %s is missing from the sample config", key)
+		}
+	}
+}
+
+func TestSample_Loads(t *testing.T) {
+	originalGlobal := globalConfigFile
+	defer func() { globalConfigFile = originalGlobal }()
+
+	for _, active := range []bool{false, true} {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configPath, []byte(sample(active)), 0600); err != nil {
+			t.Fatal(err)
+		}
+		SetGlobalConfigFile(configPath)
+
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig() of the sample with options set %v: %v", active, err)
+		}
+		if cfg.DefaultProfile != "default" || cfg.Profiles["default"].URL != "https://metabase.example.com" {
+			t.Errorf("sample profile = %q %+v", cfg.DefaultProfile, cfg.Profiles)
+		}
+		if !active {
+			if cfg.Prefetch || cfg.Proxy != "" || cfg.Keymap != nil || cfg.Profiles["default"].DefaultDatabase != 0 {
+				t.Errorf("commented sample set options: %+v", cfg)
+			}
+			continue
+		}
+
+		if !cfg.Prefetch || cfg.Headers["X-Tenant-ID"] != "acme" || len(cfg.Keymap["up"]) != 2 || cfg.Profiles["default"].DefaultDatabase != 1 {
+			t.Errorf("options were not all read: %+v", cfg)
+		}
+		if _, err := cfg.BearerAuth(); err != nil {
+			t.Error(err)
+		}
+		if _, err := cfg.RequestHeaders(nil); err != nil {
+			t.Error(err)
+		}
+		if _, err := cfg.ProxyURL(); err != nil {
+			t.Error(err)
+		}
+		if _, err := cfg.CacheTTLDuration(); err != nil {
+			t.Error(err)
+		}
+		if _, _, err := cfg.TimeLayout(); err != nil {
+			t.Error(err)
+		}
+		if _, err := cfg.Location(); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,534 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Actions shared by key bindings and the command palette
+
+func (m Model) openCollections() (Model, tea.Cmd) {
+	m.currentView = viewCollections
+	m.cursor = 0
+	m.error = ""
+	if cmd := m.showCachedCollections(); cmd != nil {
+		return m, cmd
+	}
+	m.loading = true
+	return m, withSpinner(m.loadRootCollections())
+}
+
+func (m Model) openDatabases() (Model, tea.Cmd) {
+	m.currentView = viewDatabases
+	m.cursor = 0
+	m.error = ""
+	if cmd := m.showCachedDatabases(); cmd != nil {
+		return m, cmd
+	}
+	m.loading = true
+	return m, withSpinner(loadDatabases(m.client))
+}
+
+func (m Model) openMainMenu() (Model, tea.Cmd) {
+	m.currentView = viewMainMenu
+	m.cursor = 0
+	m.error = ""
+	m.dropRefresh()
+	m.selectedDatabase = nil
+	m.selectedCollection = nil
+	m.collectionStack = nil
+	m.databases = nil
+	m.collections = nil
+	return m, nil
+}
+
+func (m Model) openWebURL() (Model, tea.Cmd) {
+	if err := util.OpenInBrowser(m.getWebURL()); err != nil {
+		m.error = fmt.Sprintf("Failed to open browser: %v", err)
+	}
+	return m, nil
+}
+
+func (m Model) copyWebURL() (Model, tea.Cmd) {
+	webURL := m.getWebURL()
+	if err := util.CopyToClipboard(webURL); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to copy URL: %v", err)
+	} else {
+		m.statusMessage = "Copied " + webURL
+	}
+	return m, nil
+}
+
+// copyLocation copies the path of the current view, as shown in the header,
+// for pasting into a chat or a document
+func (m Model) copyLocation() (Model, tea.Cmd) {
+	path := m.location()
+	if m.currentView == viewMainMenu || path == "" {
+		return m, nil
+	}
+	if err := util.CopyToClipboard(path); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to copy path: %v", err)
+	} else {
+		m.statusMessage = "Copied " + path
+	}
+	return m, nil
+}
+
+func (m Model) toggleArchived() (Model, tea.Cmd) {
+	m.showArchived = !m.showArchived
+	m.dropRefresh()
+	m.cursor = 0
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(m.loadRootCollections())
+}
+
+func (m Model) syncSchema() (Model, tea.Cmd) {
+	if m.selectedDatabase == nil {
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("Requesting schema sync for %s...", m.selectedDatabase.Name)
+	return m, syncDatabaseSchema(m.client, m.selectedDatabase.ID)
+}
+
+// toggleFlattenTables switches between drilling through schemas and one flat
+// list of every table in the database, reloading the current database
+func (m Model) toggleFlattenTables() (Model, tea.Cmd) {
+	m.flattenTables = !m.flattenTables
+	if m.selectedDatabase == nil || (m.currentView != viewSchemas && m.currentView != viewTables) {
+		return m, nil
+	}
+
+	m.cursor = 0
+	m.loading = true
+	m.error = ""
+	m.selectedSchema = nil
+	m.tables = nil
+	if m.flattenTables {
+		m.currentView = viewTables
+		return m, withSpinner(loadAllTables(m.client, m.selectedDatabase.ID, m.tableLimit()))
+	}
+	m.schemas = nil
+	m.currentView = viewSchemas
+	return m, withSpinner(loadSchemas(m.client, m.selectedDatabase.ID))
+}
+
+// tableLimit is how many tables the flat list loads, 0 for all of them
+func (m Model) tableLimit() int {
+	if m.showAllTables || m.maxTablesPerDB < 0 {
+		return 0
+	}
+	return m.maxTablesPerDB
+}
+
+// tablesCapped reports whether the flat list leaves out tables of the
+// database because of max_tables_per_db
+func (m Model) tablesCapped() bool {
+	return m.currentView == viewTables && m.tablesTotal > len(m.tables)
+}
+
+// loadRemainingTables shows every table of the database in the capped flat
+// list, from the tables fetched with it when they were kept, else loading
+// them again. The tables shown come first either way, so the cursor stays.
+func (m Model) loadRemainingTables() (Model, tea.Cmd) {
+	if !m.tablesCapped() || m.selectedDatabase == nil {
+		return m, nil
+	}
+	m.showAllTables = true
+	if len(m.tables)+len(m.tablesRest) == m.tablesTotal {
+		m.tables = slices.Concat(m.tables, m.tablesRest)
+		m.tablesRest = nil
+		m.tablesTotal = 0
+		return m, nil
+	}
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(loadAllTables(m.client, m.selectedDatabase.ID, m.tableLimit()))
+}
+
+// openRelated lists the saved questions and dashboards that use the selected
+// table, or the open table in the fields view
+func (m Model) openRelated() (Model, tea.Cmd) {
+	var table *api.Table
+	if m.currentView == viewTables && m.cursor < len(m.tables) {
+		table = &m.tables[m.cursor]
+	} else if m.currentView == viewFields {
+		table = m.selectedTable
+	}
+	if table == nil {
+		return m, nil
+	}
+
+	m.relatedTable = table
+	m.relatedFrom = m.currentView
+	m.relatedCursor = m.cursor
+	m.relatedItems = nil
+	m.currentView = viewRelated
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.cursor = 0
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(loadTableRelated(m.client, table.ID))
+}
+
+// toggleFieldSort switches the fields list between column order and name
+// order, keeping the selected field under the cursor
+func (m Model) toggleFieldSort() (Model, tea.Cmd) {
+	if m.fieldSort == fieldSortPosition {
+		m.fieldSort = fieldSortName
+	} else {
+		m.fieldSort = fieldSortPosition
+	}
+	if len(m.fields) == 0 {
+		return m, nil
+	}
+
+	selected := m.fields[m.cursor].ID
+	m.fields = sortFields(m.fields, m.fieldSort)
+	for i, field := range m.fields {
+		if field.ID == selected {
+			m.cursor = i
+		}
+	}
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.statusMessage = "Fields sorted by " + m.fieldSort.String()
+	return m, nil
+}
+
+// refresh reloads the data behind the current view
+func (m Model) refresh() (Model, tea.Cmd) {
+	// Prefetched tables would bring back what the refresh replaced
+	m.prefetched = nil
+	if m.showingCached() {
+		m.dropRefresh()
+	}
+	var cmd tea.Cmd
+	switch m.currentView {
+	case viewDatabases:
+		cmd = loadDatabases(m.client)
+	case viewCollections:
+		cmd = m.loadRootCollections()
+	case viewCollectionItems:
+		cmd = loadCollectionItems(m.client, m.selectedCollection.ID)
+	case viewSchemas:
+		cmd = loadSchemas(m.client, m.selectedDatabase.ID)
+	case viewTables:
+		if m.selectedSchema == nil {
+			cmd = loadAllTables(m.client, m.selectedDatabase.ID, m.tableLimit())
+		} else {
+			cmd = loadTablesForSchema(m.client, m.selectedDatabase.ID, m.selectedSchema.Name)
+		}
+	case viewFields:
+		cmd = loadFields(m.client, m.selectedTable.ID)
+	case viewRelated:
+		cmd = loadTableRelated(m.client, m.relatedTable.ID)
+	case viewFieldSearch:
+		cmd = loadFieldIndex(m.client, m.selectedDatabase.ID)
+	case viewSearch:
+		if m.myContent {
+			return m.openMyContent()
+		}
+		if m.recentChanges {
+			return m.openRecentChanges()
+		}
+		return m.startGlobalSearch(m.globalQuery)
+	case viewItemDetail:
+		switch m.selectedItem.Model {
+		case "card", "dataset":
+			cmd = loadCardDetail(m.client, m.selectedItem.ID)
+		case "dashboard":
+			cmd = loadDashboardDetail(m.client, m.selectedItem.ID)
+		case "metric":
+			cmd = loadMetricDetail(m.client, m.selectedItem.ID)
+		}
+	case viewQueryResults:
+		return m.startQuery(m.queryParams)
+	}
+	if cmd == nil {
+		return m, nil
+	}
+
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(cmd)
+}
+
+// retry issues the load that failed again
+func (m Model) retry() (Model, tea.Cmd) {
+	// A query is re-run with a fresh context so it can still be cancelled
+	if m.currentView == viewQueryResults {
+		return m.startQuery(m.queryParams)
+	}
+	if m.lastLoad == nil {
+		return m, nil
+	}
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(m.lastLoad)
+}
+
+// visibleNames returns the names in the current list, limited to the search
+// results when a search is active. Tables are schema-qualified and fields use
+// their raw column names so they can be pasted straight into SQL.
+func (m Model) visibleNames() []string {
+	var names []string
+	switch m.currentView {
+	case viewMainMenu:
+		// Menu options aren't worth copying, even when searched
+		return nil
+	case viewDatabases:
+		for _, db := range m.databases {
+			names = append(names, db.Name)
+		}
+	case viewCollections:
+		for _, collection := range m.collections {
+			names = append(names, collection.Name)
+		}
+	case viewCollectionItems:
+		for _, item := range m.collectionItems {
+			names = append(names, item.Name)
+		}
+	case viewSchemas:
+		for _, schema := range m.schemas {
+			names = append(names, schema.Name)
+		}
+	case viewTables:
+		for _, table := range m.tables {
+			names = append(names, tableSchemaName(table)+"."+table.Name)
+		}
+	case viewFields:
+		for _, field := range m.fields {
+			names = append(names, field.Name)
+		}
+	case viewRelated:
+		for _, item := range m.relatedItems {
+			names = append(names, item.Name)
+		}
+	case viewSearch:
+		for _, result := range m.searchResults {
+			names = append(names, result.Name)
+		}
+	case viewFieldSearch:
+		for _, hit := range m.fieldIndex {
+			names = append(names, hit.path())
+		}
+	}
+
+	// Same rule as the list rendering: no matches shows everything
+	if m.searchMode && m.searchQuery != "" && len(m.filteredIndices) > 0 {
+		filtered := make([]string, 0, len(m.filteredIndices))
+		for _, index := range m.filteredIndices {
+			filtered = append(filtered, names[index])
+		}
+		return filtered
+	}
+	return names
+}
+
+// copyNames copies every visible name in the current list, one per line
+func (m Model) copyNames() (Model, tea.Cmd) {
+	names := m.visibleNames()
+	if len(names) == 0 {
+		return m, nil
+	}
+	if err := util.CopyToClipboard(strings.Join(names, "\n")); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to copy names: %v", err)
+	} else {
+		m.statusMessage = fmt.Sprintf("Copied %d names", len(names))
+	}
+	return m, nil
+}
+
+// connectionDetails are the connection settings worth sharing, in the order
+// they are listed. Secrets are left out even though Metabase masks them.
+var connectionDetails = []struct{ key, label string }{
+	{"host", "Host"},
+	{"port", "Port"},
+	{"dbname", "Database"},
+	{"db", "Database"},
+	{"project-id", "Project"},
+	{"user", "User"},
+	{"ssl", "SSL"},
+}
+
+// connectionSummary formats a database's connection metadata as text to
+// paste into a support ticket, listing only what Metabase returned
+func connectionSummary(db api.Database) string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Name: %s\n", db.Name)
+	if db.Engine != "" {
+		fmt.Fprintf(&summary, "Engine: %s\n", db.Engine)
+	}
+	fmt.Fprintf(&summary, "ID: %d\n", db.ID)
+	for _, detail := range connectionDetails {
+		value, ok := db.Details[detail.key]
+		if !ok || value == nil || value == "" {
+			continue
+		}
+		fmt.Fprintf(&summary, "%s: %v\n", detail.label, value)
+	}
+	return strings.TrimSuffix(summary.String(), "\n")
+}
+
+// databaseInView returns the highlighted database in the databases list, or
+// the database being browsed
+func (m Model) databaseInView() *api.Database {
+	switch m.currentView {
+	case viewDatabases:
+		if m.cursor < len(m.databases) {
+			return &m.databases[m.cursor]
+		}
+	case viewSchemas, viewTables, viewFields, viewFieldDetail, viewRelated, viewFieldSearch:
+		return m.selectedDatabase
+	}
+	return nil
+}
+
+// copyConnection copies the connection summary of the selected database
+func (m Model) copyConnection() (Model, tea.Cmd) {
+	db := m.databaseInView()
+	if db == nil {
+		return m, nil
+	}
+	if err := util.CopyToClipboard(connectionSummary(*db)); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to copy connection details: %v", err)
+	} else {
+		m.statusMessage = "Copied connection details of " + db.Name
+	}
+	return m, nil
+}
+
+// tableDDL builds a CREATE TABLE statement from a table's fields for docs and
+// prompts. Types are the database's own names as Metabase reports them, so
+// the result is close to, but not guaranteed to be, valid for the engine.
+func tableDDL(table api.Table, fields []api.Field) string {
+	name := sqlIdentifier(table.Name)
+	if table.Schema != "" {
+		name = sqlIdentifier(table.Schema) + "." + name
+	}
+
+	var columns []string
+	for _, field := range fields {
+		if !field.Active {
+			continue
+		}
+		columnType := field.DatabaseType
+		if columnType == "" {
+			// Fall back to Metabase's own type, e.g. "type/Text" becomes "Text"
+			columnType = strings.TrimPrefix(field.BaseType, "type/")
+		}
+		columns = append(columns, strings.TrimSpace(sqlIdentifier(field.Name)+" "+columnType))
+	}
+	if len(columns) == 0 {
+		return fmt.Sprintf("CREATE TABLE %s ();", name)
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n  %s\n);", name, strings.Join(columns, ",\n  "))
+}
+
+// sqlIdentifier quotes a name unless it is a plain identifier
+func sqlIdentifier(name string) string {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+		}
+	}
+	return name
+}
+
+// copyTableDDL copies the open table's CREATE TABLE summary
+func (m Model) copyTableDDL() (Model, tea.Cmd) {
+	if m.currentView != viewFields || m.selectedTable == nil || len(m.fields) == 0 {
+		return m, nil
+	}
+	if err := util.CopyToClipboard(tableDDL(*m.selectedTable, m.fields)); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to copy DDL: %v", err)
+	} else {
+		m.statusMessage = fmt.Sprintf("Copied CREATE TABLE for %s", m.selectedTable.Name)
+	}
+	return m, nil
+}
+
+// canRunQuery reports whether a saved question or model is open that can be
+// run
+func (m Model) canRunQuery() bool {
+	return m.selectedItem != nil && (m.selectedItem.Model == "card" || m.selectedItem.Model == "dataset") &&
+		(m.currentView == viewItemDetail || m.currentView == viewQueryResults)
+}
+
+// runQuery runs the open saved question, asking for its parameters first
+// when it has any
+func (m Model) runQuery() (Model, tea.Cmd) {
+	if !m.canRunQuery() {
+		return m, nil
+	}
+	if len(m.cardParameters()) > 0 {
+		return m.openParamForm()
+	}
+	return m.startQuery(nil)
+}
+
+// startQuery runs the open saved question with the given parameters and
+// shows its results. Navigating back while it runs cancels the query.
+func (m Model) startQuery(params []api.ParameterValue) (Model, tea.Cmd) {
+	if m.queryCancel != nil {
+		m.queryCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.queryCancel = cancel
+	m.queryParams = params
+	m.currentView = viewQueryResults
+	m.queryColumns = nil
+	m.queryRows = nil
+	m.cursor = 0
+	m.loading = true
+	m.error = ""
+	return m, tea.Batch(runCard(ctx, m.client, m.selectedItem.ID, params), delaySpinner())
+}
+
+// queryPageSize is how many result rows fit on screen
+func (m Model) queryPageSize() int {
+	return max(m.viewportHeight-2, 5) // Column header and separator
+}
+
+// exportQuery downloads the shown results through Metabase's export endpoint
+// into a file in the working directory, named after the question
+func (m Model) exportQuery(format string) (Model, tea.Cmd) {
+	if m.currentView != viewQueryResults || m.loading || m.selectedItem == nil {
+		return m, nil
+	}
+	filename := exportFileName(m.selectedItem.Name, format)
+	m.statusMessage = fmt.Sprintf("Exporting to %s...", filename)
+	return m, exportResults(m.client, m.selectedItem.ID, format, filename, m.queryParams)
+}
+
+// exportFileName turns a question name into a safe file name,
+// e.g. "Orders by month" becomes "orders-by-month.csv"
+func exportFileName(name, format string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteRune('-')
+			dash = true
+		}
+	}
+	base := strings.TrimSuffix(slug.String(), "-")
+	if base == "" {
+		base = "results"
+	}
+	return base + "." + format
+}
@@ -0,0 +1,315 @@
+package tui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestVisibleNames(t *testing.T) {
+	tables := []api.Table{
+		{ID: 1, Name: "users", Schema: "public"},
+		{ID: 2, Name: "orders", Schema: "public"},
+		{ID: 3, Name: "order_events", Schema: "analytics"},
+	}
+	fields := []api.Field{
+		{ID: 1, Name: "created_at", DisplayName: "Created At"},
+		{ID: 2, Name: "user_id", DisplayName: "User ID"},
+	}
+
+	tests := []struct {
+		name  string
+		model Model
+		want  []string
+	}{
+		{
+			name:  "qualified table names",
+			model: Model{currentView: viewTables, tables: tables},
+			want:  []string{"public.users", "public.orders", "analytics.order_events"},
+		},
+		{
+			name:  "raw field names",
+			model: Model{currentView: viewFields, fields: fields},
+			want:  []string{"created_at", "user_id"},
+		},
+		{
+			name: "active filter in result order",
+			model: Model{
+				currentView:     viewTables,
+				tables:          tables,
+				searchMode:      true,
+				searchQuery:     "order",
+				filteredIndices: []int{2, 1},
+			},
+			want: []string{"analytics.order_events", "public.orders"},
+		},
+		{
+			name: "search without matches shows everything",
+			model: Model{
+				currentView: viewFields,
+				fields:      fields,
+				searchMode:  true,
+				searchQuery: "zzz",
+			},
+			want: []string{"created_at", "user_id"},
+		},
+		{
+			name:  "main menu has no names",
+			model: Model{currentView: viewMainMenu},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.model.visibleNames(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("visibleNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunQuery(t *testing.T) {
+	m := send(t, newTestModel(), toItemDetail...)
+
+	m = send(t, m, key("x"))
+	if m.currentView != viewQueryResults || !m.loading || m.queryCancel == nil {
+		t.Fatalf("x on a saved question: view = %v, loading = %v, want a running query", m.currentView, m.loading)
+	}
+
+	m = send(t, m, fixtureQueryResult)
+	if m.loading || m.queryCancel != nil || len(m.queryRows) != 3 {
+		t.Errorf("queryFinished: loading = %v, rows = %d, want 3 rows shown", m.loading, len(m.queryRows))
+	}
+}
+
+func TestRunQuery_Cancel(t *testing.T) {
+	m := send(t, newTestModel(), toItemDetail...)
+	m = send(t, m, key("x"))
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewItemDetail || m.loading || m.queryCancel != nil {
+		t.Fatalf("esc while running: view = %v, loading = %v, want query cancelled and back on the item", m.currentView, m.loading)
+	}
+
+	// The cancelled query's result arrives late and must be ignored
+	m = send(t, m, fixtureQueryResult)
+	if m.currentView != viewItemDetail || m.queryRows != nil {
+		t.Errorf("late queryFinished: view = %v, rows = %d, want it dropped", m.currentView, len(m.queryRows))
+	}
+}
+
+func TestRetry(t *testing.T) {
+	m := send(t, newTestModel(), toFields...)
+
+	// The fields load fails the first time and succeeds on the retry
+	calls := 0
+	load := func() tea.Msg {
+		calls++
+		if calls == 1 {
+			return fieldsLoaded{err: errors.New("connection reset")}
+		}
+		return fieldsLoaded{fields: fixtureFields}
+	}
+	m = send(t, m, retryable(load)())
+	if m.error == "" || !strings.Contains(plainView(m), "Press 'r' to retry") {
+		t.Fatalf("failed load: error = %q, want the error shown with a retry hint", m.error)
+	}
+
+	updated, cmd := m.Update(key("r"))
+	m = updated.(Model)
+	if m.error != "" || !m.loading || cmd == nil {
+		t.Fatalf("r: error = %q, loading = %v, want the error cleared and the spinner shown", m.error, m.loading)
+	}
+
+	// The batch starts with the replayed load, followed by the spinner tick
+	replayed := cmd().(tea.BatchMsg)[0]()
+	m = send(t, m, replayed)
+	if calls != 2 || m.loading || m.error != "" || len(m.fields) != len(fixtureFields) {
+		t.Errorf("retried load: calls = %d, error = %q, fields = %d, want the fields shown", calls, m.error, len(m.fields))
+	}
+}
+
+func TestRetry_LaterLoad(t *testing.T) {
+	m := send(t, newTestModel(), toFields...)
+	failed := func() tea.Msg { return fieldsLoaded{err: errors.New("connection reset")} }
+	m = send(t, m, retryable(failed)())
+	if m.lastLoad == nil {
+		t.Fatal("failed load: nothing to retry")
+	}
+
+	// A load that finishes fine afterwards leaves nothing for r to replay
+	m = send(t, m, retryable(func() tea.Msg { return fieldsLoaded{fields: fixtureFields} })())
+	if m.lastLoad != nil {
+		t.Error("later load: the failed load is still set to retry")
+	}
+	if _, cmd := m.Update(key("r")); cmd != nil {
+		t.Error("r after the later load replayed a load")
+	}
+}
+
+func TestRunQuery_OnlyForQuestions(t *testing.T) {
+	m := send(t, newTestModel(), toCollectionItems...)
+	m = send(t, m, key("enter"), dashboardDetailLoaded{detail: &api.DashboardDetail{ID: 20, Name: "Revenue"}})
+
+	if m, _ = m.runQuery(); m.currentView != viewItemDetail {
+		t.Errorf("runQuery() on a dashboard switched to view %v, want it refused", m.currentView)
+	}
+}
+
+func TestExportFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{name: "Orders by month", format: "csv", expected: "orders-by-month.csv"},
+		{name: "Revenue / Region (2025)", format: "xlsx", expected: "revenue-region-2025.xlsx"},
+		{name: "Ümsatz", format: "json", expected: "ümsatz.json"},
+		{name: "???", format: "csv", expected: "results.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exportFileName(tt.name, tt.format); got != tt.expected {
+				t.Errorf("exportFileName() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConnectionSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       api.Database
+		expected string
+	}{
+		{
+			name: "admin sees connection details",
+			db: api.Database{ID: 2, Name: "Warehouse", Engine: "postgres", Details: map[string]interface{}{
+				"host":     "db.example.com",
+				"port":     float64(5432),
+				"dbname":   "analytics",
+				"user":     "metabase",
+				"password": "**MetabasePass**",
+				"ssl":      true,
+			}},
+			expected: "Name: Warehouse\nEngine: postgres\nID: 2\nHost: db.example.com\nPort: 5432\nDatabase: analytics\nUser: metabase\nSSL: true",
+		},
+		{
+			name:     "details hidden from non-admins",
+			db:       api.Database{ID: 1, Name: "Sample Database", Engine: "h2"},
+			expected: "Name: Sample Database\nEngine: h2\nID: 1",
+		},
+		{
+			name:     "partial details",
+			db:       api.Database{ID: 3, Name: "Events", Details: map[string]interface{}{"project-id": "acme-prod", "host": nil}},
+			expected: "Name: Events\nID: 3\nProject: acme-prod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := connectionSummary(tt.db); got != tt.expected {
+				t.Errorf("connectionSummary() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTableDDL(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    api.Table
+		fields   []api.Field
+		expected string
+	}{
+		{
+			name:  "database types",
+			table: api.Table{Name: "ORDERS", Schema: "PUBLIC"},
+			fields: []api.Field{
+				{Name: "ID", DatabaseType: "BIGINT", Active: true},
+				{Name: "TOTAL", DatabaseType: "DOUBLE PRECISION", Active: true},
+				{Name: "CREATED_AT", DatabaseType: "TIMESTAMP WITH TIME ZONE", Active: true},
+				{Name: "LEGACY_CODE", DatabaseType: "VARCHAR", Active: false},
+			},
+			expected: "CREATE TABLE PUBLIC.ORDERS (\n  ID BIGINT,\n  TOTAL DOUBLE PRECISION,\n  CREATED_AT TIMESTAMP WITH TIME ZONE\n);",
+		},
+		{
+			name:  "missing database type and quoted names",
+			table: api.Table{Name: "order items"},
+			fields: []api.Field{
+				{Name: "id", DatabaseType: "int4", Active: true},
+				{Name: "payload", BaseType: "type/JSON", Active: true},
+				{Name: "2nd \"name\"", DatabaseType: "text", Active: true},
+				{Name: "unknown", Active: true},
+			},
+			expected: "CREATE TABLE \"order items\" (\n  id int4,\n  payload JSON,\n  \"2nd \"\"name\"\"\" text,\n  unknown\n);",
+		},
+		{
+			name:     "no active fields",
+			table:    api.Table{Name: "events", Schema: "analytics"},
+			fields:   []api.Field{{Name: "id", Active: false}},
+			expected: "CREATE TABLE analytics.events ();",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tableDDL(tt.table, tt.fields); got != tt.expected {
+				t.Errorf("tableDDL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	fields := []api.Field{
+		{ID: 3, Name: "created_at", Position: 2},
+		{ID: 1, Name: "id", Position: 0},
+		{ID: 4, Name: "Amount", Position: 2},
+		{ID: 2, Name: "user_id", Position: 1},
+	}
+
+	tests := []struct {
+		mode     fieldSortMode
+		expected []int
+	}{
+		{fieldSortPosition, []int{1, 2, 4, 3}},
+		{fieldSortName, []int{4, 3, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for _, field := range sortFields(fields, tt.mode) {
+			got = append(got, field.ID)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("sortFields(%v) = %v, want %v", tt.mode, got, tt.expected)
+		}
+	}
+	if fields[0].ID != 3 {
+		t.Error("sortFields() modified its input")
+	}
+}
+
+func TestToggleFieldSort(t *testing.T) {
+	m := send(t, newTestModel(), toTables...)
+	m = send(t, m, key("enter"), fieldsLoaded{fields: []api.Field{
+		{ID: 2, Name: "user_id", Position: 1},
+		{ID: 1, Name: "id", Position: 0},
+		{ID: 3, Name: "amount", Position: 2},
+	}})
+	if m.fields[0].Name != "id" || m.fields[2].Name != "amount" {
+		t.Fatalf("fieldsLoaded: fields = %+v, want them in position order", m.fields)
+	}
+
+	m = send(t, m, key("down"), key("s"))
+	if m.fields[0].Name != "amount" || m.fields[m.cursor].Name != "user_id" {
+		t.Errorf("s: fields = %+v, cursor on %s, want name order with user_id still selected", m.fields, m.fields[m.cursor].Name)
+	}
+}
@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// confirmation is a question asked before a change to Metabase. y runs it
+// and any other key cancels.
+type confirmation struct {
+	prompt string
+	run    func(Model) (Model, tea.Cmd)
+}
+
+func (m Model) updateConfirm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	confirm := m.confirm
+	m.confirm = nil
+	if msg.String() != "y" {
+		m.statusMessage = "Cancelled"
+		return m, nil
+	}
+	return confirm.run(m)
+}
+
+func (m Model) renderConfirm() string {
+	return lipgloss.NewStyle().Foreground(ColorWarning).Render(m.confirm.prompt + " y/n")
+}
+
+// archiveTarget is an item that A archives, or unarchives when it already is
+type archiveTarget struct {
+	model    string
+	id       int
+	name     string
+	archived bool
+	view     viewState // Where A was pressed, the list to reload once it is done
+}
+
+// archiveTarget returns the collection or item under the cursor, or the open
+// item. The root collection and personal collections can't be archived.
+func (m Model) archiveTarget() (archiveTarget, bool) {
+	switch m.currentView {
+	case viewCollections:
+		if m.cursor >= len(m.collections) {
+			return archiveTarget{}, false
+		}
+		collection := m.collections[m.cursor]
+		id, ok := collectionRef(collection.ID).(int)
+		if !ok || collection.IsPersonal {
+			return archiveTarget{}, false
+		}
+		return archiveTarget{model: "collection", id: id, name: collection.Name, archived: collection.Archived, view: m.currentView}, true
+	case viewCollectionItems:
+		if m.cursor >= len(m.collectionItems) {
+			return archiveTarget{}, false
+		}
+		item := m.collectionItems[m.cursor]
+		return itemArchiveTarget(item, item.Archived, m.currentView)
+	case viewItemDetail:
+		if m.selectedItem == nil {
+			return archiveTarget{}, false
+		}
+		// The details are newer than the list the item was opened from
+		archived := m.selectedItem.Archived
+		switch detail := m.itemDetail.(type) {
+		case *api.CardDetail:
+			archived = detail.Archived
+		case *api.DashboardDetail:
+			archived = detail.Archived
+		case *api.MetricDetail:
+			archived = detail.Archived
+		}
+		return itemArchiveTarget(*m.selectedItem, archived, m.currentView)
+	}
+	return archiveTarget{}, false
+}
+
+func itemArchiveTarget(item api.CollectionItem, archived bool, from viewState) (archiveTarget, bool) {
+	switch item.Model {
+	case "card", "dataset", "metric", "dashboard", "collection":
+		return archiveTarget{model: item.Model, id: item.ID, name: item.Name, archived: archived, view: from}, true
+	}
+	return archiveTarget{}, false
+}
+
+// confirmArchive asks before archiving or unarchiving the selected item
+func (m Model) confirmArchive() (Model, tea.Cmd) {
+	target, ok := m.archiveTarget()
+	if !ok || m.loading {
+		return m, nil
+	}
+	if m.readOnly {
+		m.statusMessage = "Archiving is turned off in read-only mode"
+		return m, nil
+	}
+	verb := "Archive"
+	if target.archived {
+		verb = "Unarchive"
+	}
+	m.confirm = &confirmation{
+		prompt: fmt.Sprintf("%s %q?", verb, target.name),
+		run: func(m Model) (Model, tea.Cmd) {
+			return m, setArchived(m.client, target)
+		},
+	}
+	return m, nil
+}
+
+// applyArchived reports the change and reloads the list it was made from,
+// where the item has left or joined the archived ones
+func (m Model) applyArchived(msg itemArchived) (Model, tea.Cmd) {
+	target := msg.target
+	if msg.err != nil {
+		verb := "archive"
+		if target.archived {
+			verb = "unarchive"
+		}
+		m.statusMessage = fmt.Sprintf("Failed to %s %s: %v", verb, target.name, msg.err)
+		return m, nil
+	}
+	if target.archived {
+		m.statusMessage = "Unarchived " + target.name
+	} else {
+		m.statusMessage = "Archived " + target.name
+	}
+	if m.currentView != target.view || m.loading {
+		return m, nil
+	}
+	if m.currentView == viewItemDetail && m.selectedItem != nil {
+		item := *m.selectedItem
+		item.Archived = !target.archived
+		m.selectedItem = &item
+	}
+	return m.refresh()
+}
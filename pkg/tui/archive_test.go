@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestArchive(t *testing.T) {
+	m := send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{key("down"), key("A")})...)
+	if view := plainView(m); !strings.Contains(view, `Archive "Orders by month"? y/n`) {
+		t.Fatalf("View() is missing the confirmation:\n%s", view)
+	}
+
+	m = send(t, m, key("n"))
+	if m.confirm != nil || m.statusMessage != "Cancelled" {
+		t.Fatalf("n: confirm = %v, status = %q, want the archive cancelled", m.confirm, m.statusMessage)
+	}
+
+	m = send(t, m, key("A"))
+	updated, cmd := m.Update(key("y"))
+	m = updated.(Model)
+	if m.confirm != nil || cmd == nil {
+		t.Fatalf("y: confirm = %v, cmd = %v, want the archive requested", m.confirm, cmd)
+	}
+
+	target := archiveTarget{model: "card", id: 21, name: "Orders by month", view: viewCollectionItems}
+	m = send(t, m, itemArchived{target: target})
+	if m.statusMessage != "Archived Orders by month" || !m.loading {
+		t.Errorf("status = %q, loading = %v, want the items reloading after the archive", m.statusMessage, m.loading)
+	}
+}
+
+func TestArchive_Targets(t *testing.T) {
+	collections := []api.Collection{
+		{ID: 5, Name: "Analytics", Archived: true},
+		{ID: 9, Name: "Ada's Personal Collection", IsPersonal: true},
+		{ID: "root", Name: "Our analytics"},
+	}
+	m := send(t, newTestModel(), key("enter"), collectionsLoaded{collections: collections})
+
+	target, ok := m.archiveTarget()
+	if !ok || target.model != "collection" || target.id != 5 || !target.archived {
+		t.Errorf("archiveTarget() = %+v, %v, want the archived collection 5", target, ok)
+	}
+	if m = send(t, m, key("A")); m.confirm == nil || m.confirm.prompt != `Unarchive "Analytics"?` {
+		t.Errorf("A on an archived collection asked %+v, want to unarchive it", m.confirm)
+	}
+
+	for _, cursor := range []int{1, 2} {
+		m.confirm = nil
+		m.cursor = cursor
+		if target, ok := m.archiveTarget(); ok {
+			t.Errorf("archiveTarget() on %s = %+v, want none", collections[cursor].Name, target)
+		}
+	}
+}
+
+func TestArchive_ReadOnly(t *testing.T) {
+	m := newTestModel()
+	m.readOnly = true
+	m = send(t, m, steps(toCollectionItems, []tea.Msg{key("A")})...)
+	if m.confirm != nil || m.statusMessage != "Archiving is turned off in read-only mode" {
+		t.Errorf("confirm = %v, status = %q, want the archive refused", m.confirm, m.statusMessage)
+	}
+	if strings.Contains(plainView(m), "A archive") {
+		t.Error("help offers to archive in read-only mode")
+	}
+}
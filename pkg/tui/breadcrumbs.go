@@ -0,0 +1,345 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// breadcrumb is one level of the path shown under the title, with the view
+// that lists or shows it
+type breadcrumb struct {
+	name  string
+	view  viewState
+	depth int    // Collections or items above it, for the levels that stack
+	key   string // Identifies what it names in its parent's list, see listKeyOf
+}
+
+// listKeyOf identifies an entry of one of the lists, such as "table:10"
+func listKeyOf(kind string, id interface{}) string {
+	return fmt.Sprintf("%s:%v", kind, id)
+}
+
+// breadcrumbs returns the levels of the path to the current view, outermost
+// first. Views outside the database and collection hierarchies have none, and
+// the path stops early if a selection it needs is missing.
+func (m Model) breadcrumbs() []breadcrumb {
+	var crumbs []breadcrumb
+	switch m.currentView {
+	case viewDatabases, viewSchemas, viewTables, viewFields, viewFieldDetail:
+		crumbs = append(crumbs, breadcrumb{name: "Databases", view: viewDatabases})
+		if m.currentView == viewDatabases || m.selectedDatabase == nil {
+			break
+		}
+		if m.currentView == viewTables && m.selectedSchema == nil {
+			// The flat table list stands in for both the schemas and the tables
+			crumbs = append(crumbs,
+				breadcrumb{name: m.selectedDatabase.Name, view: viewTables, key: listKeyOf("database", m.selectedDatabase.ID)},
+				breadcrumb{name: "All schemas", view: viewTables})
+			break
+		}
+		crumbs = append(crumbs, breadcrumb{name: m.selectedDatabase.Name, view: viewSchemas, key: listKeyOf("database", m.selectedDatabase.ID)})
+		if m.flattenTables {
+			crumbs[len(crumbs)-1].view = viewTables
+		}
+		if m.currentView == viewSchemas || m.selectedSchema == nil {
+			break
+		}
+		crumbs = append(crumbs, breadcrumb{name: m.selectedSchema.Name, view: viewTables, key: listKeyOf("schema", m.selectedSchema.Name)})
+		if m.currentView == viewTables || m.selectedTable == nil {
+			break
+		}
+		tableName := m.selectedTable.DisplayName
+		if tableName == "" {
+			tableName = m.selectedTable.Name
+		}
+		if technical := m.technicalName(m.selectedTable.DisplayName, m.selectedTable.Name); technical != "" {
+			tableName += " (" + technical + ")"
+		}
+		crumbs = append(crumbs, breadcrumb{name: tableName, view: viewFields, key: listKeyOf("table", m.selectedTable.ID)})
+		if m.currentView == viewFieldDetail && m.selectedField != nil {
+			fieldName := m.selectedField.DisplayName
+			if fieldName == "" {
+				fieldName = m.selectedField.Name
+			}
+			crumbs = append(crumbs, breadcrumb{name: fieldName, view: viewFieldDetail, key: listKeyOf("field", m.selectedField.ID)})
+		}
+	case viewRelated:
+		if m.selectedDatabase == nil || m.relatedTable == nil {
+			break
+		}
+		tableName := m.relatedTable.DisplayName
+		if tableName == "" {
+			tableName = m.relatedTable.Name
+		}
+		crumbs = append(crumbs,
+			breadcrumb{name: "Databases", view: viewDatabases},
+			breadcrumb{name: m.selectedDatabase.Name, view: viewSchemas, key: listKeyOf("database", m.selectedDatabase.ID)},
+			breadcrumb{name: tableSchemaName(*m.relatedTable), view: viewTables, key: listKeyOf("schema", tableSchemaName(*m.relatedTable))},
+			breadcrumb{name: tableName, view: m.relatedFrom, key: listKeyOf("table", m.relatedTable.ID)},
+			breadcrumb{name: "Related", view: viewRelated})
+		if m.flattenTables {
+			crumbs[1].view = viewTables
+		}
+	case viewFieldSearch:
+		from := m
+		from.currentView = m.fieldSearchFrom
+		crumbs = append(from.breadcrumbs(), breadcrumb{name: "Field search", view: viewFieldSearch})
+	case viewCollections, viewCollectionItems, viewItemDetail, viewQueryResults:
+		crumbs = append(crumbs, breadcrumb{name: "Collections", view: viewCollections})
+		if m.currentView == viewCollections || m.selectedCollection == nil {
+			break
+		}
+		for i, collection := range m.collectionStack {
+			crumbs = append(crumbs, breadcrumb{name: collection.Name, view: viewCollectionItems, depth: i, key: listKeyOf("collection", collection.ID)})
+		}
+		crumbs = append(crumbs, breadcrumb{name: m.selectedCollection.Name, view: viewCollectionItems, depth: len(m.collectionStack), key: listKeyOf("collection", m.selectedCollection.ID)})
+		if m.currentView == viewCollectionItems || m.selectedItem == nil {
+			break
+		}
+		for i, frame := range m.itemStack {
+			crumbs = append(crumbs, breadcrumb{name: frame.item.Name, view: viewItemDetail, depth: i, key: listKeyOf(frame.item.Model, frame.item.ID)})
+		}
+		crumbs = append(crumbs, breadcrumb{name: m.selectedItem.Name, view: viewItemDetail, depth: len(m.itemStack), key: listKeyOf(m.selectedItem.Model, m.selectedItem.ID)})
+	}
+	return crumbs
+}
+
+// breadcrumbPath joins the breadcrumbs into the path shown under the title
+func breadcrumbPath(crumbs []breadcrumb) string {
+	names := make([]string, len(crumbs))
+	for i, crumb := range crumbs {
+		names[i] = crumb.name
+	}
+	return strings.Join(names, " > ")
+}
+
+// atBreadcrumb reports whether the current view is the given level
+func (m Model) atBreadcrumb(crumb breadcrumb) bool {
+	if m.currentView != crumb.view {
+		return false
+	}
+	switch crumb.view {
+	case viewCollectionItems:
+		return len(m.collectionStack) == crumb.depth
+	case viewItemDetail:
+		return len(m.itemStack) == crumb.depth
+	}
+	return true
+}
+
+// listKeys returns the keys of the entries of the current list, in order
+func (m Model) listKeys() []string {
+	var keys []string
+	switch m.currentView {
+	case viewDatabases:
+		for _, database := range m.databases {
+			keys = append(keys, listKeyOf("database", database.ID))
+		}
+	case viewSchemas:
+		for _, schema := range m.schemas {
+			keys = append(keys, listKeyOf("schema", schema.Name))
+		}
+	case viewTables:
+		for _, table := range m.tables {
+			keys = append(keys, listKeyOf("table", table.ID))
+		}
+	case viewFields:
+		for _, field := range m.fields {
+			keys = append(keys, listKeyOf("field", field.ID))
+		}
+	case viewCollections:
+		for _, collection := range m.collections {
+			keys = append(keys, listKeyOf("collection", collection.ID))
+		}
+	case viewCollectionItems:
+		for _, item := range m.collectionItems {
+			keys = append(keys, listKeyOf(item.Model, item.ID))
+		}
+	case viewFieldSearch:
+		for _, hit := range m.fieldIndex {
+			keys = append(keys, listKeyOf("field", hit.field.ID))
+		}
+	}
+	return keys
+}
+
+// selectJumped puts the cursor on the entry the jump came from, once the
+// list it is in has loaded
+func (m *Model) selectJumped() {
+	key := m.jumpSelect
+	m.jumpSelect = ""
+	keys := m.listKeys()
+	for i, entryKey := range keys {
+		if entryKey == key {
+			m.cursor = i
+			m.updateViewport(len(keys))
+			return
+		}
+	}
+}
+
+// jumpToLevel goes back to a breadcrumb level in one step, as if back had
+// been pressed until it was reached, with the cursor on the entry that led
+// away from it. Searches restored on the levels passed through are dropped,
+// and only the load of the final level is kept.
+func (m Model) jumpToLevel(level int) (Model, tea.Cmd) {
+	crumbs := m.breadcrumbs()
+	if level < 0 || level >= len(crumbs) {
+		return m, nil
+	}
+	target := crumbs[level]
+
+	if level+1 < len(crumbs) {
+		m.jumpSelect = crumbs[level+1].key
+	}
+	var cmd tea.Cmd
+	for steps := 0; !m.atBreadcrumb(target) && m.currentView != viewMainMenu && steps <= len(crumbs); steps++ {
+		m.searchMode = false
+		m.searchQuery = ""
+		m.filteredIndices = nil
+		m.restoreIndex = nil
+		m, cmd = m.goBack()
+	}
+	if cmd == nil {
+		// Collections popped on the way would have started loads of their own
+		m.loading = false
+		m.selectJumped()
+	}
+	return m, cmd
+}
+
+// jumpToRoot goes back to the databases or collections list the current view
+// is under in one step, dropping everything selected below it. Unlike
+// jumpToLevel it doesn't walk back level by level, so no collection on the
+// way is reloaded, and loads still running for the deeper levels are left
+// to be dropped when they finish.
+func (m Model) jumpToRoot() (Model, tea.Cmd) {
+	crumbs := m.breadcrumbs()
+	if len(crumbs) == 0 || m.atBreadcrumb(crumbs[0]) {
+		return m, nil
+	}
+	m.dropSelection()
+	m.currentView = crumbs[0].view
+
+	if len(crumbs) > 1 {
+		m.jumpSelect = crumbs[1].key
+	}
+	var cmd tea.Cmd
+	switch {
+	case m.currentView == viewDatabases && m.databases == nil:
+		cmd = loadDatabases(m.client)
+	case m.currentView == viewCollections && m.collections == nil:
+		cmd = m.loadRootCollections()
+	}
+	if cmd == nil {
+		m.selectJumped()
+		return m, nil
+	}
+	m.loading = true
+	return m, withSpinner(cmd)
+}
+
+// goHome returns to the main menu from any depth in one press, closing the
+// search and help first. Unlike jumpToRoot it also leaves the databases and
+// collections lists, and loads still running are dropped when they finish.
+func (m Model) goHome() (Model, tea.Cmd) {
+	m.dropSelection()
+	m.helpMode = false
+	m.jumpMode = false
+	m.searchResults = nil
+	m.globalQuery = ""
+	m.searchScope = nil
+	m.fieldIndex = nil
+	m.databaseTables = nil
+	return m.openMainMenu()
+}
+
+// dropSelection forgets everything opened below the databases and
+// collections lists, stopping a running query
+func (m *Model) dropSelection() {
+	if m.queryCancel != nil {
+		m.queryCancel()
+		m.queryCancel = nil
+	}
+	m.selectedDatabase = nil
+	m.selectedSchema = nil
+	m.selectedTable = nil
+	m.selectedField = nil
+	m.schemas = nil
+	m.tables = nil
+	m.fields = nil
+	m.selectedCollection = nil
+	m.selectedItem = nil
+	m.itemDetail = nil
+	m.collectionStack = nil
+	m.collectionItems = nil
+	m.itemStack = nil
+	m.relatedTable = nil
+	m.relatedItems = nil
+	m.queryColumns = nil
+	m.queryRows = nil
+	m.savedSearches = nil
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.restoreIndex = nil
+	m.numberInput = ""
+	m.drilledIn = false
+	m.descriptionMode = false
+	m.viewportStart = 0
+	m.cursor = 0
+	m.error = ""
+	m.loading = false
+}
+
+// jumpLevels returns the breadcrumb levels other than the current view,
+// which are the ones that can be jumped to
+func (m Model) jumpLevels() []int {
+	var levels []int
+	for i, crumb := range m.breadcrumbs() {
+		if !m.atBreadcrumb(crumb) {
+			levels = append(levels, i)
+		}
+	}
+	return levels
+}
+
+// openJump asks which breadcrumb level to go back to
+func (m Model) openJump() (Model, tea.Cmd) {
+	if m.loading || len(m.jumpLevels()) == 0 {
+		return m, nil
+	}
+	m.jumpMode = true
+	m.numberInput = ""
+	return m, nil
+}
+
+// updateJump handles the key pressed after "b": the number of a level jumps
+// to it, anything else cancels
+func (m Model) updateJump(msg tea.KeyMsg) (Model, tea.Cmd) {
+	m.jumpMode = false
+	level, err := strconv.Atoi(msg.String())
+	if err != nil {
+		return m, nil
+	}
+	return m.jumpToLevel(level - 1)
+}
+
+// numberedBreadcrumbPath is the path with the number to press before each
+// level that can be jumped to
+func (m Model) numberedBreadcrumbPath() string {
+	numberStyle := lipgloss.NewStyle().Foreground(ColorHighlight)
+	crumbs := m.breadcrumbs()
+	names := make([]string, len(crumbs))
+	for i, crumb := range crumbs {
+		names[i] = crumb.name
+	}
+	for _, level := range m.jumpLevels() {
+		names[level] = numberStyle.Render(fmt.Sprintf("%d", level+1)) + " " + names[level]
+	}
+	return strings.Join(names, " > ")
+}
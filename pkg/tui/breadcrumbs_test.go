@@ -0,0 +1,266 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestJumpToLevel(t *testing.T) {
+	toNestedCollection := steps(toCollectionItems, []tea.Msg{key("3"), key("enter"), collectionItemsLoaded{items: []api.CollectionItem{
+		{ID: 30, Name: "Old report", Model: "card"},
+	}}})
+	toSecondTable := steps(toTables, []tea.Msg{key("down"), key("enter"), fieldsLoaded{fields: fixtureFields}})
+
+	tests := []struct {
+		name        string
+		msgs        []tea.Msg
+		level       string
+		wantView    viewState
+		wantPath    string // Prefix, the counts of a list still loading are stale
+		wantLoading bool
+		loaded      tea.Msg // Result of the load the jump started
+		wantCursor  int
+	}{
+		{name: "fields to databases", msgs: toFields, level: "1", wantView: viewDatabases, wantPath: "Databases (2)"},
+		{name: "fields to database", msgs: toFields, level: "2", wantView: viewSchemas, wantPath: "Databases > Sample Database (1)"},
+		{name: "fields to schema", msgs: toSecondTable, level: "3", wantView: viewTables, wantPath: "Databases > Sample Database > PUBLIC (2)", wantCursor: 1},
+		{name: "item to collection", msgs: toItemDetail, level: "2", wantView: viewCollectionItems, wantPath: "Collections > Analytics (3)", wantCursor: 1},
+		{
+			name:        "nested collection to parent",
+			msgs:        toNestedCollection,
+			level:       "2",
+			wantView:    viewCollectionItems,
+			wantPath:    "Collections > Analytics",
+			wantLoading: true,
+			loaded:      collectionItemsLoaded{items: fixtureCollectionItems},
+			wantCursor:  2,
+		},
+		{name: "nested collection to root", msgs: toNestedCollection, level: "1", wantView: viewCollections, wantPath: "Collections (2)"},
+		{name: "current level", msgs: toFields, level: "4", wantView: viewFields, wantPath: "Databases > Sample Database > PUBLIC > Orders (2)"},
+		{name: "not a level", msgs: toFields, level: "x", wantView: viewFields, wantPath: "Databases > Sample Database > PUBLIC > Orders (2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			m = send(t, m, key("b"))
+			if !m.jumpMode {
+				t.Fatal("b did not ask for a level to jump to")
+			}
+
+			updated, cmd := m.Update(key(tt.level))
+			m = updated.(Model)
+			if m.jumpMode {
+				t.Error("jumpMode still set after the level was picked")
+			}
+			if m.currentView != tt.wantView {
+				t.Errorf("jump to %s: view = %v, want %v", tt.level, m.currentView, tt.wantView)
+			}
+			if path := strings.Split(plainView(m), "\n")[1]; !strings.HasPrefix(path, tt.wantPath) {
+				t.Errorf("jump to %s: path = %q, want it to start with %q", tt.level, path, tt.wantPath)
+			}
+			if m.loading != tt.wantLoading || (cmd != nil) != tt.wantLoading {
+				t.Errorf("jump to %s: loading = %v, cmd = %v, want loading %v", tt.level, m.loading, cmd != nil, tt.wantLoading)
+			}
+			if tt.loaded != nil {
+				m = send(t, m, tt.loaded)
+			}
+			if m.cursor != tt.wantCursor {
+				t.Errorf("jump to %s: cursor = %d, want %d on the entry the jump came from", tt.level, m.cursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestJumpPrompt(t *testing.T) {
+	m := send(t, newTestModel(), steps(toFields, []tea.Msg{key("b")})...)
+	want := "1 Databases > 2 Sample Database > 3 PUBLIC > Orders"
+	if path := strings.Split(plainView(m), "\n")[1]; path != want {
+		t.Errorf("path while jumping = %q, want %q", path, want)
+	}
+
+	// Root lists have nowhere to jump to
+	m = send(t, newTestModel(), steps(toDatabases, []tea.Msg{key("b")})...)
+	if m.jumpMode {
+		t.Error("b on the databases list asked for a level, want it ignored")
+	}
+}
+
+func TestJumpToRoot(t *testing.T) {
+	toNestedCollection := steps(toCollectionItems, []tea.Msg{key("3"), key("enter"), collectionItemsLoaded{items: []api.CollectionItem{
+		{ID: 30, Name: "Old report", Model: "card"},
+	}}, key("enter")})
+
+	tests := []struct {
+		name     string
+		msgs     []tea.Msg
+		late     tea.Msg // Result of a load that was still running
+		wantView viewState
+		wantPath string
+	}{
+		{name: "fields", msgs: toFields, wantView: viewDatabases, wantPath: "Databases (2)"},
+		{name: "table from a search", msgs: steps(toTables, []tea.Msg{key("/"), key("o"), key("r"), key("enter"), fieldsLoaded{fields: fixtureFields}}), wantView: viewDatabases, wantPath: "Databases (2)"},
+		{name: "tables loading", msgs: steps(toSchemas, []tea.Msg{key("enter")}), late: tablesLoaded{tables: fixtureTables}, wantView: viewDatabases, wantPath: "Databases (2)"},
+		{name: "nested collection", msgs: toNestedCollection, wantView: viewCollections, wantPath: "Collections (2)"},
+		{name: "items loading", msgs: steps(toCollections, []tea.Msg{key("enter")}), late: collectionItemsLoaded{items: fixtureCollectionItems}, wantView: viewCollections, wantPath: "Collections (2)"},
+		{name: "query running", msgs: steps(toItemDetail, []tea.Msg{key("x")}), wantView: viewCollections, wantPath: "Collections (2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			cancelled := false
+			if m.queryCancel != nil {
+				m.queryCancel = func() { cancelled = true }
+			}
+			running := m.queryCancel != nil
+
+			updated, cmd := m.Update(key("H"))
+			m = updated.(Model)
+			if cmd != nil {
+				t.Error("H started a load, want the root list reused")
+			}
+			if tt.late != nil {
+				m = send(t, m, tt.late)
+			}
+
+			if m.currentView != tt.wantView {
+				t.Fatalf("view = %v, want %v", m.currentView, tt.wantView)
+			}
+			if path := strings.Split(plainView(m), "\n")[1]; path != tt.wantPath {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+			if m.cursor != 0 || m.viewportStart != 0 || m.loading || m.error != "" {
+				t.Errorf("cursor = %d, viewportStart = %d, loading = %v, error = %q, want a fresh list", m.cursor, m.viewportStart, m.loading, m.error)
+			}
+			if m.selectedDatabase != nil || m.selectedSchema != nil || m.selectedTable != nil || m.selectedField != nil ||
+				m.selectedCollection != nil || m.selectedItem != nil || m.itemDetail != nil ||
+				m.collectionStack != nil || m.itemStack != nil || m.savedSearches != nil {
+				t.Error("selections below the root were kept")
+			}
+			if m.schemas != nil || m.tables != nil || m.fields != nil || m.collectionItems != nil || m.queryRows != nil {
+				t.Error("lists below the root were kept")
+			}
+			if m.searchMode || m.searchQuery != "" || m.filteredIndices != nil {
+				t.Errorf("search = %q, want it cleared", m.searchQuery)
+			}
+			if running && !cancelled {
+				t.Error("the running query was not cancelled")
+			}
+		})
+	}
+
+	// Root lists and views outside the hierarchies stay as they are
+	for _, msgs := range [][]tea.Msg{nil, steps(toDatabases, []tea.Msg{key("down")})} {
+		m := send(t, newTestModel(), msgs...)
+		got := send(t, m, key("H"))
+		if got.currentView != m.currentView || got.cursor != m.cursor {
+			t.Errorf("H on %v moved to %v, cursor %d, want it ignored", m.currentView, got.currentView, got.cursor)
+		}
+	}
+}
+
+func TestGoHome(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []tea.Msg
+		late tea.Msg // Result of a load that was still running
+	}{
+		{name: "fields", msgs: toFields},
+		{name: "help", msgs: steps(toTables, []tea.Msg{key("?")})},
+		{name: "number input", msgs: steps(toTables, []tea.Msg{key("0")})},
+		{name: "jump prompt", msgs: steps(toFields, []tea.Msg{key("b")})},
+		{name: "items loading", msgs: steps(toCollections, []tea.Msg{key("enter")}), late: collectionItemsLoaded{items: fixtureCollectionItems}},
+		{name: "query running", msgs: steps(toItemDetail, []tea.Msg{key("x")})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			cancelled := false
+			if m.queryCancel != nil {
+				m.queryCancel = func() { cancelled = true }
+			}
+			running := m.queryCancel != nil
+
+			m = send(t, m, key("~"))
+			if tt.late != nil {
+				m = send(t, m, tt.late)
+			}
+
+			if m.currentView != viewMainMenu || m.cursor != 0 || m.loading || m.error != "" {
+				t.Fatalf("view = %v, cursor = %d, loading = %v, error = %q, want the main menu", m.currentView, m.cursor, m.loading, m.error)
+			}
+			if m.searchMode || m.searchQuery != "" || m.helpMode || m.jumpMode || m.numberInput != "" {
+				t.Errorf("search = %v, help = %v, jump = %v, number = %q, want them closed", m.searchMode, m.helpMode, m.jumpMode, m.numberInput)
+			}
+			if m.selectedDatabase != nil || m.selectedSchema != nil || m.selectedTable != nil || m.selectedField != nil ||
+				m.selectedCollection != nil || m.selectedItem != nil || m.itemDetail != nil ||
+				m.collectionStack != nil || m.itemStack != nil || m.savedSearches != nil {
+				t.Error("selections were kept")
+			}
+			if m.databases != nil || m.collections != nil || m.schemas != nil || m.tables != nil || m.fields != nil || m.collectionItems != nil {
+				t.Error("lists were kept")
+			}
+			if running && !cancelled {
+				t.Error("the running query was not cancelled")
+			}
+		})
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []tea.Msg
+		want string
+	}{
+		{name: "main menu", want: "Main Menu"},
+		{name: "databases", msgs: toDatabases, want: "Databases"},
+		{name: "schemas", msgs: steps(toDatabases, []tea.Msg{key("enter")}), want: "Databases > Sample Database"},
+		{name: "tables", msgs: toTables, want: "Databases > Sample Database > PUBLIC"},
+		{name: "fields", msgs: toFields, want: "Databases > Sample Database > PUBLIC > Orders"},
+		{name: "collections", msgs: toCollections, want: "Collections"},
+		{name: "collection items", msgs: toCollectionItems, want: "Collections > Analytics"},
+		{name: "item detail", msgs: toItemDetail, want: "Collections > Analytics > Orders by month"},
+		{
+			name: "search",
+			msgs: []tea.Msg{key("S"), key("o"), key("enter"), searchCompleted{query: "o", results: fixtureSearchResults}},
+			want: `Search "o"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			if got := m.location(); got != tt.want {
+				t.Errorf("location() = %q, want %q", got, tt.want)
+			}
+			// The header shows the same path, followed by the list's count
+			if path := strings.Split(plainView(m), "\n")[1]; !strings.HasPrefix(path, tt.want) {
+				t.Errorf("header path = %q, want it to start with %q", path, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoHome_TypedInFilter(t *testing.T) {
+	m := send(t, newTestModel(), steps(toTables, []tea.Msg{key("/"), key("o"), key("~")})...)
+	if m.currentView != viewTables || !m.searchMode || m.searchQuery != "o~" {
+		t.Errorf("~ while filtering: view = %v, query = %q, want it typed into the filter", m.currentView, m.searchQuery)
+	}
+
+	// Home keys that don't type anything still go home
+	keys, err := newKeyMap(map[string][]string{actionHome: {"~", "ctrl+g"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.keys = keys
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyCtrlG})
+	if m.currentView != viewMainMenu || m.searchMode {
+		t.Errorf("ctrl+g while filtering: view = %v, search = %v, want the main menu", m.currentView, m.searchMode)
+	}
+}
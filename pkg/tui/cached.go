@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Lists saved by an earlier session, see api.DiskCache, are shown as soon as
+// they are opened and refreshed in the background: the list is marked as
+// cached in the header until the refresh has answered, and then replaced.
+
+// listRefreshed is the answer to the refresh of a list shown from the cache,
+// tagged with the refresh it answers
+type listRefreshed struct {
+	id  int
+	msg tea.Msg
+}
+
+// cacheThenRefresh starts the refresh of the list just shown from the cache.
+// Its answer is dropped if the list was left before it came, or opened again
+// with a newer refresh.
+func (m *Model) cacheThenRefresh(view viewState, savedAt time.Time, load tea.Cmd) tea.Cmd {
+	m.refreshID++
+	m.cachedView, m.cachedAt = view, savedAt
+	id := m.refreshID
+	return func() tea.Msg {
+		return listRefreshed{id: id, msg: load()}
+	}
+}
+
+// dropRefresh forgets the list shown from the cache, so the refresh started
+// with it is dropped when it answers
+func (m *Model) dropRefresh() {
+	m.cachedAt = time.Time{}
+}
+
+// showCachedDatabases fills the databases list from the disk cache and
+// returns its refresh, or nil without cached databases
+func (m *Model) showCachedDatabases() tea.Cmd {
+	databases, savedAt, ok := m.client.CachedDatabases()
+	if !ok {
+		return nil
+	}
+	m.databases = databases
+	return m.cacheThenRefresh(viewDatabases, savedAt, loadDatabases(m.client))
+}
+
+// showCachedCollections fills the root collections from the disk cache and
+// returns their refresh, or nil without cached collections. The tree isn't
+// cached.
+func (m *Model) showCachedCollections() tea.Cmd {
+	if m.collectionTreeMode {
+		return nil
+	}
+	collections, savedAt, ok := m.client.CachedCollections(m.showArchived)
+	if !ok {
+		return nil
+	}
+	m.showCollections(collections)
+	return m.cacheThenRefresh(viewCollections, savedAt, loadCollections(m.client, m.showArchived))
+}
+
+// showingCached reports whether the current list came from the disk cache
+// and hasn't been refreshed yet
+func (m Model) showingCached() bool {
+	return !m.cachedAt.IsZero() && m.currentView == m.cachedView
+}
+
+// applyRefresh swaps the list shown from the cache for the one its refresh
+// loaded, unless the list was left since
+func (m Model) applyRefresh(msg listRefreshed) (Model, tea.Cmd) {
+	if msg.id != m.refreshID || m.cachedAt.IsZero() {
+		return m, nil
+	}
+	switch loaded := msg.msg.(type) {
+	case databasesLoaded:
+		return m.refreshDatabases(loaded)
+	case collectionsLoaded:
+		return m.refreshCollections(loaded)
+	}
+	return m, nil
+}
+
+// refreshDatabases replaces the cached databases with the ones just loaded,
+// keeping the cursor on the same database
+func (m Model) refreshDatabases(msg databasesLoaded) (Model, tea.Cmd) {
+	if m.currentView == viewDatabases {
+		m.loading = false
+	}
+	if msg.err != nil {
+		m.statusMessage = "Showing cached databases, the refresh failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.cachedAt = time.Time{}
+	if m.currentView == viewDatabases && m.cursor < len(m.databases) {
+		m.jumpSelect = listKeyOf("database", m.databases[m.cursor].ID)
+	}
+	m.databases = msg.databases
+	m.afterRefresh()
+	return m, nil
+}
+
+// refreshCollections replaces the cached root collections with the ones
+// just loaded, keeping the cursor on the same collection
+func (m Model) refreshCollections(msg collectionsLoaded) (Model, tea.Cmd) {
+	if m.currentView == viewCollections {
+		m.loading = false
+	}
+	if msg.err != nil {
+		m.statusMessage = "Showing cached collections, the refresh failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.cachedAt = time.Time{}
+	if m.currentView == viewCollections && m.cursor < len(m.collections) {
+		m.jumpSelect = listKeyOf("collection", m.collections[m.cursor].ID)
+	}
+	m.itemCounts = nil
+	m.showCollections(msg.collections)
+	m.afterRefresh()
+	return m, nil
+}
+
+// afterRefresh puts the cursor back on the entry it was on, or runs the
+// search again over the refreshed list
+func (m *Model) afterRefresh() {
+	if m.searchMode && m.searchQuery != "" {
+		m.jumpSelect = ""
+		m.updateSearch()
+		return
+	}
+	if m.jumpSelect != "" {
+		m.cursor = 0
+		m.selectJumped()
+	}
+}
@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newCachedModel returns a test model whose client has a disk cache holding
+// the fixture databases and collections
+func newCachedModel(t *testing.T) Model {
+	t.Helper()
+	m := newTestModel()
+	seedCache(t, m.client)
+	return m
+}
+
+// seedCache gives the client a disk cache holding two databases and two
+// collections, as saved by an earlier session that loaded them
+func seedCache(t *testing.T, client *api.MetabaseClient) {
+	t.Helper()
+	client.Cache = api.NewDiskCache(t.TempDir(), "", client.BaseURL, time.Hour)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/database":
+			_, _ = w.Write([]byte(`{"data": [{"id": 1, "name": "Sample Database"}, {"id": 2, "name": "Warehouse"}]}`))
+		case "/api/collection":
+			_, _ = w.Write([]byte(`[{"id": 5, "name": "Analytics", "location": "/"}, {"id": 6, "name": "Marketing", "location": "/"}]`))
+		}
+	}))
+	defer server.Close()
+	earlier := api.NewMetabaseClient(server.URL, "token", "dev")
+	earlier.Cache = client.Cache
+	if _, err := earlier.GetDatabases(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := earlier.GetCollections(false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// refreshed answers the refresh of the list the model shows from the cache
+func refreshed(m Model, msg tea.Msg) listRefreshed {
+	return listRefreshed{id: m.refreshID, msg: msg}
+}
+
+func TestCacheThenRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": [{"id": 1, "name": "Sample Database"}, {"id": 2, "name": "Warehouse"}, {"id": 3, "name": "Events"}]}`))
+	}))
+	defer server.Close()
+	m := NewModelWithClient(api.NewMetabaseClient(server.URL, "token", "dev"), "v1.0.0")
+	seedCache(t, m.client)
+
+	// The cached list renders before the refresh has answered
+	m = send(t, m, key("down"))
+	updated, cmd := m.Update(key("enter"))
+	m = updated.(Model)
+	if m.loading || !strings.Contains(plainView(m), "Warehouse") || strings.Contains(plainView(m), "Events") {
+		t.Fatalf("loading = %v, view:\n%s\nwant the cached databases", m.loading, plainView(m))
+	}
+
+	m = send(t, m, cmd())
+	if m.showingCached() || !strings.Contains(plainView(m), "Events") {
+		t.Errorf("view:\n%s\nwant the refreshed databases", plainView(m))
+	}
+}
+
+func TestCachedDatabases(t *testing.T) {
+	m := newCachedModel(t)
+
+	m = send(t, m, key("down"), key("enter"))
+	if m.currentView != viewDatabases || m.loading || len(m.databases) != 2 {
+		t.Fatalf("view = %v, loading = %v, %d databases, want the cached databases at once", m.currentView, m.loading, len(m.databases))
+	}
+	if _, path := m.header(); !strings.HasSuffix(path, "(2) · cached just now") {
+		t.Errorf("header path = %q, want the cached marker", path)
+	}
+
+	// The refresh keeps the cursor on the same database and drops the marker
+	m = send(t, m, key("down"))
+	m = send(t, m, refreshed(m, databasesLoaded{databases: []api.Database{
+		{ID: 3, Name: "Analytics"},
+		{ID: 2, Name: "Warehouse"},
+		{ID: 1, Name: "Sample Database"},
+	}}))
+	if len(m.databases) != 3 || m.cursor != 1 || m.databases[m.cursor].ID != 2 {
+		t.Errorf("cursor on %v of %d databases, want Warehouse", m.databases[m.cursor], len(m.databases))
+	}
+	if _, path := m.header(); strings.Contains(path, "cached") {
+		t.Errorf("header path = %q after the refresh", path)
+	}
+}
+
+func TestCachedRefreshFailed(t *testing.T) {
+	m := newCachedModel(t)
+	m = send(t, m, key("down"), key("enter"))
+	m = send(t, m, refreshed(m, databasesLoaded{err: errors.New("connection refused")}))
+	if len(m.databases) != 2 || !m.showingCached() || !strings.Contains(m.statusMessage, "refresh failed") {
+		t.Errorf("databases = %v, status = %q, want the cached databases kept", m.databases, m.statusMessage)
+	}
+}
+
+func TestCachedRefreshAfterLeaving(t *testing.T) {
+	m := newCachedModel(t)
+
+	// Opening a database before the refresh answers keeps its schemas loading
+	m = send(t, m, key("down"), key("enter"), key("enter"))
+	if m.currentView != viewSchemas || !m.loading {
+		t.Fatalf("view = %v, loading = %v, want the schemas loading", m.currentView, m.loading)
+	}
+	m = send(t, m, refreshed(m, databasesLoaded{databases: fixtureDatabases[:1]}))
+	if !m.loading || len(m.databases) != 1 {
+		t.Errorf("loading = %v with %d databases, want the schemas still loading over the refreshed list", m.loading, len(m.databases))
+	}
+
+	// A refresh landing on the main menu is dropped
+	m = send(t, newCachedModel(t), key("down"), key("enter"))
+	refresh := refreshed(m, databasesLoaded{databases: fixtureDatabases})
+	m = send(t, m, key("esc"), refresh)
+	if m.currentView != viewMainMenu || m.databases != nil {
+		t.Errorf("view = %v with databases %v, want the main menu", m.currentView, m.databases)
+	}
+
+	// So is one that answers after the list was opened again
+	m = send(t, m, key("down"), key("enter"), refresh)
+	if len(m.databases) != 2 || !m.showingCached() {
+		t.Errorf("%d databases, want the cached ones until the newer refresh answers", len(m.databases))
+	}
+}
+
+func TestCachedCollections(t *testing.T) {
+	m := send(t, newCachedModel(t), key("enter"))
+	if m.currentView != viewCollections || m.loading || len(m.collections) != 2 || !m.showingCached() {
+		t.Fatalf("view = %v, loading = %v, %d collections, want the cached collections", m.currentView, m.loading, len(m.collections))
+	}
+
+	// Switching to the tree before the refresh answers leaves it to the tree
+	refresh := refreshed(m, collectionsLoaded{collections: fixtureCollections[:1]})
+	m = send(t, m, key("T"), refresh)
+	if !m.loading || len(m.collections) != 2 {
+		t.Errorf("loading = %v with %d collections, want the tree still loading", m.loading, len(m.collections))
+	}
+	m = send(t, m, collectionsLoaded{collections: fixtureCollections, tree: true})
+	if m.loading || m.collectionTree == nil {
+		t.Error("tree not shown after the refresh of the cached root collections")
+	}
+}
@@ -1,10 +1,12 @@
 package tui
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/amureki/metabase-explorer/pkg/api"
@@ -12,32 +14,18 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func checkLatestVersion() tea.Cmd {
+// checkLatestVersion looks up the latest release at releaseURL with the HTTP
+// client of the release check, see releaseClient
+func checkLatestVersion(client *http.Client, releaseURL string) tea.Cmd {
 	return func() tea.Msg {
-		resp, err := http.Get("https://api.github.com/repos/amureki/metabase-explorer/releases/latest")
-		if err != nil {
-			return versionChecked{err: err}
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != 200 {
-			return versionChecked{err: fmt.Errorf("GitHub API returned status %d", resp.StatusCode)}
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return versionChecked{err: err}
-		}
-
-		var release struct {
-			TagName string `json:"tag_name"`
-		}
-
-		if err := json.Unmarshal(body, &release); err != nil {
-			return versionChecked{err: err}
-		}
+		latest, err := util.LatestVersion(client, releaseURL)
+		return versionChecked{latestVersion: latest, err: err}
+	}
+}
 
-		return versionChecked{latestVersion: release.TagName}
+func testConnection(client *api.MetabaseClient) tea.Cmd {
+	return func() tea.Msg {
+		return connectionTested{err: client.TestConnection()}
 	}
 }
 
@@ -66,19 +54,112 @@ func loadTablesForSchema(client *api.MetabaseClient, databaseID int, schemaName
 			return tablesLoaded{err: err}
 		}
 
-		var filteredTables []api.Table
-		for _, table := range allTables {
-			tableSchema := table.Schema
-			if tableSchema == "" {
-				tableSchema = "default"
-			}
-			if tableSchema == schemaName {
-				filteredTables = append(filteredTables, table)
-			}
+		return tablesLoaded{tables: filterTablesBySchema(allTables, schemaName), err: nil}
+	}
+}
+
+// defaultMaxTablesPerDB is how many tables the flat table list shows before
+// the rest are asked for, unless max_tables_per_db is set
+const defaultMaxTablesPerDB = 500
+
+// loadAllTables loads every table of a database for the flat table list,
+// showing the first limit of them when limit is above zero. Metabase sends
+// them all either way, so the rest are kept for when they are asked for.
+func loadAllTables(client *api.MetabaseClient, databaseID int, limit int) tea.Cmd {
+	return func() tea.Msg {
+		tables, err := client.GetTables(databaseID)
+		if err != nil {
+			return tablesLoaded{err: err}
 		}
+		return cappedTablesLoaded(tables, limit)
+	}
+}
+
+// cappedTablesLoaded sorts the tables of a database for the flat list and
+// caps them at limit, keeping the rest aside
+func cappedTablesLoaded(tables []api.Table, limit int) tablesLoaded {
+	sorted := sortTablesBySchema(tables)
+	shown, total := capTables(sorted, limit)
+	return tablesLoaded{tables: shown, total: total, rest: sorted[len(shown):]}
+}
 
-		return tablesLoaded{tables: filteredTables, err: nil}
+// capTables keeps the first limit tables, or all of them when limit isn't
+// above zero, and returns how many there were
+func capTables(tables []api.Table, limit int) ([]api.Table, int) {
+	total := len(tables)
+	if limit > 0 && total > limit {
+		tables = tables[:limit]
+	}
+	return tables, total
+}
+
+func filterTablesBySchema(tables []api.Table, schemaName string) []api.Table {
+	var filteredTables []api.Table
+	for _, table := range tables {
+		if tableSchemaName(table) == schemaName {
+			filteredTables = append(filteredTables, table)
+		}
 	}
+	return filteredTables
+}
+
+// sortTablesBySchema orders tables by schema, then by name within a schema
+func sortTablesBySchema(tables []api.Table) []api.Table {
+	sorted := make([]api.Table, len(tables))
+	copy(sorted, tables)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		schemaI, schemaJ := tableSchemaName(sorted[i]), tableSchemaName(sorted[j])
+		if schemaI != schemaJ {
+			return schemaI < schemaJ
+		}
+		return strings.ToLower(tableLabel(sorted[i])) < strings.ToLower(tableLabel(sorted[j]))
+	})
+	return sorted
+}
+
+// fieldSortMode is the order of the fields list
+type fieldSortMode int
+
+const (
+	fieldSortPosition fieldSortMode = iota // Column order of the table
+	fieldSortName
+)
+
+func (s fieldSortMode) String() string {
+	if s == fieldSortName {
+		return "name"
+	}
+	return "position"
+}
+
+// sortFields orders fields by their position in the table or by name. Ties
+// are broken by name, so fields without positions still list predictably.
+func sortFields(fields []api.Field, mode fieldSortMode) []api.Field {
+	sorted := make([]api.Field, len(fields))
+	copy(sorted, fields)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if mode == fieldSortPosition && sorted[i].Position != sorted[j].Position {
+			return sorted[i].Position < sorted[j].Position
+		}
+		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+	})
+	return sorted
+}
+
+// tableSchemaName returns the schema a table is listed under; tables without
+// one are grouped as "default", matching util.ExtractSchemas
+func tableSchemaName(table api.Table) string {
+	if table.Schema == "" {
+		return "default"
+	}
+	return table.Schema
+}
+
+func tableLabel(table api.Table) string {
+	if table.DisplayName != "" {
+		return table.DisplayName
+	}
+	return table.Name
 }
 
 func loadFields(client *api.MetabaseClient, tableID int) tea.Cmd {
@@ -88,13 +169,20 @@ func loadFields(client *api.MetabaseClient, tableID int) tea.Cmd {
 	}
 }
 
-func loadCollections(client *api.MetabaseClient) tea.Cmd {
+func loadCollections(client *api.MetabaseClient, includeArchived bool) tea.Cmd {
 	return func() tea.Msg {
-		collections, err := client.GetCollections()
+		collections, err := client.GetCollections(includeArchived)
 		return collectionsLoaded{collections: collections, err: err}
 	}
 }
 
+func loadCollectionTree(client *api.MetabaseClient, includeArchived bool) tea.Cmd {
+	return func() tea.Msg {
+		tree, err := client.GetCollectionTree(includeArchived)
+		return collectionsLoaded{collections: tree, tree: true, err: err}
+	}
+}
+
 func loadCollectionItems(client *api.MetabaseClient, collectionID interface{}) tea.Cmd {
 	return func() tea.Msg {
 		items, err := client.GetCollectionItems(collectionID)
@@ -102,6 +190,57 @@ func loadCollectionItems(client *api.MetabaseClient, collectionID interface{}) t
 	}
 }
 
+// loadContainingCollection fetches a collection, which may be "root", along
+// with the root collections, to open it from outside the collections
+func loadContainingCollection(client *api.MetabaseClient, collectionID interface{}, includeArchived bool) tea.Cmd {
+	return func() tea.Msg {
+		collection, err := client.GetCollection(collectionID)
+		if err != nil {
+			return collectionRevealed{err: err}
+		}
+		collections, err := client.GetCollections(includeArchived)
+		return collectionRevealed{collection: collection, collections: collections, err: err}
+	}
+}
+
+func searchMetabase(clients []api.ProfileClient, query string, filter api.SearchFilter) tea.Cmd {
+	return func() tea.Msg {
+		results, err := api.SearchProfiles(clients, query, filter)
+		return searchCompleted{query: query, results: results, err: err}
+	}
+}
+
+// loadMyContent searches for the content created by the target's user
+func loadMyContent(target api.ProfileClient) tea.Cmd {
+	return func() tea.Msg {
+		user, err := target.Client.GetCurrentUser()
+		if err != nil {
+			return searchCompleted{err: err}
+		}
+		filter := api.SearchFilter{CreatedBy: user.ID, Models: myContentModels}
+		results, err := api.SearchProfiles([]api.ProfileClient{target}, "", filter)
+		return searchCompleted{results: results, err: err}
+	}
+}
+
+// loadRecentChanges lists the content edited lately on the target's instance
+func loadRecentChanges(target api.ProfileClient) tea.Cmd {
+	return func() tea.Msg {
+		results, err := target.Client.GetRecentChanges(recentChangesLimit)
+		for i := range results {
+			results[i].Profile = target.Profile
+		}
+		return searchCompleted{results: results, err: err}
+	}
+}
+
+func loadTableRelated(client *api.MetabaseClient, tableID int) tea.Cmd {
+	return func() tea.Msg {
+		items, err := client.GetTableRelated(tableID)
+		return relatedLoaded{items: items, err: err}
+	}
+}
+
 func loadCardDetail(client *api.MetabaseClient, cardID int) tea.Cmd {
 	return func() tea.Msg {
 		detail, err := client.GetCardDetail(cardID)
@@ -123,8 +262,119 @@ func loadMetricDetail(client *api.MetabaseClient, metricID int) tea.Cmd {
 	}
 }
 
+func syncDatabaseSchema(client *api.MetabaseClient, databaseID int) tea.Cmd {
+	return func() tea.Msg {
+		return schemaSyncRequested{err: client.SyncDatabaseSchema(databaseID)}
+	}
+}
+
+// setArchived archives the target, or unarchives it when it already is
+func setArchived(client *api.MetabaseClient, target archiveTarget) tea.Cmd {
+	return func() tea.Msg {
+		return itemArchived{target: target, err: client.SetArchived(target.model, target.id, !target.archived)}
+	}
+}
+
+// moveItem saves the target in the collection to
+func moveItem(client *api.MetabaseClient, target moveTarget, to api.Collection) tea.Cmd {
+	return func() tea.Msg {
+		collectionID, _ := collectionRef(to.ID).(int)
+		return itemMoved{target: target, to: to, err: client.MoveItem(target.model, target.id, collectionID)}
+	}
+}
+
+// resolveGoto validates the target of a ":" command and fetches whatever
+// the model needs to show it
+func resolveGoto(client *api.MetabaseClient, cmd gotoCommand, includeArchived bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := gotoResolved{command: cmd}
+		notFound := func(err error) error {
+			var statusErr *api.StatusError
+			if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("%s not found", cmd)
+			}
+			return err
+		}
+
+		switch cmd.target {
+		case gotoDatabase:
+			msg.databases, msg.err = client.GetDatabases()
+			if msg.err == nil && findDatabase(msg.databases, cmd.id) == nil {
+				msg.err = fmt.Errorf("%s not found", cmd)
+			}
+		case gotoTable:
+			msg.table, msg.err = client.GetTable(cmd.id)
+			if msg.err != nil {
+				msg.err = notFound(msg.err)
+				break
+			}
+			if msg.databases, msg.err = client.GetDatabases(); msg.err != nil {
+				break
+			}
+			if findDatabase(msg.databases, msg.table.DBID) == nil {
+				msg.err = fmt.Errorf("database %d of %s not found", msg.table.DBID, cmd)
+				break
+			}
+			msg.tables, msg.err = client.GetTables(msg.table.DBID)
+		case gotoCollection:
+			msg.collection, msg.err = client.GetCollection(cmd.id)
+			if msg.err != nil {
+				msg.err = notFound(msg.err)
+				break
+			}
+			msg.collections, msg.err = client.GetCollections(includeArchived)
+		case gotoDashboard:
+			_, msg.err = client.GetDashboardDetail(cmd.id)
+			msg.err = notFound(msg.err)
+		case gotoCard:
+			_, msg.err = client.GetCardDetail(cmd.id)
+			msg.err = notFound(msg.err)
+		}
+
+		return msg
+	}
+}
+
+// spinnerDelay is how long a load runs before the spinner shows, so quick
+// loads don't flash it
+const spinnerDelay = 150 * time.Millisecond
+
+// withSpinner starts a load together with the loading spinner
+func withSpinner(cmd tea.Cmd) tea.Cmd {
+	return tea.Batch(retryable(cmd), delaySpinner())
+}
+
+// delaySpinner shows the spinner once the load has run for spinnerDelay
+func delaySpinner() tea.Cmd {
+	return tea.Tick(spinnerDelay, func(time.Time) tea.Msg {
+		return spinnerDue{}
+	})
+}
+
+// retryable hands the load back along with its result, so a failed load
+// can be issued again with r
+func retryable(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return loadFinished{msg: cmd(), retry: cmd}
+	}
+}
+
 func tickSpinner() tea.Cmd {
 	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
 		return spinnerTick{}
 	})
 }
+
+func runCard(ctx context.Context, client *api.MetabaseClient, cardID int, params []api.ParameterValue) tea.Cmd {
+	return func() tea.Msg {
+		columns, rows, err := client.RunCardContext(ctx, cardID, params...)
+		return queryFinished{cardID: cardID, columns: columns, rows: rows, err: err}
+	}
+}
+
+func exportResults(client *api.MetabaseClient, cardID int, format, filename string, params []api.ParameterValue) tea.Cmd {
+	return func() tea.Msg {
+		err := client.ExportCardToFile(cardID, format, filename, params...)
+		return resultsExported{filename: filename, err: err}
+	}
+}
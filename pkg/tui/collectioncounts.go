@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// States of a collection in itemCounts besides its count
+const (
+	itemCountPending = -1 // Being counted
+	itemCountFailed  = -2 // Couldn't be counted, and isn't asked for again
+)
+
+// collectionCountWorkers is how many collections are counted at once
+const collectionCountWorkers = 4
+
+// scheduleCollectionCounts counts the items of the listed collections that
+// have not been counted yet, when collection_counts is on
+func (m Model) scheduleCollectionCounts() (Model, tea.Cmd) {
+	if !m.collectionCounts || m.offline || m.currentView != viewCollections {
+		return m, nil
+	}
+	var ids []interface{}
+	for _, collection := range m.collections {
+		if _, known := m.itemCounts[listKeyOf("collection", collection.ID)]; !known {
+			ids = append(ids, collection.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return m, nil
+	}
+	m.itemCounts = maps.Clone(m.itemCounts)
+	if m.itemCounts == nil {
+		m.itemCounts = make(map[string]int)
+	}
+	for _, id := range ids {
+		m.itemCounts[listKeyOf("collection", id)] = itemCountPending
+	}
+	client := m.client
+	return m, func() tea.Msg {
+		counts, err := countCollectionItems(client.CountCollectionItems, ids)
+		return collectionItemsCounted{counts: counts, err: err}
+	}
+}
+
+// countCollectionItems counts the items of each collection, a few at a time.
+// Collections that fail are marked as such, and the first error is returned
+// with the counts of the others.
+func countCollectionItems(count func(collectionID interface{}) (int, error), ids []interface{}) (map[string]int, error) {
+	counts := make([]int, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, collectionCountWorkers)
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id interface{}) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			counts[i], errs[i] = count(id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	var firstErr error
+	byKey := make(map[string]int, len(ids))
+	for i, id := range ids {
+		key := listKeyOf("collection", id)
+		if errs[i] != nil {
+			byKey[key] = itemCountFailed
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		byKey[key] = counts[i]
+	}
+	return byKey, firstErr
+}
+
+// applyItemCounts shows the counts that came back, and why some are missing
+func (m Model) applyItemCounts(msg collectionItemsCounted) (Model, tea.Cmd) {
+	m.itemCounts = maps.Clone(m.itemCounts)
+	if m.itemCounts == nil {
+		m.itemCounts = make(map[string]int)
+	}
+	maps.Copy(m.itemCounts, msg.counts)
+	if msg.err != nil {
+		m.statusMessage = "Couldn't count the items of every collection: " + msg.err.Error()
+	}
+	return m, nil
+}
+
+// itemCountLabel is shown after a collection's name: its count, a
+// placeholder while it is counted, or nothing
+func (m Model) itemCountLabel(collection api.Collection) string {
+	if !m.collectionCounts {
+		return ""
+	}
+	count, known := m.itemCounts[listKeyOf("collection", collection.ID)]
+	switch {
+	case !known || count == itemCountFailed:
+		return ""
+	case count == itemCountPending:
+		return "(… items)"
+	case count == 1:
+		return "(1 item)"
+	}
+	return fmt.Sprintf("(%s items)", humanizeCount(count))
+}
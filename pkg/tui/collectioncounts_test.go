@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCountCollectionItems(t *testing.T) {
+	var mu sync.Mutex
+	running, most := 0, 0
+	count := func(collectionID interface{}) (int, error) {
+		mu.Lock()
+		running++
+		most = max(most, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		switch collectionID {
+		case "root":
+			return 12, nil
+		case 7:
+			return 0, errors.New("403 - forbidden")
+		}
+		return collectionID.(int) * 100, nil
+	}
+
+	ids := []interface{}{"root", 5, 6, 7, 8, 9, 10}
+	counts, err := countCollectionItems(count, ids)
+	if err == nil || err.Error() != "403 - forbidden" {
+		t.Errorf("error = %v, want the failed collection's", err)
+	}
+	want := map[string]int{
+		"collection:root": 12, "collection:5": 500, "collection:6": 600, "collection:7": itemCountFailed,
+		"collection:8": 800, "collection:9": 900, "collection:10": 1000,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+	if most > collectionCountWorkers {
+		t.Errorf("%d collections counted at once, want at most %d", most, collectionCountWorkers)
+	}
+}
+
+func TestCollectionCounts(t *testing.T) {
+	m := newTestModel()
+	m.collectionCounts = true
+	m = send(t, m, toCollections...)
+	if m.itemCounts[listKeyOf("collection", 5)] != itemCountPending || m.itemCounts[listKeyOf("collection", 6)] != itemCountPending {
+		t.Fatalf("itemCounts = %v, want both collections being counted", m.itemCounts)
+	}
+	if view := plainView(m); strings.Count(view, "(… items)") != 2 {
+		t.Errorf("View() is missing the placeholders:\n%s", view)
+	}
+
+	m = send(t, m, collectionItemsCounted{counts: map[string]int{listKeyOf("collection", 5): 1, listKeyOf("collection", 6): 2400}})
+	view := plainView(m)
+	if !strings.Contains(view, "Analytics (1 item)") || !strings.Contains(view, "Marketing (2.4k items)") {
+		t.Errorf("View() is missing the counts:\n%s", view)
+	}
+
+	// Counted collections aren't asked for again
+	if _, cmd := m.scheduleCollectionCounts(); cmd != nil {
+		t.Error("scheduleCollectionCounts() counted the collections again")
+	}
+}
+
+func TestCollectionCounts_Off(t *testing.T) {
+	m := send(t, newTestModel(), toCollections...)
+	if m.itemCounts != nil || strings.Contains(plainView(m), "items)") {
+		t.Errorf("itemCounts = %v, want nothing counted without collection_counts", m.itemCounts)
+	}
+}
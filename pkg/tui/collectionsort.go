@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// collectionSortMode is the order of the collections list
+type collectionSortMode int
+
+const (
+	collectionSortDefault collectionSortMode = iota // The order Metabase lists them in
+	collectionSortName
+	collectionSortCreated // Newest first
+)
+
+// collectionSortNames are the values of collection_sort, by mode
+var collectionSortNames = []string{"api", "name", "created"}
+
+func (s collectionSortMode) String() string {
+	switch s {
+	case collectionSortName:
+		return "name"
+	case collectionSortCreated:
+		return "creation date"
+	}
+	return "API order"
+}
+
+// next is the order s switches to
+func (s collectionSortMode) next() collectionSortMode {
+	return (s + 1) % collectionSortMode(len(collectionSortNames))
+}
+
+// parseCollectionSort reads the collection_sort setting, the API order when
+// it is empty
+func parseCollectionSort(name string) (collectionSortMode, error) {
+	if name == "" {
+		return collectionSortDefault, nil
+	}
+	for mode, modeName := range collectionSortNames {
+		if name == modeName {
+			return collectionSortMode(mode), nil
+		}
+	}
+	return collectionSortDefault, fmt.Errorf("invalid collection_sort '%s', use %s", name, strings.Join(collectionSortNames, ", "))
+}
+
+// sortCollections orders collections by name or newest first, keeping the
+// API order otherwise and between ties. Collections without a creation date
+// come after the dated ones. With personalLast, personal collections are
+// grouped after the others, in the same order.
+func sortCollections(collections []api.Collection, mode collectionSortMode, personalLast bool) []api.Collection {
+	sorted := make([]api.Collection, len(collections))
+	copy(sorted, collections)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if personalLast && sorted[i].IsPersonal != sorted[j].IsPersonal {
+			return sorted[j].IsPersonal
+		}
+		switch mode {
+		case collectionSortName:
+			return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+		case collectionSortCreated:
+			createdI, okI := collectionCreated(sorted[i])
+			createdJ, okJ := collectionCreated(sorted[j])
+			if okI != okJ {
+				return okI
+			}
+			return createdI.After(createdJ)
+		}
+		return false
+	})
+	return sorted
+}
+
+func collectionCreated(collection api.Collection) (time.Time, bool) {
+	created, err := time.Parse(time.RFC3339Nano, collection.CreatedAt)
+	return created, err == nil
+}
+
+// sortCollectionTree sorts every level of the tree the same way
+func sortCollectionTree(tree []api.Collection, mode collectionSortMode, personalLast bool) []api.Collection {
+	sorted := sortCollections(tree, mode, personalLast)
+	for i := range sorted {
+		if len(sorted[i].Children) > 0 {
+			sorted[i].Children = sortCollectionTree(sorted[i].Children, mode, personalLast)
+		}
+	}
+	return sorted
+}
+
+// withoutPersonal drops personal collections, at every level of a tree
+func withoutPersonal(collections []api.Collection) []api.Collection {
+	var kept []api.Collection
+	for _, collection := range collections {
+		if collection.IsPersonal {
+			continue
+		}
+		if len(collection.Children) > 0 {
+			collection.Children = withoutPersonal(collection.Children)
+		}
+		kept = append(kept, collection)
+	}
+	return kept
+}
+
+// arrangeCollections sorts collections, or a tree of them, and hides the
+// personal ones when asked to
+func (m Model) arrangeCollections(collections []api.Collection) []api.Collection {
+	if m.hidePersonal {
+		collections = withoutPersonal(collections)
+	}
+	return sortCollectionTree(collections, m.collectionSort, m.personalLast)
+}
+
+// showCollections lists the root collections as they loaded, in the chosen
+// order. They are kept as loaded so the order can change without a reload.
+func (m *Model) showCollections(collections []api.Collection) {
+	m.loadedCollections = collections
+	m.collections = m.arrangeCollections(collections)
+}
+
+// rearrangeCollections lists the loaded collections again after the order
+// or the personal filter changed, keeping the selected collection under the
+// cursor while it is still listed
+func (m *Model) rearrangeCollections() {
+	if len(m.collections) == 0 {
+		return
+	}
+	selected := listKeyOf("collection", m.collections[m.cursor].ID)
+	if m.collectionTree != nil {
+		m.showCollectionTree()
+	} else {
+		m.showCollections(m.loadedCollections)
+	}
+	m.cursor = min(m.cursor, max(len(m.collections)-1, 0))
+	for i, collection := range m.collections {
+		if listKeyOf("collection", collection.ID) == selected {
+			m.cursor = i
+		}
+	}
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+}
+
+// cycleCollectionSort switches the collections list to the next order
+func (m Model) cycleCollectionSort() (Model, tea.Cmd) {
+	m.collectionSort = m.collectionSort.next()
+	m.statusMessage = "Collections sorted by " + m.collectionSort.String()
+	m.rearrangeCollections()
+	return m, nil
+}
+
+// togglePersonal hides personal collections from the collections list, or
+// shows them again
+func (m Model) togglePersonal() (Model, tea.Cmd) {
+	m.hidePersonal = !m.hidePersonal
+	if m.hidePersonal {
+		m.statusMessage = "Personal collections hidden"
+	} else {
+		m.statusMessage = "Personal collections shown"
+	}
+	m.rearrangeCollections()
+	return m, nil
+}
@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var sortedCollections = []api.Collection{
+	{ID: 5, Name: "marketing", CreatedAt: "2024-03-01T10:00:00Z"},
+	{ID: 9, Name: "Ada's Personal Collection", IsPersonal: true, CreatedAt: "2024-05-01T10:00:00Z"},
+	{ID: 6, Name: "Analytics"},
+	{ID: 7, Name: "Finance", CreatedAt: "2024-04-01T10:00:00.123Z"},
+}
+
+func TestSortCollections(t *testing.T) {
+	tests := []struct {
+		mode         collectionSortMode
+		personalLast bool
+		expected     []int
+	}{
+		{collectionSortDefault, false, []int{5, 9, 6, 7}},
+		{collectionSortName, false, []int{9, 6, 7, 5}},
+		{collectionSortCreated, false, []int{9, 7, 5, 6}},
+		{collectionSortDefault, true, []int{5, 6, 7, 9}},
+		{collectionSortName, true, []int{6, 7, 5, 9}},
+		{collectionSortCreated, true, []int{7, 5, 6, 9}},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for _, collection := range sortCollections(sortedCollections, tt.mode, tt.personalLast) {
+			got = append(got, collection.ID.(int))
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("sortCollections(%v, personalLast %v) = %v, want %v", tt.mode, tt.personalLast, got, tt.expected)
+		}
+	}
+	if sortedCollections[0].ID != 5 {
+		t.Error("sortCollections() modified its input")
+	}
+}
+
+func TestParseCollectionSort(t *testing.T) {
+	for name, want := range map[string]collectionSortMode{"": collectionSortDefault, "api": collectionSortDefault, "name": collectionSortName, "created": collectionSortCreated} {
+		if got, err := parseCollectionSort(name); err != nil || got != want {
+			t.Errorf("parseCollectionSort(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := parseCollectionSort("size"); err == nil || !strings.Contains(err.Error(), "use api, name, created") {
+		t.Errorf("parseCollectionSort(size) error = %v, want the valid values listed", err)
+	}
+}
+
+func TestCycleCollectionSort(t *testing.T) {
+	m := send(t, newTestModel(), key("enter"), collectionsLoaded{collections: sortedCollections})
+	m = send(t, m, key("down"), key("down"), key("s"))
+	if m.collections[0].ID != 9 || m.collections[m.cursor].Name != "Analytics" {
+		t.Errorf("s: collections = %+v, cursor on %s, want name order with Analytics still selected", m.collections, m.collections[m.cursor].Name)
+	}
+	if view := plainView(m); !strings.Contains(view, "· sorted by name") || !strings.Contains(view, "s sort by creation date") {
+		t.Errorf("View() is missing the sort order or the next one:\n%s", view)
+	}
+
+	m = send(t, m, key("s"))
+	if m.collections[0].ID != 9 || m.statusMessage != "Collections sorted by creation date" {
+		t.Errorf("s twice: collections = %+v, status = %q, want newest first", m.collections, m.statusMessage)
+	}
+
+	// The order is kept when the list is reloaded
+	m = send(t, m, key("s"), key("s"), collectionsLoaded{collections: sortedCollections})
+	if m.collections[0].ID != 9 || m.collections[1].ID != 6 {
+		t.Errorf("reloaded: collections = %+v, want them still sorted by name", m.collections)
+	}
+}
+
+func TestCollectionSort_PersonalLastTree(t *testing.T) {
+	m := newTestModel()
+	m.collectionSort = collectionSortName
+	m.personalLast = true
+	tree := []api.Collection{
+		{ID: 9, Name: "Ada's Personal Collection", IsPersonal: true},
+		{ID: 6, Name: "Marketing", Children: []api.Collection{{ID: 11, Name: "Social"}, {ID: 10, Name: "Email"}}},
+		{ID: 5, Name: "Analytics"},
+	}
+	m = send(t, m, steps(toCollections, []tea.Msg{key("T"), collectionsLoaded{collections: tree, tree: true}})...)
+
+	var got []int
+	for _, collection := range m.collections {
+		got = append(got, collection.ID.(int))
+	}
+	if want := []int{5, 6, 10, 11, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tree = %v, want %v", got, want)
+	}
+}
+
+func TestTogglePersonal(t *testing.T) {
+	m := newTestModel()
+	m.hidePersonal = true
+	m = send(t, m, key("enter"), collectionsLoaded{collections: sortedCollections})
+	if len(m.collections) != 3 || !strings.Contains(plainView(m), "Collections (3)") {
+		t.Fatalf("hidden: collections = %+v, want the 3 shared ones counted in the header:\n%s", m.collections, plainView(m))
+	}
+
+	m = send(t, m, key("down"), key("p"))
+	if len(m.collections) != 4 || m.collections[m.cursor].Name != "Analytics" {
+		t.Errorf("p: collections = %+v, cursor on %s, want every collection with Analytics still selected", m.collections, m.collections[m.cursor].Name)
+	}
+	if view := plainView(m); !strings.Contains(view, "Collections (4)") || !strings.Contains(view, "p hide personal") {
+		t.Errorf("View() is missing the full count or the hide key:\n%s", view)
+	}
+
+	m = send(t, m, key("up"), key("p"))
+	if len(m.collections) != 3 || m.cursor != 1 || m.statusMessage != "Personal collections hidden" {
+		t.Errorf("p on a personal collection: collections = %+v, cursor = %d, status = %q", m.collections, m.cursor, m.statusMessage)
+	}
+}
+
+func TestTogglePersonal_Tree(t *testing.T) {
+	tree := []api.Collection{
+		{ID: 5, Name: "Analytics"},
+		{ID: 1, Name: "Personal collections", Children: []api.Collection{{ID: 9, Name: "Ada's Personal Collection", IsPersonal: true}}},
+		{ID: 9, Name: "Bo's Personal Collection", IsPersonal: true},
+	}
+	m := send(t, newTestModel(), steps(toCollections, []tea.Msg{key("T"), collectionsLoaded{collections: tree, tree: true}, key("p")})...)
+
+	var got []int
+	for _, collection := range m.collections {
+		got = append(got, collection.ID.(int))
+	}
+	if want := []int{5, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tree = %v, want %v", got, want)
+	}
+}
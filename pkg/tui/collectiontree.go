@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"maps"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadRootCollections loads the collections list: the root collections, or
+// the whole tree in tree mode
+func (m Model) loadRootCollections() tea.Cmd {
+	if m.collectionTreeMode {
+		return loadCollectionTree(m.client, m.showArchived)
+	}
+	return loadCollections(m.client, m.showArchived)
+}
+
+// toggleCollectionTree switches the collections list between the root
+// collections and the tree of every collection
+func (m Model) toggleCollectionTree() (Model, tea.Cmd) {
+	m.collectionTreeMode = !m.collectionTreeMode
+	m.dropRefresh()
+	m.cursor = 0
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(m.loadRootCollections())
+}
+
+// showCollectionTree lists the collections of the tree that are not hidden
+// under a collapsed parent, each parent followed by its children
+func (m *Model) showCollectionTree() {
+	tree := m.arrangeCollections(m.collectionTree)
+	m.collections, m.collectionDepths = flattenCollectionTree(tree, m.expandedCollections)
+}
+
+func flattenCollectionTree(tree []api.Collection, expanded map[string]bool) ([]api.Collection, []int) {
+	var collections []api.Collection
+	var depths []int
+	var walk func(nodes []api.Collection, depth int)
+	walk = func(nodes []api.Collection, depth int) {
+		for _, node := range nodes {
+			collections = append(collections, node)
+			depths = append(depths, depth)
+			if expanded[listKeyOf("collection", node.ID)] {
+				walk(node.Children, depth+1)
+			}
+		}
+	}
+	walk(tree, 0)
+	return collections, depths
+}
+
+// expandAll returns the expanded state with every collection that has
+// children open
+func expandAll(tree []api.Collection) map[string]bool {
+	expanded := make(map[string]bool)
+	var walk func(nodes []api.Collection)
+	walk = func(nodes []api.Collection) {
+		for _, node := range nodes {
+			if len(node.Children) > 0 {
+				expanded[listKeyOf("collection", node.ID)] = true
+				walk(node.Children)
+			}
+		}
+	}
+	walk(tree)
+	return expanded
+}
+
+// treeCollection returns the collection under the cursor in tree mode
+func (m Model) treeCollection() (api.Collection, bool) {
+	if m.currentView != viewCollections || m.collectionTree == nil || m.cursor >= len(m.collections) {
+		return api.Collection{}, false
+	}
+	return m.collections[m.cursor], true
+}
+
+// expandCollection shows the children of the collapsed collection under the
+// cursor. It reports false when there is nothing to expand, so the
+// collection is opened instead.
+func (m Model) expandCollection() (Model, bool) {
+	collection, ok := m.treeCollection()
+	key := listKeyOf("collection", collection.ID)
+	if !ok || len(collection.Children) == 0 || m.expandedCollections[key] {
+		return m, false
+	}
+	// Copied so earlier models keep their own state
+	m.expandedCollections = maps.Clone(m.expandedCollections)
+	if m.expandedCollections == nil {
+		m.expandedCollections = make(map[string]bool)
+	}
+	m.expandedCollections[key] = true
+	// Rows are only added below the cursor, so it stays on the collection
+	m.showCollectionTree()
+	return m, true
+}
+
+// collapseCollection hides the children of the expanded collection under
+// the cursor. It reports false when it isn't expanded, so back leaves the
+// list instead.
+func (m Model) collapseCollection() (Model, bool) {
+	collection, ok := m.treeCollection()
+	key := listKeyOf("collection", collection.ID)
+	if !ok || !m.expandedCollections[key] {
+		return m, false
+	}
+	m.expandedCollections = maps.Clone(m.expandedCollections)
+	delete(m.expandedCollections, key)
+	m.showCollectionTree()
+	return m, true
+}
+
+// collectionDepth is how deep the collection at index sits in the tree, 0
+// outside tree mode
+func (m Model) collectionDepth(index int) int {
+	if index < len(m.collectionDepths) {
+		return m.collectionDepths[index]
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var fixtureCollectionTree = []api.Collection{
+	{ID: float64(5), Name: "Analytics", Children: []api.Collection{
+		{ID: float64(8), Name: "Quarterly", Children: []api.Collection{
+			{ID: float64(9), Name: "Q1"},
+		}},
+		{ID: float64(10), Name: "Finance"},
+	}},
+	{ID: float64(6), Name: "Marketing"},
+}
+
+func TestCollectionTree_Render(t *testing.T) {
+	toTree := steps(toCollections, []tea.Msg{key("T"), collectionsLoaded{collections: fixtureCollectionTree, tree: true}})
+
+	tests := []struct {
+		name     string
+		expanded map[string]bool // Nil keeps the initial state, everything expanded
+		expected []string
+	}{
+		{
+			name: "expanded",
+			expected: []string{
+				"1 ▶ ▾ Analytics",
+				"2     ▾ Quarterly",
+				"3         Q1",
+				"4       Finance",
+				"5     Marketing",
+			},
+		},
+		{
+			name:     "child collapsed",
+			expanded: map[string]bool{"collection:5": true},
+			expected: []string{
+				"1 ▶ ▾ Analytics",
+				"2     ▸ Quarterly",
+				"3       Finance",
+				"4     Marketing",
+			},
+		},
+		{
+			name:     "all collapsed",
+			expanded: map[string]bool{},
+			expected: []string{
+				"1 ▶ ▸ Analytics",
+				"2     Marketing",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel()
+			if tt.expanded != nil {
+				m.expandedCollections = tt.expanded
+			}
+			m = send(t, m, toTree...)
+			if !m.collectionTreeMode {
+				t.Fatal("T did not switch to the collection tree")
+			}
+
+			view := plainView(m)
+			for _, line := range tt.expected {
+				if !strings.Contains(view, line+"\n") {
+					t.Errorf("View() is missing %q:\n%s", line, view)
+				}
+			}
+			if len(m.collections) != len(tt.expected) {
+				t.Errorf("collections = %d entries, want %d", len(m.collections), len(tt.expected))
+			}
+		})
+	}
+}
+
+func TestCollectionTree_Toggle(t *testing.T) {
+	m := send(t, newTestModel(), toCollections...)
+	updated, cmd := m.Update(key("T"))
+	m = updated.(Model)
+	if !m.collectionTreeMode || !m.loading || cmd == nil {
+		t.Fatalf("T: tree mode = %v, loading = %v, cmd = %v, want the tree loading", m.collectionTreeMode, m.loading, cmd != nil)
+	}
+	m = send(t, m, collectionsLoaded{collections: fixtureCollectionTree, tree: true})
+
+	// Opening a nested collection works like from the root list
+	m = send(t, m, key("3"), key("enter"))
+	if m.currentView != viewCollectionItems || m.selectedCollection.Name != "Q1" {
+		t.Errorf("enter on Q1: view = %v, collection = %v, want Q1's items", m.currentView, m.selectedCollection)
+	}
+
+	m = send(t, newTestModel(), toCollections...)
+	m.collectionTreeMode = true
+	m = send(t, m, key("T"), collectionsLoaded{collections: fixtureCollections})
+	if m.collectionTreeMode || m.collectionTree != nil || strings.Contains(plainView(m), "▾") {
+		t.Errorf("T in tree mode: tree mode = %v, want the flat root list back", m.collectionTreeMode)
+	}
+}
+
+func TestCollectionTree_ExpandCollapse(t *testing.T) {
+	toTree := steps(toCollections, []tea.Msg{key("T"), collectionsLoaded{collections: fixtureCollectionTree, tree: true}})
+	names := func(m Model) string {
+		var names []string
+		for i, collection := range m.collections {
+			names = append(names, strings.Repeat(">", m.collectionDepth(i))+collection.Name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	tests := []struct {
+		name       string
+		keys       []string
+		wantView   viewState
+		wantOrder  string
+		wantCursor int
+	}{
+		{name: "initially expanded", wantView: viewCollections, wantOrder: "Analytics >Quarterly >>Q1 >Finance Marketing"},
+		{name: "collapse", keys: []string{"h"}, wantView: viewCollections, wantOrder: "Analytics Marketing"},
+		{name: "collapse and expand", keys: []string{"h", "l"}, wantView: viewCollections, wantOrder: "Analytics >Quarterly >>Q1 >Finance Marketing"},
+		{name: "collapse child", keys: []string{"down", "left"}, wantView: viewCollections, wantOrder: "Analytics >Quarterly >Finance Marketing", wantCursor: 1},
+		{name: "enter expands a collapsed child", keys: []string{"down", "left", "enter"}, wantView: viewCollections, wantOrder: "Analytics >Quarterly >>Q1 >Finance Marketing", wantCursor: 1},
+		{name: "collapse keeps later rows selectable", keys: []string{"h", "down"}, wantView: viewCollections, wantOrder: "Analytics Marketing", wantCursor: 1},
+		{name: "enter opens an expanded collection", keys: []string{"enter"}, wantView: viewCollectionItems},
+		{name: "enter opens a leaf", keys: []string{"5", "enter"}, wantView: viewCollectionItems},
+		{name: "back from a collapsed collection", keys: []string{"h", "h"}, wantView: viewMainMenu},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), toTree...)
+			for _, k := range tt.keys {
+				m = send(t, m, key(k))
+			}
+			if m.currentView != tt.wantView {
+				t.Fatalf("%v: view = %v, want %v", tt.keys, m.currentView, tt.wantView)
+			}
+			if tt.wantView != viewCollections {
+				return
+			}
+			if order := names(m); order != tt.wantOrder {
+				t.Errorf("%v: collections = %q, want %q", tt.keys, order, tt.wantOrder)
+			}
+			if m.cursor != tt.wantCursor {
+				t.Errorf("%v: cursor = %d, want %d", tt.keys, m.cursor, tt.wantCursor)
+			}
+		})
+	}
+}
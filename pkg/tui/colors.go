@@ -1,6 +1,15 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/charmbracelet/lipgloss"
+)
 
 var (
 	ColorPrimary   = lipgloss.Color("4")
@@ -29,20 +38,207 @@ func getItemTypeColor(itemType string) lipgloss.Color {
 		return ColorSecondary
 	case "card":
 		return ColorPrimary
+	case "dataset":
+		return ColorSuccess
 	case "dashboard":
 		return ColorWarning
+	case "metric":
+		return ColorHighlight
+	case "snippet":
+		return ColorString
+	case "timeline":
+		return ColorDate
+	case "pulse":
+		return ColorMuted
 	default:
 		return ColorInfo
 	}
 }
 
+// itemTypeLabel is the badge of an item of the given Metabase model. Models
+// mbx doesn't know are shown as Metabase names them.
+func itemTypeLabel(model string) string {
+	switch model {
+	case "dataset":
+		// Metabase calls models datasets in the API
+		return "model"
+	case "pulse":
+		// Pulses are the dashboard subscriptions of older versions
+		return "subscription"
+	}
+	return model
+}
+
+// itemTypeName names the kind of an item in its details
+func itemTypeName(model string) string {
+	switch model {
+	case "card":
+		return "Question"
+	case "":
+		return ""
+	}
+	label := itemTypeLabel(model)
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// semanticTypeColors are the colors of the semantic types that stand out
+// in the fields list; the others are drawn in ColorInfo
+var semanticTypeColors = map[string]lipgloss.Color{
+	"type/PK": ColorWarning,
+	"type/FK": ColorSecondary,
+}
+
 func getSemanticTypeColor(semanticType string) lipgloss.Color {
 	if semanticType == "" {
 		return ColorMuted
 	}
+	if color, ok := semanticTypeColors[semanticType]; ok {
+		return color
+	}
 	return ColorInfo
 }
 
+// parseHexColor reads a color written as #rrggbb or #rgb
+func parseHexColor(hex string) ([3]int, bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return [3]int{}, false
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return [3]int{}, false
+	}
+	return [3]int{int(value >> 16), int(value >> 8 & 0xff), int(value & 0xff)}, true
+}
+
+// ansiHues are the ANSI colors of the six hues, red to magenta, 60 degrees
+// apart. Themes change how they look but keep their hue.
+var ansiHues = []int{1, 3, 2, 6, 4, 5}
+
+// nearestANSIColor returns the ANSI color of the hue closest to an RGB
+// color, in its bright variant for light colors. Grays, which have no hue,
+// get the muted color.
+func nearestANSIColor(rgb [3]int) lipgloss.Color {
+	r, g, b := float64(rgb[0]), float64(rgb[1]), float64(rgb[2])
+	high, low := max(r, g, b), min(r, g, b)
+	if high == 0 || (high-low)/high < 0.2 {
+		return ColorMuted
+	}
+
+	var hue float64
+	switch high {
+	case r:
+		hue = math.Mod((g-b)/(high-low)+6, 6)
+	case g:
+		hue = (b-r)/(high-low) + 2
+	default:
+		hue = (r-g)/(high-low) + 4
+	}
+	color := ansiHues[int(math.Round(hue))%6]
+	if high > 205 {
+		color += 8
+	}
+	return lipgloss.Color(strconv.Itoa(color))
+}
+
+// colorDepth is how many colors a terminal can show
+type colorDepth int
+
+const (
+	colors16 colorDepth = iota
+	colors256
+	colorsTrue
+)
+
+// terminalColors is the color depth of the terminal mbx runs in
+var terminalColors = detectColorDepth(os.Getenv) // replaced in tests
+
+// detectColorDepth reads the color depth from the environment: terminals
+// showing 24-bit colors announce it in COLORTERM, and 256-color ones in TERM
+func detectColorDepth(getenv func(string) string) colorDepth {
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return colorsTrue
+	}
+	if strings.Contains(getenv("TERM"), "256color") {
+		return colors256
+	}
+	return colors16
+}
+
+// hexColor returns the color to draw a #rrggbb color with, as close as the
+// terminal shows it: the color itself with 24-bit colors, the nearest entry
+// of the 256-color palette, or else the ANSI color of its hue. It is false
+// for text that isn't a hex color.
+func hexColor(hex string) (lipgloss.Color, bool) {
+	rgb, ok := parseHexColor(hex)
+	if !ok {
+		return "", false
+	}
+	switch terminalColors {
+	case colorsTrue:
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])), true
+	case colors256:
+		return nearest256Color(rgb), true
+	}
+	return nearestANSIColor(rgb), true
+}
+
+// cubeLevels are the intensities of each channel in the 6x6x6 color cube of
+// the 256-color palette, which starts at 16
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// nearest256Color returns the entry of the 256-color palette closest to an
+// RGB color, from the color cube or the gray ramp that follows it
+func nearest256Color(rgb [3]int) lipgloss.Color {
+	var cube [3]int
+	var cubeIndex int
+	for i, value := range rgb {
+		level := 0
+		for l := range cubeLevels {
+			if abs(value-cubeLevels[l]) < abs(value-cubeLevels[level]) {
+				level = l
+			}
+		}
+		cube[i] = cubeLevels[level]
+		cubeIndex = cubeIndex*6 + level
+	}
+
+	// The gray ramp runs from 8 to 238 in steps of 10
+	average := (rgb[0] + rgb[1] + rgb[2]) / 3
+	grayStep := min(max((average-8+5)/10, 0), 23)
+	gray := 8 + grayStep*10
+
+	if colorDistance(rgb, [3]int{gray, gray, gray}) < colorDistance(rgb, cube) {
+		return lipgloss.Color(strconv.Itoa(232 + grayStep))
+	}
+	return lipgloss.Color(strconv.Itoa(16 + cubeIndex))
+}
+
+func colorDistance(a, b [3]int) int {
+	distance := 0
+	for i := range a {
+		distance += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return distance
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// collectionColor returns the terminal color of the color a collection was
+// given in Metabase, false when it has none
+func collectionColor(collection api.Collection) (lipgloss.Color, bool) {
+	return hexColor(collection.Color)
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && 
 		   (s == substr || 
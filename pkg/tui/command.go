@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// gotoTarget is the kind of object a ":" command jumps to
+type gotoTarget int
+
+const (
+	gotoDatabase gotoTarget = iota
+	gotoTable
+	gotoCollection
+	gotoDashboard
+	gotoCard
+)
+
+// gotoVerbs maps every accepted verb (and its short forms) to a target
+var gotoVerbs = map[string]gotoTarget{
+	"db":         gotoDatabase,
+	"database":   gotoDatabase,
+	"table":      gotoTable,
+	"collection": gotoCollection,
+	"coll":       gotoCollection,
+	"dashboard":  gotoDashboard,
+	"dash":       gotoDashboard,
+	"card":       gotoCard,
+	"question":   gotoCard,
+}
+
+type gotoCommand struct {
+	target gotoTarget
+	id     int
+}
+
+func (c gotoCommand) String() string {
+	var verb string
+	switch c.target {
+	case gotoDatabase:
+		verb = "db"
+	case gotoTable:
+		verb = "table"
+	case gotoCollection:
+		verb = "collection"
+	case gotoDashboard:
+		verb = "dashboard"
+	case gotoCard:
+		verb = "card"
+	}
+	return fmt.Sprintf("%s %d", verb, c.id)
+}
+
+// parseGotoCommand parses input such as "db 5" or "table 100"
+func parseGotoCommand(input string) (gotoCommand, error) {
+	parts := strings.Fields(input)
+	if len(parts) == 0 {
+		return gotoCommand{}, fmt.Errorf("empty command")
+	}
+
+	target, ok := gotoVerbs[strings.ToLower(parts[0])]
+	if !ok {
+		return gotoCommand{}, fmt.Errorf("unknown command %q", parts[0])
+	}
+	if len(parts) != 2 {
+		return gotoCommand{}, fmt.Errorf("usage: %s <id>", strings.ToLower(parts[0]))
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || id < 1 {
+		return gotoCommand{}, fmt.Errorf("invalid ID %q", parts[1])
+	}
+
+	return gotoCommand{target: target, id: id}, nil
+}
+
+// isGotoInput reports whether the prompt input starts with a go-to verb, in
+// which case it is parsed as a command rather than searched in the palette
+func isGotoInput(input string) bool {
+	parts := strings.Fields(input)
+	if len(parts) == 0 {
+		return false
+	}
+	_, ok := gotoVerbs[strings.ToLower(parts[0])]
+	return ok
+}
+
+// updateCommand handles key presses while the ":" prompt is open
+func (m Model) updateCommand(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.commandMode = false
+		m.commandInput = ""
+		m.commandError = ""
+	case "enter":
+		if !isGotoInput(m.commandInput) {
+			matches := m.paletteMatches()
+			if m.commandCursor >= len(matches) {
+				m.commandError = "no matching action"
+				return m, nil
+			}
+			action := matches[m.commandCursor]
+			m.commandMode = false
+			m.commandInput = ""
+			m.commandError = ""
+			return action.run(m)
+		}
+
+		cmd, err := parseGotoCommand(m.commandInput)
+		if err != nil {
+			m.commandError = err.Error()
+			return m, nil
+		}
+		m.commandMode = false
+		m.commandError = ""
+		m.loading = true
+		m.error = ""
+		return m, withSpinner(resolveGoto(m.client, cmd, m.showArchived))
+	case "up":
+		if m.commandCursor > 0 {
+			m.commandCursor--
+		}
+	case "down":
+		if m.commandCursor < len(m.paletteMatches())-1 {
+			m.commandCursor++
+		}
+	case "backspace":
+		if len(m.commandInput) > 0 {
+			m.commandInput = m.commandInput[:len(m.commandInput)-1]
+		}
+		m.commandCursor = 0
+		m.commandError = ""
+	default:
+		if len(msg.String()) == 1 {
+			m.commandInput += msg.String()
+			m.commandCursor = 0
+			m.commandError = ""
+		}
+	}
+	return m, nil
+}
+
+// applyGoto moves the model to the object resolved by a ":" command, filling
+// in the parent lists so back navigation works as if the user had drilled in
+func (m Model) applyGoto(msg gotoResolved) (Model, tea.Cmd) {
+	if msg.err != nil && msg.start {
+		return m.startFailed(msg)
+	}
+	if msg.err != nil {
+		// Reopen the prompt so the command can be corrected
+		m.loading = false
+		m.commandMode = true
+		m.commandInput = msg.command.String()
+		m.commandError = msg.err.Error()
+		return m, nil
+	}
+
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.numberInput = ""
+	m.cursor = 0
+	m.selectedItem = nil
+	m.itemDetail = nil
+	m.itemStack = nil
+	m.savedSearches = nil
+
+	baseURL := strings.TrimSuffix(m.client.BaseURL, "/")
+
+	switch msg.command.target {
+	case gotoDatabase:
+		m.databases = msg.databases
+		m.selectedDatabase = findDatabase(m.databases, msg.command.id)
+		m.schemas = nil
+		m.tables = nil
+		m.fields = nil
+		if m.flattenTables {
+			m.selectedSchema = nil
+			m.showAllTables = false
+			m.currentView = viewTables
+			return m, loadAllTables(m.client, m.selectedDatabase.ID, m.tableLimit())
+		}
+		m.currentView = viewSchemas
+		return m, loadSchemas(m.client, m.selectedDatabase.ID)
+
+	case gotoTable:
+		m.databases = msg.databases
+		m.selectedDatabase = findDatabase(m.databases, msg.table.DBID)
+		schemaName := msg.table.Schema
+		if schemaName == "" {
+			schemaName = "default"
+		}
+		m.schemas = util.ExtractSchemas(msg.tables)
+		for i := range m.schemas {
+			if m.schemas[i].Name == schemaName {
+				m.selectedSchema = &m.schemas[i]
+			}
+		}
+		if m.flattenTables {
+			m.tables = sortTablesBySchema(msg.tables)
+		} else {
+			m.tables = filterTablesBySchema(msg.tables, schemaName)
+		}
+		m.selectedTable = msg.table
+		for i := range m.tables {
+			if m.tables[i].ID == msg.table.ID {
+				m.selectedTable = &m.tables[i]
+			}
+		}
+		m.fields = nil
+		m.currentView = viewFields
+		return m, loadFields(m.client, m.selectedTable.ID)
+
+	case gotoCollection:
+		return m.openCollection(msg.collection, msg.collections)
+
+	case gotoDashboard:
+		m.loading = false
+		if err := util.OpenInBrowser(fmt.Sprintf("%s/dashboard/%d", baseURL, msg.command.id)); err != nil {
+			m.error = fmt.Sprintf("Failed to open browser: %v", err)
+		}
+
+	case gotoCard:
+		m.loading = false
+		if err := util.OpenInBrowser(fmt.Sprintf("%s/question/%d", baseURL, msg.command.id)); err != nil {
+			m.error = fmt.Sprintf("Failed to open browser: %v", err)
+		}
+	}
+
+	return m, nil
+}
+
+// openCollection lists the items of a collection reached from outside the
+// collections hierarchy, stacking its ancestors so back walks up through them
+func (m Model) openCollection(collection *api.Collection, collections []api.Collection) (Model, tea.Cmd) {
+	m.showCollections(collections)
+	m.collectionStack = nil
+	for i := range collection.EffectiveAncestors {
+		ancestor := collection.EffectiveAncestors[i]
+		// The root collection is the list itself, not a stack entry
+		if ancestor.ID == "root" {
+			continue
+		}
+		m.collectionStack = append(m.collectionStack, &ancestor)
+	}
+	m.selectedCollection = collection
+	m.collectionItems = nil
+	m.currentView = viewCollectionItems
+	return m, loadCollectionItems(m.client, m.selectedCollection.ID)
+}
+
+func findDatabase(databases []api.Database, id int) *api.Database {
+	for i := range databases {
+		if databases[i].ID == id {
+			return &databases[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+)
+
+func TestParseGotoCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    gotoCommand
+		expectError string
+	}{
+		{
+			name:     "database short verb",
+			input:    "db 5",
+			expected: gotoCommand{target: gotoDatabase, id: 5},
+		},
+		{
+			name:     "table with extra spaces",
+			input:    "  table   100 ",
+			expected: gotoCommand{target: gotoTable, id: 100},
+		},
+		{
+			name:     "collection is case-insensitive",
+			input:    "Collection 12",
+			expected: gotoCommand{target: gotoCollection, id: 12},
+		},
+		{
+			name:     "dashboard",
+			input:    "dashboard 7",
+			expected: gotoCommand{target: gotoDashboard, id: 7},
+		},
+		{
+			name:     "question alias",
+			input:    "question 3",
+			expected: gotoCommand{target: gotoCard, id: 3},
+		},
+		{
+			name:        "empty",
+			input:       "   ",
+			expectError: "empty command",
+		},
+		{
+			name:        "unknown verb",
+			input:       "schema 1",
+			expectError: `unknown command "schema"`,
+		},
+		{
+			name:        "missing id",
+			input:       "db",
+			expectError: "usage: db <id>",
+		},
+		{
+			name:        "non-numeric id",
+			input:       "table abc",
+			expectError: `invalid ID "abc"`,
+		},
+		{
+			name:        "zero id",
+			input:       "table 0",
+			expectError: `invalid ID "0"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseGotoCommand(tt.input)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("parseGotoCommand(%q) error = %v, want %q", tt.input, err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGotoCommand(%q) unexpected error = %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("parseGotoCommand(%q) = %+v, want %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyGoto_Table(t *testing.T) {
+	m := Model{client: api.NewMetabaseClient("http://metabase.local", "token", "dev")}
+	table := &api.Table{ID: 100, DBID: 5, Name: "orders", Schema: "sales"}
+
+	m, cmd := m.applyGoto(gotoResolved{
+		command:   gotoCommand{target: gotoTable, id: 100},
+		databases: []api.Database{{ID: 1, Name: "Sample"}, {ID: 5, Name: "Warehouse"}},
+		table:     table,
+		tables: []api.Table{
+			{ID: 99, DBID: 5, Name: "users", Schema: "public"},
+			{ID: 100, DBID: 5, Name: "orders", Schema: "sales"},
+		},
+	})
+
+	if cmd == nil {
+		t.Fatal("applyGoto() returned no command, want fields load")
+	}
+	if m.currentView != viewFields {
+		t.Errorf("applyGoto() view = %v, want %v", m.currentView, viewFields)
+	}
+	if m.selectedDatabase == nil || m.selectedDatabase.Name != "Warehouse" {
+		t.Errorf("applyGoto() selectedDatabase = %+v, want Warehouse", m.selectedDatabase)
+	}
+	if m.selectedSchema == nil || m.selectedSchema.Name != "sales" {
+		t.Errorf("applyGoto() selectedSchema = %+v, want sales", m.selectedSchema)
+	}
+	if len(m.tables) != 1 || m.selectedTable.ID != 100 {
+		t.Errorf("applyGoto() tables = %+v, selectedTable = %+v, want only table 100", m.tables, m.selectedTable)
+	}
+}
+
+func TestApplyGoto_ErrorReopensPrompt(t *testing.T) {
+	m := Model{loading: true}
+
+	m, _ = m.applyGoto(gotoResolved{
+		command: gotoCommand{target: gotoCollection, id: 12},
+		err:     errors.New("collection 12 not found"),
+	})
+
+	if !m.commandMode || m.commandInput != "collection 12" || m.commandError != "collection 12 not found" {
+		t.Errorf("applyGoto() prompt = %v %q %q, want reopened with error", m.commandMode, m.commandInput, m.commandError)
+	}
+	if m.loading {
+		t.Error("applyGoto() left loading set after an error")
+	}
+}
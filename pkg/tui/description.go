@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// descriptionLines wraps the open item's description to the terminal width,
+// capped at 80 columns to keep it readable
+func (m Model) descriptionLines() []string {
+	if m.selectedItem == nil || m.selectedItem.Description == "" {
+		return nil
+	}
+	width := min(80, m.terminalWidth)
+	wrapped := lipgloss.NewStyle().Width(width).Render(m.selectedItem.Description)
+	return strings.Split(wrapped, "\n")
+}
+
+// descriptionLimit is how many description lines fit in the item detail
+// next to the title, metadata and help
+func (m Model) descriptionLimit() int {
+	return max(m.terminalHeight-16, 3)
+}
+
+// descriptionPageSize is how many lines the full description view shows
+func (m Model) descriptionPageSize() int {
+	return max(m.terminalHeight-7, 3) // Header, footer and help
+}
+
+// openDescription shows the whole description of the open item in a
+// scrollable view
+func (m Model) openDescription() (Model, tea.Cmd) {
+	if m.currentView != viewItemDetail || m.loading || len(m.descriptionLines()) == 0 {
+		return m, nil
+	}
+	m.descriptionMode = true
+	m.descriptionOffset = 0
+	return m, nil
+}
+
+// updateDescription handles key presses while the full description is shown
+func (m Model) updateDescription(msg tea.KeyMsg) (Model, tea.Cmd) {
+	lastOffset := max(len(m.descriptionLines())-m.descriptionPageSize(), 0)
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "d", "left", "h", "backspace":
+		m.descriptionMode = false
+	case "up", "k":
+		m.descriptionOffset = max(m.descriptionOffset-1, 0)
+	case "down", "j":
+		m.descriptionOffset = min(m.descriptionOffset+1, lastOffset)
+	case "pgup":
+		m.descriptionOffset = max(m.descriptionOffset-m.descriptionPageSize(), 0)
+	case "pgdown", " ":
+		m.descriptionOffset = min(m.descriptionOffset+m.descriptionPageSize(), lastOffset)
+	}
+	return m, nil
+}
+
+// renderDescription writes the visible part of the full description
+func (m Model) renderDescription(output *strings.Builder) {
+	lines := m.descriptionLines()
+	end := min(m.descriptionOffset+m.descriptionPageSize(), len(lines))
+	for _, line := range lines[m.descriptionOffset:end] {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorPrimary).Render(line))
+		output.WriteString("\n")
+	}
+	if len(lines) > m.descriptionPageSize() {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("lines %d-%d of %d", m.descriptionOffset+1, end, len(lines))))
+		output.WriteString("\n")
+	}
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// longDescriptionModel opens an item whose description wraps to 20 lines in
+// a 40x20 terminal
+func longDescriptionModel(t *testing.T) Model {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, "Paragraph "+strings.Repeat("x", i%5))
+	}
+	m := send(t, newTestModel(), tea.WindowSizeMsg{Width: 40, Height: 20})
+	m.currentView = viewItemDetail
+	m.selectedCollection = &api.Collection{ID: 5, Name: "Analytics"}
+	m.selectedItem = &api.CollectionItem{ID: 21, Name: "Orders by month", Model: "card", Description: strings.Join(lines, "\n")}
+	return m
+}
+
+func TestRenderItemDetail_LongDescription(t *testing.T) {
+	m := longDescriptionModel(t)
+
+	view := plainView(m)
+	if !strings.Contains(view, "Paragraph xxxx\n") || strings.Contains(view, "Paragraph\n") {
+		t.Errorf("View() should show the first 4 description lines only:\n%s", view)
+	}
+	if !strings.Contains(view, "… 16 more lines, press d to read the full description") {
+		t.Errorf("View() is missing the overflow hint:\n%s", view)
+	}
+
+	// Taller terminals show more of it
+	m = send(t, m, tea.WindowSizeMsg{Width: 40, Height: 40})
+	if view := plainView(m); strings.Contains(view, "more lines") {
+		t.Errorf("View() at height 40 should fit the description:\n%s", view)
+	}
+}
+
+func TestDescriptionMode_Scroll(t *testing.T) {
+	m := send(t, longDescriptionModel(t), key("d"))
+	if !m.descriptionMode {
+		t.Fatal("d did not open the full description")
+	}
+
+	m = send(t, m, key("down"), key("down"))
+	view := plainView(m)
+	if !strings.HasPrefix(strings.SplitN(view, "\n", 4)[3], "Paragraph xx") || !strings.Contains(view, "lines 3-15 of 20") {
+		t.Errorf("View() after scrolling two lines:\n%s", view)
+	}
+
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyPgDown}, tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.descriptionOffset != 7 {
+		t.Errorf("descriptionOffset = %d, want 7 (stops at the last page)", m.descriptionOffset)
+	}
+
+	m = send(t, m, key("esc"))
+	if m.descriptionMode || m.currentView != viewItemDetail {
+		t.Errorf("esc: descriptionMode = %v, view = %v, want back on the item detail", m.descriptionMode, m.currentView)
+	}
+}
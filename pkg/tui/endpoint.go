@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// viewEndpoint is the API request that loads the data of the current view,
+// worked out from what is selected
+func (m Model) viewEndpoint() string {
+	switch m.currentView {
+	case viewDatabases:
+		return "GET /api/database"
+	case viewSchemas, viewTables, viewFieldSearch:
+		if m.selectedDatabase != nil {
+			return fmt.Sprintf("GET /api/database/%d/metadata", m.selectedDatabase.ID)
+		}
+	case viewFields:
+		if m.selectedTable != nil {
+			return fmt.Sprintf("GET /api/table/%d/query_metadata", m.selectedTable.ID)
+		}
+	case viewCollections:
+		switch {
+		case !m.collectionTreeMode && m.showArchived:
+			return "GET /api/collection and /api/collection?archived=true"
+		case !m.collectionTreeMode:
+			return "GET /api/collection"
+		case m.showArchived:
+			return "GET /api/collection/tree"
+		}
+		return "GET /api/collection/tree?exclude-archived=true"
+	case viewCollectionItems:
+		if m.selectedCollection != nil {
+			return fmt.Sprintf("GET /api/collection/%v/items", m.selectedCollection.ID)
+		}
+	case viewItemDetail:
+		if m.selectedItem == nil {
+			break
+		}
+		switch m.selectedItem.Model {
+		case "card", "dataset", "metric":
+			return fmt.Sprintf("GET /api/card/%d", m.selectedItem.ID)
+		case "dashboard":
+			return fmt.Sprintf("GET /api/dashboard/%d", m.selectedItem.ID)
+		}
+	case viewQueryResults:
+		if m.selectedItem != nil {
+			return fmt.Sprintf("POST /api/card/%d/query", m.selectedItem.ID)
+		}
+	case viewRelated:
+		if m.relatedTable != nil {
+			return fmt.Sprintf("GET /api/card?f=table&model_id=%d", m.relatedTable.ID)
+		}
+	case viewSearch:
+		switch {
+		case m.myContent:
+			return "GET /api/user/current, then GET /api/search filtered on your user ID"
+		case m.recentChanges:
+			return "GET /api/activity, or GET /api/search on versions without it"
+		}
+		filter := api.SearchFilter{}
+		if m.searchScope != nil {
+			filter.CollectionID = m.searchScope.ID
+		}
+		return "GET " + filter.Path(m.globalQuery)
+	}
+	return ""
+}
+
+// noteEndpoint remembers the endpoint of the current view when the update
+// from before started loading it, either from scratch or behind a cached list
+func (m Model) noteEndpoint(before Model) Model {
+	started := m.loading && (!before.loading || m.currentView != before.currentView)
+	if !started && m.refreshID == before.refreshID {
+		return m
+	}
+	endpoint := m.viewEndpoint()
+	if endpoint == "" || m.endpoints[m.currentView] == endpoint {
+		return m
+	}
+	// Copied so earlier models keep their own endpoints
+	endpoints := maps.Clone(m.endpoints)
+	if endpoints == nil {
+		endpoints = make(map[viewState]string)
+	}
+	endpoints[m.currentView] = endpoint
+	m.endpoints = endpoints
+	return m
+}
+
+// showEndpoint puts the instance and the request behind the current view in
+// the status line
+func (m Model) showEndpoint() (Model, tea.Cmd) {
+	baseURL := strings.TrimSuffix(m.client.BaseURL, "/")
+	endpoint, ok := m.endpoints[m.currentView]
+	if !ok {
+		m.statusMessage = fmt.Sprintf("%s: nothing loaded from the API for this view", baseURL)
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("%s: %s", baseURL, endpoint)
+	return m, nil
+}
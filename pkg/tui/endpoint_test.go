@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestViewEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		msgs     []tea.Msg
+		view     viewState
+		endpoint string
+	}{
+		{name: "databases", msgs: toDatabases, view: viewDatabases, endpoint: "GET /api/database"},
+		{name: "schemas", msgs: steps(toDatabases, []tea.Msg{key("enter")}), view: viewSchemas, endpoint: "GET /api/database/1/metadata"},
+		{name: "tables", msgs: toTables, view: viewTables, endpoint: "GET /api/database/1/metadata"},
+		{name: "fields", msgs: toFields, view: viewFields, endpoint: "GET /api/table/10/query_metadata"},
+		{name: "collections", msgs: toCollections, view: viewCollections, endpoint: "GET /api/collection"},
+		{name: "collection tree", msgs: steps(toCollections, []tea.Msg{key("T")}), view: viewCollections, endpoint: "GET /api/collection/tree?exclude-archived=true"},
+		{name: "collection items", msgs: toCollectionItems, view: viewCollectionItems, endpoint: "GET /api/collection/5/items"},
+		{name: "dashboard", msgs: toDashboard, view: viewItemDetail, endpoint: "GET /api/dashboard/20"},
+		{name: "question", msgs: toItemDetail, view: viewItemDetail, endpoint: "GET /api/card/21"},
+		{name: "query", msgs: steps(toItemDetail, []tea.Msg{key("x")}), view: viewQueryResults, endpoint: "POST /api/card/21/query"},
+		{name: "related", msgs: steps(toFields, []tea.Msg{key("R")}), view: viewRelated, endpoint: "GET /api/card?f=table&model_id=10"},
+		{name: "search", msgs: steps(toCollectionItems, []tea.Msg{key("S"), key("tab"), key("o"), key("r"), key("d"), key("enter")}), view: viewSearch, endpoint: "GET /api/search?q=ord"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			if m.currentView != tt.view {
+				t.Fatalf("currentView = %v, want %v", m.currentView, tt.view)
+			}
+			if got := m.endpoints[tt.view]; got != tt.endpoint {
+				t.Errorf("endpoint = %q, want %q", got, tt.endpoint)
+			}
+			if m = send(t, m, key("i")); m.statusMessage != "http://metabase.local: "+tt.endpoint {
+				t.Errorf("i: status = %q", m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestViewEndpoints_NothingLoaded(t *testing.T) {
+	m := send(t, newTestModel(), key("i"))
+	if m.statusMessage != "http://metabase.local: nothing loaded from the API for this view" {
+		t.Errorf("i on the main menu: status = %q", m.statusMessage)
+	}
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/util"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fieldHit is an entry of the field index of a database
+type fieldHit struct {
+	table api.Table
+	field api.Field
+}
+
+// path names the field as schema.table.field, the text the field search
+// matches against
+func (h fieldHit) path() string {
+	return tableSchemaName(h.table) + "." + h.table.Name + "." + h.field.Name
+}
+
+// buildFieldIndex lists every field of the tables, grouped by schema and
+// table in the order the flat table list uses
+func buildFieldIndex(tables []api.Table) []fieldHit {
+	var index []fieldHit
+	for _, table := range sortTablesBySchema(tables) {
+		for _, field := range sortFields(table.Fields, fieldSortPosition) {
+			index = append(index, fieldHit{table: table, field: field})
+		}
+	}
+	return index
+}
+
+// canSearchFields reports whether a database is open to search the fields of
+func (m Model) canSearchFields() bool {
+	switch m.currentView {
+	case viewSchemas, viewTables, viewFields:
+		return m.selectedDatabase != nil
+	}
+	return false
+}
+
+// openFieldSearch lists every field of the open database, ready to be
+// filtered by typing. The metadata the tables were loaded from has the fields
+// of every table, so one request is enough.
+func (m Model) openFieldSearch() (Model, tea.Cmd) {
+	if !m.canSearchFields() {
+		return m, nil
+	}
+	m.fieldSearchFrom = m.currentView
+	m.fieldSearchCursor = m.cursor
+	m.currentView = viewFieldSearch
+	m.fieldIndex = nil
+	m.databaseTables = nil
+	m.searchMode = true
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.numberInput = ""
+	m.cursor = 0
+	m.viewportStart = 0
+	m.error = ""
+
+	if tables := m.prefetchedTables(m.selectedDatabase.ID); tables != nil {
+		m.databaseTables = tables
+		m.fieldIndex = buildFieldIndex(tables)
+		return m, nil
+	}
+	m.loading = true
+	return m, withSpinner(loadFieldIndex(m.client, m.selectedDatabase.ID))
+}
+
+func loadFieldIndex(client *api.MetabaseClient, databaseID int) tea.Cmd {
+	return func() tea.Msg {
+		tables, err := client.GetTables(databaseID)
+		return fieldIndexLoaded{databaseID: databaseID, tables: tables, err: err}
+	}
+}
+
+// applyFieldIndex shows the fields of the database once its metadata loaded
+func (m Model) applyFieldIndex(msg fieldIndexLoaded) (Model, tea.Cmd) {
+	// The index of a database that was left is dropped
+	if m.currentView != viewFieldSearch || m.selectedDatabase == nil || m.selectedDatabase.ID != msg.databaseID {
+		return m, nil
+	}
+	m.loading = false
+	if msg.err != nil {
+		m.error = msg.err.Error()
+		return m, nil
+	}
+	m.databaseTables = msg.tables
+	m.fieldIndex = buildFieldIndex(msg.tables)
+	if m.searchMode && m.searchQuery != "" {
+		// Typed while the metadata loaded
+		m.updateSearch()
+	}
+	return m, nil
+}
+
+// openFieldHit opens the fields of the table a hit is in, with the cursor
+// on the field. The table list and schema are filled in so back walks up
+// from the table as if it had been opened by drilling in.
+func (m Model) openFieldHit(index int) (Model, tea.Cmd) {
+	hit := m.fieldIndex[index]
+	schemaName := tableSchemaName(hit.table)
+
+	m.schemas = util.ExtractSchemas(m.databaseTables)
+	m.selectedSchema = &api.Schema{Name: schemaName}
+	for i := range m.schemas {
+		if m.schemas[i].Name == schemaName {
+			m.selectedSchema = &m.schemas[i]
+		}
+	}
+	if m.flattenTables {
+		// The whole list, so the table is in it whatever the cap
+		m.tables = sortTablesBySchema(m.databaseTables)
+		m.tablesTotal = 0
+		m.showAllTables = true
+	} else {
+		m.tables = filterTablesBySchema(m.databaseTables, schemaName)
+	}
+	m.selectedTable = &hit.table
+	for i := range m.tables {
+		if m.tables[i].ID == hit.table.ID {
+			m.selectedTable = &m.tables[i]
+		}
+	}
+
+	m.fieldIndex = nil
+	m.databaseTables = nil
+	m.fields = nil
+	m.selectedField = nil
+	m.currentView = viewFields
+	m.cursor = 0
+	m.jumpSelect = listKeyOf("field", hit.field.ID)
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(loadFields(m.client, m.selectedTable.ID))
+}
+
+// renderFieldSearch lists the fields of the database as schema.table.field
+// with their type
+func (m Model) renderFieldSearch(output *strings.Builder) {
+	if len(m.fieldIndex) == 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No fields found in this database"))
+		output.WriteString("\n")
+		return
+	}
+
+	var itemsToShow []int
+	if m.searchMode && m.searchQuery != "" && len(m.filteredIndices) > 0 {
+		itemsToShow = m.filteredIndices
+	} else if m.searchMode && m.searchQuery != "" {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No matches found"))
+		return
+	} else {
+		for i := range m.fieldIndex {
+			itemsToShow = append(itemsToShow, i)
+		}
+	}
+
+	viewportEnd := min(m.viewportStart+m.viewportHeight, len(itemsToShow))
+	if m.viewportStart > 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↑ ... %d-%d of %d fields", m.viewportStart+1, viewportEnd, len(itemsToShow))))
+		output.WriteString("\n")
+	}
+
+	for i := m.viewportStart; i < viewportEnd; i++ {
+		hit := m.fieldIndex[itemsToShow[i]]
+		if len(itemsToShow) < 10 {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%d ", i+1)))
+		} else {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%02d ", i+1)))
+		}
+
+		fieldType := strings.TrimPrefix(hit.field.BaseType, "type/")
+		path := m.trimText(hit.path(), m.terminalWidth-len(fieldType)-9)
+		if i == m.cursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + path))
+		} else {
+			output.WriteString("  " + path)
+		}
+		if fieldType != "" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("[" + fieldType + "]"))
+		}
+		output.WriteString("\n")
+	}
+
+	if viewportEnd < len(itemsToShow) {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↓ ... %d-%d of %d fields", m.viewportStart+1, viewportEnd, len(itemsToShow))))
+		output.WriteString("\n")
+	}
+}
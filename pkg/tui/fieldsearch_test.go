@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fieldSearchTables is a database with two schemas for the field search
+var fieldSearchTables = []api.Table{
+	{ID: 11, Name: "PEOPLE", Schema: "PUBLIC", Fields: []api.Field{
+		{ID: 110, Name: "NAME", BaseType: "type/Text", Position: 1},
+		{ID: 111, Name: "ID", BaseType: "type/BigInteger", Position: 0},
+	}},
+	{ID: 10, Name: "ORDERS", Schema: "PUBLIC", Fields: []api.Field{
+		{ID: 100, Name: "ID", BaseType: "type/BigInteger", Position: 0},
+		{ID: 101, Name: "CUSTOMER_ID", BaseType: "type/Integer", Position: 1},
+	}},
+	{ID: 30, Name: "events", Schema: "analytics", Fields: []api.Field{
+		{ID: 300, Name: "customer_id", BaseType: "type/Integer"},
+	}},
+}
+
+func TestBuildFieldIndex(t *testing.T) {
+	index := buildFieldIndex(fieldSearchTables)
+
+	var paths []string
+	for _, hit := range index {
+		paths = append(paths, hit.path())
+	}
+	want := []string{
+		"PUBLIC.ORDERS.ID",
+		"PUBLIC.ORDERS.CUSTOMER_ID",
+		"PUBLIC.PEOPLE.ID",
+		"PUBLIC.PEOPLE.NAME",
+		"analytics.events.customer_id",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+}
+
+func TestFieldSearch(t *testing.T) {
+	m := send(t, newTestModel(), steps(toDatabases, []tea.Msg{
+		key("enter"),
+		schemasLoaded{schemas: []api.Schema{{Name: "PUBLIC"}, {Name: "analytics"}}},
+		key("F"),
+	})...)
+	if m.currentView != viewFieldSearch || !m.loading || !m.searchMode {
+		t.Fatalf("view = %v, loading = %v, searchMode = %v, want the field search loading", m.currentView, m.loading, m.searchMode)
+	}
+
+	m = send(t, m, fieldIndexLoaded{databaseID: 1, tables: fieldSearchTables}, key("c"), key("u"), key("s"), key("t"))
+	if len(m.fieldIndex) != 5 {
+		t.Fatalf("fieldIndex has %d fields, want 5", len(m.fieldIndex))
+	}
+	var matches []string
+	for _, index := range m.filteredIndices {
+		matches = append(matches, m.fieldIndex[index].path())
+	}
+	if want := []string{"PUBLIC.ORDERS.CUSTOMER_ID", "analytics.events.customer_id"}; !reflect.DeepEqual(matches, want) {
+		t.Fatalf("matches for %q = %q, want %q", m.searchQuery, matches, want)
+	}
+	if _, path := m.header(); path != "Databases > Sample Database > Field search (5) · filtered: cust (2 matches)" {
+		t.Errorf("header path = %q", path)
+	}
+
+	// The hit opens its table's fields with the cursor on it once they load
+	m = send(t, m, key("down"), key("enter"))
+	if m.currentView != viewFields || m.selectedTable == nil || m.selectedTable.ID != 30 || m.selectedSchema.Name != "analytics" {
+		t.Fatalf("view = %v, table = %v, want the fields of analytics.events", m.currentView, m.selectedTable)
+	}
+	m = send(t, m, fieldsLoaded{fields: fieldSearchTables[2].Fields})
+	if m.cursor != 0 || m.fields[m.cursor].ID != 300 {
+		t.Errorf("cursor on field %d, want 300", m.fields[m.cursor].ID)
+	}
+	if len(m.tables) != 1 || m.tables[0].ID != 30 {
+		t.Errorf("tables = %v, want the tables of the analytics schema", m.tables)
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewTables || m.selectedSchema.Name != "analytics" {
+		t.Errorf("back went to view %v, want the analytics tables", m.currentView)
+	}
+}
+
+func TestFieldSearchBack(t *testing.T) {
+	m := send(t, newTestModel(), steps(toTables, []tea.Msg{key("down"), key("F")})...)
+	m = send(t, m, fieldIndexLoaded{databaseID: 1, tables: fieldSearchTables})
+	if m.currentView != viewFieldSearch || len(m.fieldIndex) != 5 {
+		t.Fatalf("view = %v with %d fields, want the field search", m.currentView, len(m.fieldIndex))
+	}
+
+	// Esc first ends the filter, then leaves the field search
+	m = send(t, m, key("esc"), key("esc"))
+	if m.currentView != viewTables || m.cursor != 1 || m.fieldIndex != nil {
+		t.Errorf("view = %v, cursor = %d, want the tables with the cursor where it was", m.currentView, m.cursor)
+	}
+
+	// An index that loads after the search was left is dropped
+	m = send(t, m, fieldIndexLoaded{databaseID: 1, tables: fieldSearchTables})
+	if m.fieldIndex != nil {
+		t.Error("index of a field search that was left was kept")
+	}
+}
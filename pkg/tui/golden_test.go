@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files in testdata")
+
+// assertGolden compares output with testdata/<name>.golden, or rewrites the
+// file when the tests run with -update
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatalf("MkdirAll() unexpected error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("WriteFile() unexpected error = %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("View() does not match %s (run with -update to accept)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+// Message sequences that navigate a fresh model to each view
+var (
+	toDatabases = []tea.Msg{key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases}}
+	toSchemas   = steps(toDatabases, []tea.Msg{key("enter"), schemasLoaded{schemas: fixtureSchemas}})
+	toTables    = steps(toSchemas, []tea.Msg{key("enter"), tablesLoaded{tables: fixtureTables}})
+	toFields    = steps(toTables, []tea.Msg{key("enter"), fieldsLoaded{fields: fixtureFields}})
+
+	toCollections     = []tea.Msg{key("enter"), collectionsLoaded{collections: fixtureCollections}}
+	toCollectionItems = steps(toCollections, []tea.Msg{key("enter"), collectionItemsLoaded{items: fixtureCollectionItems}})
+	toDashboard       = steps(toCollectionItems, []tea.Msg{key("enter"), fixtureDashboard})
+	toItemDetail      = steps(toCollectionItems, []tea.Msg{key("down"), key("enter"), cardDetailLoaded{detail: &api.CardDetail{
+		ID:        21,
+		Name:      "Orders by month",
+		CreatedAt: "2025-01-15T09:30:00Z",
+		UpdatedAt: "2025-03-02T17:05:00Z",
+		Creator:   &api.UserInfo{FirstName: "Ada", LastName: "Lovelace"},
+	}}})
+)
+
+// steps concatenates message sequences without sharing their backing arrays
+func steps(groups ...[]tea.Msg) []tea.Msg {
+	var msgs []tea.Msg
+	for _, group := range groups {
+		msgs = append(msgs, group...)
+	}
+	return msgs
+}
+
+func TestViewGolden(t *testing.T) {
+	search := func(query string) []tea.Msg {
+		msgs := []tea.Msg{key("/")}
+		for _, r := range query {
+			msgs = append(msgs, key(string(r)))
+		}
+		return msgs
+	}
+
+	tests := []struct {
+		name string
+		msgs []tea.Msg
+		slow bool // The load has run long enough to show the spinner
+	}{
+		{name: "main_menu"},
+		{name: "databases", msgs: toDatabases},
+		{name: "databases_selected", msgs: steps(toDatabases, []tea.Msg{key("down")})},
+		{name: "databases_empty", msgs: []tea.Msg{key("down"), key("enter"), databasesLoaded{}}},
+		{name: "databases_filtered", msgs: steps(toDatabases, search("ware"))},
+		{name: "schemas", msgs: toSchemas},
+		{name: "schemas_empty", msgs: steps(toDatabases, []tea.Msg{key("enter"), schemasLoaded{}})},
+		{name: "tables", msgs: toTables},
+		{name: "tables_selected", msgs: steps(toTables, []tea.Msg{key("down")})},
+		{name: "tables_empty", msgs: steps(toSchemas, []tea.Msg{key("enter"), tablesLoaded{}})},
+		{name: "tables_filtered", msgs: steps(toTables, search("peo"))},
+		{name: "fields", msgs: toFields},
+		{name: "fields_selected", msgs: steps(toFields, []tea.Msg{key("down")})},
+		{name: "fields_empty", msgs: steps(toTables, []tea.Msg{key("enter"), fieldsLoaded{}})},
+		{name: "fields_filtered", msgs: steps(toFields, search("tot"))},
+		{name: "fields_sorted", msgs: steps(toFields, []tea.Msg{key("s")})},
+		{name: "fields_sorted_filtered", msgs: steps(toFields, []tea.Msg{key("s")}, search("a"))},
+		{name: "collections", msgs: toCollections},
+		{name: "collections_selected", msgs: steps(toCollections, []tea.Msg{key("down")})},
+		{name: "collections_empty", msgs: []tea.Msg{key("enter"), collectionsLoaded{}}},
+		{name: "collections_filtered", msgs: steps(toCollections, search("mark"))},
+		{name: "collection_items", msgs: toCollectionItems},
+		{name: "collection_items_selected", msgs: steps(toCollectionItems, []tea.Msg{key("down")})},
+		{name: "collection_items_empty", msgs: steps(toCollections, []tea.Msg{key("enter"), collectionItemsLoaded{}})},
+		{name: "collection_items_filtered", msgs: steps(toCollectionItems, search("rev"))},
+		{name: "item_detail", msgs: toItemDetail},
+		{name: "related", msgs: steps(toTables, []tea.Msg{key("R"), relatedLoaded{items: fixtureRelated}, key("down")})},
+		{name: "related_empty", msgs: steps(toFields, []tea.Msg{key("R"), relatedLoaded{}})},
+		{name: "search_prompt", msgs: []tea.Msg{key("S"), key("o"), key("r")}},
+		{name: "search_results", msgs: []tea.Msg{key("S"), key("o"), key("enter"), searchCompleted{query: "o", results: fixtureSearchResults}}},
+		{name: "search_results_empty", msgs: []tea.Msg{key("S"), key("o"), key("enter"), searchCompleted{query: "o"}}},
+		{name: "dashboard_cards", msgs: steps(toDashboard, []tea.Msg{key("down")})},
+		{name: "dashboard_card_detail", msgs: steps(toDashboard, []tea.Msg{key("down"), key("enter"), cardDetailLoaded{detail: &api.CardDetail{ID: 22, Name: "Top products"}}})},
+		{name: "fields_loading", msgs: steps(toTables, []tea.Msg{key("enter")}), slow: true},
+		{name: "item_detail_loading", msgs: steps(toCollectionItems, []tea.Msg{key("down"), key("enter")}), slow: true},
+		{name: "query_running", msgs: steps(toItemDetail, []tea.Msg{key("x")}), slow: true},
+		{name: "query_results", msgs: steps(toItemDetail, []tea.Msg{key("x"), fixtureQueryResult})},
+		{name: "query_results_empty", msgs: steps(toItemDetail, []tea.Msg{key("x"), queryFinished{cardID: 21, columns: []string{"Month"}}})},
+		{name: "query_parameters", msgs: steps(toParamForm, []tea.Msg{key("down"), key("enter")})},
+		{name: "help", msgs: []tea.Msg{key("?")}},
+		{name: "help_selected", msgs: []tea.Msg{key("?"), key("down")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			if tt.slow {
+				m.loadingSince = m.loadingSince.Add(-spinnerDelay)
+				m = send(t, m, spinnerDue{})
+			}
+			assertGolden(t, tt.name, plainView(m))
+		})
+	}
+}
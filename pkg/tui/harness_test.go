@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Fixtures shared by the model tests. They describe a small instance with
+// one database, one schema, a couple of tables and a collection tree.
+var (
+	fixtureDatabases = []api.Database{
+		{ID: 1, Name: "Sample Database", Engine: "h2"},
+		{ID: 2, Name: "Warehouse", Engine: "postgres"},
+	}
+	fixtureSchemas = []api.Schema{
+		{Name: "PUBLIC", TableCount: 2},
+	}
+	fixtureTables = []api.Table{
+		{ID: 10, Name: "ORDERS", DisplayName: "Orders", Schema: "PUBLIC", DBID: 1},
+		{ID: 11, Name: "PEOPLE", DisplayName: "People", Schema: "PUBLIC", DBID: 1},
+	}
+	fixtureFields = []api.Field{
+		{ID: 100, Name: "ID", DisplayName: "ID", BaseType: "type/BigInteger", SemanticType: "type/PK"},
+		{ID: 101, Name: "TOTAL", DisplayName: "Total", BaseType: "type/Float"},
+	}
+	fixtureCollections = []api.Collection{
+		{ID: 5, Name: "Analytics"},
+		{ID: 6, Name: "Marketing"},
+	}
+	fixtureCollectionItems = []api.CollectionItem{
+		{ID: 20, Name: "Revenue", Model: "dashboard"},
+		{ID: 21, Name: "Orders by month", Model: "card"},
+		{ID: 7, Name: "Archive", Model: "collection"},
+	}
+	fixtureDashboard = dashboardDetailLoaded{detail: &api.DashboardDetail{
+		ID:   20,
+		Name: "Revenue",
+		Dashcards: []api.DashboardCard{
+			{ID: 1, CardID: intPtr(21), Row: 0, Card: &api.DashboardCardInfo{ID: 21, Name: "Orders by month", Display: "line"}},
+			{ID: 2, Row: 2, Card: &api.DashboardCardInfo{Display: "text"}},
+			{ID: 3, CardID: intPtr(22), Row: 4, Card: &api.DashboardCardInfo{ID: 22, Name: "Top products", Display: "table"}},
+		},
+	}}
+	fixtureRelated = []api.CollectionItem{
+		{ID: 20, Name: "Revenue", Model: "dashboard"},
+		{ID: 21, Name: "Orders by month", Model: "card"},
+		{ID: 23, Name: "Orders model", Model: "dataset"},
+	}
+	fixtureSearchResults = []api.SearchResult{
+		{ID: 10, Name: "Orders", Model: "table", DatabaseID: 1},
+		{ID: 21, Name: "Orders by month", Model: "card", ModeratedStatus: "verified"},
+		{ID: 20, Name: "Revenue", Model: "dashboard"},
+	}
+	fixtureQueryResult = queryFinished{
+		cardID:  21,
+		columns: []string{"Month", "Orders", "Top product"},
+		rows: [][]interface{}{
+			{"2025-01", json.Number("1520"), "Rustic Paper Wallet"},
+			{"2025-02", json.Number("1388"), "An exceptionally long product name that will not fit"},
+			{"2025-03", json.Number("97"), nil},
+		},
+	}
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// newTestModel returns a model on the main menu backed by a client for an
+// instance that is never contacted
+func newTestModel() Model {
+	return NewModelWithClient(api.NewMetabaseClient("http://metabase.local", "token", "dev"), "v1.0.0")
+}
+
+// send feeds messages through Update in order, dropping the commands they
+// return; results are injected as their *Loaded messages instead
+func send(t *testing.T, m Model, msgs ...tea.Msg) Model {
+	t.Helper()
+	for _, msg := range msgs {
+		updated, _ := m.Update(msg)
+		next, ok := updated.(Model)
+		if !ok {
+			t.Fatalf("Update(%T) returned %T, want Model", msg, updated)
+		}
+		m = next
+	}
+	return m
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "space":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// plainView renders the model without colors or trailing padding, so the
+// output only depends on layout
+func plainView(m Model) string {
+	lines := strings.Split(stripANSI(m.View()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// viewHeader returns the title and breadcrumb lines of a rendered view
+func viewHeader(m Model) string {
+	lines := strings.SplitN(plainView(m), "\n", 3)
+	return strings.Join(lines[:2], "\n")
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// header returns the title and the path line shown above every view
+func (m Model) header() (string, string) {
+	if m.profileMode {
+		return fmt.Sprintf("Metabase Explorer %s | Profiles", m.Version), "Switch profile"
+	}
+	if m.moveMode {
+		return fmt.Sprintf("Metabase Explorer %s | Move", m.Version), fmt.Sprintf("Move %s to", m.moving.name)
+	}
+
+	title := fmt.Sprintf("Metabase Explorer %s", m.Version)
+	if name := m.viewTitle(); name != "" {
+		title += " | " + name
+	}
+	if m.jumpMode {
+		return title, m.numberedBreadcrumbPath()
+	}
+
+	path := m.location()
+	count, isList := m.listCount()
+	if !isList {
+		return title, path
+	}
+	// A list that is still loading has no count yet
+	if count != "" {
+		path += " (" + count + ")"
+	}
+	if m.showingCached() {
+		path += " · cached " + formatRelativeTime(m.cachedAt, time.Now())
+	}
+	if m.selecting() {
+		path += fmt.Sprintf(" · %d selected", len(m.selectedRows()))
+	}
+	if m.listSort() != "" {
+		path += " · sorted by " + m.listSort()
+	}
+	if m.searchMode && m.searchQuery != "" {
+		path += fmt.Sprintf(" · filtered: %s (%s)", m.searchQuery, pluralize(len(m.filteredIndices), "match", "matches"))
+	}
+	return title, path
+}
+
+// viewTitle names the current view after the app name in the title
+func (m Model) viewTitle() string {
+	switch m.currentView {
+	case viewDatabases:
+		return "Databases"
+	case viewCollections:
+		return "Collections"
+	case viewCollectionItems:
+		return "Collection items"
+	case viewItemDetail:
+		return "Item Details"
+	case viewQueryResults:
+		return "Query results"
+	case viewSchemas:
+		return "Database schemas"
+	case viewTables:
+		if m.selectedSchema == nil {
+			// Flat list of every table in the database
+			return "Database tables"
+		}
+		return "Schema tables"
+	case viewFields:
+		return "Table fields"
+	case viewSearch:
+		if m.myContent {
+			return "My content"
+		}
+		if m.recentChanges {
+			return "Recent changes"
+		}
+		return "Search"
+	case viewRelated:
+		return "Related questions"
+	case viewFieldDetail:
+		return "Field details"
+	case viewFieldSearch:
+		return "Field search"
+	}
+	return ""
+}
+
+// location is where the current view is, the breadcrumb path for the
+// database and collection hierarchies
+func (m Model) location() string {
+	switch m.currentView {
+	case viewMainMenu:
+		return "Main Menu"
+	case viewSearch:
+		if m.myContent {
+			return "My content"
+		}
+		if m.recentChanges {
+			return "Recent changes"
+		}
+		if m.searchScope != nil {
+			return fmt.Sprintf("Searching in: %s > %q", m.searchScope.Name, m.globalQuery)
+		}
+		path := fmt.Sprintf("Search %q", m.globalQuery)
+		if targets := m.searchProfileNames(); len(targets) > 1 {
+			path += " in " + strings.Join(targets, ", ")
+		}
+		return path
+	}
+	return breadcrumbPath(m.breadcrumbs())
+}
+
+// listCount is how many entries the current list has, as shown in the
+// header, and whether the view is a list at all. The count is empty while
+// an empty list loads.
+func (m Model) listCount() (string, bool) {
+	var count int
+	switch m.currentView {
+	case viewDatabases:
+		count = len(m.databases)
+	case viewCollections:
+		count = len(m.collections)
+	case viewCollectionItems:
+		count = len(m.collectionItems)
+	case viewSchemas:
+		count = len(m.schemas)
+	case viewTables:
+		if m.tablesCapped() {
+			return fmt.Sprintf("showing %d of %d", len(m.tables), m.tablesTotal), true
+		}
+		count = len(m.tables)
+	case viewFields:
+		count = len(m.fields)
+	case viewSearch:
+		count = len(m.searchResults)
+	case viewRelated:
+		count = len(m.relatedItems)
+	case viewFieldSearch:
+		count = len(m.fieldIndex)
+	case viewQueryResults:
+		if len(m.queryRows) == 0 {
+			return "", true
+		}
+		return pluralize(len(m.queryRows), "row", "rows"), true
+	default:
+		return "", false
+	}
+	if count == 0 && m.loading {
+		return "", true
+	}
+	return humanizeCount(count), true
+}
+
+// listSort names the order of the current list when it isn't the default
+func (m Model) listSort() string {
+	if m.currentView == viewFields && m.fieldSort != fieldSortPosition {
+		return m.fieldSort.String()
+	}
+	if m.currentView == viewCollections && m.collectionSort != collectionSortDefault {
+		return m.collectionSort.String()
+	}
+	if m.currentView == viewSearch && m.searchSort != searchSortRelevance {
+		return m.searchSortLabel(m.searchSort)
+	}
+	return ""
+}
+
+// pluralize formats a count with the singular or plural noun
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return humanizeCount(n) + " " + singular
+	}
+	return humanizeCount(n) + " " + plural
+}
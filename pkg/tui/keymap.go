@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Actions that can be bound to other keys in the keymap section of the config
+const (
+	actionUp      = "up"
+	actionDown    = "down"
+	actionBack    = "back"
+	actionForward = "forward"
+	actionSearch  = "search"
+	actionWeb     = "web"
+	actionQuit    = "quit"
+	actionRefresh = "refresh"
+	actionHome    = "home"
+)
+
+// reservedKeys run fixed commands, which a binding would shadow since bound
+// keys are looked up first
+var reservedKeys = []string{
+	"?", ":", " ", "ctrl+y", "pgup", "pgdown",
+	"0", "1", "2", "3", "4", "5", "6", "7", "8", "9",
+	"a", "b", "c", "d", "e", "f", "i", "m", "n", "o", "p", "r", "s", "t", "v", "x", "y",
+	"A", "B", "D", "F", "H", "K", "L", "M", "P", "R", "S", "T", "U",
+}
+
+// keyMap lists the keys bound to each action, in the order they are shown
+type keyMap map[string][]string
+
+// defaultKeyMap binds the keys mbx has always used
+func defaultKeyMap() keyMap {
+	return keyMap{
+		actionUp:      {"up", "k"},
+		actionDown:    {"down", "j"},
+		actionBack:    {"left", "h", "esc", "backspace"},
+		actionForward: {"right", "l", "enter"},
+		actionSearch:  {"/"},
+		actionWeb:     {"w"},
+		actionQuit:    {"q", "ctrl+c"},
+		actionRefresh: {"ctrl+r"},
+		actionHome:    {"~"},
+	}
+}
+
+// newKeyMap applies the configured bindings over the defaults. Each entry
+// replaces all keys of its action. Unknown actions, actions left without a
+// key, keys bound to two actions and keys of fixed commands are rejected.
+func newKeyMap(bindings map[string][]string) (keyMap, error) {
+	keys := defaultKeyMap()
+	for action, bound := range bindings {
+		if _, ok := keys[action]; !ok {
+			return nil, fmt.Errorf("unknown action '%s', expected one of %s", action, strings.Join(keys.actions(), ", "))
+		}
+		if len(bound) == 0 {
+			return nil, fmt.Errorf("no keys given for '%s'", action)
+		}
+		for _, key := range bound {
+			if slices.Contains(reservedKeys, key) {
+				return nil, fmt.Errorf("key '%s' of '%s' already runs another command", key, action)
+			}
+		}
+		keys[action] = bound
+	}
+
+	boundTo := make(map[string]string)
+	for _, action := range keys.actions() {
+		for _, key := range keys[action] {
+			if other, ok := boundTo[key]; ok && other != action {
+				return nil, fmt.Errorf("key '%s' is bound to both '%s' and '%s'", key, other, action)
+			}
+			boundTo[key] = action
+		}
+	}
+	return keys, nil
+}
+
+// actions returns the bindable actions in a stable order
+func (k keyMap) actions() []string {
+	var actions []string
+	for action := range k {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// action returns the action bound to a key, or "" when there is none
+func (k keyMap) action(key string) string {
+	for action, bound := range k {
+		for _, b := range bound {
+			if b == key {
+				return action
+			}
+		}
+	}
+	return ""
+}
+
+// first returns the key shown in the help for an action
+func (k keyMap) first(action string) string {
+	if bound := k[action]; len(bound) > 0 {
+		return bound[0]
+	}
+	return ""
+}
+
+// keyMap returns the model's bindings, falling back to the defaults for
+// models built without a configuration
+func (m Model) keyMap() keyMap {
+	if m.keys == nil {
+		return defaultKeyMap()
+	}
+	return m.keys
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		bindings    map[string][]string
+		expectError string
+		action      string
+		expected    []string
+	}{
+		{name: "defaults", action: actionBack, expected: []string{"left", "h", "esc", "backspace"}},
+		{name: "override replaces the defaults", bindings: map[string][]string{actionBack: {"esc"}}, action: actionBack, expected: []string{"esc"}},
+		{name: "key moved between actions", bindings: map[string][]string{actionBack: {"left", "h"}, actionQuit: {"esc"}}, action: actionQuit, expected: []string{"esc"}},
+		{
+			name:        "unknown action",
+			bindings:    map[string][]string{"jump": {"g"}},
+			expectError: "unknown action 'jump', expected one of back, down, forward, home, quit, refresh, search, up, web",
+		},
+		{
+			name:        "no keys",
+			bindings:    map[string][]string{actionQuit: {}},
+			expectError: "no keys given for 'quit'",
+		},
+		{
+			name:        "conflict with a default",
+			bindings:    map[string][]string{actionSearch: {"j"}},
+			expectError: "key 'j' is bound to both 'down' and 'search'",
+		},
+		{
+			name:        "fixed command",
+			bindings:    map[string][]string{actionHome: {"~", "s"}},
+			expectError: "key 's' of 'home' already runs another command",
+		},
+		{
+			name:        "conflict between overrides",
+			bindings:    map[string][]string{actionBack: {"esc"}, actionQuit: {"q", "esc"}},
+			expectError: "key 'esc' is bound to both 'back' and 'quit'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := newKeyMap(tt.bindings)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("newKeyMap() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newKeyMap() unexpected error = %v", err)
+			}
+			if got := keys[tt.action]; !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("newKeyMap()[%s] = %v, want %v", tt.action, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCustomKeyMap(t *testing.T) {
+	keys, err := newKeyMap(map[string][]string{
+		actionBack: {"esc"},
+		actionQuit: {"ctrl+c"},
+		actionUp:   {"up", "g"},
+	})
+	if err != nil {
+		t.Fatalf("newKeyMap() unexpected error = %v", err)
+	}
+	m := newTestModel()
+	m.keys = keys
+	m = send(t, m, toTables...)
+	m = send(t, m, key("down"), key("g"))
+	if m.cursor != 0 {
+		t.Errorf("g bound to up: cursor = %d, want 0", m.cursor)
+	}
+
+	// Keys dropped from an action no longer trigger it
+	for _, k := range []string{"q", "h", "left"} {
+		updated, cmd := m.Update(key(k))
+		if updated.(Model).currentView != viewTables || cmd != nil {
+			t.Errorf("%s after rebinding: view = %v, cmd = %v, want nothing to happen", k, updated.(Model).currentView, cmd)
+		}
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewSchemas {
+		t.Errorf("esc bound to back: view = %v, want schemas", m.currentView)
+	}
+	if _, cmd := m.Update(key("ctrl+c")); cmd == nil {
+		t.Fatal("ctrl+c bound to quit returned no command")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("ctrl+c bound to quit: cmd() = %T, want tea.QuitMsg", cmd())
+	}
+}
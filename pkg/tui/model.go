@@ -1,10 +1,12 @@
 package tui
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/amureki/metabase-explorer/pkg/api"
 	"github.com/amureki/metabase-explorer/pkg/config"
@@ -23,164 +25,391 @@ const (
 	viewCollections
 	viewCollectionItems
 	viewItemDetail
+	viewFieldDetail
+	viewQueryResults
+	viewRelated
+	viewSearch
+	viewFieldSearch
 )
 
 type Model struct {
-	databases          []api.Database
-	schemas            []api.Schema
-	tables             []api.Table
-	fields             []api.Field
-	collections        []api.Collection
-	collectionItems    []api.CollectionItem
-	cursor             int
-	loading            bool
-	error              string
-	client             *api.MetabaseClient
-	currentView        viewState
-	selectedDatabase   *api.Database
-	selectedSchema     *api.Schema
-	selectedTable      *api.Table
-	selectedCollection *api.Collection
-	selectedItem       *api.CollectionItem
-	itemDetail         api.DetailInfo
-	collectionStack    []*api.Collection // Track collection hierarchy for proper back navigation
-	viewportStart      int               // Starting index for viewport scrolling
-	viewportHeight     int               // Number of items that can be displayed at once
-	terminalWidth      int               // Terminal width for text wrapping
-	searchMode         bool
-	searchQuery        string
-	filteredIndices    []int
-	spinnerIndex       int
-	numberInput        string
-	helpMode           bool
-	helpCursor         int
-	latestVersion      string
-	updateAvailable    bool
-	Version            string
+	databases           []api.Database
+	schemas             []api.Schema
+	tables              []api.Table
+	fields              []api.Field
+	collections         []api.Collection
+	collectionItems     []api.CollectionItem
+	cursor              int
+	loading             bool
+	error               string
+	client              *api.MetabaseClient
+	clients             *api.ClientPool // Clients per profile, reused across profile switches
+	currentView         viewState
+	selectedDatabase    *api.Database
+	selectedSchema      *api.Schema
+	selectedTable       *api.Table
+	selectedField       *api.Field
+	fieldCursor         int // Cursor position in the fields list to restore when leaving field detail
+	selectedCollection  *api.Collection
+	selectedItem        *api.CollectionItem
+	itemDetail          api.DetailInfo
+	collectionStack     []*api.Collection   // Track collection hierarchy for proper back navigation
+	itemStack           []itemFrame         // Dashboards drilled through to reach the open card
+	extraClients        []api.ProfileClient // Other profiles global search also runs on
+	globalSearchMode    bool                // Prompt for a search across Metabase is open
+	globalSearchInput   string
+	globalQuery         string          // Query the shown search results are for
+	searchScope         *api.Collection // Collection global search is limited to, nil for everywhere
+	myContent           bool            // Search results list the user's own content
+	recentChanges       bool            // Search results list the content edited lately
+	searchResults       []api.SearchResult
+	loadedSearchResults []api.SearchResult // Results in Metabase's order, before sorting
+	searchSort          searchSortMode
+	searchFrom          viewState // View to return to when leaving the search results
+	searchFromCursor    int
+	relatedItems        []api.CollectionItem // Questions and dashboards built on relatedTable
+	relatedTable        *api.Table
+	relatedFrom         viewState // View to return to when leaving the related list
+	relatedCursor       int
+	queryColumns        []string
+	queryRows           [][]interface{}
+	lastLoad            tea.Cmd              // Load that failed, issued again by r
+	reconnecting        bool                 // A load failed on a rejected token and the connection is tested again
+	reconnected         bool                 // The load running was issued again after reconnecting
+	tokenRejected       bool                 // The error shown is Metabase rejecting the token
+	endpoints           map[viewState]string // API request behind each view's data, noted when its load starts
+	queryCancel         context.CancelFunc   // Cancels the running query, nil when none is running
+	queryParams         []api.ParameterValue // Parameters the shown results were run with
+	paramMode           bool                 // Parameter form for the question about to run is open
+	paramCardID         int                  // Question the form inputs were typed for
+	paramInputs         []string
+	paramCursor         int
+	paramError          string
+	viewportStart       int // Starting index for viewport scrolling
+	viewportHeight      int // Number of items that can be displayed at once
+	terminalWidth       int // Terminal width for text wrapping
+	terminalHeight      int
+	descriptionMode     bool // Full description of the open item is shown
+	descriptionOffset   int  // First description line shown
+	searchMode          bool
+	searchQuery         string
+	filteredIndices     []int
+	commandMode         bool // ":" prompt (go-to commands and action palette) is open
+	commandInput        string
+	commandError        string // Parse or lookup error shown next to the prompt
+	commandCursor       int    // Highlighted palette action
+	statusMessage       string // One-off feedback such as "Copied ...", cleared on the next key
+	spinnerIndex        int
+	spinnerVisible      bool      // The load has run long enough to show the spinner
+	loadingSince        time.Time // When the running load started
+	numberInput         string
+	helpMode            bool
+	helpCursor          int
+	updateSnoozedUntil  time.Time // Update notices are hidden until then
+	skipVersion         string    // Release whose update notice is never shown
+	latestVersion       string
+	updateAvailable     bool
+	updateDismissed     bool // The update banner was hidden for the rest of the session
+	showTechnicalNames  bool // Append raw SQL identifiers to display names
+	showArchived        bool // Include archived root collections
+	relativeTime        bool // Show item dates as "3 days ago" instead of the date
+	flattenTables       bool // List every table of a database under schema headers
+	fieldSort           fieldSortMode
+	collectionSort      collectionSortMode
+	personalLast        bool             // List personal collections after the others
+	hidePersonal        bool             // Leave personal collections out of the collections list
+	loadedCollections   []api.Collection // Root collections in the API order, before sorting
+	collectionCounts    bool             // Show the number of items next to each collection
+	startAt             *gotoCommand     // Default database or collection to open once connected
+	tokenMode           bool             // Typing a new API key after the token was rejected
+	tokenInput          string
+	itemCounts          map[string]int // Items per collection by list key, or one of the itemCount states
+	stickySearch        bool           // Restore a list's search when navigating back to it
+	savedSearches       []savedSearch
+	autoSkipSingle      bool          // Open the only table or collection item instead of listing it
+	drilledIn           bool          // The list being loaded was opened by drilling into its parent
+	keys                keyMap        // Keys bound to the configurable actions, nil for the defaults
+	restoreIndex        *int          // Item to put the cursor on once a restored search has results
+	profile             string        // Active config profile; empty when connected via flags only
+	readOnly            bool          // Write actions are hidden and refused by the client
+	jumpMode            bool          // Waiting for the number of a breadcrumb level to go back to
+	confirm             *confirmation // Question waiting for y before a change to Metabase
+	collectionTreeMode  bool          // List every collection as a tree instead of the root ones
+	collectionTree      []api.Collection
+	collectionDepths    []int           // Tree depth of each entry of collections in tree mode
+	expandedCollections map[string]bool // Tree nodes showing their children, by listKeyOf
+	jumpSelect          string          // Entry to put the cursor on once the level jumped to has loaded
+	maxTablesPerDB      int             // Tables the flat list shows at first, 0 or less for all
+	tablesTotal         int             // Tables of the database when the flat list is capped, else 0
+	showAllTables       bool            // The rest of the capped flat list was asked for
+	tablesRest          []api.Table     // Tables the cap left out of the flat list, already fetched
+	prefetch            bool            // Fetch the tables of the hovered database in the background
+	prefetchHover       int             // Database the pending or running prefetch is for, 0 for none
+	prefetchCancel      context.CancelFunc
+	prefetched          *prefetchedTables // Tables of the last database prefetched
+	releaseClient       *http.Client      // Looks up the latest release, through the proxy of the API clients
+	releaseURL          string            // Where the latest release is looked up
+	offline             bool              // No update check or prefetching, only the requests asked for
+	cachedView          viewState         // List last filled from the disk cache
+	cachedAt            time.Time         // When that list was saved, zero once it has been refreshed
+	refreshID           int               // Refresh of that list, answers to older ones are dropped
+	showLegend          bool              // Explain the badges under the fields list
+	timeLayout          string            // Go layout of dates, config.DefaultTimeFormat when empty
+	timezone            *time.Location    // Zone dates are shown in, nil to show them as Metabase sent them
+	databaseTables      []api.Table       // Tables of the database the field search covers
+	fieldIndex          []fieldHit        // Every field of the database, see buildFieldIndex
+	fieldSearchFrom     viewState
+	fieldSearchCursor   int
+	profileMode         bool
+	profileNames        []string
+	profileCursor       int
+	profiles            map[string]config.Profile
+	moveMode            bool             // Collection picker for the item being moved is open
+	moving              moveTarget       // Item the collection picker is for
+	moveCollections     []api.Collection // Collections the picker lists, nil while they load
+	moveDepths          []int
+	moveCursor          int
+	selectMode          bool            // Space selects entries of the list for the bulk actions
+	selectList          string          // selectionList of the list being selected in
+	selected            map[string]bool // Selected entries, by selectableRow key
+	Version             string
 }
 
-func InitialModel(flagURL, flagToken, flagProfile, version string) Model {
-	metabaseURL, apiToken, err := config.ResolveConfiguration(flagURL, flagToken, flagProfile)
+// Options are the startup settings taken from the command line
+type Options struct {
+	URL      string
+	Token    string
+	Profile  string
+	Version  string
+	ReadOnly bool
+	Offline  bool // Skip the update check and other requests mbx makes on its own
+	// Headers from --header, sent with every request on top of the ones in
+	// the config file
+	Headers map[string]string
+	// More profiles to include in global search, from --profile work,dev
+	ExtraProfiles []string
+}
+
+// InitialModel builds the model for the resolved connection. It returns an
+// error when the flags and config file don't add up to a URL and token.
+func InitialModel(opts Options) (Model, error) {
+	metabaseURL, apiToken, err := config.ResolveConfiguration(opts.URL, opts.Token, opts.Profile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, `Error: %v
+		return Model{}, err
+	}
 
-Configuration sources (in priority order):
-1. CLI flags: --url and --token
-2. Config file: ~/.config/mbx/config.yaml (or --config <path>)
+	// Display preferences are optional, so a missing or unreadable config
+	// simply falls back to the defaults
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = &config.Config{}
+	}
 
-To get started, run: mbx init
+	// Name the profile the connection came from, unless flags supplied it all
+	profile := ""
+	if opts.URL == "" || opts.Token == "" {
+		profile = opts.Profile
+		if profile == "" {
+			profile = cfg.DefaultProfile
+		}
+	}
 
-See https://www.metabase.com/docs/latest/people-and-groups/api-keys for API token setup.
-Run 'mbx --help' for more information.
-`, err)
-		os.Exit(1)
+	proxy, err := cfg.ProxyURL()
+	if err != nil {
+		return Model{}, err
+	}
+	clients := api.NewClientPool(opts.Version)
+	clients.ReadOnly = opts.ReadOnly || cfg.ReadOnly
+	clients.UserAgent = cfg.UserAgent
+	if clients.Bearer, err = cfg.BearerAuth(); err != nil {
+		return Model{}, err
+	}
+	if clients.Headers, err = cfg.RequestHeaders(opts.Headers); err != nil {
+		return Model{}, err
+	}
+	clients.Proxy = proxy
+	if cfg.DiskCache {
+		if clients.CacheTTL, err = cfg.CacheTTLDuration(); err != nil {
+			return Model{}, err
+		}
+		if clients.CacheDir, err = config.GetCacheDir(); err != nil {
+			return Model{}, err
+		}
+	}
+	m := NewModelWithClient(clients.Get(profile, metabaseURL, apiToken), opts.Version)
+	m.clients = clients
+	m.releaseClient = &http.Client{Transport: api.NewTransport(proxy)}
+	m.releaseURL = util.ReleaseURL(cfg.ReleaseURL)
+	for _, name := range opts.ExtraProfiles {
+		extraURL, extraToken, err := config.ResolveConfiguration("", "", name)
+		if err != nil {
+			return Model{}, fmt.Errorf("profile '%s': %v", name, err)
+		}
+		m.extraClients = append(m.extraClients, api.ProfileClient{Profile: name, Client: clients.Get(name, extraURL, extraToken)})
+	}
+	m.showTechnicalNames = cfg.ShowTechnicalNames
+	m.stickySearch = cfg.StickySearch
+	m.autoSkipSingle = cfg.AutoSkipSingle
+	layout, relative, err := cfg.TimeLayout()
+	if err != nil {
+		return Model{}, err
+	}
+	m.timeLayout = layout
+	if m.timezone, err = cfg.Location(); err != nil {
+		return Model{}, err
+	}
+	m.relativeTime = cfg.RelativeTime || relative
+	m.updateSnoozedUntil = cfg.UpdateSnoozedUntil
+	m.skipVersion = cfg.SkipVersion
+	m.prefetch = cfg.Prefetch
+	m.maxTablesPerDB = cfg.MaxTablesPerDB
+	if m.maxTablesPerDB == 0 {
+		m.maxTablesPerDB = defaultMaxTablesPerDB
+	}
+	if m.collectionSort, err = parseCollectionSort(cfg.CollectionSort); err != nil {
+		return Model{}, err
+	}
+	m.personalLast = cfg.PersonalLast
+	m.hidePersonal = cfg.HidePersonal
+	m.collectionCounts = cfg.CollectionCounts
+	if m.keys, err = newKeyMap(cfg.Keymap); err != nil {
+		return Model{}, fmt.Errorf("invalid keymap in config: %v", err)
 	}
+	m.profile = profile
+	m.offline = opts.Offline
+	m.startAt = startTarget(cfg.Profiles[profile])
+	m.statusMessage = cfg.Warning()
+	return m, nil
+}
 
-	client := api.NewMetabaseClient(metabaseURL, apiToken)
+// NewModelWithClient builds a model on the main menu for an existing client,
+// without reading any configuration
+func NewModelWithClient(client *api.MetabaseClient, version string) Model {
 	return Model{
 		loading:        false,
 		client:         client,
 		currentView:    viewMainMenu,
 		Version:        version,
 		terminalWidth:  80, // Conservative default
+		terminalHeight: 24, // Conservative default
 		viewportHeight: 15, // Conservative default
+		readOnly:       client.ReadOnly,
+		releaseClient:  http.DefaultClient,
+		releaseURL:     util.ReleaseURL(""),
 	}
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.offline {
+		return retryable(testConnection(m.client))
+	}
 	return tea.Batch(
-		func() tea.Msg {
-			err := m.client.TestConnection()
-			if err != nil {
-				return connectionTested{err: err}
-			}
-			return connectionTested{err: nil}
-		},
-		checkLatestVersion(),
+		retryable(testConnection(m.client)),
+		checkLatestVersion(m.releaseClient, m.releaseURL),
 	)
 }
 
+// Update handles a message, then notes the endpoint of a load it started and
+// schedules a prefetch when the cursor has moved to another database
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	next := updated.(Model).trackLoading(m).noteEndpoint(m)
+	next, prefetch := next.schedulePrefetch()
+	next, counts := next.scheduleCollectionCounts()
+	return next, tea.Batch(cmd, prefetch, counts)
+}
+
+// trackLoading notes when the update from before started a load, and hides
+// the spinner again once nothing is loading
+func (m Model) trackLoading(before Model) Model {
+	if !m.loading {
+		m.spinnerVisible = false
+	} else if !before.loading {
+		m.loadingSince = time.Now()
+	}
+	return m
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.statusMessage = ""
+		// Pressing a key before connecting takes over from the default to open
+		m.startAt = nil
+		if m.confirm != nil {
+			return m.updateConfirm(msg)
+		}
+		if m.commandMode {
+			return m.updateCommand(msg)
+		}
+		if m.profileMode {
+			return m.updateProfilePicker(msg)
+		}
+		if m.moveMode {
+			return m.updateMovePicker(msg)
+		}
+		if m.paramMode {
+			return m.updateParamForm(msg)
+		}
+		if m.descriptionMode {
+			return m.updateDescription(msg)
+		}
+		if m.globalSearchMode {
+			return m.updateGlobalSearch(msg)
+		}
+		if m.tokenMode {
+			return m.updateTokenPrompt(msg)
+		}
+		// Home also ends a jump or help that is open, and a search unless the
+		// key types a character into it
+		typed := m.searchMode && len(msg.String()) == 1
+		if m.keyMap().action(msg.String()) == actionHome && !typed {
+			return m.goHome()
+		}
+		if m.jumpMode {
+			return m.updateJump(msg)
+		}
+
+		// Multi-select takes space and the bulk actions, also while filtering
+		if m.selecting() {
+			switch {
+			case msg.String() == " ":
+				return m.toggleSelected()
+			case msg.String() == "esc" && !m.searchMode:
+				return m.toggleSelectMode()
+			case msg.String() == "e" && !m.searchMode:
+				return m.exportSelected()
+			case msg.String() == "y" && !m.searchMode:
+				return m.copySelectedURLs()
+			case m.keyMap().action(msg.String()) == actionWeb && !m.searchMode:
+				return m.openSelected()
+			}
+		}
+
 		// Handle search mode
 		if m.searchMode {
 			switch msg.String() {
+			case "ctrl+y":
+				return m.copyNames()
 			case "esc":
 				m.searchMode = false
 				m.searchQuery = ""
 				m.filteredIndices = nil
+				m.restoreIndex = nil
 				m.cursor = 0
 			case "enter":
 				// Select from filtered results
 				if len(m.filteredIndices) > 0 && m.cursor < len(m.filteredIndices) {
 					actualIndex := m.filteredIndices[m.cursor]
+					m = m.saveSearch(actualIndex)
+					m.restoreIndex = nil
 					m.cursor = actualIndex
 					m.searchMode = false
 					m.searchQuery = ""
 					m.filteredIndices = nil
 
 					// Trigger selection
-					if m.currentView == viewDatabases && len(m.databases) > 0 {
-						m.selectedDatabase = &m.databases[actualIndex]
-						m.currentView = viewSchemas
-						m.cursor = 0
-						m.loading = true
-						m.error = ""
-						return m, tea.Batch(loadSchemas(m.client, m.selectedDatabase.ID), tickSpinner())
-					} else if m.currentView == viewCollections && len(m.collections) > 0 {
-						m.selectedCollection = &m.collections[actualIndex]
-						m.collectionStack = nil // Clear stack when entering from root collections
-						m.currentView = viewCollectionItems
-						m.cursor = 0
-						m.loading = true
-						m.error = ""
-						return m, tea.Batch(loadCollectionItems(m.client, m.selectedCollection.ID), tickSpinner())
-					} else if m.currentView == viewCollectionItems && len(m.collectionItems) > 0 {
-						item := m.collectionItems[actualIndex]
-						if item.Model == "collection" {
-							// Push current collection to stack before drilling into sub-collection
-							m.collectionStack = append(m.collectionStack, m.selectedCollection)
-							m.selectedCollection = &api.Collection{
-								ID:   item.ID,
-								Name: item.Name,
-							}
-							m.currentView = viewCollectionItems
-							m.cursor = 0
-							m.loading = true
-							m.error = ""
-							return m, tea.Batch(loadCollectionItems(m.client, item.ID), tickSpinner())
-						} else {
-							// Show item detail for non-collection items
-							m.selectedItem = &item
-							m.currentView = viewItemDetail
-							m.cursor = 0
-							m.loading = true
-							m.error = ""
-							// Load detailed information for cards, dashboards, and metrics
-							if item.Model == "card" {
-								return m, tea.Batch(loadCardDetail(m.client, item.ID), tickSpinner())
-							} else if item.Model == "dashboard" {
-								return m, tea.Batch(loadDashboardDetail(m.client, item.ID), tickSpinner())
-							} else if item.Model == "metric" {
-								return m, tea.Batch(loadMetricDetail(m.client, item.ID), tickSpinner())
-							}
-						}
-					} else if m.currentView == viewSchemas && len(m.schemas) > 0 {
-						m.selectedSchema = &m.schemas[actualIndex]
-						m.currentView = viewTables
-						m.cursor = 0
-						m.loading = true
-						m.error = ""
-						return m, tea.Batch(loadTablesForSchema(m.client, m.selectedDatabase.ID, m.selectedSchema.Name), tickSpinner())
-					} else if m.currentView == viewTables && len(m.tables) > 0 {
-						m.selectedTable = &m.tables[actualIndex]
-						m.currentView = viewFields
-						m.cursor = 0
-						m.loading = true
-						m.error = ""
-						return m, tea.Batch(loadFields(m.client, m.selectedTable.ID), tickSpinner())
-					}
+					return m.selectItem(actualIndex)
 				}
 			case "backspace":
 				if len(m.searchQuery) > 0 {
@@ -205,23 +434,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		// Normal navigation mode
-		switch msg.String() {
-		case "ctrl+c", "q":
+		// Normal navigation mode, keys of bindable actions come first
+		switch m.keyMap().action(msg.String()) {
+		case actionQuit:
 			return m, tea.Quit
+		case actionSearch:
+			return m.openSearch(), nil
+		case actionUp:
+			return m.moveUp(), nil
+		case actionDown:
+			return m.moveDown(), nil
+		case actionBack:
+			return m.back()
+		case actionForward:
+			return m.forward()
+		case actionWeb:
+			return m.openWebURL()
+		case actionRefresh:
+			if !m.helpMode && !m.loading {
+				return m.refresh()
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
 		case "?":
 			m.helpMode = !m.helpMode
 			if m.helpMode {
 				m.helpCursor = 0
 			}
 			return m, nil
-		case "/":
-			if m.helpMode || m.currentView == viewMainMenu {
+		case ":":
+			if m.helpMode || m.loading {
 				return m, nil
 			}
-			m.searchMode = true
-			m.searchQuery = ""
-			m.cursor = 0
+			m.commandMode = true
+			m.commandInput = ""
+			m.commandError = ""
+			m.commandCursor = 0
+			m.numberInput = ""
 			return m, nil
 		case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
 			if m.helpMode {
@@ -234,7 +485,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			var itemCount int
 			switch m.currentView {
 			case viewMainMenu:
-				itemCount = 2 // Collections and Databases
+				itemCount = len(mainMenu)
 			case viewDatabases:
 				itemCount = len(m.databases)
 			case viewCollections:
@@ -247,6 +498,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				itemCount = len(m.tables)
 			case viewFields:
 				itemCount = len(m.fields)
+			case viewRelated:
+				itemCount = len(m.relatedItems)
+			case viewSearch:
+				itemCount = len(m.searchResults)
+			case viewFieldSearch:
+				itemCount = len(m.fieldIndex)
 			}
 
 			// Try to parse the number and hover over the item if valid
@@ -260,459 +517,294 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.numberInput = ""
 				}
 			}
-		case "up", "k":
-			if m.helpMode {
-				if m.helpCursor > 0 {
-					m.helpCursor--
-				}
-				return m, nil
+		case "b":
+			if !m.helpMode {
+				return m.openJump()
 			}
-			m.numberInput = "" // Clear number input when using arrow keys
-			if m.cursor > 0 {
-				m.cursor--
-				// Update viewport for collections and other views that might have many items
-				if m.currentView == viewCollectionItems && len(m.collectionItems) > 0 {
-					m.updateViewport(len(m.collectionItems))
-				}
+		case "H":
+			if !m.helpMode {
+				return m.jumpToRoot()
 			}
-		case "down", "j":
-			if m.helpMode {
-				// We have 3 links: Repository, Issues, Sponsor
-				if m.helpCursor < 2 {
-					m.helpCursor++
-				}
-				return m, nil
+		case "A":
+			if !m.helpMode {
+				return m.confirmArchive()
 			}
-			m.numberInput = "" // Clear number input when using arrow keys
-			if m.currentView == viewMainMenu && m.cursor < 1 {
-				m.cursor++
-			} else if m.currentView == viewDatabases && m.cursor < len(m.databases)-1 {
-				m.cursor++
-			} else if m.currentView == viewCollections && m.cursor < len(m.collections)-1 {
-				m.cursor++
-			} else if m.currentView == viewCollectionItems && m.cursor < len(m.collectionItems)-1 {
-				m.cursor++
-				m.updateViewport(len(m.collectionItems))
-			} else if m.currentView == viewSchemas && m.cursor < len(m.schemas)-1 {
-				m.cursor++
-			} else if m.currentView == viewTables && m.cursor < len(m.tables)-1 {
-				m.cursor++
-			} else if m.currentView == viewFields && m.cursor < len(m.fields)-1 {
-				m.cursor++
-			}
-		case "left", "h":
-			if m.helpMode {
-				// Exit help mode
-				m.helpMode = false
-				return m, nil
+		case "M":
+			if !m.helpMode {
+				return m.openMovePicker()
 			}
-			if m.numberInput != "" {
-				// Clear number input
-				m.numberInput = ""
-			} else if m.currentView == viewDatabases || m.currentView == viewCollections {
-				m.currentView = viewMainMenu
-				m.cursor = 0
-				m.selectedDatabase = nil
-				m.databases = nil
-				m.collections = nil
-			} else if m.currentView == viewCollectionItems {
-				if len(m.collectionStack) > 0 {
-					// Pop from stack to go to parent collection
-					m.selectedCollection = m.collectionStack[len(m.collectionStack)-1]
-					m.collectionStack = m.collectionStack[:len(m.collectionStack)-1]
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadCollectionItems(m.client, m.selectedCollection.ID), tickSpinner())
-				} else {
-					// Go back to root collections
-					m.currentView = viewCollections
-					m.cursor = 0
-					m.selectedCollection = nil
-					m.collectionItems = nil
-				}
-			} else if m.currentView == viewItemDetail {
-				// Go back to collection items
-				m.currentView = viewCollectionItems
-				m.cursor = 0
-				m.selectedItem = nil
-				m.itemDetail = nil
-			} else if m.currentView == viewSchemas {
-				m.currentView = viewDatabases
-				m.cursor = 0
-				m.selectedDatabase = nil
-				m.schemas = nil
-			} else if m.currentView == viewTables {
-				m.currentView = viewSchemas
-				m.cursor = 0
-				m.selectedSchema = nil
-				m.tables = nil
-			} else if m.currentView == viewFields {
-				m.currentView = viewTables
-				m.cursor = 0
-				m.selectedTable = nil
-				m.fields = nil
+		case "v":
+			if !m.helpMode {
+				return m.toggleSelectMode()
 			}
-		case "right", "l":
-			if m.helpMode {
-				// Open selected link in browser (same as Enter)
-				var url string
-				switch m.helpCursor {
-				case 0:
-					url = "https://github.com/amureki/metabase-explorer"
-				case 1:
-					url = "https://github.com/amureki/metabase-explorer/issues"
-				case 2:
-					url = "https://github.com/sponsors/amureki"
-				}
-				if err := util.OpenInBrowser(url); err != nil {
-					m.error = fmt.Sprintf("Failed to open browser: %v", err)
-				}
-				return m, nil
+		case "o":
+			if !m.helpMode {
+				return m.revealCollection()
 			}
-			// Clear number input after navigation
-			m.numberInput = ""
-
-			if m.currentView == viewMainMenu {
-				if m.cursor == 0 {
-					// Navigate to Collections
-					m.currentView = viewCollections
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadCollections(m.client), tickSpinner())
-				} else if m.cursor == 1 {
-					// Navigate to Databases
-					m.currentView = viewDatabases
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadDatabases(m.client), tickSpinner())
-				}
-			} else if m.currentView == viewDatabases && len(m.databases) > 0 {
-				m.selectedDatabase = &m.databases[m.cursor]
-				m.currentView = viewSchemas
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadSchemas(m.client, m.selectedDatabase.ID), tickSpinner())
-			} else if m.currentView == viewCollections && len(m.collections) > 0 {
-				m.selectedCollection = &m.collections[m.cursor]
-				m.collectionStack = nil // Clear stack when entering from root collections
-				m.currentView = viewCollectionItems
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadCollectionItems(m.client, m.selectedCollection.ID), tickSpinner())
-			} else if m.currentView == viewCollectionItems && len(m.collectionItems) > 0 {
-				item := m.collectionItems[m.cursor]
-				if item.Model == "collection" {
-					// Push current collection to stack before drilling into sub-collection
-					m.collectionStack = append(m.collectionStack, m.selectedCollection)
-					m.selectedCollection = &api.Collection{
-						ID:   item.ID,
-						Name: item.Name,
-					}
-					m.currentView = viewCollectionItems
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadCollectionItems(m.client, item.ID), tickSpinner())
-				} else {
-					// Show item detail for non-collection items
-					m.selectedItem = &item
-					m.currentView = viewItemDetail
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					// Load detailed information for cards, dashboards, and metrics
-					if item.Model == "card" {
-						return m, tea.Batch(loadCardDetail(m.client, item.ID), tickSpinner())
-					} else if item.Model == "dashboard" {
-						return m, tea.Batch(loadDashboardDetail(m.client, item.ID), tickSpinner())
-					} else if item.Model == "metric" {
-						return m, tea.Batch(loadMetricDetail(m.client, item.ID), tickSpinner())
-					}
-				}
-			} else if m.currentView == viewSchemas && len(m.schemas) > 0 {
-				m.selectedSchema = &m.schemas[m.cursor]
-				m.currentView = viewTables
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadTablesForSchema(m.client, m.selectedDatabase.ID, m.selectedSchema.Name), tickSpinner())
-			} else if m.currentView == viewTables && len(m.tables) > 0 {
-				m.selectedTable = &m.tables[m.cursor]
-				m.currentView = viewFields
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadFields(m.client, m.selectedTable.ID), tickSpinner())
+		case "B":
+			if !m.helpMode {
+				return m.copyLocation()
 			}
-		case "enter":
-			if m.helpMode {
-				// Open selected link in browser
-				var url string
-				switch m.helpCursor {
-				case 0:
-					url = "https://github.com/amureki/metabase-explorer"
-				case 1:
-					url = "https://github.com/amureki/metabase-explorer/issues"
-				case 2:
-					url = "https://github.com/sponsors/amureki"
-				}
-				if err := util.OpenInBrowser(url); err != nil {
-					m.error = fmt.Sprintf("Failed to open browser: %v", err)
-				}
-				return m, nil
+		case "i":
+			// Show the request behind the current view, for debugging
+			if !m.helpMode {
+				return m.showEndpoint()
 			}
-
-			// Keep Enter as alternative to right arrow
-			m.numberInput = ""
-
-			if m.currentView == viewMainMenu {
-				if m.cursor == 0 {
-					// Navigate to Collections
-					m.currentView = viewCollections
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadCollections(m.client), tickSpinner())
-				} else if m.cursor == 1 {
-					// Navigate to Databases
-					m.currentView = viewDatabases
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadDatabases(m.client), tickSpinner())
-				}
-			} else if m.currentView == viewDatabases && len(m.databases) > 0 {
-				m.selectedDatabase = &m.databases[m.cursor]
-				m.currentView = viewSchemas
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadSchemas(m.client, m.selectedDatabase.ID), tickSpinner())
-			} else if m.currentView == viewCollections && len(m.collections) > 0 {
-				m.selectedCollection = &m.collections[m.cursor]
-				m.collectionStack = nil // Clear stack when entering from root collections
-				m.currentView = viewCollectionItems
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadCollectionItems(m.client, m.selectedCollection.ID), tickSpinner())
-			} else if m.currentView == viewCollectionItems && len(m.collectionItems) > 0 {
-				item := m.collectionItems[m.cursor]
-				if item.Model == "collection" {
-					// Push current collection to stack before drilling into sub-collection
-					m.collectionStack = append(m.collectionStack, m.selectedCollection)
-					m.selectedCollection = &api.Collection{
-						ID:   item.ID,
-						Name: item.Name,
-					}
-					m.currentView = viewCollectionItems
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadCollectionItems(m.client, item.ID), tickSpinner())
-				} else {
-					// Show item detail for non-collection items
-					m.selectedItem = &item
-					m.currentView = viewItemDetail
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					// Load detailed information for cards, dashboards, and metrics
-					if item.Model == "card" {
-						return m, tea.Batch(loadCardDetail(m.client, item.ID), tickSpinner())
-					} else if item.Model == "dashboard" {
-						return m, tea.Batch(loadDashboardDetail(m.client, item.ID), tickSpinner())
-					} else if item.Model == "metric" {
-						return m, tea.Batch(loadMetricDetail(m.client, item.ID), tickSpinner())
-					}
-				}
-			} else if m.currentView == viewSchemas && len(m.schemas) > 0 {
-				m.selectedSchema = &m.schemas[m.cursor]
-				m.currentView = viewTables
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadTablesForSchema(m.client, m.selectedDatabase.ID, m.selectedSchema.Name), tickSpinner())
-			} else if m.currentView == viewTables && len(m.tables) > 0 {
-				m.selectedTable = &m.tables[m.cursor]
-				m.currentView = viewFields
-				m.cursor = 0
-				m.loading = true
-				m.error = ""
-				return m, tea.Batch(loadFields(m.client, m.selectedTable.ID), tickSpinner())
-			}
-		case "w":
-			webURL := m.getWebURL()
-			if err := util.OpenInBrowser(webURL); err != nil {
-				m.error = fmt.Sprintf("Failed to open browser: %v", err)
-			}
-		case "backspace":
-			// Keep backspace as alternative to left arrow
-			if m.numberInput != "" {
-				// Clear number input
-				m.numberInput = ""
-			} else if m.currentView == viewDatabases || m.currentView == viewCollections {
-				m.currentView = viewMainMenu
-				m.cursor = 0
-				m.selectedDatabase = nil
-				m.databases = nil
-				m.collections = nil
-			} else if m.currentView == viewCollectionItems {
-				if len(m.collectionStack) > 0 {
-					// Pop from stack to go to parent collection
-					m.selectedCollection = m.collectionStack[len(m.collectionStack)-1]
-					m.collectionStack = m.collectionStack[:len(m.collectionStack)-1]
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadCollectionItems(m.client, m.selectedCollection.ID), tickSpinner())
-				} else {
-					// Go back to root collections
-					m.currentView = viewCollections
-					m.cursor = 0
-					m.selectedCollection = nil
-					m.collectionItems = nil
-				}
-			} else if m.currentView == viewItemDetail {
-				// Go back to collection items
-				m.currentView = viewCollectionItems
-				m.cursor = 0
-				m.selectedItem = nil
-				m.itemDetail = nil
-			} else if m.currentView == viewSchemas {
-				m.currentView = viewDatabases
-				m.cursor = 0
-				m.selectedDatabase = nil
-				m.schemas = nil
-			} else if m.currentView == viewTables {
-				m.currentView = viewSchemas
-				m.cursor = 0
-				m.selectedSchema = nil
-				m.tables = nil
-			} else if m.currentView == viewFields {
-				m.currentView = viewTables
-				m.cursor = 0
-				m.selectedTable = nil
-				m.fields = nil
+		case "F":
+			if !m.helpMode && !m.loading {
+				return m.openFieldSearch()
 			}
-		case "esc":
-			if m.helpMode {
-				m.helpMode = false
-				return m, nil
-			} else if m.numberInput != "" {
-				// Clear number input
-				m.numberInput = ""
-			} else if m.currentView == viewDatabases || m.currentView == viewCollections {
-				m.currentView = viewMainMenu
-				m.cursor = 0
-				m.selectedDatabase = nil
-				m.databases = nil
-				m.collections = nil
-			} else if m.currentView == viewCollectionItems {
-				if len(m.collectionStack) > 0 {
-					// Pop from stack to go to parent collection
-					m.selectedCollection = m.collectionStack[len(m.collectionStack)-1]
-					m.collectionStack = m.collectionStack[:len(m.collectionStack)-1]
-					m.cursor = 0
-					m.loading = true
-					m.error = ""
-					return m, tea.Batch(loadCollectionItems(m.client, m.selectedCollection.ID), tickSpinner())
-				} else {
-					// Go back to root collections
-					m.currentView = viewCollections
-					m.cursor = 0
-					m.selectedCollection = nil
-					m.collectionItems = nil
-				}
-			} else if m.currentView == viewItemDetail {
-				// Go back to collection items
-				m.currentView = viewCollectionItems
-				m.cursor = 0
-				m.selectedItem = nil
-				m.itemDetail = nil
-			} else if m.currentView == viewSchemas {
-				m.currentView = viewDatabases
-				m.cursor = 0
-				m.selectedDatabase = nil
-				m.schemas = nil
-			} else if m.currentView == viewTables {
-				m.currentView = viewSchemas
-				m.cursor = 0
-				m.selectedSchema = nil
-				m.tables = nil
-			} else if m.currentView == viewFields {
-				m.currentView = viewTables
-				m.cursor = 0
-				m.selectedTable = nil
-				m.fields = nil
+		case "U":
+			if !m.helpMode && m.updateAvailable {
+				return m.dismissUpdate()
+			}
+		case "K":
+			// Try another API key once Metabase rejected the token
+			if m.canEnterToken() {
+				return m.openTokenPrompt()
+			}
+		case "T":
+			if !m.helpMode && m.currentView == viewCollections && !m.loading {
+				return m.toggleCollectionTree()
+			}
+		case "a":
+			// Toggle archived collections and reload the root list
+			if !m.helpMode && m.currentView == viewCollections && !m.loading {
+				return m.toggleArchived()
+			}
+		case "f":
+			// Toggle the flat, schema-grouped table list
+			if !m.helpMode && !m.loading && (m.currentView == viewSchemas || m.currentView == viewTables) {
+				return m.toggleFlattenTables()
+			}
+		case "m":
+			if !m.helpMode && !m.loading {
+				return m.loadRemainingTables()
+			}
+		case "t":
+			// Toggle relative and absolute item dates
+			if !m.helpMode && m.currentView == viewItemDetail {
+				m.relativeTime = !m.relativeTime
+			}
+		case "s":
+			if !m.helpMode && !m.loading && m.currentView == viewFields {
+				return m.toggleFieldSort()
+			}
+			if !m.helpMode && !m.loading && m.currentView == viewCollections {
+				return m.cycleCollectionSort()
+			}
+			if !m.helpMode && !m.loading && m.currentView == viewSearch && len(m.searchResults) > 1 {
+				return m.cycleSearchSort()
+			}
+		case "p":
+			if !m.helpMode && !m.loading && m.currentView == viewCollections {
+				return m.togglePersonal()
+			}
+		case "L":
+			// Toggle the legend of the badges under the fields list
+			if !m.helpMode && m.currentView == viewFields {
+				m.showLegend = !m.showLegend
+				m.scrollFields()
+			}
+		case "n":
+			// Toggle technical (SQL) names next to display names
+			if !m.helpMode {
+				m.showTechnicalNames = !m.showTechnicalNames
+			}
+		case "y":
+			if !m.helpMode {
+				return m.copyWebURL()
+			}
+		case "D":
+			if !m.helpMode {
+				return m.copyTableDDL()
+			}
+		case "S":
+			if !m.helpMode {
+				return m.openGlobalSearch()
+			}
+		case "R":
+			// List the questions and dashboards built on a table
+			if !m.helpMode && !m.loading && (m.currentView == viewTables || m.currentView == viewFields) {
+				return m.openRelated()
+			}
+		case "r":
+			if !m.helpMode && m.error != "" {
+				return m.retry()
+			}
+		case "c":
+			if !m.helpMode {
+				return m.copyConnection()
+			}
+		case "d":
+			if !m.helpMode {
+				return m.openDescription()
+			}
+		case "P":
+			if !m.helpMode {
+				return m.openProfilePicker()
+			}
+		case "x":
+			// Run the selected saved question
+			if !m.helpMode && !m.loading && m.canRunQuery() {
+				return m.runQuery()
+			}
+		case "e":
+			if !m.helpMode {
+				return m.exportQuery("csv")
+			}
+		case "pgdown":
+			if m.currentView == viewQueryResults {
+				m.cursor = min(m.cursor+m.queryPageSize(), max(len(m.queryRows)-1, 0))
+			}
+		case "pgup":
+			if m.currentView == viewQueryResults {
+				m.cursor = max(m.cursor-m.queryPageSize(), 0)
+			}
+		case "ctrl+y":
+			if !m.helpMode {
+				return m.copyNames()
 			}
 		}
 
 	case tea.WindowSizeMsg:
 		m.terminalWidth = msg.Width
+		m.terminalHeight = msg.Height
 		// Conservative estimate for viewport height
 		m.viewportHeight = msg.Height - 10
 
+	case loadFinished:
+		// r replays the load that failed, not whichever finished last
+		if loadError(msg.msg) != nil {
+			m.lastLoad = msg.retry
+		} else {
+			m.lastLoad = nil
+		}
+		updated, cmd := m.Update(msg.msg)
+		return updated.(Model).checkToken(msg.msg, cmd)
+
+	case tokenTested:
+		return m.applyNewToken(msg)
+
 	case connectionTested:
+		m.loading = false
+		if m.reconnecting {
+			return m.applyReconnect(msg)
+		}
 		if msg.err != nil {
 			m.error = msg.err.Error()
+		} else if m.startAt != nil {
+			return m.openStart()
 		}
 
+	case listRefreshed:
+		return m.applyRefresh(msg)
+
 	case databasesLoaded:
 		m.loading = false
 		if msg.err != nil {
 			m.error = msg.err.Error()
 		} else {
 			m.databases = msg.databases
+			if m.jumpSelect != "" {
+				m.selectJumped()
+			}
 		}
 
 	case collectionsLoaded:
 		m.loading = false
+		if msg.err == nil {
+			m.itemCounts = nil
+		}
 		if msg.err != nil {
 			m.error = msg.err.Error()
+		} else if msg.tree {
+			m.collectionTree = msg.collections
+			if m.expandedCollections == nil {
+				m.expandedCollections = expandAll(m.collectionTree)
+			}
+			m.showCollectionTree()
 		} else {
-			m.collections = msg.collections
+			m.showCollections(msg.collections)
+			m.collectionTree = nil
+			m.collectionDepths = nil
+		}
+		if msg.err == nil && m.jumpSelect != "" {
+			m.selectJumped()
 		}
 
 	case collectionItemsLoaded:
 		m.loading = false
+		// Items of a collection that was left, such as by H, are dropped
+		if m.currentView != viewCollectionItems {
+			return m, nil
+		}
+		drilledIn := m.drilledIn
+		m.drilledIn = false
 		if msg.err != nil {
 			m.error = msg.err.Error()
 		} else {
 			m.collectionItems = msg.items
 			m.viewportStart = 0 // Reset viewport when loading new items
+			if m.searchMode && m.searchQuery != "" {
+				// A restored search was applied before the items arrived
+				m.updateSearch()
+				m.selectRestoredSearchItem()
+			}
 			if len(m.collectionItems) > 0 {
 				m.updateViewport(len(m.collectionItems))
 			}
+			if m.jumpSelect != "" {
+				m.selectJumped()
+			}
+			if m.autoSkip(drilledIn, len(m.collectionItems)) {
+				return m.selectItem(0)
+			}
 		}
 
 	case schemasLoaded:
 		m.loading = false
+		// Schemas of a database that was left are dropped
+		if m.currentView != viewSchemas {
+			return m, nil
+		}
+		drilledIn := m.drilledIn
+		m.drilledIn = false
 		if msg.err != nil {
 			m.error = msg.err.Error()
 		} else {
 			m.schemas = msg.schemas
 			// Auto-skip schema view if only one schema
 			if len(m.schemas) == 1 {
+				m.drilledIn = drilledIn
 				m.selectedSchema = &m.schemas[0]
 				m.currentView = viewTables
 				m.cursor = 0
 				m.loading = true
-				return m, tea.Batch(loadTablesForSchema(m.client, m.selectedDatabase.ID, m.selectedSchema.Name), tickSpinner())
+				return m, withSpinner(m.loadTablesOfSchema(m.selectedDatabase.ID, m.selectedSchema.Name))
 			}
 		}
 
 	case tablesLoaded:
 		m.loading = false
+		// Tables of a database or schema that was left are dropped
+		if m.currentView != viewTables {
+			return m, nil
+		}
+		drilledIn := m.drilledIn
+		m.drilledIn = false
 		if msg.err != nil {
 			m.error = msg.err.Error()
 		} else {
 			m.tables = msg.tables
+			m.tablesRest = msg.rest
+			m.tablesTotal = 0
+			if msg.total > len(msg.tables) {
+				m.tablesTotal = msg.total
+			}
+			if m.autoSkip(drilledIn, len(m.tables)) {
+				return m.selectItem(0)
+			}
 		}
 
 	case fieldsLoaded:
@@ -720,7 +812,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.error = msg.err.Error()
 		} else {
-			m.fields = msg.fields
+			m.fields = sortFields(msg.fields, m.fieldSort)
+			if m.jumpSelect != "" {
+				m.selectJumped()
+			}
+		}
+
+	case searchCompleted:
+		// Results for a query that was replaced or left are dropped
+		if m.currentView != viewSearch || msg.query != m.globalQuery {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil && len(msg.results) == 0 && api.SearchUnavailable(msg.err) {
+			return m.searchLoadedLists(msg.err)
+		} else if msg.err != nil && len(msg.results) == 0 {
+			m.error = msg.err.Error()
+		} else {
+			m.showSortedResults(msg.results)
+			if msg.err != nil {
+				// Some instances answered, show what they found
+				m.statusMessage = "Search failed on " + strings.ReplaceAll(msg.err.Error(), "\n", "; ")
+			}
+		}
+
+	case relatedLoaded:
+		m.loading = false
+		if msg.err != nil {
+			m.error = msg.err.Error()
+		} else {
+			m.relatedItems = msg.items
+			m.viewportStart = 0
 		}
 
 	case cardDetailLoaded:
@@ -747,19 +869,84 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.itemDetail = msg.detail
 		}
 
+	case queryFinished:
+		// Results of a query that was cancelled or replaced are dropped
+		if m.currentView != viewQueryResults || m.selectedItem == nil || m.selectedItem.ID != msg.cardID {
+			return m, nil
+		}
+		m.loading = false
+		m.queryCancel = nil
+		if msg.err != nil {
+			m.error = msg.err.Error()
+		} else {
+			m.queryColumns = msg.columns
+			m.queryRows = msg.rows
+			m.cursor = 0
+		}
+
+	case resultsExported:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Failed to export: %v", msg.err)
+		} else {
+			m.statusMessage = "Exported " + msg.filename
+		}
+
+	case selectionExported:
+		return m.applySelectionExported(msg)
+
+	case itemArchived:
+		return m.applyArchived(msg)
+
+	case moveCollectionsLoaded:
+		return m.applyMoveCollections(msg)
+
+	case itemMoved:
+		return m.applyMoved(msg)
+
+	case schemaSyncRequested:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Failed to sync schema: %v", msg.err)
+		} else {
+			m.statusMessage = "Schema sync started"
+		}
+
+	case gotoResolved:
+		return m.applyGoto(msg)
+
+	case collectionRevealed:
+		return m.applyReveal(msg)
+
+	case prefetchDue:
+		return m.startPrefetch(msg)
+
+	case tablesPrefetched:
+		return m.applyPrefetch(msg)
+
+	case collectionItemsCounted:
+		return m.applyItemCounts(msg)
+
+	case fieldIndexLoaded:
+		return m.applyFieldIndex(msg)
+
 	case versionChecked:
 		if msg.err == nil && msg.latestVersion != "" {
 			m.latestVersion = msg.latestVersion
-			// Compare versions (handle v prefix)
-			currentVersion := m.Version
-			if currentVersion != "dev" {
-				// Normalize versions by removing v prefix
-				normalizedCurrent := strings.TrimPrefix(currentVersion, "v")
-				normalizedLatest := strings.TrimPrefix(msg.latestVersion, "v")
-				if normalizedLatest != normalizedCurrent {
-					m.updateAvailable = true
-				}
-			}
+			m.updateAvailable = updateWanted(m.Version, msg.latestVersion, m.skipVersion, m.updateSnoozedUntil, time.Now())
+		}
+
+	case updateReminderSaved:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Update notice hidden for this session, saving failed: %v", msg.err)
+		} else {
+			m.statusMessage = msg.status
+		}
+
+	case spinnerDue:
+		// Loads that started later than this one, or already finished,
+		// have nothing to show yet
+		if m.loading && !m.spinnerVisible && time.Since(m.loadingSince) >= spinnerDelay {
+			m.spinnerVisible = true
+			return m, tickSpinner()
 		}
 
 	case spinnerTick:
@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInitialModel(t *testing.T) {
+	tests := []struct {
+		name        string
+		configData  string
+		opts        Options
+		expectError string
+	}{
+		{
+			name:        "no configuration",
+			expectError: "missing configuration",
+		},
+		{
+			name:        "malformed config file",
+			configData:  "profiles: [not a map",
+			expectError: "failed to load config",
+		},
+		{
+			name:        "unknown profile",
+			configData:  "default_profile: home\nprofiles:\n  home:\n    url: https://home.metabase.com\n    token: home-token\n",
+			opts:        Options{Profile: "work"},
+			expectError: "missing configuration",
+		},
+		{
+			name:       "profile from config",
+			configData: "default_profile: home\nprofiles:\n  home:\n    url: https://home.metabase.com\n    token: home-token\n",
+		},
+		{
+			name: "flags only",
+			opts: Options{URL: "https://flags.metabase.com", Token: "flag-token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			config.SetGlobalConfigFile(configPath)
+			defer config.SetGlobalConfigFile("")
+			if tt.configData != "" {
+				if err := os.WriteFile(configPath, []byte(tt.configData), 0644); err != nil {
+					t.Fatalf("WriteFile() unexpected error = %v", err)
+				}
+			}
+
+			m, err := InitialModel(tt.opts)
+
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("InitialModel() error = %v, want error containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InitialModel() unexpected error = %v", err)
+			}
+			if m.client == nil || m.currentView != viewMainMenu {
+				t.Errorf("InitialModel() client = %v, view = %v, want a client on the main menu", m.client, m.currentView)
+			}
+		})
+	}
+}
+
+func TestNewModelWithClient(t *testing.T) {
+	client := api.NewMetabaseClient("http://metabase.local", "token", "dev")
+	client.ReadOnly = true
+
+	m := NewModelWithClient(client, "v1.0.0")
+
+	if m.client != client || m.Version != "v1.0.0" || m.currentView != viewMainMenu {
+		t.Errorf("NewModelWithClient() client = %v, version = %q, view = %v, want the given client on the main menu", m.client, m.Version, m.currentView)
+	}
+	if !m.readOnly {
+		t.Error("NewModelWithClient() readOnly = false, want it taken from the client")
+	}
+}
+
+// recordingTransport fails every request, noting the hosts asked for
+type recordingTransport struct {
+	hosts *[]string
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	*t.hosts = append(*t.hosts, req.URL.Host)
+	return nil, errors.New("no network in tests")
+}
+
+// runCmd runs a command and the commands of the batches it returns
+func runCmd(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			runCmd(cmd)
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	for _, offline := range []bool{false, true} {
+		var hosts []string
+		client := &http.Client{Transport: recordingTransport{hosts: &hosts}}
+		m := NewModelWithClient(api.NewMetabaseClient("http://metabase.local", "token", "dev"), "v1.0.0")
+		m.client.HTTPClient = client
+		m.releaseClient = client
+		m.offline = offline
+		m.prefetch = true
+
+		runCmd(m.Init())
+		wantHosts := []string{"metabase.local"}
+		if !offline {
+			wantHosts = []string{"metabase.local", "api.github.com"}
+		}
+		if strings.Join(hosts, ",") != strings.Join(wantHosts, ",") {
+			t.Errorf("offline = %v: Init() requested %v, want %v", offline, hosts, wantHosts)
+		}
+
+		m = send(t, m, key("down"), key("enter"))
+		updated, cmd := m.Update(databasesLoaded{databases: fixtureDatabases})
+		if scheduled := cmd != nil; scheduled == offline {
+			t.Errorf("offline = %v: prefetch scheduled = %v", offline, scheduled)
+		}
+
+		header := strings.SplitN(plainView(updated.(Model)), "\n", 2)[0]
+		if strings.Contains(header, "offline") != offline {
+			t.Errorf("offline = %v: header = %q", offline, header)
+		}
+	}
+}
+
+func TestViewHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		msgs       []tea.Msg
+		wantHeader string
+		wantEmpty  string
+	}{
+		{
+			name:       "main menu",
+			wantHeader: "Metabase Explorer v1.0.0\nMain Menu",
+		},
+		{
+			name:       "databases",
+			msgs:       []tea.Msg{key("down"), key("enter"), databasesLoaded{}},
+			wantHeader: "Metabase Explorer v1.0.0 | Databases\nDatabases (0)",
+			wantEmpty:  "No databases found",
+		},
+		{
+			name: "schemas",
+			msgs: []tea.Msg{
+				key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases},
+				key("enter"), schemasLoaded{},
+			},
+			wantHeader: "Metabase Explorer v1.0.0 | Database schemas\nDatabases > Sample Database (0)",
+			wantEmpty:  "No schemas found",
+		},
+		{
+			name: "tables",
+			msgs: []tea.Msg{
+				key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases},
+				key("enter"), schemasLoaded{schemas: fixtureSchemas},
+				key("enter"), tablesLoaded{},
+			},
+			wantHeader: "Metabase Explorer v1.0.0 | Schema tables\nDatabases > Sample Database > PUBLIC (0)",
+			wantEmpty:  "No tables found",
+		},
+		{
+			name: "fields",
+			msgs: []tea.Msg{
+				key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases},
+				key("enter"), schemasLoaded{schemas: fixtureSchemas},
+				key("enter"), tablesLoaded{tables: fixtureTables},
+				key("enter"), fieldsLoaded{},
+			},
+			wantHeader: "Metabase Explorer v1.0.0 | Table fields\nDatabases > Sample Database > PUBLIC > Orders (0)",
+			wantEmpty:  "No fields found",
+		},
+		{
+			name:       "collections",
+			msgs:       []tea.Msg{key("enter"), collectionsLoaded{}},
+			wantHeader: "Metabase Explorer v1.0.0 | Collections\nCollections (0)",
+			wantEmpty:  "No collections found",
+		},
+		{
+			name: "collection items",
+			msgs: []tea.Msg{
+				key("enter"), collectionsLoaded{collections: fixtureCollections},
+				key("enter"), collectionItemsLoaded{},
+			},
+			wantHeader: "Metabase Explorer v1.0.0 | Collection items\nCollections > Analytics (0)",
+			wantEmpty:  "No items found in this collection",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+
+			if got := viewHeader(m); got != tt.wantHeader {
+				t.Errorf("View() header = %q, want %q", got, tt.wantHeader)
+			}
+			if tt.wantEmpty != "" && !strings.Contains(plainView(m), tt.wantEmpty) {
+				t.Errorf("View() = %q, want empty state %q", plainView(m), tt.wantEmpty)
+			}
+		})
+	}
+}
+
+func TestDismissUpdate(t *testing.T) {
+	m := send(t, newTestModel(), versionChecked{latestVersion: "v1.1.0"})
+	if !strings.Contains(plainView(m), "Update available: v1.1.0") {
+		t.Fatalf("banner not shown:\n%s", plainView(m))
+	}
+
+	m = send(t, m, key("U"))
+	for _, msgs := range [][]tea.Msg{nil, toDatabases, {key("?"), key("?")}} {
+		m = send(t, m, msgs...)
+		if strings.Contains(plainView(m), "Update available") {
+			t.Errorf("banner shown again after %d more messages", len(msgs))
+		}
+	}
+}
+
+func TestSpinnerDelay(t *testing.T) {
+	m := send(t, newTestModel(), key("down"), key("enter"))
+	if !m.loading || m.loadingSince.IsZero() {
+		t.Fatalf("loading = %v, loadingSince = %v, want the load timed", m.loading, m.loadingSince)
+	}
+
+	// A quick load is over before its spinner is due
+	m = send(t, m, databasesLoaded{databases: fixtureDatabases}, spinnerDue{})
+	if m.spinnerVisible || strings.Contains(plainView(m), "Loading") {
+		t.Errorf("spinner shown for a load that finished in time:\n%s", plainView(m))
+	}
+
+	// The due tick of the quick load doesn't show the spinner of the next
+	m = send(t, m, key("enter"), spinnerDue{})
+	if view := plainView(m); m.spinnerVisible || strings.Contains(view, "Loading") {
+		t.Errorf("spinner shown before the load was slow:\n%s", view)
+	}
+
+	m.loadingSince = m.loadingSince.Add(-spinnerDelay)
+	m = send(t, m, spinnerDue{})
+	if view := plainView(m); !m.spinnerVisible || !strings.Contains(view, "Loading schemas") {
+		t.Errorf("spinner missing for a slow load:\n%s", view)
+	}
+	if m = send(t, m, schemasLoaded{schemas: []api.Schema{{Name: "PUBLIC"}, {Name: "SALES"}}}); m.spinnerVisible {
+		t.Error("spinner still visible after the load finished")
+	}
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rootCollection is the entry of the move picker for the root collection
+var rootCollection = api.Collection{ID: "root", Name: "Our analytics"}
+
+// moveTarget is the question or dashboard M moves to another collection
+type moveTarget struct {
+	model        string
+	id           int
+	name         string
+	collectionID interface{} // Collection it is in now, "root" for the root collection
+	view         viewState   // Where M was pressed, the list to reload once it is done
+}
+
+// moveTarget returns the item under the cursor, or the open item, when it
+// is one that can be moved
+func (m Model) moveTarget() (moveTarget, bool) {
+	var item api.CollectionItem
+	var collectionID interface{}
+	switch m.currentView {
+	case viewCollectionItems:
+		if m.cursor >= len(m.collectionItems) || m.selectedCollection == nil {
+			return moveTarget{}, false
+		}
+		item = m.collectionItems[m.cursor]
+		collectionID = collectionRef(m.selectedCollection.ID)
+	case viewItemDetail:
+		if m.selectedItem == nil {
+			return moveTarget{}, false
+		}
+		item = *m.selectedItem
+		collectionID, _, _ = m.containingCollection()
+	default:
+		return moveTarget{}, false
+	}
+	switch item.Model {
+	case "card", "dataset", "metric", "dashboard":
+		return moveTarget{model: item.Model, id: item.ID, name: item.Name, collectionID: collectionID, view: m.currentView}, true
+	}
+	return moveTarget{}, false
+}
+
+// openMovePicker lists the collections the selected item can be moved to
+func (m Model) openMovePicker() (Model, tea.Cmd) {
+	target, ok := m.moveTarget()
+	if !ok || m.loading {
+		return m, nil
+	}
+	if m.readOnly {
+		m.statusMessage = "Moving is turned off in read-only mode"
+		return m, nil
+	}
+	m.moveMode = true
+	m.moving = target
+	m.moveCollections = nil
+	m.moveDepths = nil
+	m.moveCursor = 0
+	return m, loadMoveCollections(m.client)
+}
+
+func loadMoveCollections(client *api.MetabaseClient) tea.Cmd {
+	return func() tea.Msg {
+		tree, err := client.GetCollectionTree(false)
+		return moveCollectionsLoaded{tree: tree, err: err}
+	}
+}
+
+// applyMoveCollections fills the picker with the whole tree under the root
+// collection, with the cursor on the collection the item is in now
+func (m Model) applyMoveCollections(msg moveCollectionsLoaded) (Model, tea.Cmd) {
+	if !m.moveMode {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.moveMode = false
+		m.statusMessage = fmt.Sprintf("Failed to load collections: %v", msg.err)
+		return m, nil
+	}
+	collections, depths := flattenCollectionTree(msg.tree, expandAll(msg.tree))
+	m.moveCollections = append([]api.Collection{rootCollection}, collections...)
+	m.moveDepths = append([]int{0}, depths...)
+	for i, collection := range m.moveCollections {
+		if m.isMoveSource(collection) {
+			m.moveCursor = i
+		}
+	}
+	return m, nil
+}
+
+// isMoveSource reports whether the item being moved is in the collection
+func (m Model) isMoveSource(collection api.Collection) bool {
+	return fmt.Sprint(collectionRef(collection.ID)) == fmt.Sprint(m.moving.collectionID)
+}
+
+// updateMovePicker handles key presses while the collection picker is open
+func (m Model) updateMovePicker(msg tea.KeyMsg) (Model, tea.Cmd) {
+	action := m.keyMap().action(msg.String())
+	switch {
+	case msg.String() == "M" || action == actionBack || action == actionQuit:
+		m.moveMode = false
+	case action == actionUp:
+		if m.moveCursor > 0 {
+			m.moveCursor--
+		}
+	case action == actionDown:
+		if m.moveCursor < len(m.moveCollections)-1 {
+			m.moveCursor++
+		}
+	case action == actionForward:
+		if m.moveCursor >= len(m.moveCollections) {
+			return m, nil
+		}
+		target := m.moving
+		to := m.moveCollections[m.moveCursor]
+		if m.isMoveSource(to) {
+			m.statusMessage = fmt.Sprintf("%s is already in %s", target.name, to.Name)
+			return m, nil
+		}
+		m.moveMode = false
+		m.confirm = &confirmation{
+			prompt: fmt.Sprintf("Move %q to %q?", target.name, to.Name),
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, moveItem(m.client, target, to)
+			},
+		}
+	}
+	return m, nil
+}
+
+// applyMoved reports the move and reloads the list the item was moved from
+func (m Model) applyMoved(msg itemMoved) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to move %s: %v", msg.target.name, msg.err)
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("Moved %s to %s", msg.target.name, msg.to.Name)
+	if m.currentView != msg.target.view || m.loading {
+		return m, nil
+	}
+	return m.refresh()
+}
+
+func (m Model) renderMovePicker(output *strings.Builder) {
+	if m.moveCollections == nil {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("Loading collections..."))
+		output.WriteString("\n")
+		return
+	}
+	// Scrolled so the cursor stays in view
+	start, end := 0, len(m.moveCollections)
+	if m.viewportHeight > 0 {
+		start = max(m.moveCursor-m.viewportHeight+1, 0)
+		end = min(start+m.viewportHeight, end)
+	}
+	for i := start; i < end; i++ {
+		collection := m.moveCollections[i]
+		label := strings.Repeat("  ", m.moveDepths[i]) + collection.Name
+		if i == m.moveCursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + label))
+		} else {
+			output.WriteString("  " + label)
+		}
+		if m.isMoveSource(collection) {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(" (current)"))
+		}
+		output.WriteString("\n")
+	}
+}
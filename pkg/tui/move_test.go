@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var moveTree = []api.Collection{
+	{ID: 5, Name: "Analytics", Children: []api.Collection{{ID: 8, Name: "Quarterly"}}},
+	{ID: 6, Name: "Marketing"},
+}
+
+func TestMoveItem(t *testing.T) {
+	m := send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{key("down"), key("M")})...)
+	if !m.moveMode || m.moving.id != 21 {
+		t.Fatalf("M: moveMode = %v, moving = %+v, want the picker for Orders by month", m.moveMode, m.moving)
+	}
+
+	m = send(t, m, moveCollectionsLoaded{tree: moveTree})
+	view := plainView(m)
+	for _, want := range []string{"Move Orders by month to", "  Our analytics\n", "▶ Analytics (current)", "    Quarterly\n", "  Marketing\n"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+
+	m = send(t, m, key("enter"))
+	if !m.moveMode || m.statusMessage != "Orders by month is already in Analytics" {
+		t.Errorf("enter on the current collection: moveMode = %v, status = %q", m.moveMode, m.statusMessage)
+	}
+
+	m = send(t, m, key("down"), key("enter"))
+	if m.moveMode || m.confirm == nil || m.confirm.prompt != `Move "Orders by month" to "Quarterly"?` {
+		t.Fatalf("enter on Quarterly: moveMode = %v, confirm = %+v, want the move confirmed first", m.moveMode, m.confirm)
+	}
+	updated, cmd := m.Update(key("y"))
+	m = updated.(Model)
+	if m.confirm != nil || cmd == nil {
+		t.Fatalf("y: confirm = %v, cmd = %v, want the move requested", m.confirm, cmd)
+	}
+
+	m = send(t, m, itemMoved{target: m.moving, to: moveTree[0].Children[0]})
+	if m.statusMessage != "Moved Orders by month to Quarterly" || !m.loading {
+		t.Errorf("status = %q, loading = %v, want the items reloading after the move", m.statusMessage, m.loading)
+	}
+}
+
+func TestMoveItem_Root(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	target := moveTarget{model: "dashboard", id: 20, name: "Revenue", collectionID: 5}
+	msg := moveItem(api.NewMetabaseClient(server.URL, "token", "dev"), target, rootCollection)()
+	if moved, ok := msg.(itemMoved); !ok || moved.err != nil {
+		t.Fatalf("moveItem() = %#v, want the item moved", msg)
+	}
+	if body != `{"collection_id":null}` {
+		t.Errorf("request body = %s, want the root collection", body)
+	}
+}
+
+func TestMoveItem_ReadOnly(t *testing.T) {
+	m := newTestModel()
+	m.readOnly = true
+	m = send(t, m, steps(toCollectionItems, []tea.Msg{key("down"), key("M")})...)
+	if m.moveMode || m.statusMessage != "Moving is turned off in read-only mode" {
+		t.Errorf("moveMode = %v, status = %q, want the move refused", m.moveMode, m.statusMessage)
+	}
+}
+
+func TestMoveItem_CustomKeys(t *testing.T) {
+	keys, err := newKeyMap(map[string][]string{
+		actionUp:      {"g"},
+		actionDown:    {"z"},
+		actionForward: {"ctrl+f"},
+		actionBack:    {"u"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{key("down"), key("M"), moveCollectionsLoaded{tree: moveTree}})...)
+	m.keys = keys
+
+	// Keys dropped from an action no longer move the cursor
+	cursor := m.moveCursor
+	m = send(t, m, key("down"), key("j"))
+	if m.moveCursor != cursor {
+		t.Errorf("down after rebinding: cursor = %d, want %d", m.moveCursor, cursor)
+	}
+	m = send(t, m, key("z"), key("z"), key("g"))
+	if m.moveCursor != cursor+1 {
+		t.Errorf("z z g: cursor = %d, want %d", m.moveCursor, cursor+1)
+	}
+
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyCtrlF})
+	if m.moveMode || m.confirm == nil || m.confirm.prompt != `Move "Orders by month" to "Quarterly"?` {
+		t.Errorf("ctrl+f bound to forward: moveMode = %v, confirm = %+v, want the move confirmed", m.moveMode, m.confirm)
+	}
+
+	m = send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{key("down"), key("M")})...)
+	m.keys = keys
+	m = send(t, m, key("u"))
+	if m.moveMode {
+		t.Error("u bound to back: want the picker closed")
+	}
+}
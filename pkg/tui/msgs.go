@@ -2,6 +2,7 @@ package tui
 
 import (
 	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 
@@ -17,6 +18,8 @@ type schemasLoaded struct {
 
 type tablesLoaded struct {
 	tables []api.Table
+	total  int         // Tables before the flat list was capped, see capTables
+	rest   []api.Table // The tables the cap left out, shown without a new request
 	err    error
 }
 
@@ -30,14 +33,30 @@ type versionChecked struct {
 	err           error
 }
 
+type updateReminderSaved struct {
+	status string // Feedback for the user when saving worked
+	err    error
+}
+
 type spinnerTick struct{}
 
+// spinnerDue is sent spinnerDelay after a load started
+type spinnerDue struct{}
+
+// loadFinished carries the result of a load started with withSpinner and
+// the load itself for retrying
+type loadFinished struct {
+	msg   tea.Msg
+	retry tea.Cmd
+}
+
 type connectionTested struct {
 	err error
 }
 
 type collectionsLoaded struct {
 	collections []api.Collection
+	tree        bool // The collections are the top of the tree, with children
 	err         error
 }
 
@@ -46,6 +65,17 @@ type collectionItemsLoaded struct {
 	err   error
 }
 
+type searchCompleted struct {
+	query   string
+	results []api.SearchResult
+	err     error
+}
+
+type relatedLoaded struct {
+	items []api.CollectionItem
+	err   error
+}
+
 type cardDetailLoaded struct {
 	detail *api.CardDetail
 	err    error
@@ -60,3 +90,97 @@ type metricDetailLoaded struct {
 	detail *api.MetricDetail
 	err    error
 }
+
+type gotoResolved struct {
+	command     gotoCommand
+	databases   []api.Database
+	table       *api.Table
+	tables      []api.Table // Every table in the table's database
+	collection  *api.Collection
+	collections []api.Collection
+	start       bool // Opening the profile's default at launch
+	err         error
+}
+
+// prefetchDue is sent once the cursor has rested on a database long enough
+// to prefetch its tables
+type prefetchDue struct {
+	databaseID int
+}
+
+type tablesPrefetched struct {
+	profile    string // Profile the tables were fetched from
+	databaseID int
+	tables     []api.Table
+	err        error
+}
+
+// fieldIndexLoaded carries the metadata the field search of a database
+// indexes
+type fieldIndexLoaded struct {
+	databaseID int
+	tables     []api.Table
+	err        error
+}
+
+// collectionRevealed is the collection an item is saved in, with the root
+// collections to go back to
+type collectionRevealed struct {
+	collection  *api.Collection
+	collections []api.Collection
+	err         error
+}
+
+type queryFinished struct {
+	cardID  int
+	columns []string
+	rows    [][]interface{}
+	err     error
+}
+
+type resultsExported struct {
+	filename string
+	err      error
+}
+
+type schemaSyncRequested struct {
+	err error
+}
+
+type moveCollectionsLoaded struct {
+	tree []api.Collection
+	err  error
+}
+
+// itemMoved is the answer to moving target to the collection to
+type itemMoved struct {
+	target moveTarget
+	to     api.Collection
+	err    error
+}
+
+// selectionExported lists the files the selected questions were saved to,
+// up to the one that failed
+type selectionExported struct {
+	filenames []string
+	err       error
+}
+
+// itemArchived is the answer to archiving or unarchiving target
+type itemArchived struct {
+	target archiveTarget
+	err    error
+}
+
+// collectionItemsCounted carries the item counts of collections by list
+// key, with the first error of the ones that could not be counted
+type collectionItemsCounted struct {
+	counts map[string]int
+	err    error
+}
+
+// tokenTested is the answer to connecting with a new API key
+type tokenTested struct {
+	token string
+	err   error
+}
@@ -0,0 +1,526 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// menuEntry is an option on the main menu
+type menuEntry struct {
+	name string
+	key  string // Shortcut of a palette action, shown when searching
+	open func(Model) (Model, tea.Cmd)
+}
+
+// mainMenu lists the main menu options in display order
+var mainMenu = []menuEntry{
+	{name: "Collections", open: Model.openCollections},
+	{name: "Databases", open: Model.openDatabases},
+	{name: "My content", open: Model.openMyContent},
+	{name: "Recent changes", open: Model.openRecentChanges},
+}
+
+// menuEntries are what a search of the main menu goes through: its options,
+// then the palette actions available there
+func (m Model) menuEntries() []menuEntry {
+	entries := append([]menuEntry(nil), mainMenu...)
+	for _, action := range m.availableActions() {
+		entries = append(entries, menuEntry{name: action.name, key: m.actionKey(action), open: action.run})
+	}
+	return entries
+}
+
+// selectItem drills into the item at index in the current view
+func (m Model) selectItem(index int) (Model, tea.Cmd) {
+	if m.currentView == viewMainMenu {
+		entries := m.menuEntries()
+		if index >= len(entries) {
+			return m, nil
+		}
+		if index >= len(mainMenu) {
+			m.cursor = 0
+		}
+		return entries[index].open(m)
+	}
+	if m.currentView == viewDatabases && len(m.databases) > 0 && m.flattenTables {
+		m.selectedDatabase = &m.databases[index]
+		m.selectedSchema = nil
+		m.showAllTables = false
+		m.currentView = viewTables
+		m.cursor = 0
+		m.loading = true
+		m.error = ""
+		m.drilledIn = true
+		return m, withSpinner(m.loadAllTablesOf(m.selectedDatabase.ID))
+	} else if m.currentView == viewDatabases && len(m.databases) > 0 {
+		m.selectedDatabase = &m.databases[index]
+		m.currentView = viewSchemas
+		m.cursor = 0
+		m.loading = true
+		m.error = ""
+		m.drilledIn = true
+		return m, withSpinner(m.loadSchemasOf(m.selectedDatabase.ID))
+	} else if m.currentView == viewCollections && len(m.collections) > 0 {
+		m.selectedCollection = &m.collections[index]
+		m.collectionStack = nil // Clear stack when entering from root collections
+		m.currentView = viewCollectionItems
+		m.cursor = 0
+		m.loading = true
+		m.error = ""
+		m.drilledIn = true
+		return m, withSpinner(loadCollectionItems(m.client, m.selectedCollection.ID))
+	} else if m.currentView == viewCollectionItems && len(m.collectionItems) > 0 {
+		item := m.collectionItems[index]
+		if item.Model == "collection" {
+			// Push current collection to stack before drilling into sub-collection
+			m.collectionStack = append(m.collectionStack, m.selectedCollection)
+			m.selectedCollection = &api.Collection{
+				ID:   item.ID,
+				Name: item.Name,
+			}
+			m.currentView = viewCollectionItems
+			m.cursor = 0
+			m.loading = true
+			m.error = ""
+			m.drilledIn = true
+			return m, withSpinner(loadCollectionItems(m.client, item.ID))
+		} else {
+			// Show item detail for non-collection items
+			m.selectedItem = &item
+			m.currentView = viewItemDetail
+			m.cursor = 0
+			m.loading = true
+			m.error = ""
+			// Load detailed information for cards, dashboards, and metrics
+			if item.Model == "card" || item.Model == "dataset" {
+				return m, withSpinner(loadCardDetail(m.client, item.ID))
+			} else if item.Model == "dashboard" {
+				return m, withSpinner(loadDashboardDetail(m.client, item.ID))
+			} else if item.Model == "metric" {
+				return m, withSpinner(loadMetricDetail(m.client, item.ID))
+			}
+			// Snippets, pulses and the like only have what the list returned
+			m.loading = false
+			return m, nil
+		}
+	} else if m.currentView == viewSchemas && len(m.schemas) > 0 {
+		m.selectedSchema = &m.schemas[index]
+		m.currentView = viewTables
+		m.cursor = 0
+		m.loading = true
+		m.error = ""
+		m.drilledIn = true
+		return m, withSpinner(m.loadTablesOfSchema(m.selectedDatabase.ID, m.selectedSchema.Name))
+	} else if m.currentView == viewTables && len(m.tables) > 0 {
+		m.selectedTable = &m.tables[index]
+		if m.flattenTables {
+			// Keep the breadcrumb complete even though no schema was picked
+			m.selectedSchema = &api.Schema{Name: tableSchemaName(*m.selectedTable)}
+		}
+		m.currentView = viewFields
+		m.cursor = 0
+		m.loading = true
+		m.error = ""
+		return m, withSpinner(loadFields(m.client, m.selectedTable.ID))
+	} else if m.currentView == viewItemDetail && len(m.dashboardCards()) > 0 {
+		// Open a dashboard's card, remembering the dashboard to return to
+		card := m.dashboardCards()[index]
+		m.itemStack = append(m.itemStack, itemFrame{item: m.selectedItem, detail: m.itemDetail, cursor: index})
+		m.selectedItem = &api.CollectionItem{
+			ID:          *card.CardID,
+			Name:        card.Card.Name,
+			Description: card.Card.Description,
+			Model:       "card",
+		}
+		m.itemDetail = nil
+		m.cursor = 0
+		m.loading = true
+		m.error = ""
+		return m, withSpinner(loadCardDetail(m.client, *card.CardID))
+	} else if m.currentView == viewSearch && len(m.searchResults) > 0 {
+		return m.openSearchResult(index)
+	} else if m.currentView == viewRelated && len(m.relatedItems) > 0 {
+		// Related items live outside the browsed collection, so open them in Metabase
+		m.cursor = index
+		return m.openWebURL()
+	} else if m.currentView == viewFieldSearch && len(m.fieldIndex) > 0 {
+		return m.openFieldHit(index)
+	} else if m.currentView == viewFields && len(m.fields) > 0 {
+		// Field details come with the table metadata, so there is nothing to load
+		m.selectedField = &m.fields[index]
+		m.fieldCursor = index
+		m.currentView = viewFieldDetail
+		m.cursor = 0
+		m.error = ""
+	}
+	return m, nil
+}
+
+// autoSkip reports whether a list that just loaded should be skipped by
+// opening its only entry. The single schema of a database is always skipped;
+// tables and collection items only with auto_skip_single, and only when the
+// list was reached by drilling in, so going back or refreshing stays put.
+func (m Model) autoSkip(drilledIn bool, count int) bool {
+	return m.autoSkipSingle && drilledIn && count == 1
+}
+
+// openSearch starts filtering the current list
+func (m Model) openSearch() Model {
+	if m.helpMode || m.currentView == viewFieldDetail || m.currentView == viewQueryResults {
+		return m
+	}
+	m.searchMode = true
+	m.searchQuery = ""
+	m.cursor = 0
+	return m
+}
+
+// moveUp moves the cursor to the previous item or help link
+func (m Model) moveUp() Model {
+	if m.helpMode {
+		if m.helpCursor > 0 {
+			m.helpCursor--
+		}
+		return m
+	}
+	m.numberInput = "" // Clear number input when using arrow keys
+	if m.cursor > 0 {
+		m.cursor--
+		// Update viewport for collections and other views that might have many items
+		if m.currentView == viewCollectionItems && len(m.collectionItems) > 0 {
+			m.updateViewport(len(m.collectionItems))
+		} else if m.currentView == viewRelated {
+			m.updateViewport(len(m.relatedItems))
+		} else if m.currentView == viewSearch {
+			m.updateViewport(len(m.searchResults))
+		} else if m.currentView == viewFieldSearch {
+			m.updateViewport(len(m.fieldIndex))
+		} else if m.currentView == viewTables && m.flattenTables {
+			m.scrollTableRows()
+		} else if m.currentView == viewFields && m.showLegend {
+			m.scrollFields()
+		}
+	}
+	return m
+}
+
+// moveDown moves the cursor to the next item or help link
+func (m Model) moveDown() Model {
+	if m.helpMode {
+		// We have 3 links: Repository, Issues, Sponsor
+		if m.helpCursor < 2 {
+			m.helpCursor++
+		}
+		return m
+	}
+	m.numberInput = "" // Clear number input when using arrow keys
+	if m.currentView == viewMainMenu && m.cursor < len(mainMenu)-1 {
+		m.cursor++
+	} else if m.currentView == viewDatabases && m.cursor < len(m.databases)-1 {
+		m.cursor++
+	} else if m.currentView == viewCollections && m.cursor < len(m.collections)-1 {
+		m.cursor++
+	} else if m.currentView == viewCollectionItems && m.cursor < len(m.collectionItems)-1 {
+		m.cursor++
+		m.updateViewport(len(m.collectionItems))
+	} else if m.currentView == viewSchemas && m.cursor < len(m.schemas)-1 {
+		m.cursor++
+	} else if m.currentView == viewTables && m.cursor < len(m.tables)-1 {
+		m.cursor++
+		if m.flattenTables {
+			m.scrollTableRows()
+		}
+	} else if m.currentView == viewFields && m.cursor < len(m.fields)-1 {
+		m.cursor++
+		if m.showLegend {
+			m.scrollFields()
+		}
+	} else if m.currentView == viewItemDetail && m.cursor < len(m.dashboardCards())-1 {
+		m.cursor++
+	} else if m.currentView == viewQueryResults && m.cursor < len(m.queryRows)-1 {
+		m.cursor++
+	} else if m.currentView == viewRelated && m.cursor < len(m.relatedItems)-1 {
+		m.cursor++
+		m.updateViewport(len(m.relatedItems))
+	} else if m.currentView == viewSearch && m.cursor < len(m.searchResults)-1 {
+		m.cursor++
+		m.updateViewport(len(m.searchResults))
+	} else if m.currentView == viewFieldSearch && m.cursor < len(m.fieldIndex)-1 {
+		m.cursor++
+		m.updateViewport(len(m.fieldIndex))
+	}
+	return m
+}
+
+// back closes the help or a typed number before leaving the current view
+func (m Model) back() (Model, tea.Cmd) {
+	if m.helpMode {
+		m.helpMode = false
+		return m, nil
+	}
+	if m.numberInput != "" {
+		// Clear number input
+		m.numberInput = ""
+		return m, nil
+	}
+	if collapsed, ok := m.collapseCollection(); ok {
+		return collapsed, nil
+	}
+	return m.goBack()
+}
+
+// forward opens the selected help link, menu entry or item
+func (m Model) forward() (Model, tea.Cmd) {
+	if m.helpMode {
+		// Open selected link in browser
+		var url string
+		switch m.helpCursor {
+		case 0:
+			url = "https://github.com/amureki/metabase-explorer"
+		case 1:
+			url = "https://github.com/amureki/metabase-explorer/issues"
+		case 2:
+			url = "https://github.com/sponsors/amureki"
+		}
+		if err := util.OpenInBrowser(url); err != nil {
+			m.error = fmt.Sprintf("Failed to open browser: %v", err)
+		}
+		return m, nil
+	}
+	// Clear number input after navigation
+	m.numberInput = ""
+
+	if expanded, ok := m.expandCollection(); ok {
+		return expanded, nil
+	}
+
+	if m.currentView == viewMainMenu {
+		if m.cursor < len(mainMenu) {
+			return mainMenu[m.cursor].open(m)
+		}
+		return m, nil
+	}
+	return m.selectItem(m.cursor)
+}
+
+// goBack returns to the parent of the current view, restoring the search
+// that led away from it when sticky search is enabled
+func (m Model) goBack() (Model, tea.Cmd) {
+	m, cmd := m.leaveView()
+	return m.restoreSearch(), cmd
+}
+
+// leaveView switches to the parent of the current view
+func (m Model) leaveView() (Model, tea.Cmd) {
+	if m.currentView == viewDatabases || m.currentView == viewCollections {
+		m.currentView = viewMainMenu
+		m.cursor = 0
+		m.dropRefresh()
+		m.selectedDatabase = nil
+		m.databases = nil
+		m.collections = nil
+	} else if m.currentView == viewCollectionItems {
+		if len(m.collectionStack) > 0 {
+			// Pop from stack to go to parent collection
+			m.selectedCollection = m.collectionStack[len(m.collectionStack)-1]
+			m.collectionStack = m.collectionStack[:len(m.collectionStack)-1]
+			m.cursor = 0
+			m.loading = true
+			m.error = ""
+			return m, withSpinner(loadCollectionItems(m.client, m.selectedCollection.ID))
+		} else {
+			// Go back to root collections
+			m.currentView = viewCollections
+			m.cursor = 0
+			m.selectedCollection = nil
+			m.collectionItems = nil
+		}
+	} else if m.currentView == viewItemDetail && len(m.itemStack) > 0 {
+		// Return to the dashboard the card was opened from
+		frame := m.itemStack[len(m.itemStack)-1]
+		m.itemStack = m.itemStack[:len(m.itemStack)-1]
+		m.selectedItem = frame.item
+		m.itemDetail = frame.detail
+		m.cursor = frame.cursor
+		m.loading = false
+		m.error = ""
+	} else if m.currentView == viewItemDetail {
+		// Go back to collection items
+		m.currentView = viewCollectionItems
+		m.cursor = 0
+		m.selectedItem = nil
+		m.itemDetail = nil
+	} else if m.currentView == viewSchemas {
+		m.currentView = viewDatabases
+		m.cursor = 0
+		m.selectedDatabase = nil
+		m.schemas = nil
+	} else if m.currentView == viewTables && m.flattenTables {
+		// The flat list skipped the schemas view on the way in
+		m.currentView = viewDatabases
+		m.cursor = 0
+		m.selectedDatabase = nil
+		m.selectedSchema = nil
+		m.tables = nil
+	} else if m.currentView == viewTables {
+		m.currentView = viewSchemas
+		m.cursor = 0
+		m.selectedSchema = nil
+		m.tables = nil
+	} else if m.currentView == viewFields {
+		m.currentView = viewTables
+		m.cursor = 0
+		m.selectedTable = nil
+		m.fields = nil
+		if m.flattenTables {
+			m.selectedSchema = nil
+		}
+	} else if m.currentView == viewQueryResults {
+		// Leaving while the query runs cancels it
+		if m.queryCancel != nil {
+			m.queryCancel()
+			m.queryCancel = nil
+		}
+		m.currentView = viewItemDetail
+		m.cursor = 0
+		m.loading = false
+		m.error = ""
+		m.queryColumns = nil
+		m.queryRows = nil
+	} else if m.currentView == viewSearch {
+		// Return to wherever the search was started from
+		m.currentView = m.searchFrom
+		m.cursor = m.searchFromCursor
+		m.loading = false
+		m.error = ""
+		m.searchResults = nil
+		m.globalQuery = ""
+		m.searchScope = nil
+		m.myContent = false
+		m.recentChanges = false
+	} else if m.currentView == viewRelated {
+		// Return to the table list or fields the related list was opened from
+		m.currentView = m.relatedFrom
+		m.cursor = m.relatedCursor
+		m.loading = false
+		m.error = ""
+		m.relatedItems = nil
+		m.relatedTable = nil
+	} else if m.currentView == viewFieldSearch {
+		// Return to wherever the field search was started from
+		m.currentView = m.fieldSearchFrom
+		m.cursor = m.fieldSearchCursor
+		m.loading = false
+		m.error = ""
+		m.fieldIndex = nil
+		m.databaseTables = nil
+	} else if m.currentView == viewFieldDetail {
+		// Return to the field that was opened rather than the top of the list
+		m.currentView = viewFields
+		m.cursor = m.fieldCursor
+		m.selectedField = nil
+	}
+	return m, nil
+}
+
+// itemFrame is an item that was left to open one of its children, such as a
+// dashboard whose card is now open
+type itemFrame struct {
+	item   *api.CollectionItem
+	detail api.DetailInfo
+	cursor int
+}
+
+// dashboardCards returns the cards of the open dashboard, if one is open
+func (m Model) dashboardCards() []api.DashboardCard {
+	if dashboard, ok := m.itemDetail.(*api.DashboardDetail); ok {
+		return dashboard.Cards()
+	}
+	return nil
+}
+
+// savedSearch is a search that was active when the user drilled out of a list
+type savedSearch struct {
+	list  string // listKey of the list the search filtered
+	query string
+	index int // Item that was selected from the filtered results
+}
+
+// listKey identifies the list on screen, so a saved search is only restored
+// onto the list it was typed in
+func (m Model) listKey() string {
+	switch m.currentView {
+	case viewDatabases:
+		return "databases"
+	case viewCollections:
+		return "collections"
+	case viewCollectionItems:
+		return fmt.Sprintf("collection:%v", m.selectedCollection.ID)
+	case viewSchemas:
+		return fmt.Sprintf("schemas:%d", m.selectedDatabase.ID)
+	case viewTables:
+		if m.selectedSchema == nil {
+			return fmt.Sprintf("tables:%d", m.selectedDatabase.ID)
+		}
+		return fmt.Sprintf("tables:%d:%s", m.selectedDatabase.ID, m.selectedSchema.Name)
+	case viewFields:
+		return fmt.Sprintf("fields:%d", m.selectedTable.ID)
+	}
+	return ""
+}
+
+// saveSearch remembers the active search before selecting index from it
+func (m Model) saveSearch(index int) Model {
+	// The main menu isn't reloaded, so its search has nothing to restore
+	if !m.stickySearch || m.searchQuery == "" || m.currentView == viewMainMenu {
+		return m
+	}
+	m.savedSearches = append(m.savedSearches, savedSearch{
+		list:  m.listKey(),
+		query: m.searchQuery,
+		index: index,
+	})
+	return m
+}
+
+// restoreSearch re-applies the saved search for the list just returned to.
+// Searches saved for lists that are no longer on the way back are dropped.
+func (m Model) restoreSearch() Model {
+	if len(m.savedSearches) == 0 {
+		return m
+	}
+
+	saved := m.savedSearches[len(m.savedSearches)-1]
+	if saved.list != m.listKey() {
+		if m.currentView == viewMainMenu || m.currentView == viewDatabases || m.currentView == viewCollections {
+			// Back at a root list, nothing deeper can still apply
+			m.savedSearches = nil
+		}
+		return m
+	}
+
+	m.savedSearches = m.savedSearches[:len(m.savedSearches)-1]
+	m.searchMode = true
+	m.searchQuery = saved.query
+	m.restoreIndex = &saved.index
+	m.updateSearch()
+	m.selectRestoredSearchItem()
+	return m
+}
+
+// selectRestoredSearchItem moves the cursor onto the item that was picked
+// from a restored search. Lists that reload on the way back call this again
+// once their items arrive.
+func (m *Model) selectRestoredSearchItem() {
+	if m.restoreIndex == nil {
+		return
+	}
+	for i, index := range m.filteredIndices {
+		if index == *m.restoreIndex {
+			m.cursor = i
+		}
+	}
+}
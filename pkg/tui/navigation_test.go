@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func tablesModel(sticky bool) Model {
+	return Model{
+		client:           api.NewMetabaseClient("http://metabase.local", "token", "dev"),
+		stickySearch:     sticky,
+		currentView:      viewTables,
+		selectedDatabase: &api.Database{ID: 1, Name: "Sample"},
+		selectedSchema:   &api.Schema{Name: "public"},
+		tables: []api.Table{
+			{ID: 1, Name: "users"},
+			{ID: 2, Name: "orders"},
+			{ID: 3, Name: "order_items"},
+		},
+	}
+}
+
+// searchAndOpen searches the tables list for "orders" and opens the match
+func searchAndOpen(t *testing.T, m Model) Model {
+	m = send(t, m, key("/"))
+	for _, r := range "orders" {
+		m = send(t, m, key(string(r)))
+	}
+	m = send(t, m, key("enter"))
+	// Fields load asynchronously; the test only cares about the navigation
+	m.loading = false
+	return m
+}
+
+func TestStickySearch_RestoredOnBack(t *testing.T) {
+	m := searchAndOpen(t, tablesModel(true))
+	if m.currentView != viewFields || m.searchMode {
+		t.Fatalf("selecting a search result: view = %v, searchMode = %v, want fields without search", m.currentView, m.searchMode)
+	}
+
+	m = send(t, m, key("esc"))
+
+	if m.currentView != viewTables || !m.searchMode || m.searchQuery != "orders" {
+		t.Fatalf("goBack() view = %v, searchMode = %v, query = %q, want tables searching %q", m.currentView, m.searchMode, m.searchQuery, "orders")
+	}
+	if len(m.filteredIndices) == 0 || m.filteredIndices[m.cursor] != 1 {
+		t.Errorf("goBack() cursor = %d over %v, want it on orders (index 1)", m.cursor, m.filteredIndices)
+	}
+	if len(m.savedSearches) != 0 {
+		t.Errorf("goBack() left %d saved searches, want the restored one consumed", len(m.savedSearches))
+	}
+}
+
+func TestStickySearch_Disabled(t *testing.T) {
+	m := searchAndOpen(t, tablesModel(false))
+	m = send(t, m, key("esc"))
+
+	if m.currentView != viewTables || m.searchMode || m.searchQuery != "" {
+		t.Errorf("goBack() searchMode = %v, query = %q, want search cleared", m.searchMode, m.searchQuery)
+	}
+}
+
+func TestStickySearch_EscClears(t *testing.T) {
+	m := searchAndOpen(t, tablesModel(true))
+	m = send(t, m, key("esc"), key("esc"))
+
+	if m.currentView != viewTables || m.searchMode || m.searchQuery != "" {
+		t.Errorf("esc in restored search: searchMode = %v, query = %q, want search cleared", m.searchMode, m.searchQuery)
+	}
+
+	// Leaving and re-entering must not bring the cleared search back
+	m, _ = m.selectItem(1)
+	m.loading = false
+	m = send(t, m, key("esc"))
+	if m.searchMode {
+		t.Errorf("goBack() restored a search that was cleared with esc, query = %q", m.searchQuery)
+	}
+}
+
+func TestStickySearch_DifferentList(t *testing.T) {
+	m := searchAndOpen(t, tablesModel(true))
+
+	// Go back through a different schema's table list: its search must not
+	// be applied there, and reaching a root list drops it entirely
+	m.currentView = viewTables
+	m.selectedSchema = &api.Schema{Name: "analytics"}
+	m = m.restoreSearch()
+	if m.searchMode {
+		t.Errorf("restoreSearch() applied %q to another list", m.searchQuery)
+	}
+	if len(m.savedSearches) != 1 {
+		t.Fatalf("restoreSearch() saved searches = %d, want 1 kept for the way back", len(m.savedSearches))
+	}
+
+	m.currentView = viewDatabases
+	m = m.restoreSearch()
+	if m.searchMode || len(m.savedSearches) != 0 {
+		t.Errorf("restoreSearch() at databases: searchMode = %v, saved = %d, want everything cleared", m.searchMode, len(m.savedSearches))
+	}
+}
+
+func TestDashboardCardNavigation(t *testing.T) {
+	m := send(t, newTestModel(), toDashboard...)
+	m = send(t, m, key("down"), key("enter"))
+
+	if m.currentView != viewItemDetail || m.selectedItem.ID != 22 || m.selectedItem.Model != "card" || !m.loading {
+		t.Fatalf("enter on a dashboard card: item = %+v, loading = %v, want card 22 loading", m.selectedItem, m.loading)
+	}
+	m = send(t, m, cardDetailLoaded{detail: &api.CardDetail{ID: 22, Name: "Top products"}})
+	if !m.canRunQuery() {
+		t.Error("canRunQuery() = false for a card opened from a dashboard, want it runnable")
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewItemDetail || m.selectedItem.ID != 20 || m.cursor != 1 || len(m.dashboardCards()) != 2 {
+		t.Errorf("esc from the card: item = %+v, cursor = %d, want the dashboard with the card selected", m.selectedItem, m.cursor)
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewCollectionItems {
+		t.Errorf("esc from the dashboard: view = %v, want collection items", m.currentView)
+	}
+}
+
+func TestRelatedNavigation(t *testing.T) {
+	tests := []struct {
+		name     string
+		msgs     []tea.Msg
+		wantView viewState
+	}{
+		{name: "from tables", msgs: steps(toTables, []tea.Msg{key("down")}), wantView: viewTables},
+		{name: "from fields", msgs: steps(toFields, []tea.Msg{key("down")}), wantView: viewFields},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			wantTable := m.selectedTable
+			if tt.wantView == viewTables {
+				wantTable = &m.tables[m.cursor]
+			}
+
+			m = send(t, m, key("R"))
+			if m.currentView != viewRelated || !m.loading || m.relatedTable.ID != wantTable.ID {
+				t.Fatalf("R: view = %v, loading = %v, table = %+v, want related for %s", m.currentView, m.loading, m.relatedTable, wantTable.Name)
+			}
+			m = send(t, m, relatedLoaded{items: fixtureRelated}, key("down"))
+			if got, want := m.getWebURL(), "http://metabase.local/question/21"; got != want {
+				t.Errorf("getWebURL() = %q, want %q", got, want)
+			}
+
+			m = send(t, m, key("esc"))
+			if m.currentView != tt.wantView || m.cursor != 1 || m.relatedItems != nil {
+				t.Errorf("esc: view = %v, cursor = %d, want %v with the cursor kept", m.currentView, m.cursor, tt.wantView)
+			}
+		})
+	}
+}
+
+func TestAutoSkipSingle(t *testing.T) {
+	oneTable := tablesLoaded{tables: fixtureTables[:1]}
+	oneItem := collectionItemsLoaded{items: fixtureCollectionItems[1:2]}
+	oneSubCollection := collectionItemsLoaded{items: fixtureCollectionItems[2:]}
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		msgs     []tea.Msg
+		wantView viewState
+		wantLoad bool
+	}{
+		{name: "single schema is always skipped", msgs: steps(toDatabases, []tea.Msg{key("enter"), schemasLoaded{schemas: fixtureSchemas[:1]}}), wantView: viewTables, wantLoad: true},
+		{name: "single table listed by default", msgs: steps(toSchemas, []tea.Msg{key("enter"), oneTable}), wantView: viewTables},
+		{name: "single table opened", enabled: true, msgs: steps(toSchemas, []tea.Msg{key("enter"), oneTable}), wantView: viewFields, wantLoad: true},
+		{name: "several tables listed", enabled: true, msgs: toTables, wantView: viewTables},
+		{name: "single item listed by default", msgs: steps(toCollections, []tea.Msg{key("enter"), oneItem}), wantView: viewCollectionItems},
+		{name: "single item opened", enabled: true, msgs: steps(toCollections, []tea.Msg{key("enter"), oneItem}), wantView: viewItemDetail, wantLoad: true},
+		{name: "refresh does not skip", enabled: true, msgs: steps(toTables, []tea.Msg{oneTable}), wantView: viewTables},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel()
+			m.autoSkipSingle = tt.enabled
+			m = send(t, m, tt.msgs...)
+			if m.currentView != tt.wantView || m.loading != tt.wantLoad {
+				t.Errorf("view = %v, loading = %v, want %v, %v", m.currentView, m.loading, tt.wantView, tt.wantLoad)
+			}
+		})
+	}
+
+	t.Run("back lands on the skipped level", func(t *testing.T) {
+		m := newTestModel()
+		m.autoSkipSingle = true
+		m = send(t, m, steps(toCollections, []tea.Msg{key("enter"), oneSubCollection})...)
+		if m.selectedCollection.ID != 7 || !m.loading {
+			t.Fatalf("selectedCollection = %+v, want the only sub-collection opened", m.selectedCollection)
+		}
+		m = send(t, m, collectionItemsLoaded{items: fixtureCollectionItems[:2]}, key("esc"), oneSubCollection)
+		if m.currentView != viewCollectionItems || m.selectedCollection.ID != 5 || m.loading {
+			t.Errorf("after esc: collection = %+v, loading = %v, want Analytics listed again", m.selectedCollection, m.loading)
+		}
+	})
+}
+
+func TestMainMenu_AdaptsToEntries(t *testing.T) {
+	original := mainMenu
+	defer func() { mainMenu = original }()
+	opened := ""
+	mainMenu = nil
+	for _, name := range []string{"One", "Two", "Three", "Four", "Five"} {
+		mainMenu = append(mainMenu, menuEntry{name: name, open: func(m Model) (Model, tea.Cmd) {
+			opened = name
+			return m, nil
+		}})
+	}
+
+	m := send(t, newTestModel(), key("5"))
+	if m.cursor != 4 {
+		t.Errorf("number select 5: cursor = %d, want 4", m.cursor)
+	}
+	m = send(t, m, key("enter"))
+	if opened != "Five" {
+		t.Errorf("enter after 5 opened %q, want Five", opened)
+	}
+
+	m = send(t, newTestModel(), key("6"))
+	if m.cursor != 0 {
+		t.Errorf("number select past the menu: cursor = %d, want 0", m.cursor)
+	}
+	for i := 0; i < 10; i++ {
+		m = send(t, m, key("down"))
+	}
+	if m.cursor != 4 {
+		t.Errorf("down past the end: cursor = %d, want 4", m.cursor)
+	}
+	if view := plainView(m); !strings.Contains(view, "5 ▶ Five") {
+		t.Errorf("View() does not show the last entry selected:\n%s", view)
+	}
+}
+
+func TestMainMenu_Search(t *testing.T) {
+	m := send(t, newTestModel(), key("/"), key("d"), key("a"), key("t"), key("a"))
+	if !m.searchMode || len(m.filteredIndices) == 0 || len(m.filteredIndices) >= len(m.menuEntries()) {
+		t.Fatalf("/data: filteredIndices = %v, want some of the %d entries", m.filteredIndices, len(m.menuEntries()))
+	}
+	if entry := m.menuEntries()[m.filteredIndices[0]]; entry.name != "Databases" {
+		t.Errorf("best match = %q, want Databases", entry.name)
+	}
+	if view := plainView(m); !strings.Contains(view, "1 ▶ Databases") || strings.Contains(view, "Collections") {
+		t.Errorf("View() does not list only the matches:\n%s", view)
+	}
+
+	m = send(t, m, key("enter"))
+	if m.currentView != viewDatabases || m.searchMode {
+		t.Errorf("enter: view = %v, searchMode = %v, want the databases opened", m.currentView, m.searchMode)
+	}
+}
+
+func TestMainMenu_SearchActions(t *testing.T) {
+	m := send(t, newTestModel(), key("/"), key("a"), key("b"), key("o"), key("u"), key("t"))
+	if view := plainView(m); !strings.Contains(view, "▶ About ?") {
+		t.Fatalf("/about: View() does not offer the action with its key:\n%s", view)
+	}
+
+	m = send(t, m, key("enter"))
+	if !m.helpMode || m.currentView != viewMainMenu || m.cursor != 0 {
+		t.Errorf("enter: helpMode = %v, view = %v, cursor = %d, want the help over the menu", m.helpMode, m.currentView, m.cursor)
+	}
+}
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// canEnterToken reports whether K may ask for a new API key: Metabase
+// rejected the token and nothing is loading
+func (m Model) canEnterToken() bool {
+	return m.error != "" && !m.loading && m.tokenRejected
+}
+
+// openTokenPrompt asks for a new API key to try instead of the rejected one
+func (m Model) openTokenPrompt() (Model, tea.Cmd) {
+	m.tokenMode = true
+	m.tokenInput = ""
+	return m, nil
+}
+
+// updateTokenPrompt handles key presses while a new API key is typed
+func (m Model) updateTokenPrompt(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.tokenMode = false
+		m.tokenInput = ""
+	case "enter":
+		token := strings.TrimSpace(m.tokenInput)
+		if token == "" {
+			return m, nil
+		}
+		m.tokenMode = false
+		m.tokenInput = ""
+		m.loading = true
+		m.statusMessage = "Testing the new API key..."
+		return m, tea.Batch(testToken(m.client, token), delaySpinner())
+	case "backspace":
+		if input := []rune(m.tokenInput); len(input) > 0 {
+			m.tokenInput = string(input[:len(input)-1])
+		}
+	default:
+		// Pasted keys arrive as runes too
+		if len(msg.Runes) > 0 {
+			m.tokenInput += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+// renderTokenPrompt shows the new API key as dots, the way mbx init reads it
+// without echo
+func (m Model) renderTokenPrompt() string {
+	return lipgloss.NewStyle().Foreground(ColorInfo).Render("New API key: " + strings.Repeat("•", utf8.RuneCountInString(m.tokenInput)) + "_")
+}
+
+// testToken checks a new API key on a copy of the client, so the old one
+// stays in use if it fails
+func testToken(client *api.MetabaseClient, token string) tea.Cmd {
+	return func() tea.Msg {
+		return tokenTested{token: token, err: client.WithToken(token).TestConnection()}
+	}
+}
+
+// applyNewToken switches to a client with the new API key once it
+// connects, loads the view again with it, and offers to save the key to the
+// profile. The old client is left alone as loads in the background may
+// still be using it.
+func (m Model) applyNewToken(msg tokenTested) (Model, tea.Cmd) {
+	m.loading = false
+	m.statusMessage = ""
+	if msg.err != nil {
+		m.error = "The new API key didn't connect either: " + msg.err.Error()
+		m.tokenRejected = isTokenRejected(msg.err)
+		return m, nil
+	}
+
+	if m.clients != nil && m.profile != "" {
+		m.client = m.clients.Get(m.profile, m.client.BaseURL, msg.token)
+	} else {
+		m.client = m.client.WithToken(msg.token)
+	}
+	m.error = ""
+	m.tokenRejected = false
+	// The failed load holds the old client, so the view's load is built again
+	m, cmd := m.refresh()
+	m.statusMessage = "Connected with the new API key"
+	if m.profile != "" {
+		token := msg.token
+		m.confirm = &confirmation{
+			prompt: fmt.Sprintf("Connected. Save the new API key to profile '%s'?", m.profile),
+			run: func(m Model) (Model, tea.Cmd) {
+				return m.saveToken(token), nil
+			},
+		}
+	}
+	return m, cmd
+}
+
+// saveToken replaces the API key of the current profile in the config file,
+// leaving the rest of the file as it is
+func (m Model) saveToken(token string) Model {
+	cfg, err := config.LoadConfig()
+	if err == nil {
+		if _, ok := cfg.Profiles[m.profile]; !ok {
+			err = fmt.Errorf("profile '%s' not found", m.profile)
+		} else {
+			err = config.UpdateKeys(config.KeyChange{Path: []string{"profiles", m.profile, "token"}, Value: token})
+		}
+	}
+	if err != nil {
+		m.statusMessage = "Couldn't save the API key: " + err.Error()
+	} else {
+		m.statusMessage = fmt.Sprintf("Saved the new API key to profile '%s'", m.profile)
+	}
+	return m
+}
@@ -0,0 +1,446 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// paletteAction is an entry in the ":" command palette
+type paletteAction struct {
+	name      string
+	key       string           // Equivalent shortcut, shown as a hint; empty if none
+	binding   string           // Configurable action whose key is shown instead of key
+	available func(Model) bool // Nil means the action is available everywhere
+	write     bool             // Modifies Metabase, so it is hidden in read-only mode
+	run       func(Model) (Model, tea.Cmd)
+}
+
+var paletteActions = []paletteAction{
+	{
+		name:    "Open in browser",
+		binding: actionWeb,
+		run:     Model.openWebURL,
+	},
+	{
+		name: "Copy URL",
+		key:  "y",
+		run:  Model.copyWebURL,
+	},
+	{
+		name: "Copy all names",
+		key:  "ctrl+y",
+		available: func(m Model) bool {
+			return len(m.visibleNames()) > 0
+		},
+		run: Model.copyNames,
+	},
+	{
+		name:      "Run question",
+		key:       "x",
+		available: Model.canRunQuery,
+		run:       Model.runQuery,
+	},
+	{
+		name: "Search Metabase",
+		key:  "S",
+		run:  Model.openGlobalSearch,
+	},
+	{
+		name: "Read full description",
+		key:  "d",
+		available: func(m Model) bool {
+			return m.currentView == viewItemDetail && len(m.descriptionLines()) > 0
+		},
+		run: Model.openDescription,
+	},
+	{
+		name:      "Export results as CSV",
+		key:       "e",
+		available: canExport,
+		run: func(m Model) (Model, tea.Cmd) {
+			return m.exportQuery("csv")
+		},
+	},
+	{
+		name:      "Export results as JSON",
+		available: canExport,
+		run: func(m Model) (Model, tea.Cmd) {
+			return m.exportQuery("json")
+		},
+	},
+	{
+		name:      "Export results as Excel",
+		available: canExport,
+		run: func(m Model) (Model, tea.Cmd) {
+			return m.exportQuery("xlsx")
+		},
+	},
+	{
+		name:    "Refresh",
+		binding: actionRefresh,
+		available: func(m Model) bool {
+			return m.currentView != viewMainMenu
+		},
+		run: Model.refresh,
+	},
+	{
+		name: "Switch profile",
+		key:  "P",
+		run:  Model.openProfilePicker,
+	},
+	{
+		name: "Copy connection details",
+		key:  "c",
+		available: func(m Model) bool {
+			return m.databaseInView() != nil
+		},
+		run: Model.copyConnection,
+	},
+	{
+		name: "Copy table DDL",
+		key:  "D",
+		available: func(m Model) bool {
+			return m.currentView == viewFields && len(m.fields) > 0
+		},
+		run: Model.copyTableDDL,
+	},
+	{
+		name: "Show related questions",
+		key:  "R",
+		available: func(m Model) bool {
+			return (m.currentView == viewTables && len(m.tables) > 0) || m.currentView == viewFields
+		},
+		run: Model.openRelated,
+	},
+	{
+		name:  "Sync database schema",
+		write: true,
+		available: func(m Model) bool {
+			return m.selectedDatabase != nil &&
+				(m.currentView == viewSchemas || m.currentView == viewTables || m.currentView == viewFields)
+		},
+		run: Model.syncSchema,
+	},
+	{
+		name:  "Archive or unarchive",
+		key:   "A",
+		write: true,
+		available: func(m Model) bool {
+			_, ok := m.archiveTarget()
+			return ok
+		},
+		run: Model.confirmArchive,
+	},
+	{
+		name:  "Move to another collection",
+		key:   "M",
+		write: true,
+		available: func(m Model) bool {
+			_, ok := m.moveTarget()
+			return ok
+		},
+		run: Model.openMovePicker,
+	},
+	{
+		name: "Select several items",
+		key:  "v",
+		available: func(m Model) bool {
+			return m.selectionList() != "" && !m.selecting()
+		},
+		run: Model.toggleSelectMode,
+	},
+	{
+		name: "Open the selected items",
+		available: func(m Model) bool {
+			return len(m.selectedRows()) > 0
+		},
+		run: Model.openSelected,
+	},
+	{
+		name: "Copy the URLs of the selected items",
+		available: func(m Model) bool {
+			return len(m.selectedRows()) > 0
+		},
+		run: Model.copySelectedURLs,
+	},
+	{
+		name: "Export the selected questions",
+		available: func(m Model) bool {
+			return len(m.selectedRows()) > 0
+		},
+		run: Model.exportSelected,
+	},
+	{
+		name: "Jump to a parent level",
+		key:  "b",
+		available: func(m Model) bool {
+			return len(m.jumpLevels()) > 0
+		},
+		run: Model.openJump,
+	},
+	{
+		name: "Jump to the top of the hierarchy",
+		key:  "H",
+		available: func(m Model) bool {
+			crumbs := m.breadcrumbs()
+			return len(crumbs) > 0 && !m.atBreadcrumb(crumbs[0])
+		},
+		run: Model.jumpToRoot,
+	},
+	{
+		name: "Go home to the main menu",
+		key:  "~",
+		available: func(m Model) bool {
+			return m.currentView != viewMainMenu
+		},
+		run: Model.goHome,
+	},
+	{
+		name: "Open the collection it is saved in",
+		key:  "o",
+		available: func(m Model) bool {
+			_, _, ok := m.containingCollection()
+			return ok
+		},
+		run: Model.revealCollection,
+	},
+	{
+		name:      "Search fields in this database",
+		key:       "F",
+		available: Model.canSearchFields,
+		run:       Model.openFieldSearch,
+	},
+	{
+		name: "Copy the path to this view",
+		key:  "B",
+		available: func(m Model) bool {
+			return m.currentView != viewMainMenu && m.location() != ""
+		},
+		run: Model.copyLocation,
+	},
+	{
+		name: "Show the API endpoint of this view",
+		key:  "i",
+		available: func(m Model) bool {
+			return m.currentView != viewMainMenu
+		},
+		run: Model.showEndpoint,
+	},
+	{
+		name: "Go to collections",
+		run:  Model.openCollections,
+	},
+	{
+		name: "Go to databases",
+		run:  Model.openDatabases,
+	},
+	{
+		name: "Dismiss the update notice",
+		key:  "U",
+		available: func(m Model) bool {
+			return m.updateAvailable && !m.updateDismissed
+		},
+		run: Model.dismissUpdate,
+	},
+	{
+		name: "Remind me about the update in a week",
+		available: func(m Model) bool {
+			return m.updateAvailable && !m.updateDismissed
+		},
+		run: Model.snoozeUpdate,
+	},
+	{
+		name: "Skip this version",
+		available: func(m Model) bool {
+			return m.updateAvailable && !m.updateDismissed
+		},
+		run: Model.skipUpdateVersion,
+	},
+	{
+		name: "Go to main menu",
+		available: func(m Model) bool {
+			return m.currentView != viewMainMenu
+		},
+		run: Model.openMainMenu,
+	},
+	{
+		name: "Search",
+		key:  "/",
+		available: func(m Model) bool {
+			return m.currentView != viewMainMenu && m.currentView != viewItemDetail && m.currentView != viewFieldDetail && m.currentView != viewQueryResults
+		},
+		run: func(m Model) (Model, tea.Cmd) {
+			m.searchMode = true
+			m.searchQuery = ""
+			m.cursor = 0
+			return m, nil
+		},
+	},
+	{
+		name: "Toggle technical names",
+		key:  "n",
+		run: func(m Model) (Model, tea.Cmd) {
+			m.showTechnicalNames = !m.showTechnicalNames
+			return m, nil
+		},
+	},
+	{
+		name: "Toggle field sort (position/name)",
+		key:  "s",
+		available: func(m Model) bool {
+			return m.currentView == viewFields && len(m.fields) > 1
+		},
+		run: Model.toggleFieldSort,
+	},
+	{
+		name: "Cycle collection sort (API order/name/creation date)",
+		key:  "s",
+		available: func(m Model) bool {
+			return m.currentView == viewCollections && len(m.collections) > 1
+		},
+		run: Model.cycleCollectionSort,
+	},
+	{
+		name: "Toggle relative dates",
+		key:  "t",
+		available: func(m Model) bool {
+			return m.currentView == viewItemDetail
+		},
+		run: func(m Model) (Model, tea.Cmd) {
+			m.relativeTime = !m.relativeTime
+			return m, nil
+		},
+	},
+	{
+		name: "Toggle flat table list",
+		key:  "f",
+		available: func(m Model) bool {
+			return m.currentView == viewSchemas || m.currentView == viewTables
+		},
+		run: Model.toggleFlattenTables,
+	},
+	{
+		name:      "Load the remaining tables",
+		key:       "m",
+		available: Model.tablesCapped,
+		run:       Model.loadRemainingTables,
+	},
+	{
+		name: "Toggle archived collections",
+		key:  "a",
+		available: func(m Model) bool {
+			return m.currentView == viewCollections
+		},
+		run: Model.toggleArchived,
+	},
+	{
+		name: "Toggle personal collections",
+		key:  "p",
+		available: func(m Model) bool {
+			return m.currentView == viewCollections
+		},
+		run: Model.togglePersonal,
+	},
+	{
+		name: "Toggle collection tree",
+		key:  "T",
+		available: func(m Model) bool {
+			return m.currentView == viewCollections
+		},
+		run: Model.toggleCollectionTree,
+	},
+	{
+		name: "About",
+		key:  "?",
+		run: func(m Model) (Model, tea.Cmd) {
+			m.helpMode = true
+			m.helpCursor = 0
+			return m, nil
+		},
+	},
+	{
+		name: "Quit",
+		key:  "q",
+		run: func(m Model) (Model, tea.Cmd) {
+			return m, tea.Quit
+		},
+	},
+}
+
+func canExport(m Model) bool {
+	return m.currentView == viewQueryResults && !m.loading
+}
+
+// availableActions returns the actions available in the current context
+func (m Model) availableActions() []paletteAction {
+	var available []paletteAction
+	for _, action := range paletteActions {
+		if action.write && m.readOnly {
+			continue
+		}
+		if action.available == nil || action.available(m) {
+			available = append(available, action)
+		}
+	}
+	return available
+}
+
+// actionKey is the shortcut shown next to an action, if it has one
+func (m Model) actionKey(action paletteAction) string {
+	if action.binding != "" {
+		return m.keyMap().first(action.binding)
+	}
+	return action.key
+}
+
+// paletteMatches returns the actions available in the current context,
+// fuzzy-filtered by the prompt input and ordered by match quality
+func (m Model) paletteMatches() []paletteAction {
+	available := m.availableActions()
+
+	query := strings.TrimSpace(m.commandInput)
+	if query == "" {
+		return available
+	}
+
+	var names []string
+	for _, action := range available {
+		names = append(names, action.name)
+	}
+	var matches []paletteAction
+	for _, match := range fuzzy.Find(query, names) {
+		matches = append(matches, available[match.Index])
+	}
+	return matches
+}
+
+func (m Model) renderPalette(output *strings.Builder) {
+	if isGotoInput(m.commandInput) {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("Press enter to go to " + strings.TrimSpace(m.commandInput)))
+		output.WriteString("\n")
+		return
+	}
+
+	matches := m.paletteMatches()
+	if len(matches) == 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No matching actions"))
+		output.WriteString("\n")
+		return
+	}
+
+	for i, action := range matches {
+		if i == m.commandCursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + action.name))
+		} else {
+			output.WriteString("  " + action.name)
+		}
+		if key := m.actionKey(action); key != "" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(key))
+		}
+		output.WriteString("\n")
+	}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func actionNames(actions []paletteAction) []string {
+	var names []string
+	for _, action := range actions {
+		names = append(names, action.name)
+	}
+	return names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPaletteMatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		view        viewState
+		input       string
+		wantFirst   string
+		wantPresent []string
+		wantAbsent  []string
+	}{
+		{
+			name:        "empty input lists every available action",
+			view:        viewCollections,
+			input:       "",
+			wantFirst:   "Open in browser",
+			wantPresent: []string{"Copy URL", "Refresh", "Toggle archived collections", "Quit"},
+		},
+		{
+			name:       "context-only actions are hidden elsewhere",
+			view:       viewMainMenu,
+			input:      "",
+			wantAbsent: []string{"Refresh", "Search", "Toggle archived collections", "Go to main menu"},
+		},
+		{
+			name:        "fuzzy query",
+			view:        viewTables,
+			input:       "brows",
+			wantFirst:   "Open in browser",
+			wantAbsent:  []string{"Quit"},
+			wantPresent: []string{"Open in browser"},
+		},
+		{
+			name:       "no match",
+			view:       viewTables,
+			input:      "zzz",
+			wantAbsent: []string{"Open in browser", "Refresh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{currentView: tt.view, commandInput: tt.input}
+			names := actionNames(m.paletteMatches())
+
+			if tt.wantFirst != "" && (len(names) == 0 || names[0] != tt.wantFirst) {
+				t.Errorf("paletteMatches() = %v, want %q first", names, tt.wantFirst)
+			}
+			for _, name := range tt.wantPresent {
+				if !containsName(names, name) {
+					t.Errorf("paletteMatches() = %v, missing %q", names, name)
+				}
+			}
+			for _, name := range tt.wantAbsent {
+				if containsName(names, name) {
+					t.Errorf("paletteMatches() = %v, should not contain %q", names, name)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateCommand_RunsSelectedAction(t *testing.T) {
+	m := Model{
+		client:       api.NewMetabaseClient("http://metabase.local", "token", "dev"),
+		currentView:  viewMainMenu,
+		commandMode:  true,
+		commandInput: "go to data",
+	}
+
+	m, cmd := m.updateCommand(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.commandMode {
+		t.Error("updateCommand() left the prompt open after running an action")
+	}
+	if m.currentView != viewDatabases || !m.loading || cmd == nil {
+		t.Errorf("updateCommand() view = %v, loading = %v, cmd = %v, want databases loading", m.currentView, m.loading, cmd)
+	}
+}
+
+func TestUpdateCommand_TogglesWithoutCommand(t *testing.T) {
+	m := Model{currentView: viewTables, commandMode: true, commandInput: "technical"}
+
+	m, cmd := m.updateCommand(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if !m.showTechnicalNames || cmd != nil {
+		t.Errorf("updateCommand() showTechnicalNames = %v, cmd = %v, want toggled with no command", m.showTechnicalNames, cmd)
+	}
+}
+
+func TestUpdateCommand_NoMatch(t *testing.T) {
+	m := Model{currentView: viewTables, commandMode: true, commandInput: "zzz"}
+
+	m, _ = m.updateCommand(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if !m.commandMode || m.commandError != "no matching action" {
+		t.Errorf("updateCommand() commandMode = %v, commandError = %q, want prompt kept with error", m.commandMode, m.commandError)
+	}
+}
+
+func TestPaletteMatches_ReadOnly(t *testing.T) {
+	m := Model{
+		currentView:      viewSchemas,
+		selectedDatabase: &api.Database{ID: 1, Name: "Sample"},
+	}
+	if names := actionNames(m.paletteMatches()); !containsName(names, "Sync database schema") {
+		t.Errorf("paletteMatches() = %v, want write action available", names)
+	}
+
+	m.readOnly = true
+	if names := actionNames(m.paletteMatches()); containsName(names, "Sync database schema") {
+		t.Errorf("paletteMatches() = %v, want write action hidden in read-only mode", names)
+	}
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cardParameters returns the parameters the open saved question asks for
+func (m Model) cardParameters() []api.CardParameter {
+	if card, ok := m.itemDetail.(*api.CardDetail); ok {
+		return card.Parameters
+	}
+	return nil
+}
+
+// openParamForm asks for the question's parameters before running it. Values
+// typed for the same question earlier are kept so it can be re-run quickly.
+func (m Model) openParamForm() (Model, tea.Cmd) {
+	params := m.cardParameters()
+	if m.paramCardID != m.selectedItem.ID || len(m.paramInputs) != len(params) {
+		m.paramCardID = m.selectedItem.ID
+		m.paramInputs = make([]string, len(params))
+		for i, param := range params {
+			m.paramInputs[i] = param.DefaultInput()
+		}
+	}
+	m.paramCursor = 0
+	m.paramError = ""
+	m.paramMode = true
+	return m, nil
+}
+
+// updateParamForm handles key presses while the parameter form is open
+func (m Model) updateParamForm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.paramMode = false
+		m.paramError = ""
+	case "up", "shift+tab":
+		if m.paramCursor > 0 {
+			m.paramCursor--
+		}
+	case "down", "tab":
+		if m.paramCursor < len(m.paramInputs)-1 {
+			m.paramCursor++
+		}
+	case "enter":
+		values, err := m.parameterValues()
+		if err != nil {
+			m.paramError = err.Error()
+			return m, nil
+		}
+		m.paramMode = false
+		m.paramError = ""
+		return m.startQuery(values)
+	case "backspace":
+		if input := []rune(m.paramInputs[m.paramCursor]); len(input) > 0 {
+			m.paramInputs[m.paramCursor] = string(input[:len(input)-1])
+		}
+		m.paramError = ""
+	default:
+		if len(msg.Runes) > 0 {
+			m.paramInputs[m.paramCursor] += string(msg.Runes)
+			m.paramError = ""
+		}
+	}
+	return m, nil
+}
+
+// parameterValues validates the form and converts it into query parameters,
+// leaving out optional parameters that were not filled in
+func (m Model) parameterValues() ([]api.ParameterValue, error) {
+	var values []api.ParameterValue
+	for i, param := range m.cardParameters() {
+		value, ok, err := param.Value(m.paramInputs[i])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func (m Model) renderParamForm(output *strings.Builder) {
+	output.WriteString(lipgloss.NewStyle().Bold(true).Render("Parameters for " + m.selectedItem.Name))
+	output.WriteString("\n\n")
+
+	params := m.cardParameters()
+	labels := make([]string, len(params))
+	labelWidth := 0
+	for i, param := range params {
+		labels[i] = fmt.Sprintf("%s (%s)", param.Name, param.Kind())
+		if param.Required {
+			labels[i] += " *"
+		}
+		labelWidth = max(labelWidth, len(labels[i]))
+	}
+
+	for i, label := range labels {
+		line := fmt.Sprintf("%-*s  %s", labelWidth, label, m.paramInputs[i])
+		if i == m.paramCursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + line + "_"))
+		} else {
+			output.WriteString("  " + line)
+		}
+		output.WriteString("\n")
+	}
+
+	if m.paramError != "" {
+		output.WriteString("\n")
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorError).Render(m.paramError))
+		output.WriteString("\n")
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var fixtureParameterizedCard = cardDetailLoaded{detail: &api.CardDetail{
+	ID:   21,
+	Name: "Orders by month",
+	Parameters: []api.CardParameter{
+		{ID: "p1", Type: "category", Name: "Category", Required: true},
+		{ID: "p2", Type: "number/=", Name: "Min total", Default: float64(10)},
+		{ID: "p3", Type: "date/single", Name: "Since"},
+	},
+}}
+
+// toParamForm opens the parameter form of a question with parameters
+var toParamForm = steps(toCollectionItems, []tea.Msg{key("down"), key("enter"), fixtureParameterizedCard, key("x")})
+
+func TestParamForm(t *testing.T) {
+	m := send(t, newTestModel(), toParamForm...)
+	if !m.paramMode || m.currentView != viewItemDetail {
+		t.Fatalf("x on a question with parameters: paramMode = %v, view = %v, want the form", m.paramMode, m.currentView)
+	}
+	if m.paramInputs[1] != "10" {
+		t.Errorf("openParamForm() inputs = %q, want the default filled in", m.paramInputs)
+	}
+
+	// The required category is still empty
+	m = send(t, m, key("enter"))
+	if !m.paramMode || m.paramError != "Category is required" {
+		t.Fatalf("enter with a missing value: paramMode = %v, error = %q, want the form kept", m.paramMode, m.paramError)
+	}
+
+	m = send(t, m, key("Gadget"), key("enter"))
+	if m.paramMode || m.currentView != viewQueryResults || !m.loading {
+		t.Fatalf("enter with valid values: paramMode = %v, view = %v, want the query running", m.paramMode, m.currentView)
+	}
+
+	// Optional empty parameters are left out
+	got, _ := json.Marshal(m.queryParams)
+	expected := `[{"id":"p1","type":"category","value":"Gadget"},{"id":"p2","type":"number/=","value":[10]}]`
+	if string(got) != expected {
+		t.Errorf("queryParams = %s, want %s", got, expected)
+	}
+}
+
+func TestParamForm_KeepsInputsForRerun(t *testing.T) {
+	m := send(t, newTestModel(), toParamForm...)
+	m = send(t, m, key("Gadget"), key("enter"), fixtureQueryResult)
+
+	m = send(t, m, key("x"))
+	if !m.paramMode || m.paramInputs[0] != "Gadget" {
+		t.Errorf("x on results: paramMode = %v, inputs = %q, want the form with the previous values", m.paramMode, m.paramInputs)
+	}
+
+	m = send(t, m, key("esc"))
+	if m.paramMode || m.currentView != viewQueryResults {
+		t.Errorf("esc in the form: paramMode = %v, view = %v, want the results kept", m.paramMode, m.currentView)
+	}
+}
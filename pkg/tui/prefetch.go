@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prefetchDelay is how long the cursor has to rest on a database before its
+// tables are fetched, so scrolling past databases doesn't load each of them
+var prefetchDelay = 300 * time.Millisecond // replaced in tests
+
+// prefetchedTables are the tables of a database fetched before it was opened
+type prefetchedTables struct {
+	databaseID int
+	tables     []api.Table
+}
+
+// hoveredDatabase is the ID of the database under the cursor, or 0 outside
+// the databases list
+func (m Model) hoveredDatabase() int {
+	if m.currentView != viewDatabases || m.loading {
+		return 0
+	}
+	index := m.cursor
+	if m.searchMode && m.searchQuery != "" {
+		if index >= len(m.filteredIndices) {
+			return 0
+		}
+		index = m.filteredIndices[index]
+	}
+	if index >= len(m.databases) {
+		return 0
+	}
+	return m.databases[index].ID
+}
+
+// schedulePrefetch starts the delay before prefetching the hovered database
+// when the cursor has moved to another one, cancelling the prefetch of the
+// database it moved away from
+func (m Model) schedulePrefetch() (Model, tea.Cmd) {
+	if !m.prefetch || m.offline {
+		return m, nil
+	}
+	id := m.hoveredDatabase()
+	if id == m.prefetchHover {
+		return m, nil
+	}
+	if m.prefetchCancel != nil {
+		m.prefetchCancel()
+		m.prefetchCancel = nil
+	}
+	m.prefetchHover = id
+	if id == 0 || m.prefetchedTables(id) != nil {
+		return m, nil
+	}
+	return m, tea.Tick(prefetchDelay, func(time.Time) tea.Msg {
+		return prefetchDue{databaseID: id}
+	})
+}
+
+// startPrefetch fetches the tables of the database once the cursor has
+// rested on it, unless it has moved on since
+func (m Model) startPrefetch(msg prefetchDue) (Model, tea.Cmd) {
+	if msg.databaseID != m.prefetchHover || m.prefetchCancel != nil {
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.prefetchCancel = cancel
+	return m, prefetchTables(ctx, m.client, m.profile, msg.databaseID)
+}
+
+// applyPrefetch keeps the prefetched tables; failed and cancelled prefetches
+// are dropped, opening the database loads it as usual. So are the tables of
+// a profile switched away from, whose database IDs mean other databases.
+func (m Model) applyPrefetch(msg tablesPrefetched) (Model, tea.Cmd) {
+	if msg.profile != m.profile {
+		return m, nil
+	}
+	if msg.databaseID == m.prefetchHover && m.prefetchCancel != nil {
+		m.prefetchCancel()
+		m.prefetchCancel = nil
+	}
+	if msg.err == nil {
+		m.prefetched = &prefetchedTables{databaseID: msg.databaseID, tables: msg.tables}
+	}
+	return m, nil
+}
+
+// dropPrefetch cancels a running prefetch and forgets the prefetched tables
+func (m *Model) dropPrefetch() {
+	if m.prefetchCancel != nil {
+		m.prefetchCancel()
+		m.prefetchCancel = nil
+	}
+	m.prefetchHover = 0
+	m.prefetched = nil
+}
+
+// prefetchedTables returns the tables prefetched for the database, nil when
+// there are none
+func (m Model) prefetchedTables(databaseID int) []api.Table {
+	if m.prefetched == nil || m.prefetched.databaseID != databaseID {
+		return nil
+	}
+	return m.prefetched.tables
+}
+
+func prefetchTables(ctx context.Context, client *api.MetabaseClient, profile string, databaseID int) tea.Cmd {
+	return func() tea.Msg {
+		tables, err := client.GetTablesContext(ctx, databaseID)
+		return tablesPrefetched{profile: profile, databaseID: databaseID, tables: tables, err: err}
+	}
+}
+
+// loadSchemasOf lists the schemas of the database from the prefetched tables
+// when there are some, instead of fetching them again
+func (m Model) loadSchemasOf(databaseID int) tea.Cmd {
+	if tables := m.prefetchedTables(databaseID); tables != nil {
+		return func() tea.Msg {
+			return schemasLoaded{schemas: util.ExtractSchemas(tables)}
+		}
+	}
+	return loadSchemas(m.client, databaseID)
+}
+
+// loadAllTablesOf is loadAllTables, using the prefetched tables when there
+// are some
+func (m Model) loadAllTablesOf(databaseID int) tea.Cmd {
+	if tables := m.prefetchedTables(databaseID); tables != nil {
+		limit := m.tableLimit()
+		return func() tea.Msg {
+			return cappedTablesLoaded(tables, limit)
+		}
+	}
+	return loadAllTables(m.client, databaseID, m.tableLimit())
+}
+
+// loadTablesOfSchema is loadTablesForSchema, using the prefetched tables
+// when there are some
+func (m Model) loadTablesOfSchema(databaseID int, schemaName string) tea.Cmd {
+	if tables := m.prefetchedTables(databaseID); tables != nil {
+		return func() tea.Msg {
+			return tablesLoaded{tables: filterTablesBySchema(tables, schemaName)}
+		}
+	}
+	return loadTablesForSchema(m.client, databaseID, schemaName)
+}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// blockingTransport holds every request until its context is cancelled
+type blockingTransport struct {
+	started chan struct{}
+}
+
+func (t blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	close(t.started)
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func newPrefetchModel(t *testing.T) Model {
+	t.Helper()
+	original := prefetchDelay
+	prefetchDelay = time.Millisecond
+	t.Cleanup(func() { prefetchDelay = original })
+
+	m := newTestModel()
+	m.prefetch = true
+	return m
+}
+
+func TestSchedulePrefetch(t *testing.T) {
+	m := newPrefetchModel(t)
+	m = send(t, m, key("down"), key("enter"))
+
+	updated, cmd := m.Update(databasesLoaded{databases: fixtureDatabases})
+	m = updated.(Model)
+	if m.prefetchHover != 1 || cmd == nil {
+		t.Fatalf("prefetchHover = %d, cmd = %v, want a prefetch of database 1 scheduled", m.prefetchHover, cmd != nil)
+	}
+
+	updated, cmd = m.Update(key("down"))
+	m = updated.(Model)
+	if m.prefetchHover != 2 || cmd == nil {
+		t.Fatalf("prefetchHover = %d, cmd = %v, want a prefetch of database 2 scheduled", m.prefetchHover, cmd != nil)
+	}
+	if due, ok := cmd().(prefetchDue); !ok || due.databaseID != 2 {
+		t.Errorf("scheduled %#v, want prefetchDue for database 2", due)
+	}
+
+	// The delay for the database the cursor left ends without a prefetch
+	updated, cmd = m.Update(prefetchDue{databaseID: 1})
+	if m = updated.(Model); cmd != nil || m.prefetchCancel != nil {
+		t.Error("prefetch started for a database no longer hovered")
+	}
+	updated, cmd = m.Update(prefetchDue{databaseID: 2})
+	if m = updated.(Model); cmd == nil || m.prefetchCancel == nil {
+		t.Fatal("prefetch not started for the hovered database")
+	}
+
+	// Tables already prefetched are not fetched again
+	m = send(t, m, tablesPrefetched{databaseID: 2, tables: fixtureTables})
+	if m.prefetchCancel != nil {
+		t.Error("prefetchCancel still set once the prefetch finished")
+	}
+	m = send(t, m, key("up"))
+	updated, cmd = m.Update(key("down"))
+	if m = updated.(Model); cmd != nil {
+		t.Error("prefetch scheduled again for a database already prefetched")
+	}
+
+	// Leaving the list stops prefetching
+	m = send(t, m, key("esc"))
+	if m.prefetchHover != 0 {
+		t.Errorf("prefetchHover = %d after leaving the databases, want 0", m.prefetchHover)
+	}
+}
+
+func TestSchedulePrefetch_Disabled(t *testing.T) {
+	m := newTestModel()
+	updated, cmd := send(t, m, key("down"), key("enter")).Update(databasesLoaded{databases: fixtureDatabases})
+	if m = updated.(Model); m.prefetchHover != 0 || cmd != nil {
+		t.Errorf("prefetchHover = %d, cmd = %v, want no prefetch without the setting", m.prefetchHover, cmd != nil)
+	}
+}
+
+func TestPrefetch_Cancelled(t *testing.T) {
+	m := newPrefetchModel(t)
+	transport := blockingTransport{started: make(chan struct{})}
+	m.client.HTTPClient = &http.Client{Transport: transport}
+	m = send(t, m, key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases})
+
+	updated, cmd := m.Update(prefetchDue{databaseID: 1})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("prefetch not started")
+	}
+
+	done := make(chan tea.Msg)
+	go func() { done <- cmd() }()
+	<-transport.started
+	m = send(t, m, key("down"))
+
+	select {
+	case msg := <-done:
+		prefetched, ok := msg.(tablesPrefetched)
+		if !ok || !errors.Is(prefetched.err, context.Canceled) {
+			t.Fatalf("prefetch ended with %#v, want it cancelled", msg)
+		}
+		if m = send(t, m, prefetched); m.prefetched != nil {
+			t.Error("cancelled prefetch kept")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("prefetch not cancelled when the cursor moved on")
+	}
+}
+
+func TestPrefetch_Used(t *testing.T) {
+	m := newPrefetchModel(t)
+	m = send(t, m, key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases},
+		tablesPrefetched{databaseID: 1, tables: fixtureTables})
+
+	msg := m.loadSchemasOf(1)()
+	if loaded, ok := msg.(schemasLoaded); !ok || len(loaded.schemas) != 1 || loaded.schemas[0].Name != "PUBLIC" {
+		t.Errorf("loadSchemasOf(1)() = %#v, want the schemas of the prefetched tables", msg)
+	}
+	msg = m.loadTablesOfSchema(1, "PUBLIC")()
+	if loaded, ok := msg.(tablesLoaded); !ok || len(loaded.tables) != 2 {
+		t.Errorf("loadTablesOfSchema(1)() = %#v, want the prefetched tables", msg)
+	}
+
+	// Refreshing drops what was prefetched
+	m, _ = m.refresh()
+	if m.prefetchedTables(1) != nil {
+		t.Error("prefetched tables kept after a refresh")
+	}
+}
+
+func TestPrefetch_SwitchProfile(t *testing.T) {
+	m := newPrefetchModel(t)
+	m.profile = "home"
+	m = send(t, m, key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases})
+	updated, _ := m.Update(prefetchDue{databaseID: 1})
+	m = send(t, updated.(Model), tablesPrefetched{profile: "home", databaseID: 1, tables: fixtureTables})
+	if m.prefetchedTables(1) == nil {
+		t.Fatal("tables of the active profile not kept")
+	}
+
+	m = send(t, m, key("esc"), key("esc"))
+	m, _ = m.switchProfile("work", config.Profile{URL: "https://work.metabase.com", Token: "work-token"})
+	if m.prefetchedTables(1) != nil || m.prefetchHover != 0 || m.prefetchCancel != nil {
+		t.Error("prefetch of the previous profile kept after switching")
+	}
+
+	// A prefetch of the previous profile finishing late is dropped
+	m = send(t, m, tablesPrefetched{profile: "home", databaseID: 1, tables: fixtureTables})
+	if m.prefetchedTables(1) != nil {
+		t.Error("late prefetch of the previous profile kept")
+	}
+}
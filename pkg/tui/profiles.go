@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openProfilePicker lists the configured profiles so the user can switch
+// instances without restarting
+func (m Model) openProfilePicker() (Model, tea.Cmd) {
+	if m.loading {
+		m.statusMessage = "Wait for the current load to finish before switching profiles"
+		return m, nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load config: %v", err)
+		return m, nil
+	}
+	if len(cfg.Profiles) == 0 {
+		m.statusMessage = "No profiles configured, run 'mbx init' to add one"
+		return m, nil
+	}
+
+	m.profiles = cfg.Profiles
+	m.profileNames = nil
+	for name := range cfg.Profiles {
+		m.profileNames = append(m.profileNames, name)
+	}
+	sort.Strings(m.profileNames)
+
+	m.profileCursor = 0
+	for i, name := range m.profileNames {
+		if name == m.profile {
+			m.profileCursor = i
+		}
+	}
+	m.profileMode = true
+	return m, nil
+}
+
+// updateProfilePicker handles key presses while the profile list is open
+func (m Model) updateProfilePicker(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "P":
+		m.profileMode = false
+	case "up", "k":
+		if m.profileCursor > 0 {
+			m.profileCursor--
+		}
+	case "down", "j":
+		if m.profileCursor < len(m.profileNames)-1 {
+			m.profileCursor++
+		}
+	case "enter", "right", "l":
+		name := m.profileNames[m.profileCursor]
+		m.profileMode = false
+		return m.switchProfile(name, m.profiles[name])
+	}
+	return m, nil
+}
+
+// switchProfile points the model at another Metabase instance: it rebuilds
+// the client, drops everything loaded from the previous instance and
+// re-tests the connection from the main menu
+func (m Model) switchProfile(name string, profile config.Profile) (Model, tea.Cmd) {
+	if m.loading {
+		m.statusMessage = "Wait for the current load to finish before switching profiles"
+		return m, nil
+	}
+	if profile.URL == "" || profile.Token == "" {
+		m.statusMessage = fmt.Sprintf("Profile '%s' is missing a URL or token", name)
+		return m, nil
+	}
+
+	if m.clients == nil {
+		m.clients = api.NewClientPool(m.Version)
+	}
+	m.client = m.clients.Get(name, profile.URL, profile.Token)
+	m.profile = name
+	m.dropRefresh()
+	m.dropPrefetch()
+
+	m.databases = nil
+	m.schemas = nil
+	m.tables = nil
+	m.fields = nil
+	m.collections = nil
+	m.collectionItems = nil
+	m.selectedDatabase = nil
+	m.selectedSchema = nil
+	m.selectedTable = nil
+	m.selectedCollection = nil
+	m.selectedItem = nil
+	m.itemDetail = nil
+	m.collectionStack = nil
+	m.itemStack = nil
+	m.searchResults = nil
+	m.globalQuery = ""
+	m.searchScope = nil
+	m.myContent = false
+	m.recentChanges = false
+	m.savedSearches = nil
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.numberInput = ""
+	m.viewportStart = 0
+	m.cursor = 0
+	m.error = ""
+	m.currentView = viewMainMenu
+	m.statusMessage = fmt.Sprintf("Switched to profile '%s'", name)
+
+	return m, testConnection(m.client)
+}
+
+func (m Model) renderProfilePicker(output *strings.Builder) {
+	for i, name := range m.profileNames {
+		label := name
+		if name == m.profile {
+			label += " (active)"
+		}
+		if i == m.profileCursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + label))
+		} else {
+			output.WriteString("  " + label)
+		}
+		output.WriteString(" ")
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(m.profiles[name].URL))
+		output.WriteString("\n")
+	}
+}
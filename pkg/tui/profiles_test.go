@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSwitchProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		loading       bool
+		profile       config.Profile
+		expectSwitch  bool
+		expectedURL   string
+		expectedToken string
+	}{
+		{
+			name:          "rebuilds client",
+			profile:       config.Profile{URL: "https://work.metabase.com", Token: "work-token"},
+			expectSwitch:  true,
+			expectedURL:   "https://work.metabase.com",
+			expectedToken: "work-token",
+		},
+		{
+			name:          "blocked while loading",
+			loading:       true,
+			profile:       config.Profile{URL: "https://work.metabase.com", Token: "work-token"},
+			expectSwitch:  false,
+			expectedURL:   "https://home.metabase.com",
+			expectedToken: "home-token",
+		},
+		{
+			name:          "incomplete profile",
+			profile:       config.Profile{URL: "https://work.metabase.com"},
+			expectSwitch:  false,
+			expectedURL:   "https://home.metabase.com",
+			expectedToken: "home-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{
+				client:           api.NewMetabaseClient("https://home.metabase.com", "home-token", "dev"),
+				profile:          "home",
+				loading:          tt.loading,
+				currentView:      viewTables,
+				databases:        []api.Database{{ID: 1, Name: "Sample"}},
+				selectedDatabase: &api.Database{ID: 1, Name: "Sample"},
+				tables:           []api.Table{{ID: 1, Name: "users"}},
+			}
+
+			result, cmd := m.switchProfile("work", tt.profile)
+
+			if result.client.BaseURL != tt.expectedURL || result.client.APIToken != tt.expectedToken {
+				t.Errorf("switchProfile() client = %s/%s, want %s/%s", result.client.BaseURL, result.client.APIToken, tt.expectedURL, tt.expectedToken)
+			}
+
+			if !tt.expectSwitch {
+				if cmd != nil || result.profile != "home" || result.currentView != viewTables {
+					t.Errorf("switchProfile() switched to %q (view %v), want unchanged", result.profile, result.currentView)
+				}
+				return
+			}
+
+			if cmd == nil {
+				t.Error("switchProfile() returned no command, want connection test")
+			}
+			if result.profile != "work" || result.currentView != viewMainMenu {
+				t.Errorf("switchProfile() profile = %q, view = %v, want work on main menu", result.profile, result.currentView)
+			}
+			if result.databases != nil || result.selectedDatabase != nil || result.tables != nil {
+				t.Error("switchProfile() kept data from the previous instance")
+			}
+			// The original model must still point at the old instance
+			if m.client.BaseURL != "https://home.metabase.com" {
+				t.Errorf("switchProfile() mutated the original client to %s", m.client.BaseURL)
+			}
+		})
+	}
+}
+
+func TestSwitchProfile_ReusesClient(t *testing.T) {
+	work := config.Profile{URL: "https://work.metabase.com", Token: "work-token"}
+	home := config.Profile{URL: "https://home.metabase.com", Token: "home-token"}
+	m := Model{clients: api.NewClientPool("dev")}
+
+	m, _ = m.switchProfile("work", work)
+	first := m.client
+	m, _ = m.switchProfile("home", home)
+	m, _ = m.switchProfile("work", work)
+
+	if m.client != first {
+		t.Error("switchProfile() built a new client for a profile used before, want the pooled one")
+	}
+}
+
+func TestProfilePicker(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config.SetGlobalConfigFile(configPath)
+	defer config.SetGlobalConfigFile("")
+
+	err := config.SaveConfig(&config.Config{
+		DefaultProfile: "home",
+		Profiles: map[string]config.Profile{
+			"work": {URL: "https://work.metabase.com", Token: "work-token"},
+			"home": {URL: "https://home.metabase.com", Token: "home-token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SaveConfig() unexpected error = %v", err)
+	}
+
+	m := Model{
+		client:  api.NewMetabaseClient("https://home.metabase.com", "home-token", "dev"),
+		profile: "home",
+	}
+
+	m, _ = m.openProfilePicker()
+	if !m.profileMode || len(m.profileNames) != 2 || m.profileNames[m.profileCursor] != "home" {
+		t.Fatalf("openProfilePicker() names = %v, cursor = %d, want both profiles with home selected", m.profileNames, m.profileCursor)
+	}
+
+	m, _ = m.updateProfilePicker(tea.KeyMsg{Type: tea.KeyDown})
+	m, cmd := m.updateProfilePicker(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.profileMode || m.profile != "work" || m.client.BaseURL != "https://work.metabase.com" || cmd == nil {
+		t.Errorf("updateProfilePicker() profile = %q, client = %s, want switched to work", m.profile, m.client.BaseURL)
+	}
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tokenExpired is shown when Metabase keeps rejecting the API token
+const tokenExpired = "Metabase rejected the API token, it may have expired or been revoked. Press 'K' to enter a new one"
+
+// checkToken tests the connection again when the load that just finished
+// failed on a rejected token, once per load so a token that connects but
+// can't load the data doesn't retry forever
+func (m Model) checkToken(msg tea.Msg, cmd tea.Cmd) (Model, tea.Cmd) {
+	if _, tested := msg.(connectionTested); tested {
+		return m, cmd
+	}
+	retried := m.reconnected
+	m.reconnected = false
+	m.tokenRejected = m.error != "" && isTokenRejected(loadError(msg))
+	if !m.tokenRejected || retried || m.reconnecting {
+		return m, cmd
+	}
+	m.reconnecting = true
+	m.tokenRejected = false
+	m.loading = true
+	m.error = ""
+	m.statusMessage = "Reconnecting..."
+	return m, tea.Batch(cmd, testConnection(m.client), delaySpinner())
+}
+
+// applyReconnect issues the failed load again once the token is accepted,
+// or asks for a new token
+func (m Model) applyReconnect(msg connectionTested) (Model, tea.Cmd) {
+	m.reconnecting = false
+	m.statusMessage = ""
+	if msg.err == nil {
+		m.reconnected = true
+		return m.retry()
+	}
+	m.tokenRejected = isTokenRejected(msg.err)
+	if m.tokenRejected {
+		m.error = tokenExpired
+	} else {
+		m.error = msg.err.Error()
+	}
+	return m, nil
+}
+
+// isTokenRejected reports whether Metabase answered with 401 Unauthorized, as
+// it does once an API key is revoked or deleted
+func isTokenRejected(err error) bool {
+	var statusErr *api.StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized
+}
+
+// loadError is the error a load started with withSpinner failed with
+func loadError(msg tea.Msg) error {
+	switch msg := msg.(type) {
+	case databasesLoaded:
+		return msg.err
+	case schemasLoaded:
+		return msg.err
+	case tablesLoaded:
+		return msg.err
+	case fieldsLoaded:
+		return msg.err
+	case collectionsLoaded:
+		return msg.err
+	case collectionItemsLoaded:
+		return msg.err
+	case searchCompleted:
+		return msg.err
+	case relatedLoaded:
+		return msg.err
+	case cardDetailLoaded:
+		return msg.err
+	case dashboardDetailLoaded:
+		return msg.err
+	case metricDetailLoaded:
+		return msg.err
+	case gotoResolved:
+		return msg.err
+	case fieldIndexLoaded:
+		return msg.err
+	case collectionRevealed:
+		return msg.err
+	}
+	return nil
+}
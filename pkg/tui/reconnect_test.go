@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// settle runs a command and feeds what it returns back through Update until
+// nothing is left to run, leaving out the spinner
+func settle(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	if cmd == nil {
+		return m
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			m = settle(t, m, cmd)
+		}
+	case spinnerTick, nil:
+	default:
+		updated, next := m.Update(msg)
+		m = settle(t, updated.(Model), next)
+	}
+	return m
+}
+
+func TestReconnect(t *testing.T) {
+	tests := []struct {
+		name       string
+		userStatus int // Answer to the connection test
+		dataStatus []int
+		wantError  string
+		wantLoaded bool
+	}{
+		{name: "token expired", userStatus: http.StatusUnauthorized, dataStatus: []int{http.StatusUnauthorized}, wantError: tokenExpired},
+		{name: "blip", userStatus: http.StatusOK, dataStatus: []int{http.StatusUnauthorized, http.StatusOK}, wantLoaded: true},
+		{name: "connects but can't load", userStatus: http.StatusOK, dataStatus: []int{http.StatusUnauthorized, http.StatusUnauthorized}, wantError: "failed to get databases: 401 - denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loads int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/user/current" {
+					w.WriteHeader(tt.userStatus)
+					w.Write([]byte(`{"id": 1}`))
+					return
+				}
+				status := tt.dataStatus[min(loads, len(tt.dataStatus)-1)]
+				loads++
+				w.WriteHeader(status)
+				if status != http.StatusOK {
+					w.Write([]byte(`denied`))
+					return
+				}
+				w.Write([]byte(`{"data": [{"id": 1, "name": "Sample Database"}]}`))
+			}))
+			defer server.Close()
+
+			m := NewModelWithClient(api.NewMetabaseClient(server.URL, "token", "dev"), "v1.0.0")
+			m = send(t, m, key("down"))
+			updated, cmd := m.Update(key("enter"))
+			m = settle(t, updated.(Model), cmd)
+
+			if m.error != tt.wantError {
+				t.Errorf("error = %q, want %q", m.error, tt.wantError)
+			}
+			if loaded := len(m.databases) == 1; loaded != tt.wantLoaded {
+				t.Errorf("databases = %+v, loaded = %v, want %v", m.databases, loaded, tt.wantLoaded)
+			}
+			if m.reconnecting || m.loading || loads != len(tt.dataStatus) {
+				t.Errorf("reconnecting = %v, loading = %v, loads = %d, want %d loads and the reconnect over", m.reconnecting, m.loading, loads, len(tt.dataStatus))
+			}
+		})
+	}
+}
+
+func TestReconnect_NewToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`denied`))
+			return
+		}
+		w.Write([]byte(`{"id": 1, "data": [{"id": 1, "name": "Sample Database"}]}`))
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("default_profile: work\nprofiles:\n  work:\n    url: "+server.URL+"\n    token: old-token # rotated monthly\nfuture_option: true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config.SetGlobalConfigFile(configPath)
+	defer config.SetGlobalConfigFile("")
+
+	clients := api.NewClientPool("dev")
+	oldClient := clients.Get("work", server.URL, "old-token")
+	m := NewModelWithClient(oldClient, "v1.0.0")
+	m.clients = clients
+	m.profile = "work"
+	m = send(t, m, key("down"))
+	updated, cmd := m.Update(key("enter"))
+	m = settle(t, updated.(Model), cmd)
+	if m.error != tokenExpired || !strings.Contains(plainView(m), "Press 'K' to enter a new API key") {
+		t.Fatalf("error = %q, want the token rejected with K offered:\n%s", m.error, plainView(m))
+	}
+
+	// A key that is rejected too leaves the old one in place
+	m = send(t, m, key("K"), key("b"), key("a"), key("d"))
+	if view := plainView(m); !strings.Contains(view, "New API key: •••_") {
+		t.Errorf("View() shows the key as typed:\n%s", view)
+	}
+	updated, cmd = m.Update(key("enter"))
+	m = settle(t, updated.(Model), cmd)
+	if !strings.HasPrefix(m.error, "The new API key didn't connect either") || m.client.APIToken != "old-token" {
+		t.Fatalf("bad key: error = %q, token = %q", m.error, m.client.APIToken)
+	}
+
+	m = send(t, m, key("K"))
+	for _, r := range "new-token" {
+		m = send(t, m, key(string(r)))
+	}
+	updated, cmd = m.Update(key("enter"))
+	m = settle(t, updated.(Model), cmd)
+	if m.error != "" || m.client.APIToken != "new-token" || len(m.databases) != 1 {
+		t.Fatalf("new key: error = %q, token = %q, databases = %+v, want the databases loaded again", m.error, m.client.APIToken, m.databases)
+	}
+	if oldClient.APIToken != "old-token" || clients.Get("work", server.URL, "new-token") != m.client {
+		t.Errorf("old client token = %q, want it left alone and the pool handing out the new client", oldClient.APIToken)
+	}
+	if m.confirm == nil {
+		t.Fatal("no offer to save the new key")
+	}
+
+	m = send(t, m, key("y"))
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg.Profiles["work"].Token != "new-token" {
+		t.Errorf("saved profile = %+v, err = %v, want the new token", cfg.Profiles["work"], err)
+	}
+	if data, _ := os.ReadFile(configPath); !strings.Contains(string(data), "token: new-token # rotated monthly\nfuture_option: true\n") {
+		t.Errorf("saving dropped the comment or unknown key:\n%s", data)
+	}
+	if m.statusMessage != "Saved the new API key to profile 'work'" {
+		t.Errorf("status = %q", m.statusMessage)
+	}
+}
+
+func TestReconnect_LoadError(t *testing.T) {
+	rejected := &api.StatusError{Action: "get databases", StatusCode: http.StatusUnauthorized, Body: "denied"}
+	tests := []struct {
+		name          string
+		msg           tea.Msg
+		wantReconnect bool
+	}{
+		{name: "load rejected", msg: databasesLoaded{err: rejected}, wantReconnect: true},
+		{name: "load failed otherwise", msg: databasesLoaded{err: &api.StatusError{Action: "get databases", StatusCode: http.StatusInternalServerError}}},
+		// Another request's 401 is not the load's to act on
+		{name: "load succeeded", msg: databasesLoaded{databases: fixtureDatabases}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), key("down"), loadFinished{msg: tt.msg, retry: func() tea.Msg { return nil }})
+			if m.reconnecting != tt.wantReconnect {
+				t.Errorf("reconnecting = %v, want %v", m.reconnecting, tt.wantReconnect)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// containingCollection returns the collection the selected search result or
+// item is saved in and the key of the entry to put the cursor on there. The
+// ID is "root" for the root collection.
+func (m Model) containingCollection() (interface{}, string, bool) {
+	switch m.currentView {
+	case viewSearch:
+		if m.cursor >= len(m.searchResults) {
+			return nil, "", false
+		}
+		result := m.searchResults[m.cursor]
+		// Results of other profiles are in collections of another instance
+		if result.Profile != m.profile {
+			return nil, "", false
+		}
+		switch result.Model {
+		case "card", "dataset", "metric", "dashboard":
+			return collectionRef(result.Collection.ID), listKeyOf(result.Model, result.ID), true
+		}
+	case viewRelated:
+		if m.cursor >= len(m.relatedItems) {
+			return nil, "", false
+		}
+		item := m.relatedItems[m.cursor]
+		return collectionRef(item.CollectionID), listKeyOf(item.Model, item.ID), true
+	case viewItemDetail, viewQueryResults:
+		if m.selectedItem == nil {
+			return nil, "", false
+		}
+		item := m.selectedItem
+		key := listKeyOf(item.Model, item.ID)
+		// The details know the collection even when the list didn't say
+		switch detail := m.itemDetail.(type) {
+		case *api.CardDetail:
+			return collectionRef(detail.CollectionID), key, true
+		case *api.DashboardDetail:
+			return collectionRef(detail.CollectionID), key, true
+		case *api.MetricDetail:
+			return collectionRef(detail.CollectionID), key, true
+		}
+		if len(m.itemStack) == 0 && m.selectedCollection != nil {
+			return m.selectedCollection.ID, key, true
+		}
+		return collectionRef(item.CollectionID), key, true
+	}
+	return nil, "", false
+}
+
+// collectionRef turns a collection ID as the API returns it into one to
+// request, with items outside any collection in the root collection
+func collectionRef(id interface{}) interface{} {
+	switch id := id.(type) {
+	case nil:
+		return "root"
+	case int:
+		if id == 0 {
+			return "root"
+		}
+	case float64:
+		if id == 0 {
+			return "root"
+		}
+		return int(id)
+	}
+	return id
+}
+
+// revealCollection opens the collection the selected search result or item
+// is saved in, with the cursor on it
+func (m Model) revealCollection() (Model, tea.Cmd) {
+	collectionID, key, ok := m.containingCollection()
+	if !ok {
+		return m, nil
+	}
+	m.jumpSelect = key
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(loadContainingCollection(m.client, collectionID, m.showArchived))
+}
+
+// applyReveal moves to the collection loaded by revealCollection, leaving
+// whatever view it was revealed from
+func (m Model) applyReveal(msg collectionRevealed) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.loading = false
+		m.jumpSelect = ""
+		m.error = fmt.Sprintf("Failed to open the collection: %v", msg.err)
+		return m, nil
+	}
+	if m.queryCancel != nil {
+		m.queryCancel()
+		m.queryCancel = nil
+	}
+
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.numberInput = ""
+	m.cursor = 0
+	m.viewportStart = 0
+	m.selectedItem = nil
+	m.itemDetail = nil
+	m.itemStack = nil
+	m.savedSearches = nil
+	m.descriptionMode = false
+	return m.openCollection(msg.collection, msg.collections)
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRevealCollection(t *testing.T) {
+	// Search results are decoded from JSON, so collection IDs are float64
+	var results []api.SearchResult
+	if err := json.Unmarshal([]byte(`[
+		{"id": 21, "name": "Orders by month", "model": "card", "collection": {"id": 5, "name": "Analytics"}},
+		{"id": 30, "name": "Scratch", "model": "card", "collection": {"id": null}}
+	]`), &results); err != nil {
+		t.Fatal(err)
+	}
+	toResults := []tea.Msg{key("S"), key("o"), key("enter"), searchCompleted{query: "o", results: results}}
+
+	tests := []struct {
+		name       string
+		msgs       []tea.Msg
+		wantID     interface{}
+		collection *api.Collection
+		items      []api.CollectionItem
+		wantPath   string
+		wantCursor int
+	}{
+		{
+			name:   "search result",
+			msgs:   toResults,
+			wantID: 5,
+			collection: &api.Collection{ID: float64(5), Name: "Analytics", EffectiveAncestors: []api.Collection{
+				{ID: "root", Name: "Our analytics"},
+				{ID: float64(3), Name: "Finance"},
+			}},
+			items:      fixtureCollectionItems,
+			wantPath:   "Collections > Finance > Analytics (3)",
+			wantCursor: 1,
+		},
+		{
+			name:       "root collection",
+			msgs:       steps(toResults, []tea.Msg{key("down")}),
+			wantID:     "root",
+			collection: &api.Collection{ID: "root", Name: "Our analytics"},
+			items:      []api.CollectionItem{{ID: 5, Name: "Analytics", Model: "collection"}, {ID: 30, Name: "Scratch", Model: "card"}},
+			wantPath:   "Collections > Our analytics (2)",
+			wantCursor: 1,
+		},
+		{
+			name: "item detail",
+			msgs: steps(toCollectionItems, []tea.Msg{key("down"), key("enter"), cardDetailLoaded{detail: &api.CardDetail{
+				ID: 21, Name: "Orders by month", CollectionID: 5,
+			}}}),
+			wantID:     5,
+			collection: &api.Collection{ID: float64(5), Name: "Analytics"},
+			items:      fixtureCollectionItems,
+			wantPath:   "Collections > Analytics (3)",
+			wantCursor: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(), tt.msgs...)
+			if id, _, ok := m.containingCollection(); !ok || id != tt.wantID {
+				t.Fatalf("containingCollection() = %v, %v, want %v", id, ok, tt.wantID)
+			}
+
+			updated, cmd := m.Update(key("o"))
+			m = updated.(Model)
+			if cmd == nil || !m.loading {
+				t.Fatalf("o: loading = %v, cmd = %v, want the collection loading", m.loading, cmd != nil)
+			}
+			m = send(t, m, collectionRevealed{collection: tt.collection, collections: fixtureCollections}, collectionItemsLoaded{items: tt.items})
+
+			if m.currentView != viewCollectionItems {
+				t.Fatalf("view = %v, want the collection's items", m.currentView)
+			}
+			if path := strings.Split(plainView(m), "\n")[1]; path != tt.wantPath {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+			if m.cursor != tt.wantCursor {
+				t.Errorf("cursor = %d, want %d on the revealed item", m.cursor, tt.wantCursor)
+			}
+
+			// Back walks up through the ancestors
+			m = send(t, m, key("esc"))
+			if len(tt.collection.EffectiveAncestors) > 1 && (m.selectedCollection == nil || m.selectedCollection.Name != "Finance") {
+				t.Errorf("back went to %q, want the parent collection", m.location())
+			}
+		})
+	}
+}
+
+func TestRevealCollection_NotInCollection(t *testing.T) {
+	m := send(t, newTestModel(), key("S"), key("o"), key("enter"), searchCompleted{query: "o", results: fixtureSearchResults})
+	// The first result is a table
+	if _, _, ok := m.containingCollection(); ok {
+		t.Error("containingCollection() ok for a table, want no collection")
+	}
+	if _, cmd := m.Update(key("o")); cmd != nil {
+		t.Error("o on a table started a load")
+	}
+}
@@ -0,0 +1,352 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// searchTargets returns the instances global search runs on: the active
+// profile first, then any extra profiles given with --profile a,b
+func (m Model) searchTargets() []api.ProfileClient {
+	targets := []api.ProfileClient{{Profile: m.profile, Client: m.client}}
+	for _, extra := range m.extraClients {
+		if extra.Profile != m.profile {
+			targets = append(targets, extra)
+		}
+	}
+	return targets
+}
+
+// searchProfileNames lists the profiles global search runs on
+func (m Model) searchProfileNames() []string {
+	var names []string
+	for _, target := range m.searchTargets() {
+		names = append(names, target.Profile)
+	}
+	return names
+}
+
+// scopeCollection is the collection a search can be limited to: the one
+// being browsed, or the one the shown results were opened from
+func (m Model) scopeCollection() *api.Collection {
+	view := m.currentView
+	if view == viewSearch {
+		view = m.searchFrom
+	}
+	if view != viewCollectionItems || m.selectedCollection == nil || fmt.Sprint(m.selectedCollection.ID) == "root" {
+		return nil
+	}
+	return m.selectedCollection
+}
+
+// openGlobalSearch opens the prompt for a search across Metabase. Inside a
+// collection the search starts out limited to it.
+func (m Model) openGlobalSearch() (Model, tea.Cmd) {
+	if m.loading {
+		return m, nil
+	}
+	if m.currentView != viewSearch {
+		m.searchScope = m.scopeCollection()
+	}
+	m.globalSearchMode = true
+	m.globalSearchInput = ""
+	m.numberInput = ""
+	return m, nil
+}
+
+// updateGlobalSearch handles key presses while the search prompt is open
+func (m Model) updateGlobalSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.globalSearchMode = false
+	case "tab":
+		// Switch between the collection and the whole instance
+		if m.searchScope != nil {
+			m.searchScope = nil
+		} else {
+			m.searchScope = m.scopeCollection()
+		}
+	case "enter":
+		query := strings.TrimSpace(m.globalSearchInput)
+		if query == "" {
+			return m, nil
+		}
+		m.globalSearchMode = false
+		return m.startGlobalSearch(query)
+	case "backspace":
+		if input := []rune(m.globalSearchInput); len(input) > 0 {
+			m.globalSearchInput = string(input[:len(input)-1])
+		}
+	default:
+		if len(msg.Runes) > 0 {
+			m.globalSearchInput += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+// showSearchResults switches to an empty results view, remembering where to
+// return to
+func (m Model) showSearchResults(query string) Model {
+	if m.currentView != viewSearch {
+		m.searchFrom = m.currentView
+		m.searchFromCursor = m.cursor
+	}
+	m.currentView = viewSearch
+	m.globalQuery = query
+	m.myContent = false
+	m.recentChanges = false
+	m.searchResults = nil
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.viewportStart = 0
+	m.cursor = 0
+	m.loading = true
+	m.error = ""
+	return m
+}
+
+// startGlobalSearch shows the results view and searches every target
+func (m Model) startGlobalSearch(query string) (Model, tea.Cmd) {
+	m = m.showSearchResults(query)
+	// Collection IDs only mean something on the active profile's instance
+	if m.searchScope != nil {
+		filter := api.SearchFilter{CollectionID: m.searchScope.ID}
+		return m, withSpinner(searchMetabase(m.searchTargets()[:1], query, filter))
+	}
+	return m, withSpinner(searchMetabase(m.searchTargets(), query, api.SearchFilter{}))
+}
+
+// myContentModels are the kinds of content listed under My content
+var myContentModels = []string{"card", "dataset", "metric", "dashboard"}
+
+// openMyContent lists the questions and dashboards created by the user the
+// API token belongs to
+func (m Model) openMyContent() (Model, tea.Cmd) {
+	m = m.showSearchResults("")
+	m.myContent = true
+	m.searchScope = nil
+	return m, withSpinner(loadMyContent(m.searchTargets()[0]))
+}
+
+// recentChangesLimit is how many recently edited items are listed
+const recentChangesLimit = 50
+
+// openRecentChanges lists the questions and dashboards edited lately on the
+// active profile's instance, with who edited them and when
+func (m Model) openRecentChanges() (Model, tea.Cmd) {
+	m = m.showSearchResults("")
+	m.recentChanges = true
+	m.searchScope = nil
+	return m, withSpinner(loadRecentChanges(m.searchTargets()[0]))
+}
+
+// openSearchResult navigates to a result of the active profile the way a ":"
+// command would. Results from other profiles open in the browser.
+func (m Model) openSearchResult(index int) (Model, tea.Cmd) {
+	result := m.searchResults[index]
+	m.cursor = index
+	if result.Profile != m.profile {
+		return m.openWebURL()
+	}
+
+	var cmd gotoCommand
+	switch result.Model {
+	case "database":
+		cmd = gotoCommand{target: gotoDatabase, id: result.ID}
+	case "table":
+		cmd = gotoCommand{target: gotoTable, id: result.ID}
+	case "collection":
+		cmd = gotoCommand{target: gotoCollection, id: result.ID}
+	default:
+		return m.openWebURL()
+	}
+	m.loading = true
+	m.error = ""
+	return m, withSpinner(resolveGoto(m.client, cmd, m.showArchived))
+}
+
+// searchUnavailable starts the message shown when the instance doesn't
+// answer search
+const searchUnavailable = "Search is not available on this instance"
+
+// searchLoadedLists stands in for a search the instance refused, looking
+// through the databases, tables, collections and items loaded so far
+func (m Model) searchLoadedLists(err error) (Model, tea.Cmd) {
+	reason := searchUnavailable
+	var statusErr *api.StatusError
+	if errors.As(err, &statusErr) {
+		reason = fmt.Sprintf("%s (%d)", searchUnavailable, statusErr.StatusCode)
+	}
+	if m.myContent || m.recentChanges {
+		m.error = reason + ", browse Collections from the main menu instead"
+		return m, nil
+	}
+	m.showSortedResults(m.matchLoadedLists(m.globalQuery))
+	if len(m.searchResults) == 0 {
+		m.error = reason + " and nothing loaded so far matches; open Databases or Collections and filter them with /"
+		return m, nil
+	}
+	m.statusMessage = reason + ", showing matches among the lists loaded so far"
+	return m, nil
+}
+
+// matchLoadedLists returns the loaded entries whose name contains query,
+// ignoring case, as search results
+func (m Model) matchLoadedLists(query string) []api.SearchResult {
+	query = strings.ToLower(query)
+	matches := func(name string) bool {
+		return strings.Contains(strings.ToLower(name), query)
+	}
+
+	var results []api.SearchResult
+	for _, database := range m.databases {
+		if matches(database.Name) {
+			results = append(results, api.SearchResult{ID: database.ID, Name: database.Name, Model: "database", Profile: m.profile})
+		}
+	}
+	for _, table := range m.tables {
+		name := table.DisplayName
+		if name == "" {
+			name = table.Name
+		}
+		if matches(name) {
+			results = append(results, api.SearchResult{ID: table.ID, Name: name, Model: "table", DatabaseID: table.DBID, TableSchema: table.Schema, Profile: m.profile})
+		}
+	}
+	for _, collection := range m.collections {
+		// The root collection has no ID to open it by
+		if id, ok := collectionRef(collection.ID).(int); ok && matches(collection.Name) {
+			results = append(results, api.SearchResult{ID: id, Name: collection.Name, Model: "collection", Profile: m.profile})
+		}
+	}
+	for _, item := range m.collectionItems {
+		if matches(item.Name) {
+			result := api.SearchResult{ID: item.ID, Name: item.Name, Model: item.Model, Profile: m.profile}
+			if m.selectedCollection != nil {
+				result.Collection.ID = m.selectedCollection.ID
+				result.Collection.Name = m.selectedCollection.Name
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// searchResultURL is the Metabase page of a search result
+func (m Model) searchResultURL(result api.SearchResult) string {
+	baseURL := m.client.BaseURL
+	for _, target := range m.searchTargets() {
+		if target.Profile == result.Profile {
+			baseURL = target.Client.BaseURL
+		}
+	}
+	return result.URL(baseURL)
+}
+
+// renderSearchResults lists global search results with their type and, when
+// several instances are searched, the profile they came from
+func (m Model) renderSearchResults(output *strings.Builder) {
+	if len(m.searchResults) == 0 {
+		nothing := fmt.Sprintf("Nothing found for %q", m.globalQuery)
+		if m.myContent {
+			nothing = "You haven't created any questions or dashboards yet"
+		} else if m.recentChanges {
+			nothing = "Nothing was edited lately"
+		} else if m.searchScope != nil {
+			nothing += " in " + m.searchScope.Name
+		}
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(nothing))
+		output.WriteString("\n")
+		return
+	}
+
+	var itemsToShow []int
+	if m.searchMode && m.searchQuery != "" && len(m.filteredIndices) > 0 {
+		itemsToShow = m.filteredIndices
+	} else if m.searchMode && m.searchQuery != "" {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No matches found"))
+		return
+	} else {
+		for i := range m.searchResults {
+			itemsToShow = append(itemsToShow, i)
+		}
+	}
+	showProfile := len(m.searchTargets()) > 1 && m.searchScope == nil && !m.myContent && !m.recentChanges
+
+	viewportEnd := min(m.viewportStart+m.viewportHeight, len(itemsToShow))
+	if m.viewportStart > 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↑ ... %d-%d of %d results", m.viewportStart+1, viewportEnd, len(itemsToShow))))
+		output.WriteString("\n")
+	}
+
+	for i := m.viewportStart; i < viewportEnd; i++ {
+		result := m.searchResults[itemsToShow[i]]
+		if len(m.searchResults) < 10 {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%d ", i+1)))
+		} else {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%02d ", i+1)))
+		}
+
+		badges := len(itemTypeLabel(result.Model)) + 3 // " [" and "]"
+		if result.ModeratedStatus == "verified" {
+			badges += utf8.RuneCountInString(verifiedBadge) + 1
+		}
+		if showProfile {
+			badges += len(result.Profile) + 2 // " @"
+		}
+		edited := ""
+		if m.recentChanges {
+			edited = m.editedBy(result)
+			badges += utf8.RuneCountInString(edited) + 1
+		}
+		name := m.trimText(result.Name, m.terminalWidth-badges-6)
+		output.WriteString(m.selectMark(result.Profile + "/" + listKeyOf(result.Model, result.ID)))
+		if i == m.cursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + name))
+		} else {
+			output.WriteString("  " + name)
+		}
+		output.WriteString(" ")
+		output.WriteString(lipgloss.NewStyle().Foreground(getItemTypeColor(result.Model)).Render("[" + itemTypeLabel(result.Model) + "]"))
+		if result.ModeratedStatus == "verified" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSuccess).Render(verifiedBadge))
+		}
+		if showProfile {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("@" + result.Profile))
+		}
+		if edited != "" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(edited))
+		}
+		output.WriteString("\n")
+	}
+
+	if viewportEnd < len(itemsToShow) {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↓ ... %d-%d of %d results", m.viewportStart+1, viewportEnd, len(itemsToShow))))
+		output.WriteString("\n")
+	}
+}
+
+// editedBy says who edited a recent change and how long ago, such as "Ann
+// Lee, 3 days ago"
+func (m Model) editedBy(result api.SearchResult) string {
+	var parts []string
+	if result.LastEditor != "" {
+		parts = append(parts, result.LastEditor)
+	}
+	if edited, ok := parseMetabaseTime(result.LastEditedAt); ok {
+		parts = append(parts, formatRelativeTime(edited, time.Now()))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGlobalSearch(t *testing.T) {
+	m := newTestModel()
+	m.profile = "work"
+	m.extraClients = []api.ProfileClient{
+		{Profile: "dev", Client: api.NewMetabaseClient("http://dev.metabase.local", "dev-token", "dev")},
+	}
+	m = send(t, m, steps(toCollections, []tea.Msg{key("S"), key("o"), key("r"), key("d"), key("enter")})...)
+
+	if m.currentView != viewSearch || !m.loading || m.globalQuery != "ord" {
+		t.Fatalf("enter in the search prompt: view = %v, loading = %v, query = %q, want searching %q", m.currentView, m.loading, m.globalQuery, "ord")
+	}
+
+	// A late answer for an earlier query is ignored
+	m = send(t, m, searchCompleted{query: "or", results: []api.SearchResult{{ID: 1, Name: "Stale"}}})
+	if !m.loading || len(m.searchResults) != 0 {
+		t.Fatalf("stale results were applied: %+v", m.searchResults)
+	}
+
+	m = send(t, m, searchCompleted{query: "ord", results: []api.SearchResult{
+		{ID: 10, Name: "Orders", Model: "table", DatabaseID: 1, Profile: "work"},
+		{ID: 7, Name: "Orders", Model: "table", DatabaseID: 2, Profile: "dev"},
+		{ID: 21, Name: "Orders by month", Model: "card", Profile: "work"},
+	}}, key("down"))
+
+	view := plainView(m)
+	for _, want := range []string{
+		`Search "ord" in work, dev (3)`,
+		"1   Orders [table] @work",
+		"2 ▶ Orders [table] @dev",
+		"3   Orders by month [card] @work",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+	if got, want := m.getWebURL(), "http://dev.metabase.local/reference/databases/2/tables/7"; got != want {
+		t.Errorf("getWebURL() = %q, want %q", got, want)
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewCollections || m.searchResults != nil {
+		t.Errorf("esc: view = %v, want back on collections", m.currentView)
+	}
+}
+
+func TestGlobalSearch_OpenResult(t *testing.T) {
+	m := send(t, newTestModel(), key("S"), key("x"), key("enter"), searchCompleted{query: "x", results: []api.SearchResult{
+		{ID: 10, Name: "Orders", Model: "table", DatabaseID: 1},
+	}})
+	if strings.Contains(plainView(m), "@") {
+		t.Errorf("View() labels results with a profile when only one is searched:\n%s", plainView(m))
+	}
+
+	m = send(t, m, key("enter"))
+	if !m.loading {
+		t.Error("enter on a table result should resolve it like a :table command")
+	}
+}
+
+func TestGlobalSearch_CollectionScope(t *testing.T) {
+	m := newTestModel()
+	m.extraClients = []api.ProfileClient{
+		{Profile: "dev", Client: api.NewMetabaseClient("http://dev.metabase.local", "dev-token", "dev")},
+	}
+	m = send(t, m, steps(toCollectionItems, []tea.Msg{key("S"), key("o")})...)
+	if m.searchScope == nil || m.searchScope.ID != fixtureCollections[0].ID || !strings.Contains(plainView(m), "Search in Analytics: o_") {
+		t.Fatalf("S in a collection: scope = %+v, want Analytics", m.searchScope)
+	}
+
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.searchScope != nil || !strings.Contains(plainView(m), "Search Metabase: o_") {
+		t.Fatalf("tab: scope = %+v, want everywhere", m.searchScope)
+	}
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyTab}, key("enter"))
+	if m.searchScope == nil || m.currentView != viewSearch {
+		t.Fatalf("enter after tab twice: scope = %+v, view = %v, want a search in Analytics", m.searchScope, m.currentView)
+	}
+
+	m = send(t, m, searchCompleted{query: "o", results: []api.SearchResult{{ID: 21, Name: "Orders by month", Model: "card"}}})
+	view := plainView(m)
+	if !strings.Contains(view, `Searching in: Analytics > "o" (1)`) || strings.Contains(view, "@") {
+		t.Errorf("View() does not name the scope, or labels profiles of a single-instance search:\n%s", view)
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewCollectionItems || m.searchScope != nil {
+		t.Errorf("esc: view = %v, scope = %+v, want the collection without a scope", m.currentView, m.searchScope)
+	}
+
+	// Outside a collection there is nothing to scope to
+	m = send(t, m, key("esc"), key("S"), tea.KeyMsg{Type: tea.KeyTab})
+	if m.searchScope != nil {
+		t.Errorf("tab on the collection list: scope = %+v, want everywhere", m.searchScope)
+	}
+}
+
+func TestMyContent(t *testing.T) {
+	m := send(t, newTestModel(), key("down"), key("down"), key("enter"))
+	if m.currentView != viewSearch || !m.myContent || !m.loading {
+		t.Fatalf("enter on My content: view = %v, myContent = %v, loading = %v, want my content loading", m.currentView, m.myContent, m.loading)
+	}
+
+	m = send(t, m, searchCompleted{results: []api.SearchResult{
+		{ID: 21, Name: "Orders by month", Model: "card"},
+		{ID: 20, Name: "Revenue", Model: "dashboard"},
+	}}, key("down"))
+	view := plainView(m)
+	for _, want := range []string{"My content (2)", "1   Orders by month [card]", "2 ▶ Revenue [dashboard]"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+	if got, want := m.getWebURL(), "http://metabase.local/dashboard/20"; got != want {
+		t.Errorf("getWebURL() = %q, want %q", got, want)
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewMainMenu || m.cursor != 2 || m.myContent {
+		t.Errorf("esc: view = %v, cursor = %d, want the main menu on My content", m.currentView, m.cursor)
+	}
+}
+
+func TestMyContent_CreatorUnknown(t *testing.T) {
+	m := send(t, newTestModel(), key("down"), key("down"), key("enter"), searchCompleted{err: api.ErrCreatorUnknown})
+	view := plainView(m)
+	if m.error != api.ErrCreatorUnknown.Error() || strings.Contains(view, "You haven't created") {
+		t.Errorf("error = %q, want the filter reported as unsupported rather than nothing created:\n%s", m.error, view)
+	}
+}
+
+func TestRecentChanges(t *testing.T) {
+	m := send(t, newTestModel(), key("down"), key("down"), key("down"), key("enter"))
+	if m.currentView != viewSearch || !m.recentChanges || !m.loading {
+		t.Fatalf("enter on Recent changes: view = %v, recentChanges = %v, loading = %v, want recent changes loading", m.currentView, m.recentChanges, m.loading)
+	}
+
+	edited := time.Now().Add(-3 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	m = send(t, m, searchCompleted{results: []api.SearchResult{
+		{ID: 20, Name: "Revenue", Model: "dashboard", LastEditor: "Ann Lee", LastEditedAt: edited},
+		{ID: 21, Name: "Orders by month", Model: "card"},
+	}})
+	view := plainView(m)
+	for _, want := range []string{"Recent changes (2)", "1 ▶ Revenue [dashboard] Ann Lee, 3 days ago", "2   Orders by month [card]\n"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+
+	m = send(t, m, key("esc"))
+	if m.currentView != viewMainMenu || m.cursor != 3 || m.recentChanges {
+		t.Errorf("esc: view = %v, cursor = %d, want the main menu on Recent changes", m.currentView, m.cursor)
+	}
+}
+
+func TestGlobalSearch_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/search" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`API endpoint does not exist.`))
+	}))
+	defer server.Close()
+
+	search := func(t *testing.T, m Model, query string) Model {
+		t.Helper()
+		m = send(t, m, key("S"))
+		for _, r := range query {
+			m = send(t, m, key(string(r)))
+		}
+		updated, cmd := m.Update(key("enter"))
+		return settle(t, updated.(Model), cmd)
+	}
+	newModel := func() Model {
+		m := NewModelWithClient(api.NewMetabaseClient(server.URL, "token", "dev"), "v1.0.0")
+		return send(t, m, key("down"), key("enter"), databasesLoaded{databases: fixtureDatabases})
+	}
+
+	m := search(t, newModel(), "ware")
+	if m.currentView != viewSearch || m.error != "" || len(m.searchResults) != 1 || m.searchResults[0].Name != "Warehouse" || m.searchResults[0].Model != "database" {
+		t.Fatalf("search for a loaded database: error = %q, results = %+v, want Warehouse", m.error, m.searchResults)
+	}
+	if m.statusMessage != "Search is not available on this instance (404), showing matches among the lists loaded so far" {
+		t.Errorf("status = %q", m.statusMessage)
+	}
+	if view := plainView(m); !strings.Contains(view, "Warehouse [database]") {
+		t.Errorf("View() is missing the fallback result:\n%s", view)
+	}
+
+	m = search(t, newModel(), "revenue")
+	if len(m.searchResults) != 0 || !strings.HasPrefix(m.error, "Search is not available on this instance (404) and nothing loaded so far matches") {
+		t.Errorf("search for something not loaded: error = %q, results = %+v", m.error, m.searchResults)
+	}
+}
+
+func TestMatchLoadedLists_Collections(t *testing.T) {
+	// Collection IDs decode as float64, or "root" for the root collection
+	var collections []api.Collection
+	if err := json.Unmarshal([]byte(`[{"id": "root", "name": "Our analytics"}, {"id": 5, "name": "Analytics"}, {"id": 6, "name": "Marketing"}]`), &collections); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel()
+	m.collections = collections
+
+	results := m.matchLoadedLists("analytics")
+	if len(results) != 1 || results[0].ID != 5 || results[0].Model != "collection" {
+		t.Errorf("matchLoadedLists() = %+v, want the Analytics collection", results)
+	}
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchSortMode is the order of the search results
+type searchSortMode int
+
+const (
+	searchSortRelevance searchSortMode = iota // The order Metabase ranks them in
+	searchSortName
+	searchSortModel // Grouped by type, the best ranked type first
+)
+
+// searchSortModes is how many orders s cycles through
+const searchSortModes = 3
+
+func (s searchSortMode) String() string {
+	switch s {
+	case searchSortName:
+		return "name"
+	case searchSortModel:
+		return "type"
+	}
+	return "relevance"
+}
+
+// next is the order s switches to
+func (s searchSortMode) next() searchSortMode {
+	return (s + 1) % searchSortModes
+}
+
+// sortSearchResults orders results by name or groups them by type, keeping
+// Metabase's ranking otherwise and between ties. Types are listed in the
+// order of their best ranked result.
+func sortSearchResults(results []api.SearchResult, mode searchSortMode) []api.SearchResult {
+	sorted := make([]api.SearchResult, len(results))
+	copy(sorted, results)
+	rank := make(map[string]int)
+	for _, result := range results {
+		if _, ok := rank[itemTypeLabel(result.Model)]; !ok {
+			rank[itemTypeLabel(result.Model)] = len(rank)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		switch mode {
+		case searchSortName:
+			return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+		case searchSortModel:
+			return rank[itemTypeLabel(sorted[i].Model)] < rank[itemTypeLabel(sorted[j].Model)]
+		}
+		return false
+	})
+	return sorted
+}
+
+// searchSortLabel names an order of the results; the default one of recent
+// changes is the edit date rather than relevance
+func (m Model) searchSortLabel(mode searchSortMode) string {
+	if mode == searchSortRelevance && m.recentChanges {
+		return "edit date"
+	}
+	return mode.String()
+}
+
+// showSortedResults lists search results as they came, in the chosen order.
+// They are kept as they came so the order can change without searching again.
+func (m *Model) showSortedResults(results []api.SearchResult) {
+	m.loadedSearchResults = results
+	m.searchResults = sortSearchResults(results, m.searchSort)
+}
+
+// cycleSearchSort switches the search results to the next order, keeping
+// the selected result under the cursor
+func (m Model) cycleSearchSort() (Model, tea.Cmd) {
+	m.searchSort = m.searchSort.next()
+	m.statusMessage = "Results sorted by " + m.searchSortLabel(m.searchSort)
+	if len(m.searchResults) == 0 {
+		return m, nil
+	}
+
+	selected := m.searchResults[m.cursor]
+	m.showSortedResults(m.loadedSearchResults)
+	for i, result := range m.searchResults {
+		if result.Profile == selected.Profile && listKeyOf(result.Model, result.ID) == listKeyOf(selected.Model, selected.ID) {
+			m.cursor = i
+		}
+	}
+	m.searchMode = false
+	m.searchQuery = ""
+	m.filteredIndices = nil
+	m.updateViewport(len(m.searchResults))
+	return m, nil
+}
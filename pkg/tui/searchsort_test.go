@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+)
+
+var rankedResults = []api.SearchResult{
+	{ID: 10, Name: "Orders", Model: "table"},
+	{ID: 21, Name: "orders by month", Model: "card"},
+	{ID: 20, Name: "Revenue", Model: "dashboard"},
+	{ID: 11, Name: "Accounts", Model: "table"},
+	{ID: 22, Name: "Churn", Model: "card"},
+}
+
+func TestSortSearchResults(t *testing.T) {
+	tests := []struct {
+		mode     searchSortMode
+		expected []int
+	}{
+		{searchSortRelevance, []int{10, 21, 20, 11, 22}},
+		{searchSortName, []int{11, 22, 10, 21, 20}},
+		{searchSortModel, []int{10, 11, 21, 22, 20}},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for _, result := range sortSearchResults(rankedResults, tt.mode) {
+			got = append(got, result.ID)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("sortSearchResults(%v) = %v, want %v", tt.mode, got, tt.expected)
+		}
+	}
+	if rankedResults[0].ID != 10 {
+		t.Error("sortSearchResults() modified its input")
+	}
+}
+
+func TestCycleSearchSort(t *testing.T) {
+	m := send(t, newTestModel(), key("S"), key("o"), key("enter"), searchCompleted{query: "o", results: rankedResults})
+	m = send(t, m, key("down"), key("s"))
+	if m.searchResults[0].ID != 11 || m.searchResults[m.cursor].ID != 21 {
+		t.Errorf("s: results = %+v, cursor on %d, want name order with the question still selected", m.searchResults, m.searchResults[m.cursor].ID)
+	}
+	if view := plainView(m); !strings.Contains(view, "· sorted by name") || !strings.Contains(view, "s sort by type") {
+		t.Errorf("View() is missing the sort order or the next one:\n%s", view)
+	}
+
+	m = send(t, m, key("s"))
+	if m.searchResults[1].ID != 11 || m.statusMessage != "Results sorted by type" {
+		t.Errorf("s twice: results = %+v, status = %q, want them grouped by type", m.searchResults, m.statusMessage)
+	}
+
+	// A new search keeps the order, and going back needs no new search
+	m = send(t, m, key("S"), key("o"), key("enter"), searchCompleted{query: "o", results: rankedResults})
+	if m.searchResults[1].ID != 11 {
+		t.Errorf("new search: results = %+v, want them still grouped by type", m.searchResults)
+	}
+	m, cmd := m.cycleSearchSort()
+	if cmd != nil || !reflect.DeepEqual(m.searchResults, rankedResults) || strings.Contains(plainView(m), "· sorted by") {
+		t.Errorf("back to relevance: results = %+v, want Metabase's order without a new search", m.searchResults)
+	}
+}
@@ -0,0 +1,282 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/util"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// selectableRow is an entry of a list that can be multi-selected
+type selectableRow struct {
+	key     string // Identifies the item whatever its position in the list
+	name    string
+	model   string
+	id      int
+	profile string // Profile of the instance a search result is from
+	url     string
+}
+
+// selectableRows returns the entries of the list on screen when it is one
+// multi-select works on, in list order
+func (m Model) selectableRows() []selectableRow {
+	var rows []selectableRow
+	switch m.currentView {
+	case viewCollectionItems:
+		for _, item := range m.collectionItems {
+			rows = append(rows, selectableRow{
+				key:     listKeyOf(item.Model, item.ID),
+				name:    item.Name,
+				model:   item.Model,
+				id:      item.ID,
+				profile: m.profile,
+				url:     m.collectionItemURL(item),
+			})
+		}
+	case viewSearch:
+		for _, result := range m.searchResults {
+			rows = append(rows, selectableRow{
+				key:     result.Profile + "/" + listKeyOf(result.Model, result.ID),
+				name:    result.Name,
+				model:   result.Model,
+				id:      result.ID,
+				profile: result.Profile,
+				url:     m.searchResultURL(result),
+			})
+		}
+	case viewRelated:
+		for _, item := range m.relatedItems {
+			rows = append(rows, selectableRow{
+				key:     listKeyOf(item.Model, item.ID),
+				name:    item.Name,
+				model:   item.Model,
+				id:      item.ID,
+				profile: m.profile,
+				url:     m.relatedItemURL(item),
+			})
+		}
+	}
+	return rows
+}
+
+// selectionList identifies the list a selection was made in, so it only
+// applies while that list is on screen
+func (m Model) selectionList() string {
+	switch m.currentView {
+	case viewCollectionItems:
+		return m.listKey()
+	case viewSearch:
+		switch {
+		case m.myContent:
+			return "search:mine"
+		case m.recentChanges:
+			return "search:recent"
+		}
+		return "search:" + m.globalQuery
+	case viewRelated:
+		if m.relatedTable != nil {
+			return fmt.Sprintf("related:%d", m.relatedTable.ID)
+		}
+	}
+	return ""
+}
+
+// selecting reports whether multi-select is on for the list on screen
+func (m Model) selecting() bool {
+	return m.selectMode && m.selectList != "" && m.selectList == m.selectionList()
+}
+
+// toggleSelectMode starts multi-select on the list on screen, or ends it
+// and drops the selection
+func (m Model) toggleSelectMode() (Model, tea.Cmd) {
+	if m.selecting() {
+		m.selectMode = false
+		m.selected = nil
+		return m, nil
+	}
+	if m.selectionList() == "" || m.loading {
+		return m, nil
+	}
+	m.selectMode = true
+	m.selectList = m.selectionList()
+	m.selected = nil
+	return m, nil
+}
+
+// toggleSelected selects the entry under the cursor, or unselects it. With
+// a filter typed the cursor is on the filtered entries, which map back to
+// the item, so the selection stays on it when the filter changes.
+func (m Model) toggleSelected() (Model, tea.Cmd) {
+	rows := m.selectableRows()
+	index := m.cursor
+	if m.searchMode && m.searchQuery != "" {
+		if m.cursor >= len(m.filteredIndices) {
+			return m, nil
+		}
+		index = m.filteredIndices[m.cursor]
+	}
+	if index >= len(rows) {
+		return m, nil
+	}
+
+	// Copied so earlier models keep their own selection
+	selected := maps.Clone(m.selected)
+	if selected == nil {
+		selected = make(map[string]bool)
+	}
+	key := rows[index].key
+	if selected[key] {
+		delete(selected, key)
+	} else {
+		selected[key] = true
+	}
+	m.selected = selected
+	return m, nil
+}
+
+// selectedRows returns the selected entries of the list on screen in list
+// order, whether or not a filter hides them
+func (m Model) selectedRows() []selectableRow {
+	if !m.selecting() {
+		return nil
+	}
+	var rows []selectableRow
+	for _, row := range m.selectableRows() {
+		if m.selected[row.key] {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// selectMark is the checkbox drawn before an entry while multi-selecting
+func (m Model) selectMark(key string) string {
+	if !m.selecting() {
+		return ""
+	}
+	if m.selected[key] {
+		return lipgloss.NewStyle().Foreground(ColorSuccess).Render("✓ ")
+	}
+	return lipgloss.NewStyle().Foreground(ColorMuted).Render("· ")
+}
+
+// selectedURLs lists the Metabase pages of the selected items in list order
+func (m Model) selectedURLs() []string {
+	var urls []string
+	for _, row := range m.selectedRows() {
+		urls = append(urls, row.url)
+	}
+	return urls
+}
+
+// openSelected opens every selected item in the browser
+func (m Model) openSelected() (Model, tea.Cmd) {
+	urls := m.selectedURLs()
+	if len(urls) == 0 {
+		m.statusMessage = "Nothing selected, press space to select"
+		return m, nil
+	}
+	for _, url := range urls {
+		if err := util.OpenInBrowser(url); err != nil {
+			m.error = fmt.Sprintf("Failed to open browser: %v", err)
+			return m, nil
+		}
+	}
+	m.statusMessage = fmt.Sprintf("Opened %s", pluralize(len(urls), "item", "items"))
+	return m, nil
+}
+
+// copySelectedURLs copies the pages of the selected items, one per line,
+// to share them in one go
+func (m Model) copySelectedURLs() (Model, tea.Cmd) {
+	urls := m.selectedURLs()
+	if len(urls) == 0 {
+		m.statusMessage = "Nothing selected, press space to select"
+		return m, nil
+	}
+	if err := util.CopyToClipboard(strings.Join(urls, "\n")); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to copy URLs: %v", err)
+	} else {
+		m.statusMessage = fmt.Sprintf("Copied %s", pluralize(len(urls), "URL", "URLs"))
+	}
+	return m, nil
+}
+
+// exportSelected saves the results of every selected question as CSV, one
+// file per question. Dashboards and collections have no results to export.
+func (m Model) exportSelected() (Model, tea.Cmd) {
+	var questions []selectableRow
+	for _, row := range m.selectedRows() {
+		switch row.model {
+		case "card", "dataset", "metric":
+			questions = append(questions, row)
+		}
+	}
+	if len(questions) == 0 {
+		m.statusMessage = "No questions selected to export"
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("Exporting %s...", pluralize(len(questions), "question", "questions"))
+
+	clients := make(map[string]*api.MetabaseClient)
+	for _, target := range m.searchTargets() {
+		clients[target.Profile] = target.Client
+	}
+	return m, exportQuestions(clients, questions)
+}
+
+// exportFileNames names the file of each question after it, adding the ID
+// to the names questions share so no file overwrites another
+func exportFileNames(questions []selectableRow) []string {
+	count := make(map[string]int)
+	for _, question := range questions {
+		count[exportFileName(question.name, "csv")]++
+	}
+	used := make(map[string]bool)
+	filenames := make([]string, len(questions))
+	for i, question := range questions {
+		filename := exportFileName(question.name, "csv")
+		if count[filename] > 1 {
+			filename = exportFileName(fmt.Sprintf("%s %d", question.name, question.id), "csv")
+		}
+		// The same ID in two profiles still needs a counter
+		for n := 2; used[filename]; n++ {
+			filename = exportFileName(fmt.Sprintf("%s %d %d", question.name, question.id, n), "csv")
+		}
+		used[filename] = true
+		filenames[i] = filename
+	}
+	return filenames
+}
+
+// exportQuestions saves each question next to the others, stopping at the
+// first that fails
+func exportQuestions(clients map[string]*api.MetabaseClient, questions []selectableRow) tea.Cmd {
+	return func() tea.Msg {
+		var filenames []string
+		for i, filename := range exportFileNames(questions) {
+			question := questions[i]
+			if err := clients[question.profile].ExportCardToFile(question.id, "csv", filename); err != nil {
+				return selectionExported{filenames: filenames, err: fmt.Errorf("%s: %v", question.name, err)}
+			}
+			filenames = append(filenames, filename)
+		}
+		return selectionExported{filenames: filenames}
+	}
+}
+
+func (m Model) applySelectionExported(msg selectionExported) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to export %v", msg.err)
+		if len(msg.filenames) > 0 {
+			m.statusMessage += fmt.Sprintf(" (exported %s)", strings.Join(msg.filenames, ", "))
+		}
+		return m, nil
+	}
+	m.statusMessage = "Exported " + strings.Join(msg.filenames, ", ")
+	return m, nil
+}
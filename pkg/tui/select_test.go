@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectedNames lists the names of the selected entries in list order
+func selectedNames(m Model) []string {
+	var names []string
+	for _, row := range m.selectedRows() {
+		names = append(names, row.name)
+	}
+	return names
+}
+
+func TestMultiSelect(t *testing.T) {
+	m := send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{key("v"), key("space")})...)
+	if !m.selecting() {
+		t.Fatal("v didn't start multi-select")
+	}
+	view := plainView(m)
+	for _, want := range []string{"Analytics (3) · 1 selected", "1 ✓ ▶ Revenue", "2 ·   Orders by month"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+
+	// With a filter, space marks the filtered entry the cursor is on
+	m = send(t, m, key("/"), key("o"), key("r"), key("d"), key("space"))
+	if want := []string{"Revenue", "Orders by month"}; !reflect.DeepEqual(selectedNames(m), want) {
+		t.Fatalf("selected %q with the filter, want %q", selectedNames(m), want)
+	}
+	if m.searchQuery != "ord" {
+		t.Errorf("space was typed into the filter: %q", m.searchQuery)
+	}
+
+	// The selection follows the items once the filter is gone
+	m = send(t, m, key("esc"), key("down"), key("down"), key("space"), key("up"), key("up"), key("space"))
+	if want := []string{"Orders by month", "Archive"}; !reflect.DeepEqual(selectedNames(m), want) {
+		t.Errorf("selected %q, want %q", selectedNames(m), want)
+	}
+
+	// It stays with its list when another is opened, and esc drops it
+	m = send(t, m, key("down"), key("down"), key("enter"))
+	if m.selecting() || m.selectedRows() != nil {
+		t.Error("selection carried over into a subcollection")
+	}
+	m = send(t, m, key("esc"), collectionItemsLoaded{items: fixtureCollectionItems})
+	if want := []string{"Orders by month", "Archive"}; !reflect.DeepEqual(selectedNames(m), want) {
+		t.Errorf("back in the list selected %q, want %q", selectedNames(m), want)
+	}
+	m = send(t, m, key("esc"))
+	if m.selecting() || m.currentView != viewCollectionItems {
+		t.Errorf("esc: selecting = %v in view %v, want multi-select ended in the list", m.selecting(), m.currentView)
+	}
+}
+
+func TestMultiSelect_Export(t *testing.T) {
+	m := send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{key("v"), key("space"), key("e")})...)
+	if m.statusMessage != "No questions selected to export" {
+		t.Errorf("export of a dashboard: status = %q", m.statusMessage)
+	}
+
+	m = send(t, m, key("down"), key("space"))
+	updated, cmd := m.Update(key("e"))
+	m = updated.(Model)
+	if cmd == nil || m.statusMessage != "Exporting 1 question..." {
+		t.Errorf("export: status = %q, cmd = %v, want the question exported", m.statusMessage, cmd)
+	}
+
+	m = send(t, m, selectionExported{filenames: []string{"orders-by-month.csv"}})
+	if m.statusMessage != "Exported orders-by-month.csv" {
+		t.Errorf("status = %q after the export", m.statusMessage)
+	}
+}
+
+func TestExportFileNames(t *testing.T) {
+	questions := []selectableRow{
+		{name: "Orders", id: 21},
+		{name: "Revenue", id: 22},
+		{name: "orders", id: 23},
+		{name: "Orders", id: 21, profile: "staging"},
+	}
+	want := []string{"orders-21.csv", "revenue.csv", "orders-23.csv", "orders-21-2.csv"}
+	if got := exportFileNames(questions); !reflect.DeepEqual(got, want) {
+		t.Errorf("exportFileNames() = %q, want %q", got, want)
+	}
+}
+
+func TestMultiSelect_SpaceFilters(t *testing.T) {
+	m := send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{key("/"), key("o"), key("space")})...)
+	if m.searchQuery != "o " || m.selected != nil {
+		t.Errorf("query = %q, selected = %v, want space typed when not selecting", m.searchQuery, m.selected)
+	}
+}
+
+func TestSelectedURLs(t *testing.T) {
+	m := send(t, newTestModel(), steps(toCollectionItems, []tea.Msg{
+		key("v"), key("down"), key("down"), key("space"), key("up"), key("up"), key("space"),
+	})...)
+	want := []string{"http://metabase.local/dashboard/20", "http://metabase.local/collection/7"}
+	if got := m.selectedURLs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("selectedURLs() = %q, want %q in list order", got, want)
+	}
+
+	m = send(t, m, key("v"))
+	if got := m.selectedURLs(); got != nil {
+		t.Errorf("selectedURLs() after multi-select ended = %q, want none", got)
+	}
+}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// semanticTypeNames explains the semantic types Metabase assigns to fields,
+// whose raw names mean little to anyone who hasn't set up a data model
+var semanticTypeNames = map[string]string{
+	"type/PK":                "Primary key",
+	"type/FK":                "Foreign key",
+	"type/Name":              "Name",
+	"type/Title":             "Title",
+	"type/Description":       "Description",
+	"type/Comment":           "Comment",
+	"type/Category":          "Category, a small set of distinct values",
+	"type/Enum":              "Enumeration",
+	"type/Email":             "Email address",
+	"type/URL":               "Web address",
+	"type/ImageURL":          "Image URL",
+	"type/AvatarURL":         "Avatar image URL",
+	"type/SerializedJSON":    "JSON text",
+	"type/Quantity":          "Quantity",
+	"type/Score":             "Score",
+	"type/Percentage":        "Percentage",
+	"type/Share":             "Share, a fraction of a whole",
+	"type/Currency":          "Amount of money",
+	"type/Price":             "Price",
+	"type/Cost":              "Cost",
+	"type/Income":            "Income",
+	"type/Discount":          "Discount",
+	"type/GrossMargin":       "Gross margin",
+	"type/Latitude":          "Latitude",
+	"type/Longitude":         "Longitude",
+	"type/City":              "City",
+	"type/State":             "State or province",
+	"type/Country":           "Country",
+	"type/ZipCode":           "ZIP or postal code",
+	"type/Company":           "Company",
+	"type/Product":           "Product",
+	"type/Source":            "Source, where something came from",
+	"type/User":              "User",
+	"type/Owner":             "Owner",
+	"type/Author":            "Author",
+	"type/CreationTimestamp": "When the row was created",
+	"type/CreationDate":      "Date the row was created",
+	"type/CreationTime":      "Time the row was created",
+	"type/UpdatedTimestamp":  "When the row was last updated",
+	"type/UpdatedDate":       "Date the row was last updated",
+	"type/DeletionTimestamp": "When the row was deleted",
+	"type/JoinTimestamp":     "When someone joined",
+	"type/JoinDate":          "Date someone joined",
+	"type/CancelationDate":   "Date of a cancellation",
+	"type/Birthdate":         "Birthday",
+}
+
+// semanticTypeName explains a semantic type in plain words. Types missing
+// from semanticTypeNames have their name spelled out, so type/AccountAge
+// reads "Account age".
+func semanticTypeName(semanticType string) string {
+	if name, ok := semanticTypeNames[semanticType]; ok {
+		return name
+	}
+	name := strings.TrimPrefix(semanticType, "type/")
+	var words strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words.WriteRune(' ')
+			r = unicode.ToLower(r)
+		}
+		words.WriteRune(r)
+	}
+	return words.String()
+}
+
+// renderFieldLegend explains the badges of the fields list under it. The
+// key badges come from semanticBadges and semanticTypeColors, and the other
+// semantic types are the ones of the fields shown, so the legend matches
+// what is drawn.
+func (m Model) renderFieldLegend(output *strings.Builder) {
+	muted := lipgloss.NewStyle().Foreground(ColorMuted)
+	output.WriteString("\n")
+	output.WriteString(lipgloss.NewStyle().Bold(true).Render("Legend"))
+	output.WriteString("\n")
+
+	mapped := make(map[string]bool)
+	for semanticType := range semanticBadges {
+		mapped[semanticType] = true
+	}
+	for semanticType := range semanticTypeColors {
+		mapped[semanticType] = true
+	}
+	for _, semanticType := range sortedKeys(mapped) {
+		output.WriteString(lipgloss.NewStyle().Foreground(getSemanticTypeColor(semanticType)).Render("[" + semanticBadge(semanticType) + "]"))
+		output.WriteString(muted.Render(" " + semanticTypeName(semanticType) + "  "))
+	}
+	output.WriteString(muted.Render("gray: type in the database"))
+	output.WriteString("\n")
+
+	for _, semanticType := range m.legendTypes() {
+		output.WriteString(lipgloss.NewStyle().Foreground(getSemanticTypeColor(semanticType)).Render("[" + semanticBadge(semanticType) + "]"))
+		output.WriteString(muted.Render(" " + semanticTypeName(semanticType)))
+		output.WriteString("\n")
+	}
+}
+
+// legendTypes are the semantic types of the fields shown that the legend
+// lists on lines of their own, those without a key badge or color
+func (m Model) legendTypes() []string {
+	shown := make(map[string]bool)
+	for _, field := range m.fields {
+		_, badge := semanticBadges[field.SemanticType]
+		_, colored := semanticTypeColors[field.SemanticType]
+		if field.SemanticType != "" && !badge && !colored {
+			shown[field.SemanticType] = true
+		}
+	}
+	return sortedKeys(shown)
+}
+
+// legendHeight is how many lines renderFieldLegend takes: a blank line, the
+// title, the badges and a line per other semantic type
+func (m Model) legendHeight() int {
+	return 3 + len(m.legendTypes())
+}
+
+// fieldsHeight is how many fields fit above the legend, all of them when it
+// is hidden
+func (m Model) fieldsHeight() int {
+	if !m.showLegend || m.viewportHeight <= 0 {
+		return len(m.fields)
+	}
+	return max(m.viewportHeight-m.legendHeight(), 3)
+}
+
+// scrollFields keeps the cursor's field in view above the legend
+func (m *Model) scrollFields() {
+	m.viewportStart = listWindow(m.cursor, m.viewportStart, m.fieldsHeight())
+}
+
+// listWindow is the first entry to show from start on, moved just enough
+// that the cursor fits in height entries
+func listWindow(cursor, start, height int) int {
+	if cursor < start {
+		return cursor
+	}
+	if cursor >= start+height {
+		return cursor - height + 1
+	}
+	return start
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
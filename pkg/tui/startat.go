@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startTarget is where a profile opens at launch: its default database, or
+// its default collection, or nil for the main menu
+func startTarget(profile config.Profile) *gotoCommand {
+	switch {
+	case profile.DefaultDatabase > 0:
+		return &gotoCommand{target: gotoDatabase, id: profile.DefaultDatabase}
+	case profile.DefaultCollection > 0:
+		return &gotoCommand{target: gotoCollection, id: profile.DefaultCollection}
+	}
+	return nil
+}
+
+// openStart goes to the profile's default database or collection once the
+// connection test passed
+func (m Model) openStart() (Model, tea.Cmd) {
+	target := *m.startAt
+	m.startAt = nil
+	m.loading = true
+	m.error = ""
+	resolve := resolveGoto(m.client, target, m.showArchived)
+	return m, withSpinner(func() tea.Msg {
+		msg := resolve().(gotoResolved)
+		msg.start = true
+		return msg
+	})
+}
+
+// startFailed lists the databases or collections instead of a default that
+// could not be opened, saying why
+func (m Model) startFailed(msg gotoResolved) (Model, tea.Cmd) {
+	m.loading = false
+	m.statusMessage = fmt.Sprintf("Couldn't open the default %s: %v", msg.command, msg.err)
+	if msg.command.target == gotoCollection {
+		return m.openCollections()
+	}
+	return m.openDatabases()
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+)
+
+func TestStartTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile config.Profile
+		want    *gotoCommand
+	}{
+		{name: "none", profile: config.Profile{URL: "https://metabase.example.com"}},
+		{name: "database", profile: config.Profile{DefaultDatabase: 3}, want: &gotoCommand{target: gotoDatabase, id: 3}},
+		{name: "collection", profile: config.Profile{DefaultCollection: 5}, want: &gotoCommand{target: gotoCollection, id: 5}},
+		{name: "both", profile: config.Profile{DefaultDatabase: 3, DefaultCollection: 5}, want: &gotoCommand{target: gotoDatabase, id: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := startTarget(tt.profile)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("startTarget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartAt(t *testing.T) {
+	m := newTestModel()
+	m.startAt = &gotoCommand{target: gotoDatabase, id: 1}
+	m = send(t, m, connectionTested{})
+	if m.startAt != nil || !m.loading || m.currentView != viewMainMenu {
+		t.Fatalf("connected: startAt = %v, loading = %v, view = %v, want the default being resolved", m.startAt, m.loading, m.currentView)
+	}
+
+	m = send(t, m, gotoResolved{command: gotoCommand{target: gotoDatabase, id: 1}, databases: fixtureDatabases, start: true})
+	if m.currentView != viewSchemas || m.selectedDatabase == nil || m.selectedDatabase.ID != 1 {
+		t.Errorf("resolved: view = %v, database = %+v, want the schemas of database 1", m.currentView, m.selectedDatabase)
+	}
+}
+
+func TestStartAt_Navigated(t *testing.T) {
+	m := newTestModel()
+	m.startAt = &gotoCommand{target: gotoDatabase, id: 1}
+	m = send(t, m, key("down"), key("down"), connectionTested{})
+	if m.startAt != nil || m.loading || m.currentView != viewMainMenu || m.cursor != 2 {
+		t.Errorf("startAt = %v, loading = %v, view = %v, cursor = %d, want the user left where they are", m.startAt, m.loading, m.currentView, m.cursor)
+	}
+}
+
+func TestStartAt_Fallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  gotoCommand
+		view     viewState
+		wantText string
+	}{
+		{name: "database", command: gotoCommand{target: gotoDatabase, id: 99}, view: viewDatabases, wantText: "Couldn't open the default db 99: db 99 not found"},
+		{name: "collection", command: gotoCommand{target: gotoCollection, id: 99}, view: viewCollections, wantText: "Couldn't open the default collection 99: collection 99 not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel()
+			m.startAt = &tt.command
+			m = send(t, m, connectionTested{}, gotoResolved{command: tt.command, start: true, err: errors.New(tt.command.String() + " not found")})
+			if m.currentView != tt.view || !m.loading || m.commandMode {
+				t.Errorf("view = %v, loading = %v, commandMode = %v, want the parent list loading", m.currentView, m.loading, m.commandMode)
+			}
+			if m.statusMessage != tt.wantText {
+				t.Errorf("status = %q, want %q", m.statusMessage, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestStartAt_NotConnected(t *testing.T) {
+	m := newTestModel()
+	m.startAt = &gotoCommand{target: gotoCollection, id: 5}
+	m = send(t, m, connectionTested{err: &api.StatusError{Action: "test connection", StatusCode: 401}})
+	if m.startAt == nil || m.loading || m.currentView != viewMainMenu {
+		t.Errorf("startAt = %v, loading = %v, view = %v, want the main menu with the error", m.startAt, m.loading, m.currentView)
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+	"github.com/amureki/metabase-explorer/pkg/util"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateSnooze is how long "remind me later" hides update notices
+const updateSnooze = 7 * 24 * time.Hour
+
+// updateWanted reports whether the update notice should be shown for the
+// latest release, given the version skipped and the snooze saved in the
+// config. Development builds and builds newer than the latest release are
+// never told to update.
+func updateWanted(current, latest, skipped string, snoozedUntil, now time.Time) bool {
+	if current == "dev" || latest == "" || util.UpToDate(current, latest) {
+		return false
+	}
+	if strings.TrimPrefix(latest, "v") == strings.TrimPrefix(skipped, "v") {
+		return false
+	}
+	return !now.Before(snoozedUntil)
+}
+
+// dismissUpdate hides the update banner until mbx is started again
+func (m Model) dismissUpdate() (Model, tea.Cmd) {
+	m.updateDismissed = true
+	return m, nil
+}
+
+// snoozeUpdate hides update notices for a week, across restarts
+func (m Model) snoozeUpdate() (Model, tea.Cmd) {
+	m.updateDismissed = true
+	m.updateSnoozedUntil = time.Now().Add(updateSnooze)
+	return m, saveUpdateReminder(m.updateSnoozedUntil, m.skipVersion,
+		"Update notices snoozed until "+m.updateSnoozedUntil.Format("Jan 2"))
+}
+
+// skipUpdateVersion stops the notice for the latest release; a later one is
+// announced again
+func (m Model) skipUpdateVersion() (Model, tea.Cmd) {
+	m.updateDismissed = true
+	m.skipVersion = m.latestVersion
+	return m, saveUpdateReminder(m.updateSnoozedUntil, m.skipVersion,
+		fmt.Sprintf("Version %s skipped, newer ones will be announced", m.skipVersion))
+}
+
+// saveUpdateReminder writes the update snooze and skipped version to the
+// config file, reporting done once they are saved. Only these two keys are
+// changed, the rest of the file is kept as the user wrote it.
+func saveUpdateReminder(snoozedUntil time.Time, skipVersion, done string) tea.Cmd {
+	return func() tea.Msg {
+		snooze := config.KeyChange{Path: []string{"update_snoozed_until"}}
+		if !snoozedUntil.IsZero() {
+			snooze.Value = snoozedUntil
+		}
+		skip := config.KeyChange{Path: []string{"skip_version"}}
+		if skipVersion != "" {
+			skip.Value = skipVersion
+		}
+		return updateReminderSaved{status: done, err: config.UpdateKeys(snooze, skip)}
+	}
+}
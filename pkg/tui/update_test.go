@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUpdateWanted(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		current      string
+		latest       string
+		skipped      string
+		snoozedUntil time.Time
+		want         bool
+	}{
+		{name: "newer release", current: "v1.0.0", latest: "v1.1.0", want: true},
+		{name: "up to date", current: "1.1.0", latest: "v1.1.0"},
+		{name: "development build", current: "dev", latest: "v1.1.0"},
+		{name: "newer than the release", current: "v1.2.0", latest: "v1.1.0"},
+		{name: "skipped", current: "v1.0.0", latest: "v1.1.0", skipped: "1.1.0"},
+		{name: "release after the skipped one", current: "v1.0.0", latest: "v1.2.0", skipped: "v1.1.0", want: true},
+		{name: "snoozed", current: "v1.0.0", latest: "v1.1.0", snoozedUntil: now.Add(time.Hour)},
+		{name: "snooze over", current: "v1.0.0", latest: "v1.1.0", snoozedUntil: now.Add(-time.Hour), want: true},
+		{name: "snooze ends now", current: "v1.0.0", latest: "v1.1.0", snoozedUntil: now, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updateWanted(tt.current, tt.latest, tt.skipped, tt.snoozedUntil, now); got != tt.want {
+				t.Errorf("updateWanted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateReminder(t *testing.T) {
+	tests := []struct {
+		name        string
+		run         func(Model) (Model, tea.Cmd)
+		wantSkip    string
+		wantSnoozed bool
+		wantStatus  string
+	}{
+		{name: "snooze", run: Model.snoozeUpdate, wantSnoozed: true, wantStatus: "Update notices snoozed until "},
+		{name: "skip", run: Model.skipUpdateVersion, wantSkip: "v1.1.0", wantStatus: "Version v1.1.0 skipped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte("# My setup\nfuture_option: true\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			config.SetGlobalConfigFile(configPath)
+			defer config.SetGlobalConfigFile("")
+
+			m := send(t, newTestModel(), versionChecked{latestVersion: "v1.1.0"})
+			m, cmd := tt.run(m)
+			if strings.Contains(plainView(m), "Update available") {
+				t.Error("banner still shown")
+			}
+			m = send(t, m, cmd())
+			if !strings.HasPrefix(m.statusMessage, tt.wantStatus) {
+				t.Errorf("statusMessage = %q, want it to start with %q", m.statusMessage, tt.wantStatus)
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := os.ReadFile(configPath); !strings.Contains(string(data), "# My setup\nfuture_option: true\n") {
+				t.Errorf("saving dropped the comment or unknown key:\n%s", data)
+			}
+			if cfg.SkipVersion != tt.wantSkip {
+				t.Errorf("saved skip_version = %q, want %q", cfg.SkipVersion, tt.wantSkip)
+			}
+			if snoozed := cfg.UpdateSnoozedUntil.After(time.Now().Add(6 * 24 * time.Hour)); snoozed != tt.wantSnoozed {
+				t.Errorf("saved update_snoozed_until = %v, want snoozed %v", cfg.UpdateSnoozedUntil, tt.wantSnoozed)
+			}
+
+			// A restart with the saved config keeps the notice hidden
+			restarted := newTestModel()
+			restarted.skipVersion = cfg.SkipVersion
+			restarted.updateSnoozedUntil = cfg.UpdateSnoozedUntil
+			restarted = send(t, restarted, versionChecked{latestVersion: "v1.1.0"})
+			if restarted.updateAvailable {
+				t.Error("notice shown again after a restart")
+			}
+		})
+	}
+}
@@ -2,9 +2,13 @@ package tui
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/sahilm/fuzzy"
 )
@@ -20,8 +24,14 @@ func (m *Model) updateSearch() {
 
 	switch m.currentView {
 	case viewMainMenu:
-		// No search for main menu
-		return
+		var names []string
+		for _, entry := range m.menuEntries() {
+			names = append(names, entry.name)
+		}
+		matches := fuzzy.Find(m.searchQuery, names)
+		for _, match := range matches {
+			m.filteredIndices = append(m.filteredIndices, match.Index)
+		}
 	case viewDatabases:
 		var names []string
 		for _, db := range m.databases {
@@ -71,6 +81,33 @@ func (m *Model) updateSearch() {
 		for _, match := range matches {
 			m.filteredIndices = append(m.filteredIndices, match.Index)
 		}
+	case viewSearch:
+		var names []string
+		for _, result := range m.searchResults {
+			names = append(names, result.Name)
+		}
+		matches := fuzzy.Find(m.searchQuery, names)
+		for _, match := range matches {
+			m.filteredIndices = append(m.filteredIndices, match.Index)
+		}
+	case viewFieldSearch:
+		var names []string
+		for _, hit := range m.fieldIndex {
+			names = append(names, hit.path())
+		}
+		matches := fuzzy.Find(m.searchQuery, names)
+		for _, match := range matches {
+			m.filteredIndices = append(m.filteredIndices, match.Index)
+		}
+	case viewRelated:
+		var names []string
+		for _, item := range m.relatedItems {
+			names = append(names, item.Name)
+		}
+		matches := fuzzy.Find(m.searchQuery, names)
+		for _, match := range matches {
+			m.filteredIndices = append(m.filteredIndices, match.Index)
+		}
 	case viewFields:
 		var names []string
 		for _, field := range m.fields {
@@ -90,6 +127,17 @@ func (m *Model) updateSearch() {
 	m.cursor = 0
 }
 
+// collectionItemURL is the Metabase page of an item of the open collection
+func (m Model) collectionItemURL(item api.CollectionItem) string {
+	return item.URL(m.client.BaseURL, m.selectedCollection.ID)
+}
+
+// relatedItemURL is the Metabase page of a question or dashboard built on
+// the related table
+func (m Model) relatedItemURL(item api.CollectionItem) string {
+	return item.URL(m.client.BaseURL, item.CollectionID)
+}
+
 func (m Model) getWebURL() string {
 	baseURL := strings.TrimSuffix(m.client.BaseURL, "/")
 
@@ -108,17 +156,7 @@ func (m Model) getWebURL() string {
 		}
 	case viewCollectionItems:
 		if len(m.collectionItems) > 0 && m.cursor < len(m.collectionItems) {
-			item := m.collectionItems[m.cursor]
-			switch item.Model {
-			case "card":
-				return fmt.Sprintf("%s/question/%d", baseURL, item.ID)
-			case "dashboard":
-				return fmt.Sprintf("%s/dashboard/%d", baseURL, item.ID)
-			case "collection":
-				return fmt.Sprintf("%s/collection/%d", baseURL, item.ID)
-			default:
-				return fmt.Sprintf("%s/collection/%v", baseURL, m.selectedCollection.ID)
-			}
+			return m.collectionItemURL(m.collectionItems[m.cursor])
 		} else if m.selectedCollection != nil {
 			return fmt.Sprintf("%s/collection/%v", baseURL, m.selectedCollection.ID)
 		}
@@ -147,21 +185,33 @@ func (m Model) getWebURL() string {
 			// Fallback to table reference page
 			return fmt.Sprintf("%s/reference/databases/%d/tables/%d", baseURL, m.selectedDatabase.ID, m.selectedTable.ID)
 		}
-	case viewItemDetail:
+	case viewFieldSearch:
+		if len(m.fieldIndex) > 0 && m.cursor < len(m.fieldIndex) && m.selectedDatabase != nil {
+			hit := m.fieldIndex[m.cursor]
+			return fmt.Sprintf("%s/reference/databases/%d/tables/%d/fields/%d", baseURL, m.selectedDatabase.ID, hit.table.ID, hit.field.ID)
+		}
+	case viewFieldDetail:
+		if m.selectedField != nil && m.selectedTable != nil && m.selectedDatabase != nil {
+			return fmt.Sprintf("%s/reference/databases/%d/tables/%d/fields/%d", baseURL, m.selectedDatabase.ID, m.selectedTable.ID, m.selectedField.ID)
+		}
+	case viewSearch:
+		if len(m.searchResults) > 0 && m.cursor < len(m.searchResults) {
+			return m.searchResultURL(m.searchResults[m.cursor])
+		}
+	case viewRelated:
+		if len(m.relatedItems) > 0 && m.cursor < len(m.relatedItems) {
+			return m.relatedItemURL(m.relatedItems[m.cursor])
+		} else if m.relatedTable != nil && m.selectedDatabase != nil {
+			return fmt.Sprintf("%s/reference/databases/%d/tables/%d", baseURL, m.selectedDatabase.ID, m.relatedTable.ID)
+		}
+	case viewItemDetail, viewQueryResults:
 		if m.selectedItem != nil {
-			switch m.selectedItem.Model {
-			case "card":
-				return fmt.Sprintf("%s/question/%d", baseURL, m.selectedItem.ID)
-			case "dashboard":
-				return fmt.Sprintf("%s/dashboard/%d", baseURL, m.selectedItem.ID)
-			case "collection":
-				return fmt.Sprintf("%s/collection/%d", baseURL, m.selectedItem.ID)
-			default:
-				// Fallback to the current collection
-				if m.selectedCollection != nil {
-					return fmt.Sprintf("%s/collection/%v", baseURL, m.selectedCollection.ID)
-				}
+			// Items opened with a ":" command have no collection selected
+			var collectionID interface{} = m.selectedItem.CollectionID
+			if m.selectedCollection != nil {
+				collectionID = m.selectedCollection.ID
 			}
+			return m.selectedItem.URL(baseURL, collectionID)
 		}
 	}
 
@@ -177,105 +227,67 @@ func (m Model) View() string {
 	}
 
 	// Header
-	title := ""
-	path := ""
-
-	switch m.currentView {
-	case viewMainMenu:
-		title = fmt.Sprintf("Metabase Explorer %s", m.Version)
-		path = "Main Menu"
-	case viewDatabases:
-		title = fmt.Sprintf("Metabase Explorer %s | Databases", m.Version)
-		if len(m.databases) > 0 {
-			path = fmt.Sprintf("Databases (%d)", len(m.databases))
-		} else {
-			path = "Databases"
-		}
-	case viewCollections:
-		title = fmt.Sprintf("Metabase Explorer %s | Collections", m.Version)
-		if len(m.collections) > 0 {
-			path = fmt.Sprintf("Collections (%d)", len(m.collections))
-		} else {
-			path = "Collections"
-		}
-	case viewCollectionItems:
-		title = fmt.Sprintf("Metabase Explorer %s | Collection items", m.Version)
-		// Build breadcrumb path showing collection hierarchy
-		var pathParts []string
-		pathParts = append(pathParts, "Collections")
-		for _, collection := range m.collectionStack {
-			pathParts = append(pathParts, collection.Name)
-		}
-		pathParts = append(pathParts, m.selectedCollection.Name)
-
-		if len(m.collectionItems) > 0 {
-			path = fmt.Sprintf("%s (%d)", strings.Join(pathParts, " > "), len(m.collectionItems))
-		} else {
-			path = strings.Join(pathParts, " > ")
-		}
-	case viewItemDetail:
-		title = fmt.Sprintf("Metabase Explorer %s | Item Details", m.Version)
-		// Build breadcrumb path showing collection hierarchy with item name
-		var pathParts []string
-		pathParts = append(pathParts, "Collections")
-		for _, collection := range m.collectionStack {
-			pathParts = append(pathParts, collection.Name)
-		}
-		pathParts = append(pathParts, m.selectedCollection.Name)
-		pathParts = append(pathParts, m.selectedItem.Name)
-		path = strings.Join(pathParts, " > ")
-	case viewSchemas:
-		title = fmt.Sprintf("Metabase Explorer %s | Database schemas", m.Version)
-		if len(m.schemas) > 0 {
-			path = fmt.Sprintf("Databases > %s (%d)", m.selectedDatabase.Name, len(m.schemas))
-		} else {
-			path = fmt.Sprintf("Databases > %s", m.selectedDatabase.Name)
-		}
-	case viewTables:
-		title = fmt.Sprintf("Metabase Explorer %s | Schema tables", m.Version)
-		if len(m.tables) > 0 {
-			path = fmt.Sprintf("Databases > %s > %s (%d)", m.selectedDatabase.Name, m.selectedSchema.Name, len(m.tables))
-		} else {
-			path = fmt.Sprintf("Databases > %s > %s", m.selectedDatabase.Name, m.selectedSchema.Name)
-		}
-	case viewFields:
-		title = fmt.Sprintf("Metabase Explorer %s | Table fields", m.Version)
-		tableName := m.selectedTable.DisplayName
-		if tableName == "" {
-			tableName = m.selectedTable.Name
-		}
-		if len(m.fields) > 0 {
-			path = fmt.Sprintf("Databases > %s > %s > %s (%d)", m.selectedDatabase.Name, m.selectedSchema.Name, tableName, len(m.fields))
-		} else {
-			path = fmt.Sprintf("Databases > %s > %s > %s", m.selectedDatabase.Name, m.selectedSchema.Name, tableName)
-		}
-	}
+	title, path := m.header()
 
 	output.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorPrimary).Render(title))
+	if m.profile != "" {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(" [" + m.profile + "]"))
+	}
+	if m.readOnly {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorWarning).Render(" read-only"))
+	}
+	if m.offline {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorWarning).Render(" offline"))
+	}
 	output.WriteString("\n")
 	output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(path))
 
 	// Always reserve a line for search bar to prevent jumping
 	output.WriteString("\n")
-	if m.searchMode {
-		searchPrompt := "/" + m.searchQuery + "_"
-		output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render("Search: " + searchPrompt))
-		if len(m.filteredIndices) > 0 {
+	if m.confirm != nil {
+		output.WriteString(m.renderConfirm())
+	} else if m.tokenMode {
+		output.WriteString(m.renderTokenPrompt())
+	} else if m.globalSearchMode {
+		prompt := "Search Metabase: "
+		if m.searchScope != nil {
+			prompt = "Search in " + m.searchScope.Name + ": "
+		}
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render(prompt + m.globalSearchInput + "_"))
+	} else if m.commandMode {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render(":" + m.commandInput + "_"))
+		if m.commandError != "" {
 			output.WriteString(" ")
-			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("(%d matches)", len(m.filteredIndices))))
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorError).Render(m.commandError))
 		}
+	} else if m.jumpMode {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render("Jump to: press the number of a level, any other key cancels"))
+	} else if m.searchMode {
+		searchPrompt := "/" + m.searchQuery + "_"
+		// The number of matches is shown in the header
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render("Search: " + searchPrompt))
 	} else if m.numberInput != "" {
 		output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render("Select: " + m.numberInput + "_"))
+	} else if m.statusMessage != "" {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorSuccess).Render(m.statusMessage))
 	}
 
 	output.WriteString("\n")
 
-	// Handle loading
+	// Handle loading. The header above already names where the user is, so
+	// only the content area waits for the data.
 	if m.loading {
 		spinnerChars := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 		spinner := spinnerChars[m.spinnerIndex%len(spinnerChars)]
-		loadingMsg := spinner + " Loading..."
-		output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render(loadingMsg))
+		if m.currentView == viewItemDetail && m.selectedItem != nil {
+			// The name is known from the list the item was opened from
+			output.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorPrimary).Render(m.selectedItem.Name))
+			output.WriteString("\n\n")
+		}
+		// Quick loads finish before the spinner is due, without it flashing
+		if m.spinnerVisible {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render(spinner + " " + m.loadingMessage()))
+		}
 		output.WriteString("\n\n")
 		output.WriteString(m.getHelpText())
 		return output.String()
@@ -285,11 +297,51 @@ func (m Model) View() string {
 	if m.error != "" {
 		output.WriteString(lipgloss.NewStyle().Foreground(ColorError).Render("Error: " + m.error))
 		output.WriteString("\n\n")
-		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("Press 'q' to quit"))
+		quit := m.keyMap().first(actionQuit)
+		if m.canEnterToken() && m.lastLoad != nil {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("Press 'K' to enter a new API key, 'r' to retry, or '%s' to quit", quit)))
+		} else if m.canEnterToken() {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("Press 'K' to enter a new API key, or '%s' to quit", quit)))
+		} else if m.lastLoad != nil || m.currentView == viewQueryResults {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("Press 'r' to retry, or '%s' to quit", quit)))
+		} else {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("Press '%s' to quit", quit)))
+		}
 		return output.String()
 	}
 
 	// Render content based on view
+	if m.profileMode {
+		m.renderProfilePicker(&output)
+		output.WriteString("\n")
+		output.WriteString(m.getHelpText())
+		return output.String()
+	}
+	if m.moveMode {
+		m.renderMovePicker(&output)
+		output.WriteString("\n")
+		output.WriteString(m.getHelpText())
+		return output.String()
+	}
+	if m.descriptionMode {
+		m.renderDescription(&output)
+		output.WriteString("\n")
+		output.WriteString(m.getHelpText())
+		return output.String()
+	}
+	if m.paramMode {
+		m.renderParamForm(&output)
+		output.WriteString("\n")
+		output.WriteString(m.getHelpText())
+		return output.String()
+	}
+	if m.commandMode {
+		m.renderPalette(&output)
+		output.WriteString("\n")
+		output.WriteString(m.getHelpText())
+		return output.String()
+	}
+
 	switch m.currentView {
 	case viewMainMenu:
 		m.renderMainMenu(&output)
@@ -301,12 +353,25 @@ func (m Model) View() string {
 		m.renderCollectionItems(&output)
 	case viewItemDetail:
 		m.renderItemDetail(&output)
+	case viewFieldDetail:
+		m.renderFieldDetail(&output)
+	case viewQueryResults:
+		m.renderQueryResults(&output)
 	case viewSchemas:
 		m.renderSchemas(&output)
 	case viewTables:
 		m.renderTables(&output)
 	case viewFields:
 		m.renderFields(&output)
+		if m.showLegend {
+			m.renderFieldLegend(&output)
+		}
+	case viewRelated:
+		m.renderRelated(&output)
+	case viewSearch:
+		m.renderSearchResults(&output)
+	case viewFieldSearch:
+		m.renderFieldSearch(&output)
 	}
 
 	output.WriteString("\n")
@@ -315,13 +380,88 @@ func (m Model) View() string {
 	return output.String()
 }
 
+// loadingMessage describes what the current view is waiting for
+func (m Model) loadingMessage() string {
+	switch {
+	case m.currentView == viewQueryResults:
+		// Queries run on the database and can take a while
+		return "Running query on Metabase... (esc to cancel)"
+	case m.currentView == viewSchemas && m.selectedDatabase != nil:
+		return fmt.Sprintf("Loading schemas of %s...", m.selectedDatabase.Name)
+	case m.currentView == viewTables && m.selectedSchema != nil:
+		return fmt.Sprintf("Loading tables in %s...", m.selectedSchema.Name)
+	case m.currentView == viewTables && m.selectedDatabase != nil:
+		return fmt.Sprintf("Loading tables of %s...", m.selectedDatabase.Name)
+	case m.currentView == viewFields && m.selectedTable != nil:
+		name := m.selectedTable.DisplayName
+		if name == "" {
+			name = m.selectedTable.Name
+		}
+		return fmt.Sprintf("Loading fields of %s...", name)
+	case m.currentView == viewCollectionItems && m.selectedCollection != nil:
+		return fmt.Sprintf("Loading %s...", m.selectedCollection.Name)
+	case m.currentView == viewItemDetail:
+		return "Loading details..."
+	case m.currentView == viewRelated:
+		return "Looking for questions that use this table..."
+	case m.currentView == viewFieldSearch && m.selectedDatabase != nil:
+		return fmt.Sprintf("Loading fields of %s...", m.selectedDatabase.Name)
+	}
+	return "Loading..."
+}
+
 func (m Model) getHelpText() string {
 	keyStyle := lipgloss.NewStyle().Foreground(ColorHighlight)
 	descStyle := lipgloss.NewStyle().Foreground(ColorMuted)
 
-	if m.searchMode {
+	if m.profileMode {
+		return keyStyle.Render("↑↓") + descStyle.Render(" navigate  ") +
+			keyStyle.Render("enter") + descStyle.Render(" switch  ") +
+			keyStyle.Render("esc") + descStyle.Render(" cancel")
+	} else if m.moveMode {
+		return keyStyle.Render("↑↓") + descStyle.Render(" navigate  ") +
+			keyStyle.Render("enter") + descStyle.Render(" move here  ") +
+			keyStyle.Render("esc") + descStyle.Render(" cancel")
+	} else if m.globalSearchMode {
+		scope := ""
+		if m.searchScope != nil {
+			scope = keyStyle.Render("tab") + descStyle.Render(" search everywhere  ")
+		} else if collection := m.scopeCollection(); collection != nil {
+			scope = keyStyle.Render("tab") + descStyle.Render(" search in "+collection.Name+"  ")
+		}
+		return keyStyle.Render("enter") + descStyle.Render(" search  ") + scope +
+			keyStyle.Render("esc") + descStyle.Render(" cancel") + "\n" +
+			descStyle.Render("Searches questions, dashboards, collections and tables by name")
+	} else if m.descriptionMode {
+		return keyStyle.Render("↑↓") + descStyle.Render(" scroll  ") +
+			keyStyle.Render("pgup/pgdn") + descStyle.Render(" page  ") +
+			keyStyle.Render("esc") + descStyle.Render(" close")
+	} else if m.paramMode {
+		return keyStyle.Render("↑↓") + descStyle.Render(" field  ") +
+			keyStyle.Render("enter") + descStyle.Render(" run  ") +
+			keyStyle.Render("esc") + descStyle.Render(" cancel") + "\n" +
+			descStyle.Render("Dates as 2025-01-31; ranges and relative dates such as past30days are sent as typed")
+	} else if m.commandMode {
+		return keyStyle.Render("↑↓") + descStyle.Render(" navigate  ") +
+			keyStyle.Render("enter") + descStyle.Render(" run  ") +
+			keyStyle.Render("esc") + descStyle.Render(" cancel") + "\n" +
+			descStyle.Render("Type to filter actions, or go to an ID: db, table, collection, dashboard or card, e.g. table 42")
+	} else if m.selecting() && !m.searchMode {
+		return keyStyle.Render("↑↓") + descStyle.Render(" navigate  ") +
+			keyStyle.Render("space") + descStyle.Render(" mark  ") +
+			keyStyle.Render(m.keyMap().first(actionWeb)) + descStyle.Render(" open selected  ") +
+			keyStyle.Render("y") + descStyle.Render(" copy URLs  ") +
+			keyStyle.Render("e") + descStyle.Render(" export selected  ") +
+			keyStyle.Render(m.keyMap().first(actionSearch)) + descStyle.Render(" filter  ") +
+			keyStyle.Render("esc") + descStyle.Render(" done")
+	} else if m.searchMode {
+		mark := ""
+		if m.selecting() {
+			mark = keyStyle.Render("space") + descStyle.Render(" mark  ")
+		}
 		return keyStyle.Render("↑↓←→") + descStyle.Render(" navigate  ") +
-			keyStyle.Render("enter") + descStyle.Render(" select  ") +
+			keyStyle.Render("enter") + descStyle.Render(" select  ") + mark +
+			keyStyle.Render("ctrl+y") + descStyle.Render(" copy names  ") +
 			keyStyle.Render("esc") + descStyle.Render(" cancel")
 	} else {
 		var help strings.Builder
@@ -335,7 +475,7 @@ func (m Model) getHelpText() string {
 		var itemCount int
 		switch m.currentView {
 		case viewMainMenu:
-			itemCount = 2 // Collections and Databases
+			itemCount = len(mainMenu)
 		case viewDatabases:
 			itemCount = len(m.databases)
 		case viewCollections:
@@ -348,6 +488,12 @@ func (m Model) getHelpText() string {
 			itemCount = len(m.tables)
 		case viewFields:
 			itemCount = len(m.fields)
+		case viewRelated:
+			itemCount = len(m.relatedItems)
+		case viewSearch:
+			itemCount = len(m.searchResults)
+		case viewFieldSearch:
+			itemCount = len(m.fieldIndex)
 		}
 
 		if m.currentView != viewFields && itemCount > 0 {
@@ -361,13 +507,146 @@ func (m Model) getHelpText() string {
 
 		// Actions section
 		var actions strings.Builder
-		actions.WriteString(keyStyle.Render("w"))
+		keys := m.keyMap()
+		actions.WriteString(keyStyle.Render(keys.first(actionWeb)))
 		actions.WriteString(descStyle.Render(" web  "))
-		actions.WriteString(keyStyle.Render("/"))
+		if m.currentView == viewTables || m.currentView == viewFields {
+			actions.WriteString(keyStyle.Render("n"))
+			actions.WriteString(descStyle.Render(" names  "))
+		}
+		if (m.currentView == viewDatabases || m.currentView == viewSchemas) && m.databaseInView() != nil {
+			actions.WriteString(keyStyle.Render("c"))
+			actions.WriteString(descStyle.Render(" copy connection  "))
+		}
+		if m.currentView == viewTables || m.currentView == viewFields {
+			actions.WriteString(keyStyle.Render("R"))
+			actions.WriteString(descStyle.Render(" related  "))
+		}
+		if m.canSearchFields() {
+			actions.WriteString(keyStyle.Render("F"))
+			actions.WriteString(descStyle.Render(" find field  "))
+		}
+		if m.currentView == viewSearch || m.currentView == viewRelated {
+			if _, _, ok := m.containingCollection(); ok {
+				actions.WriteString(keyStyle.Render("o"))
+				actions.WriteString(descStyle.Render(" open collection  "))
+			}
+		}
+		if m.currentView == viewFields && len(m.fields) > 1 {
+			actions.WriteString(keyStyle.Render("s"))
+			if m.fieldSort == fieldSortPosition {
+				actions.WriteString(descStyle.Render(" sort by name  "))
+			} else {
+				actions.WriteString(descStyle.Render(" sort by position  "))
+			}
+		}
+		if m.currentView == viewSearch && len(m.searchResults) > 1 {
+			actions.WriteString(keyStyle.Render("s"))
+			actions.WriteString(descStyle.Render(" sort by " + m.searchSortLabel(m.searchSort.next()) + "  "))
+		}
+		if m.currentView == viewFields && len(m.fields) > 0 {
+			actions.WriteString(keyStyle.Render("D"))
+			actions.WriteString(descStyle.Render(" copy DDL  "))
+			actions.WriteString(keyStyle.Render("L"))
+			if m.showLegend {
+				actions.WriteString(descStyle.Render(" hide legend  "))
+			} else {
+				actions.WriteString(descStyle.Render(" legend  "))
+			}
+		}
+		if m.currentView == viewSchemas || m.currentView == viewTables {
+			actions.WriteString(keyStyle.Render("f"))
+			if m.flattenTables {
+				actions.WriteString(descStyle.Render(" by schema  "))
+			} else {
+				actions.WriteString(descStyle.Render(" all tables  "))
+			}
+		}
+		if m.tablesCapped() {
+			actions.WriteString(keyStyle.Render("m"))
+			actions.WriteString(descStyle.Render(" load the rest  "))
+		}
+		if m.canRunQuery() {
+			actions.WriteString(keyStyle.Render("x"))
+			if m.currentView == viewQueryResults {
+				actions.WriteString(descStyle.Render(" run again  "))
+			} else {
+				actions.WriteString(descStyle.Render(" run  "))
+			}
+		}
+		if m.currentView == viewItemDetail && m.itemDetail != nil && (m.itemDetail.GetCreatedAt() != "" || m.itemDetail.GetUpdatedAt() != "") {
+			actions.WriteString(keyStyle.Render("t"))
+			if m.relativeTime {
+				actions.WriteString(descStyle.Render(" exact dates  "))
+			} else {
+				actions.WriteString(descStyle.Render(" relative dates  "))
+			}
+		}
+		if m.currentView == viewItemDetail && len(m.descriptionLines()) > m.descriptionLimit() {
+			actions.WriteString(keyStyle.Render("d"))
+			actions.WriteString(descStyle.Render(" description  "))
+		}
+		if m.currentView == viewQueryResults {
+			actions.WriteString(keyStyle.Render("pgup/pgdn"))
+			actions.WriteString(descStyle.Render(" page  "))
+		}
+		if canExport(m) {
+			actions.WriteString(keyStyle.Render("e"))
+			actions.WriteString(descStyle.Render(" export  "))
+		}
+		if m.currentView == viewCollections {
+			actions.WriteString(keyStyle.Render("a"))
+			if m.showArchived {
+				actions.WriteString(descStyle.Render(" hide archived  "))
+			} else {
+				actions.WriteString(descStyle.Render(" show archived  "))
+			}
+			actions.WriteString(keyStyle.Render("p"))
+			if m.hidePersonal {
+				actions.WriteString(descStyle.Render(" show personal  "))
+			} else {
+				actions.WriteString(descStyle.Render(" hide personal  "))
+			}
+			actions.WriteString(keyStyle.Render("T"))
+			if m.collectionTreeMode {
+				actions.WriteString(descStyle.Render(" root only  "))
+				actions.WriteString(keyStyle.Render("→/←"))
+				actions.WriteString(descStyle.Render(" expand/collapse  "))
+			} else {
+				actions.WriteString(descStyle.Render(" tree  "))
+			}
+			if len(m.collections) > 1 {
+				actions.WriteString(keyStyle.Render("s"))
+				actions.WriteString(descStyle.Render(" sort by " + m.collectionSort.next().String() + "  "))
+			}
+		}
+		if len(m.selectableRows()) > 0 && !m.loading {
+			actions.WriteString(keyStyle.Render("v"))
+			actions.WriteString(descStyle.Render(" multi-select  "))
+		}
+		if _, ok := m.moveTarget(); ok && !m.readOnly && !m.loading {
+			actions.WriteString(keyStyle.Render("M"))
+			actions.WriteString(descStyle.Render(" move  "))
+		}
+		if target, ok := m.archiveTarget(); ok && !m.readOnly && !m.loading {
+			actions.WriteString(keyStyle.Render("A"))
+			if target.archived {
+				actions.WriteString(descStyle.Render(" unarchive  "))
+			} else {
+				actions.WriteString(descStyle.Render(" archive  "))
+			}
+		}
+		actions.WriteString(keyStyle.Render(keys.first(actionSearch)))
 		actions.WriteString(descStyle.Render(" search  "))
+		if m.currentView == viewSearch {
+			actions.WriteString(keyStyle.Render("S"))
+			actions.WriteString(descStyle.Render(" new search  "))
+		}
+		actions.WriteString(keyStyle.Render(":"))
+		actions.WriteString(descStyle.Render(" commands  "))
 		actions.WriteString(keyStyle.Render("?"))
 		actions.WriteString(descStyle.Render(" help  "))
-		actions.WriteString(keyStyle.Render("q"))
+		actions.WriteString(keyStyle.Render(keys.first(actionQuit)))
 		actions.WriteString(descStyle.Render(" quit"))
 
 		// Combine sections on separate lines
@@ -376,13 +655,16 @@ func (m Model) getHelpText() string {
 		help.WriteString(actions.String())
 
 		// Add update notification if available
-		if m.updateAvailable {
+		if m.updateAvailable && !m.updateDismissed {
 			help.WriteString("\n")
 			updateStyle := lipgloss.NewStyle().Foreground(ColorWarning)
 			help.WriteString(updateStyle.Render("⚠ Update available: "))
 			help.WriteString(updateStyle.Render(m.latestVersion))
 			help.WriteString(descStyle.Render(" - Run: "))
 			help.WriteString(keyStyle.Render("mbx update"))
+			help.WriteString(descStyle.Render("  "))
+			help.WriteString(keyStyle.Render("U"))
+			help.WriteString(descStyle.Render(" dismiss"))
 		}
 
 		return help.String()
@@ -476,11 +758,11 @@ func (m Model) renderSchemas(output *strings.Builder) {
 			output.WriteString(numberPrefix)
 			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + schema.Name))
 			output.WriteString(" ")
-			output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render(fmt.Sprintf("(%d tables)", schema.TableCount)))
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render(fmt.Sprintf("(%s tables)", humanizeCount(schema.TableCount))))
 		} else {
 			output.WriteString(numberPrefix)
 			output.WriteString("  " + schema.Name + " ")
-			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("(%d tables)", schema.TableCount)))
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("(%s tables)", humanizeCount(schema.TableCount))))
 		}
 		output.WriteString("\n")
 	}
@@ -492,22 +774,39 @@ func (m Model) renderTables(output *strings.Builder) {
 		return
 	}
 
-	// Show filtered or all tables
-	var itemsToShow []int
-
-	if m.searchMode && m.searchQuery != "" && len(m.filteredIndices) > 0 {
-		itemsToShow = m.filteredIndices
-	} else if m.searchMode && m.searchQuery != "" {
+	if m.searchMode && m.searchQuery != "" && len(m.filteredIndices) == 0 {
 		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No matches found"))
 		return
-	} else {
-		for i := range m.tables {
-			itemsToShow = append(itemsToShow, i)
+	}
+	rows := m.tableRows()
+
+	// Tables are indented under their schema header in the flat list, which
+	// scrolls by lines since the headers take lines of their own
+	indent := ""
+	below := 0
+	if m.flattenTables {
+		indent = "  "
+	}
+	if m.flattenTables && m.viewportHeight > 0 && len(rows) > m.viewportHeight {
+		start := tableWindow(rows, m.cursor, m.viewportStart, m.viewportHeight)
+		end := min(start+m.viewportHeight, len(rows))
+		if start > 0 {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↑ ... %d more lines", start)))
+			output.WriteString("\n")
 		}
+		below = len(rows) - end
+		rows = rows[start:end]
 	}
 
-	for i, tableIndex := range itemsToShow {
-		table := m.tables[tableIndex]
+	for _, row := range rows {
+		if row.tableIndex < 0 {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(row.header))
+			output.WriteString("\n")
+			continue
+		}
+
+		i := row.position
+		table := m.tables[row.tableIndex]
 		name := table.DisplayName
 		if name == "" {
 			name = table.Name
@@ -527,9 +826,16 @@ func (m Model) renderTables(output *strings.Builder) {
 		} else {
 			prefixWidth = 5 // "02 ▶ " or "02   "
 		}
-		availableWidth := m.terminalWidth - prefixWidth - 1 // -1 for safety margin
+		prefixWidth += len(indent)
+		technical := m.technicalName(table.DisplayName, table.Name)
+		technicalWidth := 0
+		if technical != "" {
+			technicalWidth = len(technical) + 3 // " (" + name + ")"
+		}
+		availableWidth := m.terminalWidth - prefixWidth - technicalWidth - 1 // -1 for safety margin
 		trimmedName := m.trimText(name, availableWidth)
 
+		output.WriteString(indent)
 		if i == m.cursor {
 			output.WriteString(numberPrefix)
 			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + trimmedName))
@@ -538,9 +844,91 @@ func (m Model) renderTables(output *strings.Builder) {
 			output.WriteString("  " + trimmedName)
 		}
 
+		if technical != "" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("(" + technical + ")"))
+		}
+
 		output.WriteString("\n")
 	}
+	if below > 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↓ ... %d more lines", below)))
+		output.WriteString("\n")
+	}
+
+}
+
+// tableRow is one line of the table list: a schema header (tableIndex -1)
+// or a table, where position is the selectable index the cursor refers to
+type tableRow struct {
+	header     string
+	position   int
+	tableIndex int
+}
+
+// tableRows lists the tables shown, the filtered ones while searching, with
+// schema headers between them in the flat list
+func (m Model) tableRows() []tableRow {
+	var itemsToShow []int
+	if m.searchMode && m.searchQuery != "" {
+		itemsToShow = m.filteredIndices
+	} else {
+		for i := range m.tables {
+			itemsToShow = append(itemsToShow, i)
+		}
+	}
+
+	if m.flattenTables {
+		return groupTableRows(m.tables, itemsToShow)
+	}
+	rows := make([]tableRow, 0, len(itemsToShow))
+	for i, tableIndex := range itemsToShow {
+		rows = append(rows, tableRow{position: i, tableIndex: tableIndex})
+	}
+	return rows
+}
+
+// tableWindow is the first line of rows to show from start on, moved just
+// enough that the cursor's table and the schema header right above it fit
+// in height lines
+func tableWindow(rows []tableRow, cursor, start, height int) int {
+	height = max(height, 1)
+	line := 0
+	for i, row := range rows {
+		if row.tableIndex >= 0 && row.position == cursor {
+			line = i
+		}
+	}
+	top := line
+	if line > 0 && rows[line-1].tableIndex < 0 {
+		top = line - 1
+	}
+	if top < start {
+		start = top
+	} else if line >= start+height {
+		start = line - height + 1
+	}
+	return max(min(start, len(rows)-height), 0)
+}
 
+// scrollTableRows keeps the cursor's table in view in the flat list
+func (m *Model) scrollTableRows() {
+	m.viewportStart = tableWindow(m.tableRows(), m.cursor, m.viewportStart, m.viewportHeight)
+}
+
+// groupTableRows puts a schema header above each run of tables from the
+// same schema. Headers take no cursor position, so number-select and cursor
+// movement only ever land on tables.
+func groupTableRows(tables []api.Table, itemsToShow []int) []tableRow {
+	var rows []tableRow
+	for position, tableIndex := range itemsToShow {
+		schema := tableSchemaName(tables[tableIndex])
+		if position == 0 || schema != tableSchemaName(tables[itemsToShow[position-1]]) {
+			rows = append(rows, tableRow{header: schema, position: -1, tableIndex: -1})
+		}
+		rows = append(rows, tableRow{position: position, tableIndex: tableIndex})
+	}
+	return rows
 }
 
 func (m Model) renderFields(output *strings.Builder) {
@@ -563,8 +951,19 @@ func (m Model) renderFields(output *strings.Builder) {
 		}
 	}
 
-	for i, fieldIndex := range itemsToShow {
-		field := m.fields[fieldIndex]
+	// The legend takes the lines under the list, so it scrolls above it
+	start, end := 0, len(itemsToShow)
+	if height := m.fieldsHeight(); len(itemsToShow) > height {
+		start = min(listWindow(m.cursor, m.viewportStart, height), len(itemsToShow)-height)
+		end = start + height
+		if start > 0 {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↑ ... %d-%d of %d fields", start+1, end, len(itemsToShow))))
+			output.WriteString("\n")
+		}
+	}
+
+	for i := start; i < end; i++ {
+		field := m.fields[itemsToShow[i]]
 		name := field.DisplayName
 		if name == "" {
 			name = field.Name
@@ -580,6 +979,11 @@ func (m Model) renderFields(output *strings.Builder) {
 			output.WriteString("  " + name)
 		}
 
+		if technical := m.technicalName(field.DisplayName, field.Name); technical != "" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("(" + technical + ")"))
+		}
+
 		// Add type info
 		if field.DatabaseType != "" {
 			output.WriteString(" ")
@@ -589,7 +993,7 @@ func (m Model) renderFields(output *strings.Builder) {
 		if field.SemanticType != "" {
 			output.WriteString(" ")
 			color := getSemanticTypeColor(field.SemanticType)
-			output.WriteString(lipgloss.NewStyle().Foreground(color).Render("[" + field.SemanticType + "]"))
+			output.WriteString(lipgloss.NewStyle().Foreground(color).Render("[" + semanticBadge(field.SemanticType) + "]"))
 		}
 
 		output.WriteString("\n")
@@ -597,6 +1001,22 @@ func (m Model) renderFields(output *strings.Builder) {
 
 }
 
+// semanticBadges shorten the key semantic types so keys stand out in the
+// fields list
+var semanticBadges = map[string]string{
+	"type/PK": "PK",
+	"type/FK": "FK",
+}
+
+// semanticBadge is the badge of a semantic type in the fields list; types
+// without a short badge are shown as they are
+func semanticBadge(semanticType string) string {
+	if badge, ok := semanticBadges[semanticType]; ok {
+		return badge
+	}
+	return semanticType
+}
+
 func (m Model) renderHelpOverlay(output *strings.Builder) string {
 	// Title and copyright
 	output.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorPrimary).Render(fmt.Sprintf("Metabase Explorer %s | About", m.Version)))
@@ -666,9 +1086,12 @@ func (m Model) renderHelpOverlay(output *strings.Builder) string {
 }
 
 func (m Model) renderMainMenu(output *strings.Builder) {
-	options := []string{"Collections", "Databases"}
-
-	for i, option := range options {
+	if m.searchMode && m.searchQuery != "" {
+		m.renderMenuMatches(output)
+		return
+	}
+	for i, entry := range mainMenu {
+		option := entry.name
 		var numberPrefix string
 		numberPrefix = lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%d ", i+1))
 
@@ -683,6 +1106,34 @@ func (m Model) renderMainMenu(output *strings.Builder) {
 	}
 }
 
+// renderMenuMatches lists the menu options and actions a search of the
+// main menu matched, best first
+func (m Model) renderMenuMatches(output *strings.Builder) {
+	if len(m.filteredIndices) == 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No matches found"))
+		return
+	}
+	entries := m.menuEntries()
+	for i, entryIndex := range m.filteredIndices {
+		entry := entries[entryIndex]
+		if len(m.filteredIndices) < 10 {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%d ", i+1)))
+		} else {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%02d ", i+1)))
+		}
+		if i == m.cursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + entry.name))
+		} else {
+			output.WriteString("  " + entry.name)
+		}
+		if entry.key != "" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(entry.key))
+		}
+		output.WriteString("\n")
+	}
+}
+
 func (m Model) renderCollections(output *strings.Builder) {
 	if len(m.collections) == 0 {
 		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No collections found"))
@@ -719,8 +1170,32 @@ func (m Model) renderCollections(output *strings.Builder) {
 		} else {
 			prefixWidth = 5 // "02 ▶ " or "02   "
 		}
-		availableWidth := m.terminalWidth - prefixWidth - 1 // -1 for safety margin
-		trimmedName := m.trimText(collection.Name, availableWidth)
+		badgeWidth := 0
+		if collection.Archived {
+			badgeWidth = len(" [archived]")
+		}
+		color, colored := collectionColor(collection)
+		if colored {
+			badgeWidth += 2 // " ●"
+		}
+		countLabel := m.itemCountLabel(collection)
+		if countLabel != "" {
+			badgeWidth += 1 + lipgloss.Width(countLabel)
+		}
+		treePrefix := ""
+		if m.collectionTree != nil {
+			treePrefix = strings.Repeat("  ", m.collectionDepth(collectionIndex))
+			switch {
+			case len(collection.Children) == 0:
+				treePrefix += "  "
+			case m.expandedCollections[listKeyOf("collection", collection.ID)]:
+				treePrefix += "▾ "
+			default:
+				treePrefix += "▸ "
+			}
+		}
+		availableWidth := m.terminalWidth - prefixWidth - badgeWidth - lipgloss.Width(treePrefix) - 1 // -1 for safety margin
+		trimmedName := treePrefix + m.trimText(collection.Name, availableWidth)
 
 		if i == m.cursor {
 			output.WriteString(numberPrefix)
@@ -729,10 +1204,29 @@ func (m Model) renderCollections(output *strings.Builder) {
 			output.WriteString(numberPrefix)
 			output.WriteString("  " + trimmedName)
 		}
+		if countLabel != "" {
+			countColor := ColorMuted
+			if i == m.cursor {
+				countColor = ColorInfo
+			}
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(countColor).Render(countLabel))
+		}
+		if colored {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(color).Render("●"))
+		}
+		if collection.Archived {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorWarning).Render("[archived]"))
+		}
 		output.WriteString("\n")
 	}
 }
 
+// verifiedBadge marks items that passed an Enterprise moderation review
+const verifiedBadge = "✓ verified"
+
 func (m Model) renderCollectionItems(output *strings.Builder) {
 	if len(m.collectionItems) == 0 {
 		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No items found in this collection"))
@@ -796,17 +1290,20 @@ func (m Model) renderCollectionItems(output *strings.Builder) {
 		}
 		typeInfoWidth := 0
 		if item.Model != "" {
-			typeInfoWidth = len(item.Model) + 3 // 3 chars for " [" and "]"
+			typeInfoWidth = len(itemTypeLabel(item.Model)) + 3 // 3 chars for " [" and "]"
+		}
+		if item.ModeratedStatus == "verified" {
+			typeInfoWidth += utf8.RuneCountInString(verifiedBadge) + 1
 		}
 		availableWidth := m.terminalWidth - prefixWidth - typeInfoWidth - 1 // -1 for safety margin
 		
 		trimmedName := m.trimText(item.Name, availableWidth)
 
+		output.WriteString(numberPrefix)
+		output.WriteString(m.selectMark(listKeyOf(item.Model, item.ID)))
 		if i == m.cursor {
-			output.WriteString(numberPrefix)
 			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + trimmedName))
 		} else {
-			output.WriteString(numberPrefix)
 			output.WriteString("  " + trimmedName)
 		}
 
@@ -814,7 +1311,11 @@ func (m Model) renderCollectionItems(output *strings.Builder) {
 		if item.Model != "" {
 			output.WriteString(" ")
 			typeColor := getItemTypeColor(item.Model)
-			output.WriteString(lipgloss.NewStyle().Foreground(typeColor).Render("[" + item.Model + "]"))
+			output.WriteString(lipgloss.NewStyle().Foreground(typeColor).Render("[" + itemTypeLabel(item.Model) + "]"))
+		}
+		if item.ModeratedStatus == "verified" {
+			output.WriteString(" ")
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSuccess).Render(verifiedBadge))
 		}
 
 		output.WriteString("\n")
@@ -838,6 +1339,59 @@ func (m Model) renderCollectionItems(output *strings.Builder) {
 	}
 }
 
+// renderRelated lists the questions and dashboards that use a table, badged
+// with their type like collection items
+func (m Model) renderRelated(output *strings.Builder) {
+	if len(m.relatedItems) == 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No saved questions use this table"))
+		output.WriteString("\n")
+		return
+	}
+
+	var itemsToShow []int
+	if m.searchMode && m.searchQuery != "" && len(m.filteredIndices) > 0 {
+		itemsToShow = m.filteredIndices
+	} else if m.searchMode && m.searchQuery != "" {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No matches found"))
+		return
+	} else {
+		for i := range m.relatedItems {
+			itemsToShow = append(itemsToShow, i)
+		}
+	}
+
+	viewportEnd := min(m.viewportStart+m.viewportHeight, len(itemsToShow))
+	if m.viewportStart > 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↑ ... %d-%d of %d items", m.viewportStart+1, viewportEnd, len(itemsToShow))))
+		output.WriteString("\n")
+	}
+
+	for i := m.viewportStart; i < viewportEnd; i++ {
+		item := m.relatedItems[itemsToShow[i]]
+		if len(m.relatedItems) < 10 {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%d ", i+1)))
+		} else {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%02d ", i+1)))
+		}
+
+		name := m.trimText(item.Name, m.terminalWidth-len(itemTypeLabel(item.Model))-9)
+		output.WriteString(m.selectMark(listKeyOf(item.Model, item.ID)))
+		if i == m.cursor {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + name))
+		} else {
+			output.WriteString("  " + name)
+		}
+		output.WriteString(" ")
+		output.WriteString(lipgloss.NewStyle().Foreground(getItemTypeColor(item.Model)).Render("[" + itemTypeLabel(item.Model) + "]"))
+		output.WriteString("\n")
+	}
+
+	if viewportEnd < len(itemsToShow) {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("↓ ... %d-%d of %d items", m.viewportStart+1, viewportEnd, len(itemsToShow))))
+		output.WriteString("\n")
+	}
+}
+
 func (m Model) renderItemDetail(output *strings.Builder) {
 	if m.selectedItem == nil {
 		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No item selected"))
@@ -848,26 +1402,47 @@ func (m Model) renderItemDetail(output *strings.Builder) {
 
 	// Item Name (title)
 	output.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorPrimary).Render(item.Name))
+	if item.ModeratedStatus == "verified" || (m.itemDetail != nil && m.itemDetail.IsVerified()) {
+		output.WriteString(" ")
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorSuccess).Render(verifiedBadge))
+	}
 	output.WriteString("\n\n")
 
 	// Item Description
 	if item.Description != "" {
 		output.WriteString(lipgloss.NewStyle().Bold(true).Render("Description:"))
 		output.WriteString("\n")
-		// Wrap description text to fit terminal width (conservative width with margin)
-		wrappedDesc := lipgloss.NewStyle().
-			Foreground(ColorPrimary).
-			Width(80).
-			Render(item.Description)
-		output.WriteString(wrappedDesc)
-		output.WriteString("\n\n")
+		// Long descriptions are cut so the metadata below stays on screen
+		lines := m.descriptionLines()
+		shown := min(len(lines), m.descriptionLimit())
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorPrimary).Render(strings.Join(lines[:shown], "\n")))
+		output.WriteString("\n")
+		if shown < len(lines) {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("… %d more lines, press d to read the full description", len(lines)-shown)))
+			output.WriteString("\n")
+		}
+		output.WriteString("\n")
 	} else {
 		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No description available"))
 		output.WriteString("\n\n")
 	}
 
+	if kind := itemTypeName(item.Model); kind != "" {
+		output.WriteString(lipgloss.NewStyle().Bold(true).Render("Type: "))
+		output.WriteString(lipgloss.NewStyle().Foreground(getItemTypeColor(item.Model)).Render(kind))
+		output.WriteString("\n")
+	}
+
 	// Show detailed metadata if available (from detail API)
 	if m.itemDetail != nil {
+		if card, ok := m.itemDetail.(*api.CardDetail); ok {
+			for _, row := range cardQueryInfo(card) {
+				output.WriteString(lipgloss.NewStyle().Bold(true).Render(row[0]))
+				output.WriteString(lipgloss.NewStyle().Foreground(ColorInfo).Render(row[1]))
+				output.WriteString("\n")
+			}
+		}
+
 		if creator := m.itemDetail.GetCreator(); creator != nil {
 			output.WriteString(lipgloss.NewStyle().Bold(true).Render("Created by: "))
 			creatorName := fmt.Sprintf("%s %s", creator.FirstName, creator.LastName)
@@ -903,29 +1478,375 @@ func (m Model) renderItemDetail(output *strings.Builder) {
 		output.WriteString("\n")
 	}
 
+	// Cards on a dashboard can be opened like collection items
+	if cards := m.dashboardCards(); len(cards) > 0 {
+		output.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Cards (%d):", len(cards))))
+		output.WriteString("\n")
+		for i, card := range cards {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("%d ", i+1)))
+			if i == m.cursor {
+				output.WriteString(lipgloss.NewStyle().Foreground(ColorSelected).Bold(true).Render("▶ " + card.Card.Name))
+			} else {
+				output.WriteString("  " + card.Card.Name)
+			}
+			if card.Card.Display != "" {
+				output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(" [" + card.Card.Display + "]"))
+			}
+			output.WriteString("\n")
+		}
+		output.WriteString("\n")
+	}
+
 	// Archived status
 	if item.Archived {
 		output.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorWarning).Render("⚠ This item is archived"))
 	}
 }
 
+// cardQueryInfo describes the query a question or model is built on, as
+// label and value rows
+func cardQueryInfo(card *api.CardDetail) [][2]string {
+	var rows [][2]string
+	query := card.DatasetQuery
+	switch query.Type {
+	case "native":
+		rows = append(rows, [2]string{"Query: ", "SQL"})
+	case "query":
+		source := "query builder"
+		switch table := query.Query.SourceTable.(type) {
+		case float64:
+			source = fmt.Sprintf("query builder on table %d", int(table))
+		case string:
+			if id, ok := strings.CutPrefix(table, "card__"); ok {
+				source = "query builder on question " + id
+			}
+		}
+		rows = append(rows, [2]string{"Query: ", source})
+	}
+	if len(card.ResultMetadata) > 0 {
+		rows = append(rows, [2]string{"Columns: ", humanizeCount(len(card.ResultMetadata))})
+	}
+	return rows
+}
+
+func (m Model) renderFieldDetail(output *strings.Builder) {
+	if m.selectedField == nil {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No field selected"))
+		return
+	}
+
+	field := m.selectedField
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(ColorInfo)
+
+	name := field.DisplayName
+	if name == "" {
+		name = field.Name
+	}
+	output.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorPrimary).Render(name))
+	output.WriteString("\n\n")
+
+	if field.Description != "" {
+		output.WriteString(labelStyle.Render("Description:"))
+		output.WriteString("\n")
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorPrimary).Width(80).Render(field.Description))
+		output.WriteString("\n\n")
+	}
+
+	rows := [][2]string{
+		{"Column: ", field.Name},
+		{"Database type: ", field.DatabaseType},
+		{"Base type: ", field.BaseType},
+		{"Semantic type: ", field.SemanticType},
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		output.WriteString(labelStyle.Render(row[0]))
+		output.WriteString(valueStyle.Render(row[1]))
+		if row[0] == "Semantic type: " {
+			output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(" (" + semanticTypeName(field.SemanticType) + ")"))
+		}
+		output.WriteString("\n")
+	}
+	output.WriteString("\n")
+
+	output.WriteString(labelStyle.Render("Statistics"))
+	output.WriteString("\n")
+	stats := fingerprintStats(field.Fingerprint)
+	if len(stats) == 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No statistics available yet"))
+		output.WriteString("\n")
+		return
+	}
+	for _, stat := range stats {
+		output.WriteString(labelStyle.Render(stat[0] + ": "))
+		output.WriteString(valueStyle.Render(stat[1]))
+		output.WriteString("\n")
+	}
+}
+
+// maxQueryColumnWidth caps how wide a result column grows, so one long
+// value doesn't push every other column off screen
+const maxQueryColumnWidth = 30
+
+func (m Model) renderQueryResults(output *strings.Builder) {
+	if len(m.queryColumns) == 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("No results"))
+		output.WriteString("\n")
+		return
+	}
+
+	// Size columns to their widest value across all rows, so paging doesn't
+	// shift them around
+	widths := make([]int, len(m.queryColumns))
+	for i, column := range m.queryColumns {
+		widths[i] = min(utf8.RuneCountInString(column), maxQueryColumnWidth)
+	}
+	for _, row := range m.queryRows {
+		for i, value := range row {
+			if i < len(widths) {
+				widths[i] = min(max(widths[i], utf8.RuneCountInString(formatCell(value))), maxQueryColumnWidth)
+			}
+		}
+	}
+
+	renderRow := func(values []string) string {
+		cells := make([]string, len(values))
+		for i, value := range values {
+			cells[i] = padCell(value, widths[i])
+		}
+		return truncateLine(strings.TrimRight(strings.Join(cells, "  "), " "), m.terminalWidth)
+	}
+
+	output.WriteString(lipgloss.NewStyle().Bold(true).Render(renderRow(m.queryColumns)))
+	output.WriteString("\n")
+	separators := make([]string, len(widths))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("─", width)
+	}
+	output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(renderRow(separators)))
+	output.WriteString("\n")
+
+	if len(m.queryRows) == 0 {
+		output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("Query returned no rows"))
+		output.WriteString("\n")
+		return
+	}
+
+	end := min(m.cursor+m.queryPageSize(), len(m.queryRows))
+	for _, row := range m.queryRows[m.cursor:end] {
+		values := make([]string, len(m.queryColumns))
+		for i := range values {
+			if i < len(row) {
+				values[i] = formatCell(row[i])
+			}
+		}
+		output.WriteString(renderRow(values))
+		output.WriteString("\n")
+	}
+
+	output.WriteString("\n")
+	output.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(
+		fmt.Sprintf("Rows %d-%d of %d", m.cursor+1, end, len(m.queryRows))))
+	output.WriteString("\n")
+}
+
+// formatCell renders a result value the way Metabase shows it in a table
+func formatCell(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	return strings.ReplaceAll(fmt.Sprint(value), "\n", " ")
+}
+
+// truncateLine cuts a line to the terminal width, counting runes rather than
+// bytes so box-drawing characters aren't split
+func truncateLine(line string, width int) string {
+	runes := []rune(line)
+	if width <= 0 || len(runes) <= width {
+		return line
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// padCell fits a value into a column, truncating it with an ellipsis
+func padCell(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return value + strings.Repeat(" ", width-len(runes))
+}
+
+// fingerprintStats lists the label/value pairs worth showing for a field's
+// fingerprint, skipping whatever Metabase did not record
+func fingerprintStats(fp *api.Fingerprint) [][2]string {
+	if fp == nil {
+		return nil
+	}
+
+	var stats [][2]string
+	if fp.Global.DistinctCount != nil {
+		stats = append(stats, [2]string{"Distinct values", fmt.Sprintf("%d", *fp.Global.DistinctCount)})
+	}
+	if fp.Global.NilPercent != nil {
+		stats = append(stats, [2]string{"Null", formatStat(*fp.Global.NilPercent*100) + "%"})
+	}
+	if number := fp.Type.Number; number != nil {
+		if number.Min != nil {
+			stats = append(stats, [2]string{"Min", formatStat(*number.Min)})
+		}
+		if number.Max != nil {
+			stats = append(stats, [2]string{"Max", formatStat(*number.Max)})
+		}
+		if number.Avg != nil {
+			stats = append(stats, [2]string{"Average", formatStat(*number.Avg)})
+		}
+	}
+	if text := fp.Type.Text; text != nil && text.AverageLength != nil {
+		stats = append(stats, [2]string{"Average length", formatStat(*text.AverageLength)})
+	}
+	if dateTime := fp.Type.DateTime; dateTime != nil {
+		if dateTime.Earliest != "" {
+			stats = append(stats, [2]string{"Earliest", dateTime.Earliest})
+		}
+		if dateTime.Latest != "" {
+			stats = append(stats, [2]string{"Latest", dateTime.Latest})
+		}
+	}
+	return stats
+}
+
+// formatStat prints whole numbers without decimals and everything else
+// rounded to two places
+func formatStat(value float64) string {
+	if value == float64(int64(value)) {
+		return fmt.Sprintf("%d", int64(value))
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// humanizeCount abbreviates large counts for lists, e.g. 1234 becomes 1.2k
+// and 1234567 becomes 1.2M. Counts under 1000 are printed as they are.
+func humanizeCount(n int) string {
+	if n > -1000 && n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	value := float64(n)
+	for _, suffix := range []string{"k", "M", "B"} {
+		value /= 1000
+		// Values that would round up to 1000 move to the next unit
+		if math.Abs(value) >= 999.5 && suffix != "B" {
+			continue
+		}
+		if math.Abs(value) < 9.95 {
+			return strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + suffix
+		}
+		return fmt.Sprintf("%.0f", value) + suffix
+	}
+	return ""
+}
+
+// technicalName returns the raw identifier to show next to a display name,
+// or an empty string when technical names are hidden or would add nothing
+func (m Model) technicalName(displayName, name string) string {
+	if !m.showTechnicalNames || displayName == "" || displayName == name {
+		return ""
+	}
+	return name
+}
+
+// metabaseTimeLayouts are the timestamp formats Metabase returns, depending
+// on the version and application database. Fractional seconds are accepted
+// after the seconds by every layout.
+var metabaseTimeLayouts = []string{
+	time.RFC3339Nano,            // 2024-01-15T10:30:00.123456Z, 2024-01-15T10:30:00+00:00
+	"2006-01-02T15:04:05Z0700",  // 2024-01-15T10:30:00.123+0000
+	"2006-01-02T15:04:05",       // 2024-01-15T10:30:00.123456, no zone
+	"2006-01-02 15:04:05Z07:00", // 2024-01-15 10:30:00+00:00
+	"2006-01-02 15:04:05",       // 2024-01-15 10:30:00
+	"2006-01-02",                // 2024-01-15
+}
+
+// parseMetabaseTime parses a timestamp in any of the formats Metabase uses.
+// Timestamps without a zone are taken as UTC.
+func parseMetabaseTime(timestamp string) (time.Time, bool) {
+	for _, layout := range metabaseTimeLayouts {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 func (m Model) formatTimestamp(timestamp string) string {
 	if timestamp == "" {
 		return ""
 	}
 
-	// Parse the timestamp (assuming ISO 8601 format)
-	t, err := time.Parse(time.RFC3339, timestamp)
-	if err != nil {
-		// Try alternative format if RFC3339 fails
-		t, err = time.Parse("2006-01-02T15:04:05.000000Z", timestamp)
-		if err != nil {
-			return timestamp // Return as-is if parsing fails
+	t, ok := parseMetabaseTime(timestamp)
+	if !ok {
+		return timestamp // Return as-is if parsing fails
+	}
+	if m.relativeTime {
+		return formatRelativeTime(t, time.Now())
+	}
+	layout := m.timeLayout
+	if layout == "" {
+		layout = config.DefaultTimeFormat
+	}
+	// Dates without a time are the same day everywhere
+	if m.timezone == nil || len(timestamp) == len("2006-01-02") {
+		return t.Format(layout)
+	}
+	if !layoutHasZone(layout) {
+		layout += " MST"
+	}
+	return t.In(m.timezone).Format(layout)
+}
+
+// layoutHasZone reports whether a Go time layout prints the time zone, by
+// name or as an offset
+func layoutHasZone(layout string) bool {
+	for _, zone := range []string{"MST", "Z07", "-07"} {
+		if strings.Contains(layout, zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRelativeTime describes how long before now t was, in its largest
+// whole unit
+func formatRelativeTime(t, now time.Time) string {
+	ago := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
 		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
 	}
 
-	// Format as a human-readable date
-	return t.Format("Jan 2, 2006 at 3:04 PM")
+	elapsed := now.Sub(t)
+	switch {
+	case elapsed < 0:
+		return t.Format(config.DefaultTimeFormat) // Clock skew, or a future date
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return ago(int(elapsed/time.Minute), "minute")
+	case elapsed < 24*time.Hour:
+		return ago(int(elapsed/time.Hour), "hour")
+	case elapsed < 48*time.Hour:
+		return "yesterday"
+	case elapsed < 30*24*time.Hour:
+		return ago(int(elapsed/(24*time.Hour)), "day")
+	case elapsed < 365*24*time.Hour:
+		return ago(int(elapsed/(30*24*time.Hour)), "month")
+	default:
+		return ago(int(elapsed/(365*24*time.Hour)), "year")
+	}
 }
 
 func (m Model) trimText(text string, maxWidth int) string {
@@ -0,0 +1,903 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+	"github.com/amureki/metabase-explorer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+func TestTechnicalName(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		displayName string
+		rawName     string
+		expected    string
+	}{
+		{
+			name:        "differs",
+			enabled:     true,
+			displayName: "Users",
+			rawName:     "users",
+			expected:    "users",
+		},
+		{
+			name:        "matches",
+			enabled:     true,
+			displayName: "users",
+			rawName:     "users",
+			expected:    "",
+		},
+		{
+			name:        "display empty",
+			enabled:     true,
+			displayName: "",
+			rawName:     "users",
+			expected:    "",
+		},
+		{
+			name:        "disabled",
+			enabled:     false,
+			displayName: "Users",
+			rawName:     "users",
+			expected:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{showTechnicalNames: tt.enabled}
+			result := m.technicalName(tt.displayName, tt.rawName)
+			if result != tt.expected {
+				t.Errorf("technicalName(%q, %q) = %q, want %q", tt.displayName, tt.rawName, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderTables_TechnicalNames(t *testing.T) {
+	m := Model{
+		showTechnicalNames: true,
+		terminalWidth:      80,
+		tables: []api.Table{
+			{ID: 1, Name: "users", DisplayName: "Users"},
+			{ID: 2, Name: "orders", DisplayName: "orders"},
+			{ID: 3, Name: "events"},
+		},
+	}
+
+	var output strings.Builder
+	m.renderTables(&output)
+	lines := strings.Split(stripANSI(output.String()), "\n")
+
+	expected := []string{
+		"1 ▶ Users (users)",
+		"2   orders",
+		"3   events",
+	}
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("renderTables() line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestRenderFields_TechnicalNames(t *testing.T) {
+	m := Model{
+		showTechnicalNames: true,
+		terminalWidth:      80,
+		fields: []api.Field{
+			{ID: 1, Name: "created_at", DisplayName: "Created At", DatabaseType: "timestamp"},
+		},
+	}
+
+	var output strings.Builder
+	m.renderFields(&output)
+	result := stripANSI(output.String())
+
+	if !strings.Contains(result, "Created At (created_at) timestamp") {
+		t.Errorf("renderFields() = %q, want technical name after display name", result)
+	}
+}
+
+func TestFingerprintStats(t *testing.T) {
+	distinct := 120
+	nilPercent := 0.125
+	minValue, maxValue, avg := 1.0, 250.0, 37.456
+	avgLength := 12.0
+
+	tests := []struct {
+		name        string
+		fingerprint *api.Fingerprint
+		expected    [][2]string
+	}{
+		{
+			name:        "no fingerprint",
+			fingerprint: nil,
+			expected:    nil,
+		},
+		{
+			name: "number field",
+			fingerprint: &api.Fingerprint{
+				Global: api.GlobalFingerprint{DistinctCount: &distinct, NilPercent: &nilPercent},
+				Type:   api.TypeFingerprint{Number: &api.NumberFingerprint{Min: &minValue, Max: &maxValue, Avg: &avg}},
+			},
+			expected: [][2]string{
+				{"Distinct values", "120"},
+				{"Null", "12.50%"},
+				{"Min", "1"},
+				{"Max", "250"},
+				{"Average", "37.46"},
+			},
+		},
+		{
+			name: "text field without null stats",
+			fingerprint: &api.Fingerprint{
+				Global: api.GlobalFingerprint{DistinctCount: &distinct},
+				Type:   api.TypeFingerprint{Text: &api.TextFingerprint{AverageLength: &avgLength}},
+			},
+			expected: [][2]string{
+				{"Distinct values", "120"},
+				{"Average length", "12"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := fingerprintStats(tt.fingerprint)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("fingerprintStats() = %v, want %v", result, tt.expected)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("fingerprintStats()[%d] = %v, want %v", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFieldDetailNavigation(t *testing.T) {
+	m := Model{
+		currentView: viewFields,
+		fields: []api.Field{
+			{ID: 1, Name: "id"},
+			{ID: 2, Name: "email"},
+		},
+		cursor: 1,
+	}
+
+	m, _ = m.selectItem(m.cursor)
+	if m.currentView != viewFieldDetail || m.selectedField == nil || m.selectedField.Name != "email" {
+		t.Fatalf("selectItem() view = %v, field = %+v, want email detail", m.currentView, m.selectedField)
+	}
+
+	m, _ = m.goBack()
+	if m.currentView != viewFields || m.cursor != 1 || m.selectedField != nil {
+		t.Errorf("goBack() view = %v, cursor = %d, want fields with cursor restored", m.currentView, m.cursor)
+	}
+}
+
+func TestGroupTableRows(t *testing.T) {
+	tables := sortTablesBySchema([]api.Table{
+		{ID: 1, Name: "users", Schema: "public"},
+		{ID: 2, Name: "events", Schema: "analytics"},
+		{ID: 3, Name: "accounts", Schema: "public"},
+		{ID: 4, Name: "sessions", Schema: "analytics"},
+	})
+
+	tests := []struct {
+		name        string
+		itemsToShow []int
+		expected    []string // "# schema" for headers, "position:table" for tables
+	}{
+		{
+			name:        "all tables",
+			itemsToShow: []int{0, 1, 2, 3},
+			expected:    []string{"# analytics", "0:events", "1:sessions", "# public", "2:accounts", "3:users"},
+		},
+		{
+			name:        "filtered tables",
+			itemsToShow: []int{3, 1},
+			expected:    []string{"# public", "0:users", "# analytics", "1:sessions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []string
+			for _, row := range groupTableRows(tables, tt.itemsToShow) {
+				if row.tableIndex < 0 {
+					result = append(result, "# "+row.header)
+				} else {
+					result = append(result, fmt.Sprintf("%d:%s", row.position, tables[row.tableIndex].Name))
+				}
+			}
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("groupTableRows() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderTables_Flattened(t *testing.T) {
+	m := Model{
+		flattenTables: true,
+		terminalWidth: 80,
+		cursor:        1,
+		tables: sortTablesBySchema([]api.Table{
+			{ID: 1, Name: "users", Schema: "public"},
+			{ID: 2, Name: "events", Schema: "analytics"},
+		}),
+	}
+
+	var output strings.Builder
+	m.renderTables(&output)
+	lines := strings.Split(stripANSI(output.String()), "\n")
+
+	expected := []string{
+		"analytics",
+		"  1   events",
+		"public",
+		"  2 ▶ users",
+	}
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("renderTables() line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestRenderTables_FlattenedScrolls(t *testing.T) {
+	var tables []api.Table
+	for i := 1; i <= 8; i++ {
+		tables = append(tables, api.Table{ID: i, Name: fmt.Sprintf("table%d", i), Schema: fmt.Sprintf("schema%d", i)})
+	}
+	m := newTestModel()
+	m.flattenTables = true
+	m = send(t, m, steps(toDatabases, []tea.Msg{key("enter"), tablesLoaded{tables: tables, total: len(tables)}})...)
+	m.viewportHeight = 5
+
+	// Every table comes with its schema header, so moving to the 4th table,
+	// on the 8th line, scrolls the first 3 lines out of the 5 shown
+	m = send(t, m, key("down"), key("down"), key("down"))
+	var output strings.Builder
+	m.renderTables(&output)
+	lines := strings.Split(strings.TrimSuffix(stripANSI(output.String()), "\n"), "\n")
+	expected := []string{
+		"↑ ... 3 more lines",
+		"  2   table2",
+		"schema3",
+		"  3   table3",
+		"schema4",
+		"  4 ▶ table4",
+		"↓ ... 8 more lines",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("renderTables() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+
+	// Going back up shows a table's schema header along with it
+	m = send(t, m, key("up"), key("up"))
+	if m.viewportStart != 2 {
+		t.Errorf("up: viewportStart = %d, want 2, the header of schema2", m.viewportStart)
+	}
+}
+
+func TestCapTables(t *testing.T) {
+	tables := []api.Table{{ID: 1}, {ID: 2}, {ID: 3}}
+	tests := []struct {
+		limit     int
+		wantCount int
+	}{
+		{limit: 2, wantCount: 2},
+		{limit: 3, wantCount: 3},
+		{limit: 5, wantCount: 3},
+		{limit: 0, wantCount: 3},
+	}
+	for _, tt := range tests {
+		capped, total := capTables(tables, tt.limit)
+		if len(capped) != tt.wantCount || total != 3 {
+			t.Errorf("capTables(%d) = %d tables of %d, want %d of 3", tt.limit, len(capped), total, tt.wantCount)
+		}
+	}
+}
+
+func TestRenderTables_Capped(t *testing.T) {
+	tables := sortTablesBySchema([]api.Table{
+		{ID: 1, Name: "users", Schema: "public"},
+		{ID: 2, Name: "events", Schema: "analytics"},
+		{ID: 3, Name: "orders", Schema: "public"},
+	})
+	m := newTestModel()
+	m.flattenTables = true
+	m.maxTablesPerDB = 2
+	m = send(t, m, steps(toDatabases, []tea.Msg{key("enter")})...)
+	capped, total := capTables(tables, m.tableLimit())
+	m = send(t, m, tablesLoaded{tables: capped, total: total})
+
+	view := plainView(m)
+	if path := strings.Split(view, "\n")[1]; path != "Databases > Sample Database > All schemas (showing 2 of 3)" {
+		t.Errorf("path = %q, want the cap noted", path)
+	}
+	if !strings.Contains(view, "m load the rest") {
+		t.Error("help doesn't offer to load the rest")
+	}
+
+	updated, cmd := m.Update(key("m"))
+	m = updated.(Model)
+	if cmd == nil || !m.loading || m.tableLimit() != 0 {
+		t.Fatalf("m: cmd = %v, loading = %v, limit = %d, want every table loading", cmd != nil, m.loading, m.tableLimit())
+	}
+	m = send(t, m, tablesLoaded{tables: tables, total: len(tables)})
+	if path := strings.Split(plainView(m), "\n")[1]; path != "Databases > Sample Database > All schemas (3)" {
+		t.Errorf("path after loading the rest = %q", path)
+	}
+	if m.tablesCapped() {
+		t.Error("list still capped after loading the rest")
+	}
+}
+
+func TestRenderTables_CappedFromMemory(t *testing.T) {
+	tables := []api.Table{
+		{ID: 1, Name: "users", Schema: "public"},
+		{ID: 2, Name: "events", Schema: "analytics"},
+		{ID: 3, Name: "orders", Schema: "public"},
+	}
+	m := newTestModel()
+	m.flattenTables = true
+	m.maxTablesPerDB = 2
+	m = send(t, m, steps(toDatabases, []tea.Msg{key("enter"), cappedTablesLoaded(tables, m.tableLimit())})...)
+	if !m.tablesCapped() || len(m.tables) != 2 {
+		t.Fatalf("tables = %+v, want 2 of 3 shown", m.tables)
+	}
+
+	// The tables left out came with the others, so no request is needed
+	updated, cmd := m.Update(key("m"))
+	m = updated.(Model)
+	if cmd != nil || m.loading || m.tablesCapped() {
+		t.Fatalf("m: cmd = %v, loading = %v, capped = %v, want the rest shown at once", cmd != nil, m.loading, m.tablesCapped())
+	}
+	var names []string
+	for _, table := range m.tables {
+		names = append(names, table.Name)
+	}
+	if want := []string{"events", "orders", "users"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("tables = %v, want %v", names, want)
+	}
+}
+
+func TestRender_VerifiedBadge(t *testing.T) {
+	m := Model{
+		terminalWidth:  80,
+		viewportHeight: 15,
+		collectionItems: []api.CollectionItem{
+			{ID: 1, Name: "Revenue", Model: "dashboard", ModeratedStatus: "verified"},
+			{ID: 2, Name: "Scratch", Model: "card"},
+		},
+	}
+
+	var output strings.Builder
+	m.renderCollectionItems(&output)
+	lines := strings.Split(stripANSI(output.String()), "\n")
+	if lines[0] != "1 ▶ Revenue [dashboard] ✓ verified" || lines[1] != "2   Scratch [card]" {
+		t.Errorf("renderCollectionItems() = %q, want the badge on the verified item only", lines[:2])
+	}
+
+	m.selectedItem = &api.CollectionItem{ID: 2, Name: "Scratch", Model: "card"}
+	m.itemDetail = &api.CardDetail{ModerationReviews: []api.ModerationReview{{Status: "verified", MostRecent: true}}}
+	output.Reset()
+	m.renderItemDetail(&output)
+	if first := strings.Split(stripANSI(output.String()), "\n")[0]; first != "Scratch ✓ verified" {
+		t.Errorf("renderItemDetail() title = %q, want %q", first, "Scratch ✓ verified")
+	}
+}
+
+func TestRenderCollectionItems_OtherModels(t *testing.T) {
+	m := Model{
+		terminalWidth:  80,
+		viewportHeight: 15,
+		collectionItems: []api.CollectionItem{
+			{ID: 1, Name: "Weekly digest", Model: "pulse"},
+			{ID: 2, Name: "Launches", Model: "timeline"},
+			{ID: 3, Name: "Active users", Model: "snippet"},
+			{ID: 4, Name: "Mystery", Model: "something-new"},
+		},
+	}
+
+	var output strings.Builder
+	m.renderCollectionItems(&output)
+	lines := strings.Split(stripANSI(output.String()), "\n")
+	want := []string{
+		"1 ▶ Weekly digest [subscription]",
+		"2   Launches [timeline]",
+		"3   Active users [snippet]",
+		"4   Mystery [something-new]",
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i, lines[i], line)
+		}
+	}
+}
+
+func TestModelItems(t *testing.T) {
+	model := api.CollectionItem{ID: 23, Name: "Orders model", Model: "dataset"}
+	toModel := steps(toCollections, []tea.Msg{key("enter"), collectionItemsLoaded{items: []api.CollectionItem{model}}})
+
+	m := send(t, newTestModel(), toModel...)
+	if line := strings.Split(plainView(m), "\n")[3]; line != "1 ▶ Orders model [model]" {
+		t.Errorf("collection item = %q, want the model badge", line)
+	}
+	if got, want := m.getWebURL(), "http://metabase.local/model/23"; got != want {
+		t.Errorf("getWebURL() = %q, want %q", got, want)
+	}
+
+	detail := &api.CardDetail{ID: 23, Name: "Orders model", Type: "model", ResultMetadata: []api.QueryColumn{{Name: "ID"}, {Name: "TOTAL"}}}
+	detail.DatasetQuery.Type = "query"
+	detail.DatasetQuery.Query.SourceTable = float64(10)
+	m = send(t, m, key("enter"), cardDetailLoaded{detail: detail})
+	for _, want := range []string{"Type: Model", "Query: query builder on table 10", "Columns: 2"} {
+		if !strings.Contains(plainView(m), want) {
+			t.Errorf("item detail missing %q:\n%s", want, plainView(m))
+		}
+	}
+	if got, want := m.getWebURL(), "http://metabase.local/model/23"; got != want {
+		t.Errorf("getWebURL() = %q, want %q", got, want)
+	}
+	if !m.canRunQuery() {
+		t.Error("canRunQuery() = false, want models to be runnable")
+	}
+}
+
+func TestCardQueryInfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  [][2]string
+	}{
+		{name: "native", query: `{"type": "native", "native": {"query": "SELECT 1"}}`, want: [][2]string{{"Query: ", "SQL"}}},
+		{name: "table", query: `{"type": "query", "query": {"source-table": 10}}`, want: [][2]string{{"Query: ", "query builder on table 10"}}},
+		{name: "card", query: `{"type": "query", "query": {"source-table": "card__12"}}`, want: [][2]string{{"Query: ", "query builder on question 12"}}},
+		{name: "missing", query: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var card api.CardDetail
+			if err := json.Unmarshal([]byte(`{"dataset_query": `+tt.query+`}`), &card); err != nil {
+				t.Fatal(err)
+			}
+			if got := cardQueryInfo(&card); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cardQueryInfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenItemWithoutDetail(t *testing.T) {
+	m := send(t, newTestModel(), steps(toCollections, []tea.Msg{key("enter"), collectionItemsLoaded{items: []api.CollectionItem{
+		{ID: 40, Name: "Launches", Model: "timeline"},
+	}}})...)
+
+	updated, cmd := m.Update(key("enter"))
+	m = updated.(Model)
+	if m.currentView != viewItemDetail || m.loading || cmd != nil {
+		t.Fatalf("view = %v, loading = %v, cmd = %v, want the detail shown without a load", m.currentView, m.loading, cmd != nil)
+	}
+	if got, want := m.getWebURL(), "http://metabase.local/collection/5/timelines/40"; got != want {
+		t.Errorf("getWebURL() = %q, want %q", got, want)
+	}
+}
+
+func TestHumanizeCount(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1k"},
+		{1049, "1k"},
+		{1234, "1.2k"},
+		{9999, "10k"},
+		{12345, "12k"},
+		{999_499, "999k"},
+		{999_999, "1M"},
+		{1_000_000, "1M"},
+		{1_234_567, "1.2M"},
+		{45_600_000, "46M"},
+		{2_500_000_000, "2.5B"},
+		{-1234, "-1.2k"},
+	}
+
+	for _, tt := range tests {
+		if got := humanizeCount(tt.n); got != tt.expected {
+			t.Errorf("humanizeCount(%d) = %q, want %q", tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestParseMetabaseTime(t *testing.T) {
+	expected := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		timestamp string
+		expected  time.Time
+		ok        bool
+	}{
+		{"2024-01-15T10:30:00Z", expected, true},
+		{"2024-01-15T10:30:00.123456Z", expected.Add(123456 * time.Microsecond), true},
+		{"2024-01-15T10:30:00.123Z", expected.Add(123 * time.Millisecond), true},
+		{"2024-01-15T10:30:00+00:00", expected, true},
+		{"2024-01-15T12:30:00.5+02:00", expected.Add(500 * time.Millisecond), true},
+		{"2024-01-15T10:30:00.123456+0000", expected.Add(123456 * time.Microsecond), true},
+		{"2024-01-15T10:30:00", expected, true},
+		{"2024-01-15T10:30:00.123456", expected.Add(123456 * time.Microsecond), true},
+		{"2024-01-15 10:30:00", expected, true},
+		{"2024-01-15 10:30:00+00:00", expected, true},
+		{"2024-01-15", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), true},
+		{"yesterday", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseMetabaseTime(tt.timestamp)
+		if ok != tt.ok || !got.Equal(tt.expected) {
+			t.Errorf("parseMetabaseTime(%q) = %v, %v, want %v, %v", tt.timestamp, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		timestamp string
+		expected  string
+	}{
+		{"2024-01-15T10:30:00.123456Z", "Jan 15, 2024 at 10:30 AM"},
+		{"2024-01-15T10:30:00+00:00", "Jan 15, 2024 at 10:30 AM"},
+		{"not a date", "not a date"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := (Model{}).formatTimestamp(tt.timestamp); got != tt.expected {
+			t.Errorf("formatTimestamp(%q) = %q, want %q", tt.timestamp, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatTimestamp_TimeFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: "iso", expected: "2024-01-15 14:30"},
+		{format: "us", expected: "01/15/2024 2:30 PM"},
+		{format: "eu", expected: "15/01/2024 14:30"},
+		{format: "2 Jan 06 15:04", expected: "15 Jan 24 14:30"},
+	}
+
+	for _, tt := range tests {
+		layout, _, err := (&config.Config{TimeFormat: tt.format}).TimeLayout()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := (Model{timeLayout: layout}).formatTimestamp("2024-01-15T14:30:00Z"); got != tt.expected {
+			t.Errorf("formatTimestamp() with time_format %q = %q, want %q", tt.format, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatTimestamp_Timezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no timezone database:", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no timezone database:", err)
+	}
+
+	tests := []struct {
+		location  *time.Location
+		timestamp string
+		expected  string
+	}{
+		{location: berlin, timestamp: "2024-01-15T14:30:00Z", expected: "Jan 15, 2024 at 3:30 PM CET"},
+		{location: berlin, timestamp: "2024-07-15T14:30:00.123Z", expected: "Jul 15, 2024 at 4:30 PM CEST"},
+		{location: newYork, timestamp: "2024-01-15T14:30:00Z", expected: "Jan 15, 2024 at 9:30 AM EST"},
+		{location: newYork, timestamp: "2024-01-15 10:30:00", expected: "Jan 15, 2024 at 5:30 AM EST"}, // No zone, taken as UTC
+		{location: newYork, timestamp: "2024-01-15T16:30:00+02:00", expected: "Jan 15, 2024 at 9:30 AM EST"},
+		{location: newYork, timestamp: "2024-01-15", expected: "Jan 15, 2024 at 12:00 AM"},
+		{location: time.UTC, timestamp: "2024-01-15T16:30:00+02:00", expected: "Jan 15, 2024 at 2:30 PM UTC"},
+	}
+
+	for _, tt := range tests {
+		if got := (Model{timezone: tt.location}).formatTimestamp(tt.timestamp); got != tt.expected {
+			t.Errorf("formatTimestamp(%q) in %v = %q, want %q", tt.timestamp, tt.location, got, tt.expected)
+		}
+	}
+
+	// A time_format with a zone of its own shows it once
+	for layout, expected := range map[string]string{
+		"2006-01-02 15:04 MST":   "2024-01-15 15:30 CET",
+		"2006-01-02T15:04Z07:00": "2024-01-15T15:30+01:00",
+		"02.01.2006 15:04 -0700": "15.01.2024 15:30 +0100",
+		"02.01.2006 15:04":       "15.01.2024 15:30 CET",
+	} {
+		if got := (Model{timezone: berlin, timeLayout: layout}).formatTimestamp("2024-01-15T14:30:00Z"); got != expected {
+			t.Errorf("formatTimestamp() with layout %q = %q, want %q", layout, got, expected)
+		}
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago      time.Duration
+		expected string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{30 * time.Hour, "yesterday"},
+		{5 * 24 * time.Hour, "5 days ago"},
+		{65 * 24 * time.Hour, "2 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+		{-time.Hour, "Jun 15, 2024 at 1:00 PM"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRelativeTime(now.Add(-tt.ago), now); got != tt.expected {
+			t.Errorf("formatRelativeTime(now - %v) = %q, want %q", tt.ago, got, tt.expected)
+		}
+	}
+}
+
+func TestRender_RelativeTimeToggle(t *testing.T) {
+	updated := time.Now().UTC().Add(-3 * time.Hour)
+	m := send(t, newTestModel(), toItemDetail...)
+	m = send(t, m, cardDetailLoaded{detail: &api.CardDetail{ID: 21, Name: "Orders by month", UpdatedAt: updated.Format(time.RFC3339)}})
+
+	absolute := "Updated: " + updated.Format("Jan 2, 2006 at 3:04 PM")
+	if view := plainView(m); !strings.Contains(view, absolute) {
+		t.Fatalf("default view does not show %q:\n%s", absolute, view)
+	}
+
+	m = send(t, m, key("t"))
+	if view := plainView(m); !strings.Contains(view, "Updated: 3 hours ago") {
+		t.Errorf("after t, view does not show the relative time:\n%s", view)
+	}
+
+	m = send(t, m, key("t"))
+	if view := plainView(m); !strings.Contains(view, absolute) {
+		t.Errorf("after t twice, view does not show %q again", absolute)
+	}
+}
+
+func TestRenderFields_KeyBadges(t *testing.T) {
+	m := Model{
+		currentView: viewFields,
+		fields: []api.Field{
+			{ID: 1, Name: "ID", DisplayName: "ID", SemanticType: "type/PK"},
+			{ID: 2, Name: "USER_ID", DisplayName: "User ID", SemanticType: "type/FK"},
+			{ID: 3, Name: "SOURCE", DisplayName: "Source", SemanticType: "type/Source"},
+			{ID: 4, Name: "TOTAL", DisplayName: "Total"},
+		},
+	}
+
+	var output strings.Builder
+	m.renderFields(&output)
+	lines := strings.Split(stripANSI(output.String()), "\n")
+
+	expected := []string{"ID [PK]", "User ID [FK]", "Source [type/Source]", "Total"}
+	for i, want := range expected {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("renderFields() line %d = %q, want it to end with %q", i, lines[i], want)
+		}
+	}
+	if getSemanticTypeColor("type/PK") == getSemanticTypeColor("type/FK") || getSemanticTypeColor("type/FK") == getSemanticTypeColor("type/Source") {
+		t.Error("getSemanticTypeColor() gives keys the same color as each other or other types")
+	}
+}
+
+func TestSemanticTypeName(t *testing.T) {
+	tests := []struct {
+		semanticType string
+		expected     string
+	}{
+		{semanticType: "type/PK", expected: "Primary key"},
+		{semanticType: "type/FK", expected: "Foreign key"},
+		{semanticType: "type/Email", expected: "Email address"},
+		{semanticType: "type/CreationTimestamp", expected: "When the row was created"},
+		{semanticType: "type/ZipCode", expected: "ZIP or postal code"},
+		// Types without an explanation are spelled out
+		{semanticType: "type/AccountAge", expected: "Account age"},
+		{semanticType: "type/Thing", expected: "Thing"},
+	}
+	for _, tt := range tests {
+		if got := semanticTypeName(tt.semanticType); got != tt.expected {
+			t.Errorf("semanticTypeName(%q) = %q, want %q", tt.semanticType, got, tt.expected)
+		}
+	}
+}
+
+func TestFieldLegend(t *testing.T) {
+	m := send(t, newTestModel(), steps(toTables, []tea.Msg{key("enter"), fieldsLoaded{fields: []api.Field{
+		{ID: 1, Name: "ID", SemanticType: "type/PK"},
+		{ID: 2, Name: "EMAIL", SemanticType: "type/Email"},
+		{ID: 3, Name: "SCORE", SemanticType: "type/AccountAge"},
+		{ID: 4, Name: "NOTE"},
+	}}})...)
+	if strings.Contains(plainView(m), "Legend") {
+		t.Fatal("legend shown before L")
+	}
+
+	m = send(t, m, key("L"))
+	view := plainView(m)
+
+	// Every badge and color the fields list draws is explained
+	for semanticType, badge := range semanticBadges {
+		if !strings.Contains(view, "["+badge+"] "+semanticTypeName(semanticType)) {
+			t.Errorf("legend misses the %s badge:\n%s", badge, view)
+		}
+	}
+	for semanticType := range semanticTypeColors {
+		if !strings.Contains(view, "["+semanticBadge(semanticType)+"]") {
+			t.Errorf("legend misses the color of %s:\n%s", semanticType, view)
+		}
+	}
+	// So are the other semantic types of the table, and only those
+	for _, want := range []string{"[type/Email] Email address", "[type/AccountAge] Account age"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("legend misses %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "type/City") {
+		t.Errorf("legend explains a type the table doesn't have:\n%s", view)
+	}
+
+	if m = send(t, m, key("L")); strings.Contains(plainView(m), "Legend") {
+		t.Error("legend still shown after the second L")
+	}
+}
+
+func TestFieldLegend_Scrolls(t *testing.T) {
+	var fields []api.Field
+	for i := 1; i <= 20; i++ {
+		fields = append(fields, api.Field{ID: i, Name: fmt.Sprintf("FIELD%d", i), Position: i})
+	}
+	fields[0].SemanticType = "type/Email"
+	m := send(t, newTestModel(), steps(toTables, []tea.Msg{key("enter"), fieldsLoaded{fields: fields}, key("L")})...)
+
+	// 15 lines, less the blank line, title, badges and the email line
+	if height := m.fieldsHeight(); height != 11 {
+		t.Fatalf("fieldsHeight() = %d, want 11", height)
+	}
+	for range 15 {
+		m = send(t, m, key("down"))
+	}
+	view := plainView(m)
+	for _, want := range []string{"↑ ... 6-16 of 20 fields", "16 ▶ FIELD16", "Legend"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "FIELD17") || strings.Contains(view, "05   FIELD5") {
+		t.Errorf("View() shows fields outside the window above the legend:\n%s", view)
+	}
+}
+
+// withColorDepth sets the color depth of the terminal for a test
+func withColorDepth(t *testing.T, depth colorDepth) {
+	t.Helper()
+	saved := terminalColors
+	terminalColors = depth
+	t.Cleanup(func() { terminalColors = saved })
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		env      map[string]string
+		expected colorDepth
+	}{
+		{env: map[string]string{"COLORTERM": "truecolor", "TERM": "xterm-256color"}, expected: colorsTrue},
+		{env: map[string]string{"COLORTERM": "24bit"}, expected: colorsTrue},
+		{env: map[string]string{"TERM": "xterm-256color"}, expected: colors256},
+		{env: map[string]string{"COLORTERM": "yes", "TERM": "screen-256color"}, expected: colors256},
+		{env: map[string]string{"TERM": "xterm"}, expected: colors16},
+		{env: map[string]string{}, expected: colors16},
+	}
+
+	for _, tt := range tests {
+		if depth := detectColorDepth(func(key string) string { return tt.env[key] }); depth != tt.expected {
+			t.Errorf("detectColorDepth(%v) = %v, want %v", tt.env, depth, tt.expected)
+		}
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	tests := []struct {
+		depth    colorDepth
+		color    string
+		expected lipgloss.Color
+	}{
+		{depth: colorsTrue, color: "#509EE3", expected: "#509ee3"},
+		{depth: colorsTrue, color: "#a7f", expected: "#aa77ff"},
+		{depth: colors256, color: "#509EE3", expected: "74"},
+		{depth: colors256, color: "#FF0000", expected: "196"},
+		{depth: colors256, color: "#949AAB", expected: "247"}, // Closer to the gray ramp than the cube
+		{depth: colors16, color: "#509EE3", expected: "14"},
+	}
+
+	for _, tt := range tests {
+		withColorDepth(t, tt.depth)
+		if color, ok := hexColor(tt.color); !ok || color != tt.expected {
+			t.Errorf("hexColor(%q) with depth %v = %q, want %q", tt.color, tt.depth, color, tt.expected)
+		}
+	}
+}
+
+func TestCollectionColor(t *testing.T) {
+	withColorDepth(t, colors16)
+	tests := []struct {
+		color    string
+		expected lipgloss.Color
+		ok       bool
+	}{
+		{color: "#509EE3", expected: "14", ok: true}, // Metabase's brand blue
+		{color: "#ED6E6E", expected: "9", ok: true},
+		{color: "#4CAF50", expected: "2", ok: true},
+		{color: "#F9D45C", expected: "11", ok: true},
+		{color: "#a7f", expected: "12", ok: true},
+		{color: "#31698A", expected: "6", ok: true},
+		{color: "#949AAB", expected: "8", ok: true}, // Gray
+		{color: ""},
+		{color: "blue"},
+		{color: "#12345G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			color, ok := collectionColor(api.Collection{Color: tt.color})
+			if color != tt.expected || ok != tt.ok {
+				t.Errorf("collectionColor(%q) = %q, %v, want %q, %v", tt.color, color, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRenderCollections_Color(t *testing.T) {
+	m := Model{
+		terminalWidth:  80,
+		viewportHeight: 15,
+		collections: []api.Collection{
+			{ID: 1, Name: "Finance", Color: "#509EE3"},
+			{ID: 2, Name: "Scratch"},
+		},
+	}
+
+	var output strings.Builder
+	m.renderCollections(&output)
+	lines := strings.Split(stripANSI(output.String()), "\n")
+	if lines[0] != "1 ▶ Finance ●" || lines[1] != "2   Scratch" {
+		t.Errorf("renderCollections() = %q, want the indicator on the colored collection only", lines[:2])
+	}
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InstallMethod is how mbx was installed, which decides how it is updated
+type InstallMethod int
+
+const (
+	InstallScript   InstallMethod = iota // install.sh, or a downloaded release
+	InstallHomebrew                      // brew install
+	InstallGo                            // go install
+)
+
+// installMethod is set by packagers that know better than the executable's
+// path, with -ldflags "-X github.com/amureki/metabase-explorer/pkg/util.installMethod=homebrew"
+var installMethod = ""
+
+// goInstallCommand updates a binary built by go install
+const goInstallCommand = "go install github.com/amureki/metabase-explorer@latest"
+
+// UpdateCommand is the command that updates mbx installed this way
+func (m InstallMethod) UpdateCommand() string {
+	switch m {
+	case InstallHomebrew:
+		return "brew upgrade mbx"
+	case InstallGo:
+		return goInstallCommand
+	}
+	return installCommand
+}
+
+func (m InstallMethod) String() string {
+	switch m {
+	case InstallHomebrew:
+		return "Homebrew"
+	case InstallGo:
+		return "go install"
+	}
+	return "the install script"
+}
+
+// DetectInstallMethod works out how the running mbx was installed
+func DetectInstallMethod() InstallMethod {
+	executable, err := os.Executable()
+	if err != nil {
+		return InstallScript
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	return installMethodOf(installMethod, executable, goBinDirs())
+}
+
+// installMethodOf reads the packaging flag, then the executable's path:
+// Homebrew keeps its packages in a Cellar, and go install writes to GOBIN or
+// the bin directory of GOPATH. Anything else is taken as the install script.
+func installMethodOf(flag, executable string, goBins []string) InstallMethod {
+	switch flag {
+	case "homebrew":
+		return InstallHomebrew
+	case "go":
+		return InstallGo
+	case "script":
+		return InstallScript
+	}
+
+	executable = filepath.ToSlash(executable)
+	if strings.Contains(executable, "/Cellar/") || strings.Contains(executable, "/.linuxbrew/") {
+		return InstallHomebrew
+	}
+	dir := filepath.Dir(filepath.FromSlash(executable))
+	for _, bin := range goBins {
+		if bin != "" && filepath.Clean(bin) == dir {
+			return InstallGo
+		}
+	}
+	return InstallScript
+}
+
+// goBinDirs are the directories go install may have written mbx to
+func goBinDirs() []string {
+	dirs := []string{os.Getenv("GOBIN")}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			gopath = filepath.Join(home, "go")
+		}
+	}
+	for _, path := range filepath.SplitList(gopath) {
+		dirs = append(dirs, filepath.Join(path, "bin"))
+	}
+	return dirs
+}
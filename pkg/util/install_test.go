@@ -0,0 +1,38 @@
+package util
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallMethodOf(t *testing.T) {
+	goBins := []string{"", filepath.FromSlash("/home/ada/go/bin")}
+	tests := []struct {
+		name       string
+		flag       string
+		executable string
+		want       InstallMethod
+		command    string
+	}{
+		{name: "homebrew on apple silicon", executable: "/opt/homebrew/Cellar/mbx/1.4.0/bin/mbx", want: InstallHomebrew, command: "brew upgrade mbx"},
+		{name: "homebrew on intel", executable: "/usr/local/Cellar/mbx/1.4.0/bin/mbx", want: InstallHomebrew, command: "brew upgrade mbx"},
+		{name: "linuxbrew", executable: "/home/linuxbrew/.linuxbrew/bin/mbx", want: InstallHomebrew, command: "brew upgrade mbx"},
+		{name: "go install", executable: "/home/ada/go/bin/metabase-explorer", want: InstallGo, command: goInstallCommand},
+		{name: "install script", executable: "/usr/local/bin/mbx", want: InstallScript, command: installCommand},
+		{name: "downloaded release", executable: "/home/ada/Downloads/mbx", want: InstallScript, command: installCommand},
+		{name: "packaging flag", flag: "homebrew", executable: "/usr/local/bin/mbx", want: InstallHomebrew, command: "brew upgrade mbx"},
+		{name: "flag for the script", flag: "script", executable: "/home/ada/go/bin/mbx", want: InstallScript, command: installCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := installMethodOf(tt.flag, filepath.FromSlash(tt.executable), goBins)
+			if got != tt.want {
+				t.Errorf("installMethodOf(%q) = %v, want %v", tt.executable, got, tt.want)
+			}
+			if command := got.UpdateCommand(); command != tt.command {
+				t.Errorf("UpdateCommand() = %q, want %q", command, tt.command)
+			}
+		})
+	}
+}
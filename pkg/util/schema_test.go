@@ -101,7 +101,7 @@ func TestExtractSchemas_Sorting(t *testing.T) {
 	}
 }
 
-func TestCompareVersions(t *testing.T) {
+func TestUpToDate(t *testing.T) {
 	tests := []struct {
 		name     string
 		current  string
@@ -138,13 +138,43 @@ func TestCompareVersions(t *testing.T) {
 			latest:   "1.0.0",
 			expected: true,
 		},
+		{
+			name:     "newer than the latest release",
+			current:  "v1.3.0",
+			latest:   "v1.2.9",
+			expected: true,
+		},
+		{
+			name:     "numbers compared as numbers",
+			current:  "v1.9.0",
+			latest:   "v1.10.0",
+			expected: false,
+		},
+		{
+			name:     "pre-release of the latest",
+			current:  "v1.2.0-rc.1",
+			latest:   "v1.2.0",
+			expected: false,
+		},
+		{
+			name:     "release after a pre-release",
+			current:  "v1.2.0",
+			latest:   "v1.2.0-rc.1",
+			expected: true,
+		},
+		{
+			name:     "not a semantic version",
+			current:  "nightly",
+			latest:   "v1.0.0",
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := compareVersions(tt.current, tt.latest)
+			result := UpToDate(tt.current, tt.latest)
 			if result != tt.expected {
-				t.Errorf("compareVersions(%s, %s) = %v, want %v", tt.current, tt.latest, result, tt.expected)
+				t.Errorf("UpToDate(%s, %s) = %v, want %v", tt.current, tt.latest, result, tt.expected)
 			}
 		})
 	}
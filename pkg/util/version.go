@@ -7,18 +7,47 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
-func getLatestVersion() (string, error) {
-	resp, err := http.Get("https://api.github.com/repos/amureki/metabase-explorer/releases/latest")
+// installCommand downloads and runs the install script for the latest release
+const installCommand = "curl -sSL https://raw.githubusercontent.com/amureki/metabase-explorer/main/install.sh | bash"
+
+// DefaultReleaseURL is the GitHub API endpoint of the latest release
+const DefaultReleaseURL = "https://api.github.com/repos/amureki/metabase-explorer/releases/latest"
+
+// ReleaseURL is where the latest release is looked up: MBX_RELEASE_URL when
+// it is set, else the configured URL, else GitHub. Forks and mirrors point it
+// at their own feed, answering like GitHub's with a tag_name.
+func ReleaseURL(configured string) string {
+	if url := strings.TrimSpace(os.Getenv("MBX_RELEASE_URL")); url != "" {
+		return url
+	}
+	if configured != "" {
+		return configured
+	}
+	return DefaultReleaseURL
+}
+
+// fetchLatestVersion looks up the latest release; replaced in tests
+var fetchLatestVersion = LatestVersion
+
+// detectInstallMethod works out how mbx was installed; replaced in tests
+var detectInstallMethod = DetectInstallMethod
+
+// LatestVersion looks up the tag of the latest release at releaseURL. The
+// client is the caller's, so the lookup goes through the same proxy as
+// Metabase requests.
+func LatestVersion(client *http.Client, releaseURL string) (string, error) {
+	resp, err := client.Get(releaseURL)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return "", fmt.Errorf("release check at %s returned status %d", releaseURL, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -37,26 +66,94 @@ func getLatestVersion() (string, error) {
 	return release.TagName, nil
 }
 
-func compareVersions(current, latest string) bool {
-	// Normalize versions by removing 'v' prefix
-	currentNorm := strings.TrimPrefix(current, "v")
-	latestNorm := strings.TrimPrefix(latest, "v")
+// UpToDate reports whether current is the latest release or newer.
+// Versions that aren't semantic versions count as up to date only when they
+// match latest, so a development build can always be updated.
+func UpToDate(current, latest string) bool {
+	currentVersion, okCurrent := parseVersion(current)
+	latestVersion, okLatest := parseVersion(latest)
+	if !okCurrent || !okLatest {
+		return strings.TrimPrefix(current, "v") == strings.TrimPrefix(latest, "v")
+	}
+	for i := range currentVersion.numbers {
+		if currentVersion.numbers[i] != latestVersion.numbers[i] {
+			return currentVersion.numbers[i] > latestVersion.numbers[i]
+		}
+	}
+	// A pre-release comes before the release of the same number
+	return !currentVersion.prerelease || latestVersion.prerelease
+}
 
-	// Handle dev version
-	if currentNorm == "dev" {
-		return false // Always allow update from dev version
+// version is a parsed semantic version, such as v1.2.3 or 1.2.0-rc.1
+type version struct {
+	numbers    [3]int
+	prerelease bool
+}
+
+// parseVersion reads a semantic version, with or without the v prefix;
+// missing minor and patch numbers are 0, and build metadata is ignored
+func parseVersion(s string) (version, bool) {
+	var v version
+	s, _, _ = strings.Cut(strings.TrimPrefix(s, "v"), "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+	v.prerelease = hasPre && pre != ""
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
+
+// UpdateDryRun reports what "mbx update" would do without changing anything.
+// It returns whether an update is available.
+func UpdateDryRun(w io.Writer, client *http.Client, releaseURL, currentVersion string) (bool, error) {
+	latestVersion, err := fetchLatestVersion(client, releaseURL)
+	if err != nil {
+		return false, err
 	}
 
-	// Simple string comparison for semantic versions
-	// This works for most cases like "1.2.3" vs "1.2.4"
-	return currentNorm == latestNorm
+	if UpToDate(currentVersion, latestVersion) {
+		fmt.Fprintf(w, "✓ Already up to date! Current version: %s\n", currentVersion)
+		return false, nil
+	}
+
+	fmt.Fprintf(w, "Update available: %s → %s\n", currentVersion, latestVersion)
+	if method := detectInstallMethod(); method != InstallScript {
+		fmt.Fprintf(w, "mbx was installed with %s, update it with:\n", method)
+		fmt.Fprintf(w, "    %s\n", method.UpdateCommand())
+		return true, nil
+	}
+	fmt.Fprintf(w, "Dry run, nothing was changed. 'mbx update' would run:\n")
+	fmt.Fprintf(w, "    %s\n", installCommand)
+	return true, nil
 }
 
-func HandleUpdateCommand(currentVersion string) {
+// HandleUpdateCommand updates mbx to the latest release, looking it up at
+// releaseURL with client. With dryRun it only reports the update, exiting
+// with 1 when one is available.
+func HandleUpdateCommand(client *http.Client, releaseURL, currentVersion string, dryRun bool) {
 	fmt.Println("Checking for updates...")
 
-	// Get the latest version from GitHub
-	latestVersion, err := getLatestVersion()
+	if dryRun {
+		available, err := UpdateDryRun(os.Stdout, client, releaseURL, currentVersion)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check for updates: %v\n", err)
+			os.Exit(2)
+		}
+		if available {
+			os.Exit(1)
+		}
+		return
+	}
+
+	latestVersion, err := fetchLatestVersion(client, releaseURL)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to check for updates: %v\n", err)
 		fmt.Fprintf(os.Stderr, "You can manually update by running:\n")
@@ -65,16 +162,23 @@ func HandleUpdateCommand(currentVersion string) {
 	}
 
 	// Compare with current version
-	if compareVersions(currentVersion, latestVersion) {
+	if UpToDate(currentVersion, latestVersion) {
 		fmt.Printf("✓ Already up to date! Current version: %s\n", currentVersion)
 		return
 	}
 
 	fmt.Printf("Update available: %s → %s\n", currentVersion, latestVersion)
+
+	// The install script would put a second binary next to a packaged one
+	if method := detectInstallMethod(); method != InstallScript {
+		fmt.Printf("mbx was installed with %s, update it with:\n", method)
+		fmt.Printf("    %s\n", method.UpdateCommand())
+		return
+	}
 	fmt.Println("Updating mbx to the latest version...")
 
 	// Download and execute the install script
-	cmd := exec.Command("bash", "-c", "curl -sSL https://raw.githubusercontent.com/amureki/metabase-explorer/main/install.sh | bash")
+	cmd := exec.Command("bash", "-c", installCommand)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -0,0 +1,151 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/amureki/metabase-explorer/pkg/api"
+)
+
+func TestUpdateDryRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       string
+		latest        string
+		method        InstallMethod
+		fetchErr      error
+		expectError   bool
+		wantAvailable bool
+		wantOutput    []string
+	}{
+		{
+			name:       "already current",
+			current:    "1.2.0",
+			latest:     "v1.2.0",
+			wantOutput: []string{"Already up to date! Current version: 1.2.0"},
+		},
+		{
+			name:          "development build",
+			current:       "dev",
+			latest:        "v1.2.0",
+			wantAvailable: true,
+			wantOutput:    []string{"Update available: dev → v1.2.0"},
+		},
+		{
+			name:          "update available",
+			current:       "v1.1.0",
+			latest:        "v1.2.0",
+			wantAvailable: true,
+			wantOutput:    []string{"Update available: v1.1.0 → v1.2.0", "nothing was changed", installCommand},
+		},
+		{
+			name:          "installed with Homebrew",
+			current:       "v1.1.0",
+			latest:        "v1.2.0",
+			method:        InstallHomebrew,
+			wantAvailable: true,
+			wantOutput:    []string{"installed with Homebrew", "brew upgrade mbx"},
+		},
+		{
+			name:        "release lookup fails",
+			current:     "v1.1.0",
+			fetchErr:    errors.New("GitHub API returned status 503"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := fetchLatestVersion
+			defer func() { fetchLatestVersion = original }()
+			fetchLatestVersion = func(*http.Client, string) (string, error) {
+				return tt.latest, tt.fetchErr
+			}
+			originalDetect := detectInstallMethod
+			defer func() { detectInstallMethod = originalDetect }()
+			detectInstallMethod = func() InstallMethod { return tt.method }
+
+			var out bytes.Buffer
+			available, err := UpdateDryRun(&out, http.DefaultClient, DefaultReleaseURL, tt.current)
+			if tt.expectError {
+				if err == nil {
+					t.Error("UpdateDryRun() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateDryRun() unexpected error = %v", err)
+			}
+			if available != tt.wantAvailable {
+				t.Errorf("UpdateDryRun() = %v, want %v", available, tt.wantAvailable)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("UpdateDryRun() output = %q, want it to contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestLatestVersionThroughProxy(t *testing.T) {
+	// The proxy answers for GitHub, which is never reachable here
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte(`{"tag_name": "v1.4.0"}`))
+	}))
+	defer proxy.Close()
+
+	releaseURL := "http://api.github.invalid/repos/amureki/metabase-explorer/releases/latest"
+	proxyURL, _ := url.Parse(proxy.URL)
+	latest, err := LatestVersion(&http.Client{Transport: api.NewTransport(proxyURL)}, releaseURL)
+	if err != nil {
+		t.Fatalf("LatestVersion() unexpected error = %v", err)
+	}
+	if latest != "v1.4.0" {
+		t.Errorf("LatestVersion() = %q, want v1.4.0", latest)
+	}
+	if proxied != releaseURL {
+		t.Errorf("proxy got %q, want the release lookup", proxied)
+	}
+}
+
+func TestReleaseURL(t *testing.T) {
+	mirror := "https://releases.example.com/mbx/latest.json"
+	t.Setenv("MBX_RELEASE_URL", "")
+	if got := ReleaseURL(""); got != DefaultReleaseURL {
+		t.Errorf("ReleaseURL() = %q, want GitHub", got)
+	}
+	if got := ReleaseURL(mirror); got != mirror {
+		t.Errorf("ReleaseURL(%q) = %q, want the configured feed", mirror, got)
+	}
+
+	t.Setenv("MBX_RELEASE_URL", "https://env.example.com/latest")
+	if got := ReleaseURL(mirror); got != "https://env.example.com/latest" {
+		t.Errorf("ReleaseURL() with MBX_RELEASE_URL = %q, want the environment to win", got)
+	}
+}
+
+func TestLatestVersion_ConfiguredURL(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		_, _ = w.Write([]byte(`{"tag_name": "v2.0.0-fork"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("MBX_RELEASE_URL", "")
+	latest, err := LatestVersion(http.DefaultClient, ReleaseURL(server.URL+"/fork/latest"))
+	if err != nil || latest != "v2.0.0-fork" {
+		t.Fatalf("LatestVersion() = %q, %v, want the fork's release", latest, err)
+	}
+	if requested != "/fork/latest" {
+		t.Errorf("requested %q, want the configured feed", requested)
+	}
+}